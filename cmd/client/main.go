@@ -0,0 +1,9 @@
+// Package main provides an entry point for the client application.
+package main
+
+import "github.com/mr-filatik/go-password-keeper/internal/client"
+
+// main starts the client application.
+func main() {
+	client.Run()
+}
@@ -3,6 +3,12 @@ package main
 
 import "github.com/mr-filatik/go-password-keeper/internal/server"
 
+// main starts the server application.
+//
+//	@securityDefinitions.apikey	BearerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				Access token in the "Bearer <token>" format.
 func main() {
 	server.Run()
 }
@@ -15,6 +15,3034 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/v1/account/2fa": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сообщает, требуется ли одноразовый код для входа и важных операций.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Состояние 2FA",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.TwoFactorStatusResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Генерирует секрет TOTP и otpauth:// URI для приложения-аутентификатора.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Подключение 2FA",
+                "parameters": [
+                    {
+                        "description": "Текущий пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.EnrollTwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.EnrollTwoFactorResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "invalid current password",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "two-factor authentication already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отключает 2FA, требует действующий одноразовый код.",
+                "tags": [
+                    "account"
+                ],
+                "summary": "Отключение 2FA",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Одноразовый код 2FA",
+                        "name": "X-Totp-Code",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "one-time code required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "two-factor authentication not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "too many invalid one-time codes",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/account/2fa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Включает 2FA после проверки кода из приложения-аутентификатора.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Подтверждение 2FA",
+                "parameters": [
+                    {
+                        "description": "Одноразовый код",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.ConfirmTwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid request or code",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "not enrolled or already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/account/audit-log": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает страницу событий безопасности аккаунта по времени.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Журнал аудита",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из предыдущего ответа",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 100, не более 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.AuditLogResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/account/password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заменяет пароль и обёрнутый ключ хранилища, отзывает другие сессии.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Смена мастер-пароля",
+                "parameters": [
+                    {
+                        "description": "Текущий и новый пароли",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.ChangePasswordRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Одноразовый код 2FA",
+                        "name": "X-Totp-Code",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.RevokeSessionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "invalid current password or one-time code",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "too many invalid one-time codes",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/account/recovery-codes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает число выданных и неиспользованных кодов.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Состояние кодов восстановления",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.RecoveryCodesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заменяет набор кодов восстановления, старые коды перестают работать.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Новые коды восстановления",
+                "parameters": [
+                    {
+                        "description": "Пароль и копии ключа",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.RegenerateRecoveryCodesRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Одноразовый код 2FA",
+                        "name": "X-Totp-Code",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "invalid current password or one-time code",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "too many invalid one-time codes",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/blobs/check": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сверяет ссылки на вложения с содержимым хранилища. Требует токен администратора.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Проверка хранилища вложений",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.BlobCheckResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "inconsistencies found",
+                        "schema": {
+                            "$ref": "#/definitions/http.BlobCheckResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/keys/rotation": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает состояние последней ротации. Требует токен администратора.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Состояние ротации мастер-ключа",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.KeyRotationResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Перечитывает ключи и перешифровывает ключи данных.\nТребует токен администратора.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Ротация мастер-ключа",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/http.KeyRotationResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "rotation in progress",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/login": {
+            "post": {
+                "description": "Проверяет учётные данные пользователя и выдаёт пару токенов доступа и обновления.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Вход пользователя",
+                "parameters": [
+                    {
+                        "description": "Учётные данные",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.CredentialsRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название клиента",
+                        "name": "X-Client-Name",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Версия клиента",
+                        "name": "X-Client-Version",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.LoginResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "one-time code required",
+                        "schema": {
+                            "$ref": "#/definitions/http.TwoFactorChallengeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/login/2fa": {
+            "post": {
+                "description": "Завершает вход по одноразовому коду 2FA и выдаёт пару токенов.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Вход с одноразовым кодом",
+                "parameters": [
+                    {
+                        "description": "Токен входа и код",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.LoginTwoFactorRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название клиента",
+                        "name": "X-Client-Name",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Версия клиента",
+                        "name": "X-Client-Version",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid challenge or code",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "too many invalid one-time codes",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отзывает текущую сессию. Её токены перестают действовать сразу.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Выход",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/prelogin": {
+            "post": {
+                "description": "Возвращает параметры вывода мастер-ключа для логина.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Параметры KDF",
+                "parameters": [
+                    {
+                        "description": "Логин",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.PreloginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.PreloginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/recovery": {
+            "post": {
+                "description": "Задаёт новый мастер-пароль по коду восстановления.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Восстановление доступа",
+                "parameters": [
+                    {
+                        "description": "Код и новые учётные данные",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.RecoverRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название клиента",
+                        "name": "X-Client-Name",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Версия клиента",
+                        "name": "X-Client-Version",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.RecoverResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid or used recovery code",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/recovery/prelogin": {
+            "post": {
+                "description": "Возвращает ключ хранилища, обёрнутый кодом восстановления.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Копия ключа для кода восстановления",
+                "parameters": [
+                    {
+                        "description": "Логин и идентификатор кода",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.RecoveryPreloginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.RecoveryPreloginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid or used recovery code",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/refresh": {
+            "post": {
+                "description": "Обменивает одноразовый токен обновления на новую пару токенов.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Обновление токенов",
+                "parameters": [
+                    {
+                        "description": "Токен обновления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.RefreshRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название клиента",
+                        "name": "X-Client-Name",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Версия клиента",
+                        "name": "X-Client-Version",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid or reused refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/register": {
+            "post": {
+                "description": "Создаёт учётную запись с обёрнутым на клиенте ключом хранилища.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Регистрация пользователя",
+                "parameters": [
+                    {
+                        "description": "Учётные данные и ключ хранилища",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/http.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "user already exists",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/conflicts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает страницу неразрешённых конфликтов офлайн-правок по времени обнаружения.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "conflicts"
+                ],
+                "summary": "Список конфликтов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из предыдущего ответа",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 100, не более 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.ConflictListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/conflicts/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает конфликт вместе с офлайн-правкой клиента.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "conflicts"
+                ],
+                "summary": "Получение конфликта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор конфликта",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.ConflictResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "conflict not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/conflicts/{id}/resolve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Оставляет версию сервера, клиента или объединённую версию.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "conflicts"
+                ],
+                "summary": "Разрешение конфликта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор конфликта",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag текущей ревизии, кроме resolution=server",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Выбранная версия",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.ResolveConflictRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "удалённый секрет создан заново",
+                        "schema": {
+                            "$ref": "#/definitions/http.SecretResponse"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "conflict or secret not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "secret has been modified",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Передаёт события изменения секретов пользователя в формате Server-Sent Events.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "secrets"
+                ],
+                "summary": "Поток изменений хранилища",
+                "responses": {
+                    "200": {
+                        "description": "поток событий, data каждого события",
+                        "schema": {
+                            "$ref": "#/definitions/http.VaultEventResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/folders": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создаёт папку с зашифрованным на клиенте именем.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Создание папки",
+                "parameters": [
+                    {
+                        "description": "Папка",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.FolderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/http.FolderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "folder not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/folders/tree": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает папки пользователя с секретами без содержимого.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Дерево папок",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.FolderTreeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/folders/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет папку с вложенными папками и перемещает их секреты в корзину.",
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Удаление папки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор папки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "folder not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/folders/{id}/move": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Перемещает папку с содержимым в другую папку или в корень.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Перемещение папки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор папки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новая родительская папка",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.MoveFolderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.FolderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "folder not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/folders/{id}/rename": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заменяет зашифрованное имя папки.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Переименование папки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор папки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новое имя",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.RenameFolderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.FolderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "folder not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/secrets": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает страницу секретов пользователя без содержимого с фильтрами и сортировкой.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "secrets"
+                ],
+                "summary": "Список секретов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из предыдущего ответа",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 100, не более 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "createdAt",
+                            "updatedAt"
+                        ],
+                        "type": "string",
+                        "default": "createdAt",
+                        "description": "Поле сортировки",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "Направление сортировки",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "credentials",
+                            "text",
+                            "binary",
+                            "card",
+                            "totp"
+                        ],
+                        "type": "string",
+                        "description": "Тип секрета",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Тег секрета",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор папки",
+                        "name": "folderId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Изменённые не раньше этого времени (RFC 3339)",
+                        "name": "updatedSince",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SecretListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создаёт секрет с зашифрованным на клиенте содержимым.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "secrets"
+                ],
+                "summary": "Создание секрета",
+                "parameters": [
+                    {
+                        "description": "Секрет",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.SecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/http.SecretResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Номер ревизии секрета"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/secrets/search": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает секреты пользователя без содержимого со всеми указанными индексами.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "secrets"
+                ],
+                "summary": "Поиск секретов по слепым индексам",
+                "parameters": [
+                    {
+                        "description": "Слепые индексы, вычисленные на клиенте",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.SearchSecretsRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из предыдущего ответа",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 100, не более 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "createdAt",
+                            "updatedAt"
+                        ],
+                        "type": "string",
+                        "default": "createdAt",
+                        "description": "Поле сортировки",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "Направление сортировки",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SecretListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/secrets/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает секрет пользователя вместе с зашифрованным содержимым.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "secrets"
+                ],
+                "summary": "Получение секрета",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор секрета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SecretResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Номер ревизии секрета"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "secret not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заменяет секрет, если он не изменился с ревизии из If-Match.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "secrets"
+                ],
+                "summary": "Изменение секрета",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор секрета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag ожидаемой ревизии или *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Секрет",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.SecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SecretResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Номер ревизии секрета"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "secret not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "secret has been modified",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Перемещает секрет в корзину, если он не изменился с ревизии из If-Match.",
+                "tags": [
+                    "secrets"
+                ],
+                "summary": "Удаление секрета",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор секрета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag ожидаемой ревизии или *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "secret not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "secret has been modified",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/secrets/{id}/blob": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Потоково отдаёт зашифрованное на клиенте содержимое бинарного секрета.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "secrets"
+                ],
+                "summary": "Скачивание бинарного секрета",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор секрета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Номер ревизии секрета"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "secret or payload not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/secrets/{id}/move": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Перемещает секрет в папку, если он не изменился с ревизии из If-Match.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Перемещение секрета",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор секрета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag ожидаемой ревизии или *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Новая папка, пустая для корня",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.MoveSecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SecretResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Номер ревизии секрета"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "secret or folder not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "secret has been modified",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/secrets/{id}/push": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Применяет правку или сохраняет конфликт, даже если секрет удалён.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "conflicts"
+                ],
+                "summary": "Отправка офлайн-правки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор секрета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Правка и её базовая ревизия",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.PushSecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SecretResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Номер ревизии секрета"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/http.ConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/secrets/{id}/versions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает предыдущие ревизии секрета, новые первыми.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "secrets"
+                ],
+                "summary": "История секрета",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор секрета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SecretVersionListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "secret not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/secrets/{id}/versions/{rev}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Делает предыдущую ревизию текущей, заменённая остаётся в истории.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "secrets"
+                ],
+                "summary": "Восстановление ревизии",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор секрета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер ревизии",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag ожидаемой текущей ревизии или *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SecretResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Номер ревизии секрета"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "secret or revision not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "secret has been modified",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает страницу активных сессий (устройств) пользователя по времени входа.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Список сессий",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из предыдущего ответа",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 100, не более 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SessionListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sessions/others": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отзывает все сессии пользователя, кроме текущей.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Отзыв остальных сессий",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Одноразовый код 2FA",
+                        "name": "X-Totp-Code",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.RevokeSessionsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "one-time code required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "too many invalid one-time codes",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отзывает сессию пользователя. Её токены перестают действовать сразу.",
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Отзыв сессии",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор сессии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Одноразовый код 2FA",
+                        "name": "X-Totp-Code",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "one-time code required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "session not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "too many invalid one-time codes",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sync": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает секреты, созданные, изменённые или удалённые после курсора.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "secrets"
+                ],
+                "summary": "Синхронизация хранилища",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Курсор предыдущего ответа, пустой для полной выгрузки",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальное число изменений (по умолчанию 100, не более 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SyncResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid cursor or limit",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает страницу удалённых секретов пользователя, недавно удалённые первыми.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Корзина",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из предыдущего ответа",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 100, не более 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.TrashListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/trash/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает удалённый секрет вместе с историей из корзины.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Восстановление из корзины",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор секрета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SecretResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Номер ревизии секрета"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "secret not found in trash",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/uploads": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Начинает возобновляемую загрузку большого содержимого двоичного секрета.",
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Создание загрузки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Версия протокола tus (1.0.0)",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер содержимого в байтах",
+                        "name": "Upload-Length",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Метаданные: пары ключа и значения base64",
+                        "name": "Upload-Metadata",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created"
+                    },
+                    "400": {
+                        "description": "invalid Upload-Length or Upload-Metadata",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "unsupported version of the tus protocol",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "upload too large",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "options": {
+                "description": "Возвращает версию и расширения протокола tus и максимальный размер загрузки.",
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Параметры загрузок",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/api/v1/uploads/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет загрузку вместе с полученными байтами.",
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Отмена загрузки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор загрузки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Версия протокола tus (1.0.0)",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "upload not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "unsupported version of the tus protocol",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "head": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает число полученных байтов, с которого продолжается загрузка.",
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Состояние загрузки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор загрузки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Версия протокола tus (1.0.0)",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "upload not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "unsupported version of the tus protocol",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Дописывает часть содержимого с позиции Upload-Offset.",
+                "consumes": [
+                    "application/offset+octet-stream"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Отправка части загрузки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор загрузки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Версия протокола tus (1.0.0)",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Число уже полученных сервером байтов",
+                        "name": "Upload-Offset",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid Upload-Offset",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "upload not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "offset does not match the received content",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "unsupported version of the tus protocol",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "chunk exceeds the upload length",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "unsupported content type",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/ping": {
             "get": {
                 "description": "Возвращает \"pong\" для проверки доступности сервиса.",
@@ -33,7 +3061,7 @@ const docTemplate = `{
                         }
                     },
                     "405": {
-                        "description": "method not allowed\"   // если validateRequestMethod отдаёт 405",
+                        "description": "method not allowed",
                         "schema": {
                             "type": "string"
                         }
@@ -47,6 +3075,1358 @@ const docTemplate = `{
                 }
             }
         }
+    },
+    "definitions": {
+        "crypto.BlindIndex": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "Field the index is computed for.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/crypto.BlindIndexField"
+                        }
+                    ]
+                },
+                "token": {
+                    "description": "HMAC-SHA256 of the normalized value (base64url without padding).",
+                    "type": "string"
+                }
+            }
+        },
+        "crypto.BlindIndexField": {
+            "type": "string",
+            "enum": [
+                "domain",
+                "username",
+                "tag"
+            ],
+            "x-enum-comments": {
+                "BlindIndexDomain": "Domain of the site the secret is used on.",
+                "BlindIndexTag": "Label the user groups secrets by.",
+                "BlindIndexUsername": "Login of the account."
+            },
+            "x-enum-descriptions": [
+                "Domain of the site the secret is used on.",
+                "Login of the account.",
+                "Label the user groups secrets by."
+            ],
+            "x-enum-varnames": [
+                "BlindIndexDomain",
+                "BlindIndexUsername",
+                "BlindIndexTag"
+            ]
+        },
+        "crypto.KDFParams": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
+                    "description": "Key derivation function.",
+                    "type": "string"
+                },
+                "iterations": {
+                    "description": "Number of iterations over the memory.",
+                    "type": "integer"
+                },
+                "memory": {
+                    "description": "Amount of memory (in kibibytes).",
+                    "type": "integer"
+                },
+                "parallelism": {
+                    "description": "Number of threads.",
+                    "type": "integer"
+                },
+                "salt": {
+                    "description": "Random salt of the user.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "crypto.RecoveryKey": {
+            "type": "object",
+            "properties": {
+                "authPassword": {
+                    "description": "Authentication password derived from the code.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Identifier derived from the recovery code.",
+                    "type": "string"
+                },
+                "vaultKey": {
+                    "description": "Vault key wrapped with the key derived from the code.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/crypto.WrappedVaultKey"
+                        }
+                    ]
+                }
+            }
+        },
+        "crypto.WrappedVaultKey": {
+            "type": "object",
+            "properties": {
+                "kdf": {
+                    "description": "Key derivation parameters.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/crypto.KDFParams"
+                        }
+                    ]
+                },
+                "wrappedKey": {
+                    "description": "Encrypted vault key.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "http.AuditEventResponse": {
+            "type": "object",
+            "properties": {
+                "clientName": {
+                    "description": "Client application name.",
+                    "type": "string"
+                },
+                "clientVersion": {
+                    "description": "Client application version.",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "Event time.",
+                    "type": "string"
+                },
+                "details": {
+                    "description": "Additional event attributes.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "description": "Event identifier.",
+                    "type": "string"
+                },
+                "ip": {
+                    "description": "Client IP address.",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Event type.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.AuditLogResponse": {
+            "type": "object",
+            "properties": {
+                "cursor": {
+                    "description": "Opaque cursor of the next page, omitted on the last page.",
+                    "type": "string"
+                },
+                "hasMore": {
+                    "description": "More events are available with the cursor.",
+                    "type": "boolean"
+                },
+                "items": {
+                    "description": "Events sorted by time.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.AuditEventResponse"
+                    }
+                }
+            }
+        },
+        "http.BlobCheckResponse": {
+            "type": "object",
+            "properties": {
+                "blobs": {
+                    "description": "Number of blobs in the storage.",
+                    "type": "integer"
+                },
+                "consistent": {
+                    "description": "No inconsistencies are found.",
+                    "type": "boolean"
+                },
+                "dangling": {
+                    "description": "References to missing blobs.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.DanglingBlobReference"
+                    }
+                },
+                "references": {
+                    "description": "Number of revisions referring to blobs.",
+                    "type": "integer"
+                },
+                "unreferenced": {
+                    "description": "Blobs no revision refers to.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.UnreferencedBlob"
+                    }
+                }
+            }
+        },
+        "http.ChangePasswordRequest": {
+            "type": "object",
+            "properties": {
+                "currentPassword": {
+                    "description": "Current authentication password.",
+                    "type": "string"
+                },
+                "newPassword": {
+                    "description": "New authentication password.",
+                    "type": "string"
+                },
+                "vaultKey": {
+                    "description": "Vault key wrapped with the new master password.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/crypto.WrappedVaultKey"
+                        }
+                    ]
+                }
+            }
+        },
+        "http.ConfirmTwoFactorRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "One-time code from the authenticator.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.ConflictListResponse": {
+            "type": "object",
+            "properties": {
+                "cursor": {
+                    "description": "Opaque cursor of the next page, omitted on the last page.",
+                    "type": "string"
+                },
+                "hasMore": {
+                    "description": "More conflicts are available with the cursor.",
+                    "type": "boolean"
+                },
+                "items": {
+                    "description": "Conflicts sorted by detection time.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.ConflictResponse"
+                    }
+                }
+            }
+        },
+        "http.ConflictResponse": {
+            "type": "object",
+            "properties": {
+                "baseRevision": {
+                    "description": "Revision the client edit is based on.",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "description": "Time the conflict was detected.",
+                    "type": "string"
+                },
+                "data": {
+                    "description": "Payload of the client edit (base64).",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "id": {
+                    "description": "Conflict identifier.",
+                    "type": "string"
+                },
+                "indexes": {
+                    "description": "Indexes - blind indexes of the client edit.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/crypto.BlindIndex"
+                    }
+                },
+                "metadata": {
+                    "description": "Metadata of the client edit.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "secretId": {
+                    "description": "Identifier of the conflicting secret.",
+                    "type": "string"
+                },
+                "serverDeleted": {
+                    "description": "The secret has been deleted on the server.",
+                    "type": "boolean"
+                },
+                "serverRevision": {
+                    "description": "Revision of the secret at detection time.",
+                    "type": "integer"
+                },
+                "tags": {
+                    "description": "Tags of the client edit.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "description": "Kind of secret of the client edit.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/secret.Type"
+                        }
+                    ]
+                }
+            }
+        },
+        "http.CredentialsRequest": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "description": "User login.",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "Authentication password derived from the master password.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.DanglingBlobReference": {
+            "type": "object",
+            "properties": {
+                "blobId": {
+                    "description": "Identifier of the missing blob.",
+                    "type": "string"
+                },
+                "revision": {
+                    "description": "Number of the revision, current or from the history.",
+                    "type": "integer"
+                },
+                "secretId": {
+                    "description": "Secret identifier.",
+                    "type": "string"
+                },
+                "userId": {
+                    "description": "Identifier of the owner of the secret.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.EnrollTwoFactorRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "description": "Current authentication password.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.EnrollTwoFactorResponse": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "description": "Shared secret in the base32 form for manual entry.",
+                    "type": "string"
+                },
+                "uri": {
+                    "description": "otpauth:// URI for the QR code.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Machine-readable error code.",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "Human-readable error description.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.FolderRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "Name encrypted on the client (base64).",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "parentId": {
+                    "description": "Parent folder, empty for the top level.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.FolderResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "Creation time.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Folder identifier.",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name encrypted on the client (base64).",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "parentId": {
+                    "description": "Parent folder, omitted at the top level.",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "Time of the last rename or move.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.FolderTreeResponse": {
+            "type": "object",
+            "properties": {
+                "folder": {
+                    "description": "Folder, omitted for the top level of the vault.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/http.FolderResponse"
+                        }
+                    ]
+                },
+                "folders": {
+                    "description": "Subfolders sorted by creation time.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.FolderTreeResponse"
+                    }
+                },
+                "secrets": {
+                    "description": "Secrets without payload sorted by creation time.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.SecretResponse"
+                    }
+                }
+            }
+        },
+        "http.KeyRotationResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error the rotation has stopped with.",
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "description": "Completion time, omitted while in progress.",
+                    "type": "string"
+                },
+                "found": {
+                    "description": "Data keys wrapped with old master keys.",
+                    "type": "integer"
+                },
+                "rewrapped": {
+                    "description": "Data keys re-wrapped with the current master key.",
+                    "type": "integer"
+                },
+                "running": {
+                    "description": "The rotation is in progress.",
+                    "type": "boolean"
+                },
+                "skipped": {
+                    "description": "Data keys changed concurrently and skipped.",
+                    "type": "integer"
+                },
+                "startedAt": {
+                    "description": "Start time, omitted if no rotation has been started.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "description": "Signed access token.",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "Expiration time of the access token.",
+                    "type": "string"
+                },
+                "refreshExpiresAt": {
+                    "description": "Expiration time of the refresh token.",
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "description": "Opaque single-use refresh token.",
+                    "type": "string"
+                },
+                "sessionId": {
+                    "description": "Identifier of the session.",
+                    "type": "string"
+                },
+                "tokenType": {
+                    "description": "Token type for the Authorization header.",
+                    "type": "string"
+                },
+                "user": {
+                    "description": "Authenticated user.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/http.UserResponse"
+                        }
+                    ]
+                },
+                "vaultKey": {
+                    "description": "Vault key wrapped on the client.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/crypto.WrappedVaultKey"
+                        }
+                    ]
+                }
+            }
+        },
+        "http.LoginTwoFactorRequest": {
+            "type": "object",
+            "properties": {
+                "challengeToken": {
+                    "description": "Token of the login challenge.",
+                    "type": "string"
+                },
+                "code": {
+                    "description": "One-time code from the authenticator.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.MoveFolderRequest": {
+            "type": "object",
+            "properties": {
+                "parentId": {
+                    "description": "New parent folder, empty for the top level.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.MoveSecretRequest": {
+            "type": "object",
+            "properties": {
+                "folderId": {
+                    "description": "New folder of the secret, empty for the top level.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.PreloginRequest": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "description": "User login.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.PreloginResponse": {
+            "type": "object",
+            "properties": {
+                "kdf": {
+                    "description": "Parameters for deriving keys from the master password.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/crypto.KDFParams"
+                        }
+                    ]
+                }
+            }
+        },
+        "http.PushSecretRequest": {
+            "type": "object",
+            "properties": {
+                "baseRevision": {
+                    "description": "Revision the edit is based on.",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "Payload encrypted on the client (base64).",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "indexes": {
+                    "description": "Indexes - blind indexes of the fields of the secret the search finds it by.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/crypto.BlindIndex"
+                    }
+                },
+                "metadata": {
+                    "description": "Free-form metadata.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "tags": {
+                    "description": "Labels the secret is grouped by.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "description": "Kind of secret.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/secret.Type"
+                        }
+                    ]
+                }
+            }
+        },
+        "http.RecoverRequest": {
+            "type": "object",
+            "properties": {
+                "codeId": {
+                    "description": "Identifier derived from the recovery code.",
+                    "type": "string"
+                },
+                "codePassword": {
+                    "description": "Authentication password derived from the code.",
+                    "type": "string"
+                },
+                "login": {
+                    "description": "User login.",
+                    "type": "string"
+                },
+                "newPassword": {
+                    "description": "New authentication password.",
+                    "type": "string"
+                },
+                "vaultKey": {
+                    "description": "Vault key wrapped with the new master password.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/crypto.WrappedVaultKey"
+                        }
+                    ]
+                }
+            }
+        },
+        "http.RecoverResponse": {
+            "type": "object",
+            "properties": {
+                "remainingCodes": {
+                    "description": "Number of unused recovery codes left.",
+                    "type": "integer"
+                },
+                "revokedSessions": {
+                    "description": "Number of revoked sessions.",
+                    "type": "integer"
+                }
+            }
+        },
+        "http.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "Generation time of the set.",
+                    "type": "string"
+                },
+                "remaining": {
+                    "description": "Number of unused codes.",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Number of codes in the set.",
+                    "type": "integer"
+                }
+            }
+        },
+        "http.RecoveryPreloginRequest": {
+            "type": "object",
+            "properties": {
+                "codeId": {
+                    "description": "Identifier derived from the recovery code.",
+                    "type": "string"
+                },
+                "login": {
+                    "description": "User login.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.RecoveryPreloginResponse": {
+            "type": "object",
+            "properties": {
+                "vaultKey": {
+                    "description": "Vault key wrapped with the key derived from the code.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/crypto.WrappedVaultKey"
+                        }
+                    ]
+                }
+            }
+        },
+        "http.RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refreshToken": {
+                    "description": "Refresh token received earlier.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.RegenerateRecoveryCodesRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "description": "Current authentication password.",
+                    "type": "string"
+                },
+                "recoveryKeys": {
+                    "description": "Vault key copies wrapped with the new codes.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/crypto.RecoveryKey"
+                    }
+                }
+            }
+        },
+        "http.RegisterRequest": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "description": "User login.",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "Authentication password.",
+                    "type": "string"
+                },
+                "recoveryKeys": {
+                    "description": "Vault key copies wrapped with recovery codes.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/crypto.RecoveryKey"
+                    }
+                },
+                "vaultKey": {
+                    "description": "Vault key wrapped on the client.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/crypto.WrappedVaultKey"
+                        }
+                    ]
+                }
+            }
+        },
+        "http.RenameFolderRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "New name encrypted on the client (base64).",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "http.ResolveConflictRequest": {
+            "type": "object",
+            "properties": {
+                "merged": {
+                    "description": "Merged content, only for the merged resolution.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/http.SecretRequest"
+                        }
+                    ]
+                },
+                "resolution": {
+                    "description": "Version to keep: server, client or merged.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Resolution"
+                        }
+                    ]
+                }
+            }
+        },
+        "http.RevokeSessionsResponse": {
+            "type": "object",
+            "properties": {
+                "revoked": {
+                    "description": "Number of revoked sessions.",
+                    "type": "integer"
+                }
+            }
+        },
+        "http.SearchSecretsRequest": {
+            "type": "object",
+            "properties": {
+                "indexes": {
+                    "description": "Blind indexes the secrets must have, all of them.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/crypto.BlindIndex"
+                    }
+                }
+            }
+        },
+        "http.SecretListResponse": {
+            "type": "object",
+            "properties": {
+                "cursor": {
+                    "description": "Opaque cursor of the next page, omitted on the last page.",
+                    "type": "string"
+                },
+                "hasMore": {
+                    "description": "More secrets are available with the cursor.",
+                    "type": "boolean"
+                },
+                "items": {
+                    "description": "Secrets without payload.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.SecretResponse"
+                    }
+                }
+            }
+        },
+        "http.SecretRequest": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Payload encrypted on the client (base64).",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "folderId": {
+                    "description": "Folder of a new secret, updates keep the folder.",
+                    "type": "string"
+                },
+                "indexes": {
+                    "description": "Indexes - blind indexes of the fields of the secret the search finds it by.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/crypto.BlindIndex"
+                    }
+                },
+                "metadata": {
+                    "description": "Free-form metadata, cards only masked.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "tags": {
+                    "description": "Labels the secrets are grouped by.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "description": "Kind of secret.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/secret.Type"
+                        }
+                    ]
+                },
+                "uploadId": {
+                    "description": "Completed upload used as the binary payload.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.SecretResponse": {
+            "type": "object",
+            "properties": {
+                "blobSize": {
+                    "description": "Size of the binary payload served by /blob.",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "description": "Creation time.",
+                    "type": "string"
+                },
+                "data": {
+                    "description": "Encrypted payload (base64), omitted in lists.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "folderId": {
+                    "description": "Folder of the secret, omitted at the top level.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Secret identifier.",
+                    "type": "string"
+                },
+                "indexes": {
+                    "description": "Indexes - blind indexes of the fields of the secret, sorted by field and token.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/crypto.BlindIndex"
+                    }
+                },
+                "metadata": {
+                    "description": "Free-form metadata.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "revision": {
+                    "description": "Number of the current revision.",
+                    "type": "integer"
+                },
+                "tags": {
+                    "description": "Labels the secret is grouped by, sorted.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "description": "Kind of secret.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/secret.Type"
+                        }
+                    ]
+                },
+                "updatedAt": {
+                    "description": "Last modification time.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.SecretVersionListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "Previous revisions, the newest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.SecretVersionResponse"
+                    }
+                }
+            }
+        },
+        "http.SecretVersionResponse": {
+            "type": "object",
+            "properties": {
+                "archivedAt": {
+                    "description": "Time the revision was replaced.",
+                    "type": "string"
+                },
+                "data": {
+                    "description": "Encrypted payload (base64).",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "indexes": {
+                    "description": "Indexes - blind indexes of the fields of the revision, sorted by field and token.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/crypto.BlindIndex"
+                    }
+                },
+                "metadata": {
+                    "description": "Free-form metadata.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "revision": {
+                    "description": "Number of the revision.",
+                    "type": "integer"
+                },
+                "tags": {
+                    "description": "Labels the secret is grouped by, sorted.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "description": "Kind of secret.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/secret.Type"
+                        }
+                    ]
+                },
+                "updatedAt": {
+                    "description": "Time the revision was written.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.SessionListResponse": {
+            "type": "object",
+            "properties": {
+                "cursor": {
+                    "description": "Opaque cursor of the next page, omitted on the last page.",
+                    "type": "string"
+                },
+                "hasMore": {
+                    "description": "More sessions are available with the cursor.",
+                    "type": "boolean"
+                },
+                "items": {
+                    "description": "Active sessions.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.SessionResponse"
+                    }
+                }
+            }
+        },
+        "http.SessionResponse": {
+            "type": "object",
+            "properties": {
+                "clientName": {
+                    "description": "Client application name.",
+                    "type": "string"
+                },
+                "clientVersion": {
+                    "description": "Client application version.",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "Login time.",
+                    "type": "string"
+                },
+                "current": {
+                    "description": "The request was made from this session.",
+                    "type": "boolean"
+                },
+                "expiresAt": {
+                    "description": "Expiration time if tokens are not refreshed.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Session identifier.",
+                    "type": "string"
+                },
+                "ip": {
+                    "description": "Client IP address.",
+                    "type": "string"
+                },
+                "lastSeenAt": {
+                    "description": "Time of the last login or token refresh.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.SyncChangeResponse": {
+            "type": "object",
+            "properties": {
+                "changedAt": {
+                    "description": "Time of the change.",
+                    "type": "string"
+                },
+                "deleted": {
+                    "description": "The secret has been deleted (tombstone).",
+                    "type": "boolean"
+                },
+                "revision": {
+                    "description": "Revision written by the change.",
+                    "type": "integer"
+                },
+                "secret": {
+                    "description": "Current state of the secret, omitted for tombstones.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/http.SecretResponse"
+                        }
+                    ]
+                },
+                "secretId": {
+                    "description": "Secret identifier.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.SyncResponse": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "description": "Changes in the order they must be applied.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.SyncChangeResponse"
+                    }
+                },
+                "cursor": {
+                    "description": "Opaque cursor of the next request.",
+                    "type": "string"
+                },
+                "hasMore": {
+                    "description": "More changes are available right away.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "http.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "description": "Signed access token.",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "Expiration time of the access token.",
+                    "type": "string"
+                },
+                "refreshExpiresAt": {
+                    "description": "Expiration time of the refresh token.",
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "description": "Opaque single-use refresh token.",
+                    "type": "string"
+                },
+                "sessionId": {
+                    "description": "Identifier of the session.",
+                    "type": "string"
+                },
+                "tokenType": {
+                    "description": "Token type for the Authorization header.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.TrashListResponse": {
+            "type": "object",
+            "properties": {
+                "cursor": {
+                    "description": "Opaque cursor of the next page, omitted on the last page.",
+                    "type": "string"
+                },
+                "hasMore": {
+                    "description": "More secrets are available with the cursor.",
+                    "type": "boolean"
+                },
+                "items": {
+                    "description": "Deleted secrets without payload, the latest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.TrashedSecretResponse"
+                    }
+                }
+            }
+        },
+        "http.TrashedSecretResponse": {
+            "type": "object",
+            "properties": {
+                "blobSize": {
+                    "description": "Size of the binary payload.",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "description": "Creation time.",
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "description": "Time the secret was moved to the trash.",
+                    "type": "string"
+                },
+                "folderId": {
+                    "description": "Folder the secret was in.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Secret identifier.",
+                    "type": "string"
+                },
+                "metadata": {
+                    "description": "Free-form metadata.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "purgeAt": {
+                    "description": "Time the secret is deleted permanently.",
+                    "type": "string"
+                },
+                "revision": {
+                    "description": "Number of the last revision.",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "Kind of secret.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/secret.Type"
+                        }
+                    ]
+                },
+                "updatedAt": {
+                    "description": "Last modification time.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.TwoFactorChallengeResponse": {
+            "type": "object",
+            "properties": {
+                "challengeToken": {
+                    "description": "Token for completing the login with the code.",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "Expiration time of the challenge.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.TwoFactorStatusResponse": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "description": "Whether the one-time code is required.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "http.UnreferencedBlob": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "Identifier of the blob.",
+                    "type": "string"
+                },
+                "savedAt": {
+                    "description": "Time the content was saved.",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "Size of the content in bytes.",
+                    "type": "integer"
+                }
+            }
+        },
+        "http.UserResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "Registration time.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "User identifier.",
+                    "type": "string"
+                },
+                "login": {
+                    "description": "User login.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.VaultEventResponse": {
+            "type": "object",
+            "properties": {
+                "occurredAt": {
+                    "description": "Time of the change.",
+                    "type": "string"
+                },
+                "revision": {
+                    "description": "Revision written by the change.",
+                    "type": "integer"
+                },
+                "secretId": {
+                    "description": "Identifier of the changed secret.",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Event type, repeated in the event field.",
+                    "type": "string"
+                }
+            }
+        },
+        "secret.Type": {
+            "type": "string",
+            "enum": [
+                "credentials",
+                "text",
+                "binary",
+                "card",
+                "totp"
+            ],
+            "x-enum-varnames": [
+                "TypeCredentials",
+                "TypeText",
+                "TypeBinary",
+                "TypeCard",
+                "TypeTOTP"
+            ]
+        },
+        "service.Resolution": {
+            "type": "string",
+            "enum": [
+                "server",
+                "client",
+                "merged"
+            ],
+            "x-enum-comments": {
+                "ResolutionClient": "Replace the current revision with the client edit.",
+                "ResolutionMerged": "Replace the current revision with the merged content.",
+                "ResolutionServer": "Keep the current revision, discard the client edit."
+            },
+            "x-enum-descriptions": [
+                "Keep the current revision, discard the client edit.",
+                "Replace the current revision with the client edit.",
+                "Replace the current revision with the merged content."
+            ],
+            "x-enum-varnames": [
+                "ResolutionServer",
+                "ResolutionClient",
+                "ResolutionMerged"
+            ]
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Access token in the \"Bearer \u003ctoken\u003e\" format.",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -0,0 +1,293 @@
+// Package client provides the command-line client of the password keeper.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+)
+
+// Errors returned by the client.
+var (
+	// ErrRequestFailed - the server rejected the request.
+	ErrRequestFailed = errors.New("request failed")
+
+	// ErrTwoFactorRequired - the login requires the one-time code from the authenticator.
+	ErrTwoFactorRequired = errors.New("two-factor code required")
+
+	// ErrNotLoggedIn - the request requires the login.
+	ErrNotLoggedIn = errors.New("not logged in")
+)
+
+// clientName - name of the client sent to the server, shown in the list of the user's sessions.
+const clientName = "go-password-keeper-cli"
+
+type preloginRequest struct {
+	Login string `json:"login"`
+}
+
+type preloginResponse struct {
+	KDF crypto.KDFParams `json:"kdf"`
+}
+
+type credentialsRequest struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+type loginTwoFactorRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+}
+
+// loginResponse describes both the completed login and the login waiting for the one-time code.
+type loginResponse struct {
+	AccessToken    string                 `json:"accessToken"`
+	VaultKey       crypto.WrappedVaultKey `json:"vaultKey"`
+	ChallengeToken string                 `json:"challengeToken"`
+}
+
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// SecretRequest describes the body of the secret creation request.
+type SecretRequest struct {
+	Type     secret.Type       `json:"type"`     // Kind of secret.
+	Metadata map[string]string `json:"metadata"` // Free-form metadata.
+	Data     []byte            `json:"data"`     // Payload encrypted on the client.
+}
+
+// SecretResponse describes the secret returned by the server.
+type SecretResponse struct {
+	ID       string            `json:"id"`       // Secret identifier.
+	Type     secret.Type       `json:"type"`     // Kind of secret.
+	Metadata map[string]string `json:"metadata"` // Free-form metadata.
+	Data     []byte            `json:"data"`     // Encrypted payload.
+}
+
+// Client - client of the password keeper server.
+//
+// The vault key is unwrapped on login and kept only in memory of the process.
+type Client struct {
+	conf        *Config
+	httpClient  *http.Client
+	accessToken string
+	vault       *crypto.Vault
+}
+
+// NewClient creates a new *Client instance.
+//
+// Parameters:
+//   - conf *Config: client configuration;
+//   - httpClient *http.Client: HTTP client of the requests to the server.
+func NewClient(conf *Config, httpClient *http.Client) *Client {
+	return &Client{
+		conf:        conf,
+		httpClient:  httpClient,
+		accessToken: "",
+		vault:       nil,
+	}
+}
+
+// Login logs the user in with the master password and unwraps the vault key.
+//
+// Parameters:
+//   - ctx context.Context: context.
+func (c *Client) Login(ctx context.Context) error {
+	var prelogin preloginResponse
+
+	err := c.do(ctx, http.MethodPost, "/api/v1/auth/prelogin",
+		preloginRequest{Login: c.conf.Login}, &prelogin)
+	if err != nil {
+		return err
+	}
+
+	keys, err := crypto.DeriveMasterKeys(c.conf.MasterPassword, prelogin.KDF)
+	if err != nil {
+		return fmt.Errorf("derive master keys: %w", err)
+	}
+
+	var login loginResponse
+
+	err = c.do(ctx, http.MethodPost, "/api/v1/auth/login",
+		credentialsRequest{Login: c.conf.Login, Password: keys.AuthPassword()}, &login)
+	if err != nil {
+		return err
+	}
+
+	if login.ChallengeToken != "" {
+		if c.conf.TwoFactorCode == "" {
+			return fmt.Errorf("%w: pass it with -%s", ErrTwoFactorRequired, flagNameTwoFactorCode)
+		}
+
+		err = c.do(ctx, http.MethodPost, "/api/v1/auth/login/2fa", loginTwoFactorRequest{
+			ChallengeToken: login.ChallengeToken,
+			Code:           c.conf.TwoFactorCode,
+		}, &login)
+		if err != nil {
+			return err
+		}
+	}
+
+	vaultKey, err := keys.UnwrapVaultKey(&login.VaultKey)
+	if err != nil {
+		return fmt.Errorf("unwrap vault key: %w", err)
+	}
+
+	c.vault, err = crypto.NewVault(vaultKey)
+	if err != nil {
+		return fmt.Errorf("open vault: %w", err)
+	}
+
+	c.accessToken = login.AccessToken
+
+	return nil
+}
+
+// Logout revokes the session of the login on the server and forgets the vault key.
+//
+// Does nothing if the client has not logged in.
+//
+// Parameters:
+//   - ctx context.Context: context.
+func (c *Client) Logout(ctx context.Context) error {
+	if c.accessToken == "" {
+		return nil
+	}
+
+	err := c.do(ctx, http.MethodPost, "/api/v1/auth/logout", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	c.accessToken = ""
+	c.vault = nil
+
+	return nil
+}
+
+// GetSecret returns the user's secret with the decrypted payload.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - secretID string: secret identifier.
+//
+//nolint:ireturn // the kind of payload is determined at runtime
+func (c *Client) GetSecret(ctx context.Context, secretID string) (secret.Payload, error) {
+	if c.vault == nil {
+		return nil, ErrNotLoggedIn
+	}
+
+	var resp SecretResponse
+
+	err := c.do(ctx, http.MethodGet, "/api/v1/secrets/"+secretID, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := secret.DecryptPayload(c.vault, resp.Type, resp.Data)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt secret %s: %w", secretID, err)
+	}
+
+	return payload, nil
+}
+
+// CreateSecret encrypts the payload and saves it as a new secret of the user.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - payload secret.Payload: secret payload;
+//   - metadata map[string]string: free-form metadata stored without encryption.
+func (c *Client) CreateSecret(
+	ctx context.Context,
+	payload secret.Payload,
+	metadata map[string]string,
+) (*SecretResponse, error) {
+	if c.vault == nil {
+		return nil, ErrNotLoggedIn
+	}
+
+	data, err := secret.EncryptPayload(c.vault, payload)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt secret: %w", err)
+	}
+
+	var resp SecretResponse
+
+	err = c.do(ctx, http.MethodPost, "/api/v1/secrets", SecretRequest{
+		Type:     payload.Type(),
+		Metadata: metadata,
+		Data:     data,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// do sends the request with the JSON body and decodes the JSON response into dst,
+// the response is not read if dst is nil.
+func (c *Client) do(ctx context.Context, method string, path string, body any, dst any) error {
+	var reader io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method,
+		strings.TrimSuffix(c.conf.ServerURL, "/")+path, reader)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Client-Name", clientName)
+
+	if c.accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var errResp errorResponse
+
+		_ = json.NewDecoder(resp.Body).Decode(&errResp)
+
+		return fmt.Errorf("%w: %s %s: %d %s", ErrRequestFailed, method, path,
+			resp.StatusCode, errResp.Message)
+	}
+
+	if dst == nil {
+		return nil
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(dst)
+	if err != nil {
+		return fmt.Errorf("decode response of %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+// Package client provides the command-line client of the password keeper.
+package client
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+)
+
+// CreateCard validates the card and saves it as a new secret of the user.
+//
+// The number, the expiry and the CVV are checked before the encryption. The network and
+// the last four digits of the number are added to the metadata, so the listings show the card
+// masked without decrypting it.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - card *secret.Card: bank card;
+//   - metadata map[string]string: free-form metadata stored without encryption.
+func (c *Client) CreateCard(
+	ctx context.Context,
+	card *secret.Card,
+	metadata map[string]string,
+) (*SecretResponse, error) {
+	card, err := secret.NewCard(card.Number, card.Holder, card.Expiry, card.CVV)
+	if err != nil {
+		return nil, fmt.Errorf("create card: %w", err)
+	}
+
+	masked := maps.Clone(metadata)
+	if masked == nil {
+		masked = map[string]string{}
+	}
+
+	maps.Copy(masked, card.Summary().Metadata())
+
+	err = secret.ValidateCardMetadata(masked)
+	if err != nil {
+		return nil, fmt.Errorf("create card: %w", err)
+	}
+
+	return c.CreateSecret(ctx, card, masked)
+}
@@ -0,0 +1,67 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_CreateCard(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeServer(t)
+	keeper := newTestClient(server.URL)
+
+	require.NoError(t, keeper.Login(context.Background()))
+
+	created, err := keeper.CreateCard(context.Background(), &secret.Card{
+		Number: "5555 5555 5555 4444",
+		Holder: "ALICE",
+		Expiry: "12/30",
+		CVV:    "123",
+	}, map[string]string{"bank": "Example"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"bank":                      "Example",
+		secret.MetadataCardNetwork:  "mastercard",
+		secret.MetadataCardLastFour: "4444",
+	}, created.Metadata)
+	assert.NotContains(t, string(created.Data), "5555")
+
+	payload, err := keeper.GetSecret(context.Background(), created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &secret.Card{
+		Number: "5555555555554444",
+		Holder: "ALICE",
+		Expiry: "12/30",
+		CVV:    "123",
+	}, payload)
+}
+
+func TestClient_CreateCardInvalid(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeServer(t)
+	keeper := newTestClient(server.URL)
+
+	require.NoError(t, keeper.Login(context.Background()))
+
+	_, err := keeper.CreateCard(context.Background(), &secret.Card{
+		Number: "4111111111111112",
+		Holder: "ALICE",
+		Expiry: "12/30",
+		CVV:    "123",
+	}, nil)
+	require.ErrorIs(t, err, secret.ErrInvalidPayload)
+
+	_, err = keeper.CreateCard(context.Background(), &secret.Card{
+		Number: "4111111111111111",
+		Holder: "ALICE",
+		Expiry: "12/30",
+		CVV:    "123",
+	}, map[string]string{"note": "4111111111111111"})
+	require.ErrorIs(t, err, secret.ErrInvalidPayload)
+}
@@ -0,0 +1,179 @@
+// Package client provides the command-line client of the password keeper.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+)
+
+// requestTimeout - maximum duration of a request to the server.
+const requestTimeout = 30 * time.Second
+
+// Commands of the client application.
+const (
+	commandTOTP       = "totp"
+	commandTOTPCode   = "code"
+	commandTOTPImport = "import"
+	commandCard       = "card"
+	commandCardAdd    = "add"
+)
+
+// errUsage - the arguments do not form a known command.
+var errUsage = errors.New("usage:\n" +
+	"  client totp code [flags] <secret-id>\n" +
+	"  client totp import [flags] <otpauth-uri>\n" +
+	"  " + envNameCardNumber + "=... " + envNameCardCVV + "=... client card add [flags] <holder> <MM/YY>")
+
+// Run executes the command given by the arguments and exits with a non-zero code on failure.
+//
+// Commands:
+//   - totp code <secret-id>: prints the current one-time code of the TOTP secret and the seconds left;
+//   - totp import <otpauth-uri>: saves the seed given by the otpauth:// URI as a new TOTP secret;
+//   - card add <holder> <MM/YY>: saves the bank card as a new card secret, the number and the CVV
+//     are set only by the environment variables, so they do not get into the process list.
+func Run() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	err := run(ctx, os.Args[1:], os.Stdout, &http.Client{
+		Transport:     nil,
+		CheckRedirect: nil,
+		Jar:           nil,
+		Timeout:       requestTimeout,
+	})
+
+	stop()
+
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "Error:", err)
+
+		os.Exit(1)
+	}
+}
+
+// run executes the command and writes its result to out.
+//
+// Every run logs in anew, so the session is revoked before the exit
+// and does not stay in the list of the user's sessions.
+func run(ctx context.Context, args []string, out io.Writer, httpClient *http.Client) (err error) {
+	if !isCommand(args) {
+		return errUsage
+	}
+
+	conf, params, err := Initialize(args[2:])
+	if err != nil {
+		return err
+	}
+
+	if len(params) != commandParams(args) {
+		return errUsage
+	}
+
+	client := NewClient(conf, httpClient)
+
+	err = client.Login(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		// The session is revoked even if the command has been interrupted.
+		logoutErr := client.Logout(context.WithoutCancel(ctx))
+		if logoutErr != nil {
+			err = errors.Join(err, fmt.Errorf("logout: %w", logoutErr))
+		}
+	}()
+
+	switch {
+	case args[0] == commandCard:
+		return runCardAdd(ctx, client, params, out)
+	case args[1] == commandTOTPImport:
+		return runTOTPImport(ctx, client, params[0], out)
+	default:
+		return runTOTPCode(ctx, client, params[0], out)
+	}
+}
+
+// runCardAdd saves the bank card given by the holder, the expiry and the environment variables.
+func runCardAdd(ctx context.Context, client *Client, params []string, out io.Writer) error {
+	created, err := client.CreateCard(ctx, &secret.Card{
+		Number: os.Getenv(envNameCardNumber),
+		Holder: params[0],
+		Expiry: params[1],
+		CVV:    os.Getenv(envNameCardCVV),
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "Card %s •••• %s added as secret %s\n",
+		created.Metadata[secret.MetadataCardNetwork],
+		created.Metadata[secret.MetadataCardLastFour],
+		created.ID)
+
+	return err //nolint:wrapcheck // error of writing to the standard output
+}
+
+// runTOTPImport saves the seed given by the otpauth:// URI as a new TOTP secret.
+func runTOTPImport(ctx context.Context, client *Client, uri string, out io.Writer) error {
+	created, err := client.ImportTOTP(ctx, uri)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "TOTP secret %s imported\n", created.ID)
+
+	return err //nolint:wrapcheck // error of writing to the standard output
+}
+
+// runTOTPCode prints the current one-time code of the TOTP secret and the seconds left.
+func runTOTPCode(ctx context.Context, client *Client, secretID string, out io.Writer) error {
+	code, err := client.TOTPCode(ctx, secretID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	// Seconds are rounded up, so the code is never shown with zero seconds left.
+	seconds := (code.Remaining + time.Second - 1) / time.Second
+
+	_, err = fmt.Fprintf(out, "%s (%ds remaining)\n", code.Code, seconds)
+
+	return err //nolint:wrapcheck // error of writing to the standard output
+}
+
+// isCommand checks whether the arguments start with a known command.
+func isCommand(args []string) bool {
+	const commandParts = 2
+
+	if len(args) < commandParts {
+		return false
+	}
+
+	switch args[0] {
+	case commandTOTP:
+		return args[1] == commandTOTPCode || args[1] == commandTOTPImport
+	case commandCard:
+		return args[1] == commandCardAdd
+	default:
+		return false
+	}
+}
+
+// commandParams returns the number of the positional arguments of the known command.
+func commandParams(args []string) int {
+	const cardAddParams = 2 // Holder and expiry.
+
+	if args[0] == commandCard {
+		return cardAddParams
+	}
+
+	return 1
+}
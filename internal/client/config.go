@@ -0,0 +1,92 @@
+// Package client provides the command-line client of the password keeper.
+package client
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Constants are default values.
+const (
+	defaultServerURL string = "http://localhost:8080"
+)
+
+// Names of the environment variables.
+const (
+	envNameServerURL      string = "KEEPER_SERVER_URL"
+	envNameLogin          string = "KEEPER_LOGIN"
+	envNameMasterPassword string = "KEEPER_MASTER_PASSWORD"
+	envNameCardNumber     string = "KEEPER_CARD_NUMBER"
+	envNameCardCVV        string = "KEEPER_CARD_CVV"
+)
+
+// Names of the command-line flags.
+const (
+	flagNameServerURL     string = "server-url"
+	flagNameLogin         string = "login"
+	flagNameTwoFactorCode string = "2fa-code"
+)
+
+// Config is a structure containing the main parameters of the client.
+type Config struct {
+	// ServerURL - base URL of the server.
+	ServerURL string
+
+	// Login - login of the user.
+	Login string
+
+	// MasterPassword - master password of the user.
+	//
+	// Set only by the environment variable, so the password does not get into the process list.
+	MasterPassword string
+
+	// TwoFactorCode - one-time code from the authenticator, required if two-factor login is enabled.
+	TwoFactorCode string
+}
+
+// Initialize creates and initializes a *Config object and returns the arguments left after the flags.
+//
+// Values are assigned (reassigned) in the following order:
+// - default values;
+// - values from command-line flags;
+// - values from environment variables.
+//
+// Parameters:
+//   - args []string: command-line arguments without the program name and the command.
+func Initialize(args []string) (*Config, []string, error) {
+	config := &Config{
+		ServerURL:      defaultServerURL,
+		Login:          "",
+		MasterPassword: "",
+		TwoFactorCode:  "",
+	}
+
+	flags := flag.NewFlagSet("client", flag.ContinueOnError)
+	flags.StringVar(
+		&config.ServerURL,
+		flagNameServerURL,
+		config.ServerURL,
+		"base URL of the server",
+	)
+	flags.StringVar(&config.Login, flagNameLogin, config.Login, "login of the user")
+	flags.StringVar(&config.TwoFactorCode, flagNameTwoFactorCode, config.TwoFactorCode,
+		"one-time code of the two-factor login")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse flags: %w", err)
+	}
+
+	if value, ok := os.LookupEnv(envNameServerURL); ok {
+		config.ServerURL = value
+	}
+
+	if value, ok := os.LookupEnv(envNameLogin); ok {
+		config.Login = value
+	}
+
+	config.MasterPassword = os.Getenv(envNameMasterPassword)
+
+	return config, flags.Args(), nil
+}
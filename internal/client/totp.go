@@ -0,0 +1,70 @@
+// Package client provides the command-line client of the password keeper.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+)
+
+// ErrNotTOTP - the secret is not a seed of one-time passwords.
+var ErrNotTOTP = errors.New("secret is not a TOTP seed")
+
+// TOTPCode describes the current one-time code of the TOTP secret.
+type TOTPCode struct {
+	Code      string        // One-time code.
+	Remaining time.Duration // Time left until the code expires.
+}
+
+// TOTPCode returns the one-time code of the user's TOTP secret at the moment.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - secretID string: identifier of the TOTP secret;
+//   - moment time.Time: moment of time.
+func (c *Client) TOTPCode(
+	ctx context.Context,
+	secretID string,
+	moment time.Time,
+) (*TOTPCode, error) {
+	payload, err := c.GetSecret(ctx, secretID)
+	if err != nil {
+		return nil, err
+	}
+
+	seed, ok := payload.(*secret.TOTP)
+	if !ok {
+		return nil, fmt.Errorf("%w: secret %s is %s", ErrNotTOTP, secretID, payload.Type())
+	}
+
+	key, err := seed.Key()
+	if err != nil {
+		return nil, fmt.Errorf("secret %s: %w", secretID, err)
+	}
+
+	code, err := key.Code(moment)
+	if err != nil {
+		return nil, fmt.Errorf("generate code: %w", err)
+	}
+
+	return &TOTPCode{Code: code, Remaining: key.Remaining(moment)}, nil
+}
+
+// ImportTOTP saves the seed given by the otpauth:// URI as a new secret of the user.
+//
+// The issuer and the account are kept in the encrypted payload only.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - uri string: otpauth://totp/ URI.
+func (c *Client) ImportTOTP(ctx context.Context, uri string) (*SecretResponse, error) {
+	seed, err := secret.ParseTOTPURI(uri)
+	if err != nil {
+		return nil, fmt.Errorf("import TOTP secret: %w", err)
+	}
+
+	return c.CreateSecret(ctx, seed, nil)
+}
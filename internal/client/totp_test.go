@@ -0,0 +1,223 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/client"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testLogin          = "user@example.com"
+	testMasterPassword = "correct horse battery staple"
+	testAccessToken    = "access-token"
+	testSecretID       = "secret-1"
+)
+
+// fakeServer imitates the server API used by the client and keeps the only secret in memory.
+type fakeServer struct {
+	mu     sync.Mutex
+	kdf    crypto.KDFParams
+	auth   string
+	vault  *crypto.WrappedVaultKey
+	stored *client.SecretResponse
+}
+
+// newFakeServer returns the server of the user with the master password testMasterPassword.
+func newFakeServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	// The lowest allowed cost keeps the test fast.
+	params := crypto.KDFParams{
+		Algorithm:   crypto.KDFAlgorithmArgon2id,
+		Memory:      19 * 1024,
+		Iterations:  2,
+		Parallelism: 1,
+		Salt:        make([]byte, crypto.SaltSize),
+	}
+
+	keys, err := crypto.DeriveMasterKeys(testMasterPassword, params)
+	require.NoError(t, err)
+
+	vaultKey, err := crypto.GenerateVaultKey()
+	require.NoError(t, err)
+
+	wrapped, err := keys.WrapVaultKey(vaultKey)
+	require.NoError(t, err)
+
+	fake := &fakeServer{
+		mu:     sync.Mutex{},
+		kdf:    params,
+		auth:   keys.AuthPassword(),
+		vault:  wrapped,
+		stored: nil,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/auth/prelogin", fake.prelogin)
+	mux.HandleFunc("POST /api/v1/auth/login", fake.login)
+	mux.HandleFunc("POST /api/v1/auth/logout", fake.authorized(fake.logout))
+	mux.HandleFunc("POST /api/v1/secrets", fake.authorized(fake.createSecret))
+	mux.HandleFunc("GET /api/v1/secrets/{id}", fake.authorized(fake.getSecret))
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (f *fakeServer) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testAccessToken {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (f *fakeServer) prelogin(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, map[string]any{"kdf": f.kdf})
+}
+
+func (f *fakeServer) login(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Login    string `json:"login"`
+		Password string `json:"password"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil || req.Login != testLogin || req.Password != f.auth {
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	writeJSON(w, map[string]any{"accessToken": testAccessToken, "vaultKey": f.vault})
+}
+
+func (f *fakeServer) logout(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeServer) createSecret(w http.ResponseWriter, r *http.Request) {
+	var req client.SecretRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	f.mu.Lock()
+	f.stored = &client.SecretResponse{
+		ID:       testSecretID,
+		Type:     req.Type,
+		Metadata: req.Metadata,
+		Data:     req.Data,
+	}
+	f.mu.Unlock()
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, f.stored)
+}
+
+func (f *fakeServer) getSecret(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.stored == nil || r.PathValue("id") != f.stored.ID {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	writeJSON(w, f.stored)
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	err := json.NewEncoder(w).Encode(value)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func newTestClient(serverURL string) *client.Client {
+	return client.NewClient(&client.Config{
+		ServerURL:      serverURL,
+		Login:          testLogin,
+		MasterPassword: testMasterPassword,
+		TwoFactorCode:  "",
+	}, http.DefaultClient)
+}
+
+func TestClient_ImportTOTPAndCode(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeServer(t)
+	keeper := newTestClient(server.URL)
+
+	require.NoError(t, keeper.Login(context.Background()))
+
+	// Seed of the RFC 6238 SHA-1 test vector.
+	created, err := keeper.ImportTOTP(context.Background(),
+		"otpauth://totp/Example:alice?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&digits=8&period=30")
+	require.NoError(t, err)
+	assert.Equal(t, testSecretID, created.ID)
+
+	code, err := keeper.TOTPCode(context.Background(), created.ID, time.Unix(59, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "94287082", code.Code)
+	assert.Equal(t, time.Second, code.Remaining)
+}
+
+func TestClient_ImportTOTPInvalidURI(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeServer(t)
+	keeper := newTestClient(server.URL)
+
+	require.NoError(t, keeper.Login(context.Background()))
+
+	_, err := keeper.ImportTOTP(
+		context.Background(),
+		"otpauth://hotp/Example:alice?secret=GEZDGNBV",
+	)
+	require.ErrorIs(t, err, secret.ErrInvalidPayload)
+}
+
+func TestClient_NotLoggedIn(t *testing.T) {
+	t.Parallel()
+
+	keeper := newTestClient("http://localhost")
+
+	_, err := keeper.TOTPCode(context.Background(), testSecretID, time.Now())
+	require.ErrorIs(t, err, client.ErrNotLoggedIn)
+}
+
+func TestClient_Logout(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeServer(t)
+	keeper := newTestClient(server.URL)
+
+	require.NoError(t, keeper.Login(context.Background()))
+	require.NoError(t, keeper.Logout(context.Background()))
+
+	_, err := keeper.GetSecret(context.Background(), testSecretID)
+	require.ErrorIs(t, err, client.ErrNotLoggedIn, "vault key must be forgotten")
+
+	require.NoError(t, keeper.Logout(context.Background()), "repeated logout must do nothing")
+}
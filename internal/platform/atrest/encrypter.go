@@ -0,0 +1,216 @@
+// Package atrest provides envelope encryption of data at rest.
+//
+// Every stored object is encrypted with its own random data encryption key (DEK),
+// and the DEK is wrapped with a master key obtained from a KeyProvider. Rotating
+// the master key only requires re-wrapping the DEKs, the data itself is not touched.
+package atrest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+)
+
+// additionalDataPrefixDataKey separates the wrapped data keys from the data
+// encrypted with the same additional data.
+const additionalDataPrefixDataKey = "go-password-keeper/v1/data-key:"
+
+// WrappedKey describes a data encryption key wrapped with a master key.
+type WrappedKey struct {
+	MasterKeyID string // Identifier of the master key.
+	Key         []byte // Envelope with the data key.
+}
+
+// Sealed describes data encrypted at rest.
+type Sealed struct {
+	Data    []byte     // Envelope with the data encrypted with the data key.
+	DataKey WrappedKey // Wrapped data key.
+}
+
+// RewrapResult describes the result of re-wrapping a batch of data keys.
+type RewrapResult struct {
+	Found     int // Number of data keys wrapped with old master keys.
+	Rewrapped int // Number of data keys re-wrapped with the current master key.
+	Skipped   int // Number of data keys changed concurrently and therefore skipped.
+}
+
+// Encrypter encrypts data with random data keys wrapped with the current master key.
+type Encrypter struct {
+	keys KeyProvider
+}
+
+// NewEncrypter creates a new *Encrypter instance.
+//
+// Parameters:
+//   - keys KeyProvider: source of master keys.
+func NewEncrypter(keys KeyProvider) *Encrypter {
+	return &Encrypter{
+		keys: keys,
+	}
+}
+
+// CurrentKeyID returns the identifier of the master key that wraps new data keys.
+func (e *Encrypter) CurrentKeyID(ctx context.Context) (string, error) {
+	key, err := e.keys.CurrentKey(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get current master key: %w", err)
+	}
+
+	return key.ID, nil
+}
+
+// Encrypt encrypts the plaintext with a new data key.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - plaintext []byte: data to encrypt;
+//   - additionalData []byte: data that binds the ciphertext to its owner, for example, the record key.
+func (e *Encrypter) Encrypt(
+	ctx context.Context,
+	plaintext []byte,
+	additionalData []byte,
+) (*Sealed, error) {
+	dataKey, err := crypto.GenerateVaultKey()
+	if err != nil {
+		return nil, fmt.Errorf("generate data key: %w", err)
+	}
+
+	dataVault, err := crypto.NewVault(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("create data vault: %w", err)
+	}
+
+	data, err := dataVault.Seal(plaintext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt data: %w", err)
+	}
+
+	wrapped, err := e.wrap(ctx, dataKey, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sealed{
+		Data:    data,
+		DataKey: *wrapped,
+	}, nil
+}
+
+// Decrypt decrypts the data encrypted by Encrypt.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - sealed *Sealed: encrypted data;
+//   - additionalData []byte: the same additional data as when encrypting.
+func (e *Encrypter) Decrypt(
+	ctx context.Context,
+	sealed *Sealed,
+	additionalData []byte,
+) ([]byte, error) {
+	dataKey, err := e.unwrap(ctx, &sealed.DataKey, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	dataVault, err := crypto.NewVault(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("create data vault: %w", err)
+	}
+
+	plaintext, err := dataVault.Open(sealed.Data, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt data: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// Rewrap re-wraps the data key with the current master key.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - wrapped *WrappedKey: data key wrapped with any known master key;
+//   - additionalData []byte: the same additional data as when encrypting.
+func (e *Encrypter) Rewrap(
+	ctx context.Context,
+	wrapped *WrappedKey,
+	additionalData []byte,
+) (*WrappedKey, error) {
+	dataKey, err := e.unwrap(ctx, wrapped, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.wrap(ctx, dataKey, additionalData)
+}
+
+// wrap encrypts the data key with the current master key.
+func (e *Encrypter) wrap(
+	ctx context.Context,
+	dataKey []byte,
+	additionalData []byte,
+) (*WrappedKey, error) {
+	masterKey, err := e.keys.CurrentKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get current master key: %w", err)
+	}
+
+	masterVault, err := newMasterVault(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := masterVault.Seal(dataKey, dataKeyAdditionalData(additionalData))
+	if err != nil {
+		return nil, fmt.Errorf("wrap data key: %w", err)
+	}
+
+	return &WrappedKey{
+		MasterKeyID: masterKey.ID,
+		Key:         key,
+	}, nil
+}
+
+// unwrap decrypts the data key with the master key it was wrapped with.
+func (e *Encrypter) unwrap(
+	ctx context.Context,
+	wrapped *WrappedKey,
+	additionalData []byte,
+) ([]byte, error) {
+	masterKey, err := e.keys.Key(ctx, wrapped.MasterKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("get master key: %w", err)
+	}
+
+	masterVault, err := newMasterVault(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := masterVault.Open(wrapped.Key, dataKeyAdditionalData(additionalData))
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key: %w", err)
+	}
+
+	return dataKey, nil
+}
+
+// newMasterVault creates a vault that writes the master key identifier into envelopes.
+func newMasterVault(masterKey *MasterKey) (*crypto.Vault, error) {
+	vault, err := crypto.NewVaultWithConfig(crypto.VaultConfig{
+		Key:       masterKey.Key,
+		KeyID:     masterKey.ID,
+		Algorithm: crypto.DefaultAlgorithm,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create master vault: %w", err)
+	}
+
+	return vault, nil
+}
+
+// dataKeyAdditionalData returns the additional data of the wrapped data key.
+func dataKeyAdditionalData(additionalData []byte) []byte {
+	return append([]byte(additionalDataPrefixDataKey), additionalData...)
+}
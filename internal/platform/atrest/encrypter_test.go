@@ -0,0 +1,70 @@
+package atrest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEncrypter creates an encrypter with the keyring, the first key is the current one.
+func newEncrypter(t *testing.T, keys ...*atrest.MasterKey) *atrest.Encrypter {
+	t.Helper()
+
+	provider, err := atrest.NewStaticKeyProvider(keys)
+	require.NoError(t, err)
+
+	return atrest.NewEncrypter(provider)
+}
+
+func TestEncrypter_EncryptDecrypt(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	encrypter := newEncrypter(t, newMasterKey(t, "2026-10"))
+
+	sealed, err := encrypter.Encrypt(ctx, []byte("client ciphertext"), []byte("user/secret"))
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10", sealed.DataKey.MasterKeyID)
+	assert.NotContains(t, string(sealed.Data), "client ciphertext")
+
+	plaintext, err := encrypter.Decrypt(ctx, sealed, []byte("user/secret"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("client ciphertext"), plaintext)
+
+	_, err = encrypter.Decrypt(ctx, sealed, []byte("user/other-secret"))
+	require.ErrorIs(t, err, crypto.ErrDecrypt, "data must be bound to its record")
+}
+
+func TestEncrypter_Rewrap(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	previous := newMasterKey(t, "2026-09")
+	current := newMasterKey(t, "2026-10")
+
+	sealed, err := newEncrypter(t, previous).Encrypt(ctx, []byte("data"), []byte("ad"))
+	require.NoError(t, err)
+
+	encrypter := newEncrypter(t, current, previous)
+
+	plaintext, err := encrypter.Decrypt(ctx, sealed, []byte("ad"))
+	require.NoError(t, err, "data wrapped with the previous key must stay readable")
+	assert.Equal(t, []byte("data"), plaintext)
+
+	rewrapped, err := encrypter.Rewrap(ctx, &sealed.DataKey, []byte("ad"))
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10", rewrapped.MasterKeyID)
+
+	rotated := &atrest.Sealed{Data: sealed.Data, DataKey: *rewrapped}
+
+	plaintext, err = newEncrypter(t, current).Decrypt(ctx, rotated, []byte("ad"))
+	require.NoError(t, err, "the previous key must not be needed after rotation")
+	assert.Equal(t, []byte("data"), plaintext)
+
+	_, err = newEncrypter(t, current).Decrypt(ctx, sealed, []byte("ad"))
+	require.ErrorIs(t, err, atrest.ErrKeyNotFound)
+}
@@ -0,0 +1,252 @@
+// Package atrest provides envelope encryption of data at rest.
+//
+// Every stored object is encrypted with its own random data encryption key (DEK),
+// and the DEK is wrapped with a master key obtained from a KeyProvider. Rotating
+// the master key only requires re-wrapping the DEKs, the data itself is not touched.
+package atrest
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+)
+
+// Errors returned when working with master keys.
+var (
+	// ErrKeyNotFound - the provider has no master key with such identifier.
+	ErrKeyNotFound = errors.New("master key not found")
+
+	// ErrInvalidKeyring - the keyring has a wrong format.
+	ErrInvalidKeyring = errors.New("invalid keyring")
+)
+
+const maxKeyIDLength = 64
+
+// MasterKey describes a key that wraps data encryption keys.
+type MasterKey struct {
+	ID  string // Key identifier stored next to the wrapped data keys.
+	Key []byte // 32-byte key.
+}
+
+// KeyProvider describes a source of master keys.
+type KeyProvider interface {
+	// CurrentKey returns the master key used to wrap new data keys.
+	CurrentKey(ctx context.Context) (*MasterKey, error)
+
+	// Key returns the master key by identifier.
+	//
+	// Returns ErrKeyNotFound if there is no such key.
+	Key(ctx context.Context, keyID string) (*MasterKey, error)
+}
+
+// StaticKeyProvider - provider of master keys loaded once at startup.
+//
+// Implements the KeyProvider interface.
+type StaticKeyProvider struct {
+	current *MasterKey
+	keys    map[string]*MasterKey
+}
+
+// NewStaticKeyProvider creates a new *StaticKeyProvider instance.
+//
+// The first key becomes the current one, the rest are kept to read data wrapped before rotation.
+//
+// Parameters:
+//   - keys []*MasterKey: master keys, at least one.
+func NewStaticKeyProvider(keys []*MasterKey) (*StaticKeyProvider, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no keys", ErrInvalidKeyring)
+	}
+
+	byID := make(map[string]*MasterKey, len(keys))
+
+	for _, key := range keys {
+		err := validateMasterKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, exists := byID[key.ID]; exists {
+			return nil, fmt.Errorf("%w: duplicate key %q", ErrInvalidKeyring, key.ID)
+		}
+
+		byID[key.ID] = key
+	}
+
+	return &StaticKeyProvider{
+		current: keys[0],
+		keys:    byID,
+	}, nil
+}
+
+// CurrentKey returns the master key used to wrap new data keys.
+//
+// Implements the KeyProvider interface.
+func (p *StaticKeyProvider) CurrentKey(_ context.Context) (*MasterKey, error) {
+	return p.current, nil
+}
+
+// Key returns the master key by identifier.
+//
+// Implements the KeyProvider interface.
+func (p *StaticKeyProvider) Key(_ context.Context, keyID string) (*MasterKey, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, keyID)
+	}
+
+	return key, nil
+}
+
+// ReloadableKeyProvider - provider of master keys that loads the keyring again on request.
+//
+// Implements the KeyProvider interface.
+type ReloadableKeyProvider struct {
+	load func() ([]*MasterKey, error)
+
+	mu   sync.RWMutex
+	keys *StaticKeyProvider
+}
+
+// NewReloadableKeyProvider creates a new *ReloadableKeyProvider instance and loads the keyring.
+//
+// Parameters:
+//   - load func() ([]*MasterKey, error): loader of the keyring, the first key is the current one.
+func NewReloadableKeyProvider(load func() ([]*MasterKey, error)) (*ReloadableKeyProvider, error) {
+	provider := &ReloadableKeyProvider{
+		load: load,
+		mu:   sync.RWMutex{},
+		keys: nil,
+	}
+
+	err := provider.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// NewFileKeyProvider creates a provider with the keyring read from the file.
+//
+// The file is read again on every Reload, so a new master key is taken into use
+// without restarting the instance.
+//
+// Parameters:
+//   - path string: path to the keyring file (see ParseKeyring).
+func NewFileKeyProvider(path string) (*ReloadableKeyProvider, error) {
+	return NewReloadableKeyProvider(func() ([]*MasterKey, error) {
+		data, err := os.ReadFile(path) //nolint:gosec // the path is set by the administrator
+		if err != nil {
+			return nil, fmt.Errorf("read keyring file: %w", err)
+		}
+
+		return ParseKeyring(string(data))
+	})
+}
+
+// Reload loads the keyring again, the previous keyring is kept if the new one is invalid.
+//
+// The data wrapped with a master key removed from the keyring can no longer be read,
+// so a key is removed only after the rotation to the current key has completed.
+func (p *ReloadableKeyProvider) Reload() error {
+	keys, err := p.load()
+	if err != nil {
+		return err
+	}
+
+	provider, err := NewStaticKeyProvider(keys)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.keys = provider
+	p.mu.Unlock()
+
+	return nil
+}
+
+// CurrentKey returns the master key used to wrap new data keys.
+//
+// Implements the KeyProvider interface.
+func (p *ReloadableKeyProvider) CurrentKey(ctx context.Context) (*MasterKey, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.keys.CurrentKey(ctx)
+}
+
+// Key returns the master key by identifier.
+//
+// Implements the KeyProvider interface.
+func (p *ReloadableKeyProvider) Key(ctx context.Context, keyID string) (*MasterKey, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.keys.Key(ctx, keyID)
+}
+
+// ParseKeyring parses master keys in the "<id>:<base64 key>" form separated by commas or new lines.
+//
+// The first key is the current one. Empty lines and lines starting with "#" are skipped.
+//
+// Parameters:
+//   - text string: keyring.
+func ParseKeyring(text string) ([]*MasterKey, error) {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == '\n'
+	})
+
+	keys := make([]*MasterKey, 0, len(fields))
+
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" || strings.HasPrefix(field, "#") {
+			continue
+		}
+
+		keyID, encoded, found := strings.Cut(field, ":")
+		if !found {
+			return nil, fmt.Errorf(
+				"%w: entry must have the <id>:<base64 key> form",
+				ErrInvalidKeyring,
+			)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q is not base64: %w", ErrInvalidKeyring, keyID, err)
+		}
+
+		keys = append(keys, &MasterKey{
+			ID:  strings.TrimSpace(keyID),
+			Key: key,
+		})
+	}
+
+	return keys, nil
+}
+
+// validateMasterKey checks the identifier and the size of the master key.
+func validateMasterKey(key *MasterKey) error {
+	if key.ID == "" || len(key.ID) > maxKeyIDLength {
+		return fmt.Errorf(
+			"%w: key ID must be from 1 to %d bytes",
+			ErrInvalidKeyring,
+			maxKeyIDLength,
+		)
+	}
+
+	if len(key.Key) != crypto.KeySize {
+		return fmt.Errorf("%w: key %q must be %d bytes", ErrInvalidKeyring, key.ID, crypto.KeySize)
+	}
+
+	return nil
+}
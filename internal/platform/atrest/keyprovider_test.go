@@ -0,0 +1,147 @@
+package atrest_test
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMasterKey creates a random master key with the identifier.
+func newMasterKey(t *testing.T, keyID string) *atrest.MasterKey {
+	t.Helper()
+
+	key, err := crypto.GenerateVaultKey()
+	require.NoError(t, err)
+
+	return &atrest.MasterKey{ID: keyID, Key: key}
+}
+
+// keyringEntry formats the master key as a keyring entry.
+func keyringEntry(key *atrest.MasterKey) string {
+	return key.ID + ":" + base64.StdEncoding.EncodeToString(key.Key)
+}
+
+func TestParseKeyring(t *testing.T) {
+	t.Parallel()
+
+	current := newMasterKey(t, "2026-10")
+	previous := newMasterKey(t, "2026-09")
+
+	text := strings.Join([]string{
+		"# current key first",
+		keyringEntry(current),
+		"",
+		" " + keyringEntry(previous) + " ",
+	}, "\n")
+
+	keys, err := atrest.ParseKeyring(text)
+	require.NoError(t, err)
+	assert.Equal(t, []*atrest.MasterKey{current, previous}, keys)
+
+	keys, err = atrest.ParseKeyring(keyringEntry(current) + "," + keyringEntry(previous))
+	require.NoError(t, err)
+	assert.Equal(t, []*atrest.MasterKey{current, previous}, keys)
+
+	for _, invalid := range []string{"no-separator", "key:not-base64!"} {
+		_, err = atrest.ParseKeyring(invalid)
+		require.ErrorIs(t, err, atrest.ErrInvalidKeyring, invalid)
+	}
+}
+
+func TestNewStaticKeyProvider(t *testing.T) {
+	t.Parallel()
+
+	current := newMasterKey(t, "2026-10")
+	previous := newMasterKey(t, "2026-09")
+
+	provider, err := atrest.NewStaticKeyProvider([]*atrest.MasterKey{current, previous})
+	require.NoError(t, err)
+
+	key, err := provider.CurrentKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, current, key)
+
+	key, err = provider.Key(context.Background(), "2026-09")
+	require.NoError(t, err)
+	assert.Equal(t, previous, key)
+
+	_, err = provider.Key(context.Background(), "2026-08")
+	require.ErrorIs(t, err, atrest.ErrKeyNotFound)
+
+	tests := []struct {
+		name string
+		keys []*atrest.MasterKey
+	}{
+		{name: "no keys", keys: nil},
+		{name: "duplicate key", keys: []*atrest.MasterKey{current, current}},
+		{name: "short key", keys: []*atrest.MasterKey{{ID: "short", Key: []byte("short")}}},
+		{name: "empty identifier", keys: []*atrest.MasterKey{newMasterKey(t, "")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := atrest.NewStaticKeyProvider(tt.keys)
+			require.ErrorIs(t, err, atrest.ErrInvalidKeyring)
+		})
+	}
+}
+
+func TestNewFileKeyProvider(t *testing.T) {
+	t.Parallel()
+
+	current := newMasterKey(t, "2026-10")
+	path := filepath.Join(t.TempDir(), "keyring")
+
+	require.NoError(t, os.WriteFile(path, []byte(keyringEntry(current)+"\n"), 0o600))
+
+	provider, err := atrest.NewFileKeyProvider(path)
+	require.NoError(t, err)
+
+	key, err := provider.CurrentKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, current, key)
+
+	_, err = atrest.NewFileKeyProvider(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
+
+func TestFileKeyProvider_Reload(t *testing.T) {
+	t.Parallel()
+
+	previous := newMasterKey(t, "2026-09")
+	current := newMasterKey(t, "2026-10")
+	path := filepath.Join(t.TempDir(), "keyring")
+
+	require.NoError(t, os.WriteFile(path, []byte(keyringEntry(previous)), 0o600))
+
+	provider, err := atrest.NewFileKeyProvider(path)
+	require.NoError(t, err)
+
+	keyring := keyringEntry(current) + "\n" + keyringEntry(previous) + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(keyring), 0o600))
+	require.NoError(t, provider.Reload())
+
+	key, err := provider.CurrentKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, current, key)
+
+	key, err = provider.Key(context.Background(), previous.ID)
+	require.NoError(t, err)
+	assert.Equal(t, previous, key)
+
+	require.NoError(t, os.WriteFile(path, []byte("broken"), 0o600))
+	require.ErrorIs(t, provider.Reload(), atrest.ErrInvalidKeyring)
+
+	key, err = provider.CurrentKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, current, key, "an invalid keyring must not replace the loaded one")
+}
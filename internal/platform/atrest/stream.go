@@ -0,0 +1,332 @@
+// Package atrest provides envelope encryption of data at rest.
+//
+// Every stored object is encrypted with its own random data encryption key (DEK),
+// and the DEK is wrapped with a master key obtained from a KeyProvider. Rotating
+// the master key only requires re-wrapping the DEKs, the data itself is not touched.
+package atrest
+
+import (
+	"bufio"
+	"context"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// ErrInvalidStream - the stream is not encrypted with the stream key, truncated or changed.
+var ErrInvalidStream = errors.New("invalid encrypted stream")
+
+// StreamVersion1 - the only format version of encrypted streams.
+//
+// Layout of version 1 (all lengths in bytes):
+//
+//	version (1) | nonce prefix (16) | segment | ... | segment
+//
+// The plaintext is split into segments of streamSegmentSize bytes, the last one may be shorter
+// or empty. Every segment is sealed with XChaCha20-Poly1305 under the nonce
+//
+//	nonce prefix (16) | segment index (7, big-endian) | last segment flag (1)
+//
+// so the segments can be neither reordered nor dropped or appended.
+const StreamVersion1 uint8 = 1
+
+const (
+	streamSegmentSize     = 64 << 10 // Size of the plaintext of a segment.
+	streamNoncePrefixSize = 16       // Size of the random prefix of the nonces.
+	streamHeaderSize      = 1 + streamNoncePrefixSize
+	streamIndexSize       = 7 // Size of the segment index in the nonce.
+	streamIndexOffset     = streamNoncePrefixSize
+	streamLastFlagOffset  = streamIndexOffset + streamIndexSize
+)
+
+// streamKeyDerivationLabel separates the stream keys derived from the master key
+// from other uses of the master key.
+const streamKeyDerivationLabel = "go-password-keeper/v1/stream-key:"
+
+// StreamKey describes the data key of a stream encrypted at rest with the prefix of its nonces.
+//
+// The same plaintext encrypted with the same stream key gives the same ciphertext, so a large
+// payload can be read twice without buffering: to hash the ciphertext and to save it.
+type StreamKey struct {
+	key         []byte
+	noncePrefix []byte
+	aead        cipher.AEAD
+}
+
+// NewStreamKey generates a new random stream key.
+func NewStreamKey() (*StreamKey, error) {
+	key, err := crypto.GenerateVaultKey()
+	if err != nil {
+		return nil, fmt.Errorf("generate data key: %w", err)
+	}
+
+	noncePrefix := make([]byte, streamNoncePrefixSize)
+
+	_, err = rand.Read(noncePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("generate nonce prefix: %w", err)
+	}
+
+	return newStreamKey(key, noncePrefix)
+}
+
+// DeriveStreamKey derives the stream key of the content from the current master key.
+//
+// The key is the HMAC of the hash of the plaintext under the master key, so equal contents
+// are encrypted to equal streams and can be stored once, while the key cannot be derived
+// without the master key. Equal streams reveal only that their contents are equal.
+// The contents encrypted after the rotation of the master key get other keys.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - contentHash []byte: hash of the plaintext of the stream.
+func (e *Encrypter) DeriveStreamKey(ctx context.Context, contentHash []byte) (*StreamKey, error) {
+	masterKey, err := e.keys.CurrentKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get current master key: %w", err)
+	}
+
+	mac := hmac.New(sha512.New, masterKey.Key)
+	_, _ = mac.Write([]byte(streamKeyDerivationLabel))
+	_, _ = mac.Write(contentHash)
+	derived := mac.Sum(nil)
+
+	return newStreamKey(
+		derived[:crypto.KeySize],
+		derived[crypto.KeySize:crypto.KeySize+streamNoncePrefixSize],
+	)
+}
+
+// Encrypt returns the reader of the ciphertext of the plaintext.
+//
+// The plaintext is read segment by segment as the ciphertext is read.
+//
+// Parameters:
+//   - plaintext io.Reader: data to encrypt;
+//   - additionalData []byte: data that binds the ciphertext to its kind.
+func (k *StreamKey) Encrypt(plaintext io.Reader, additionalData []byte) io.Reader {
+	header := append([]byte{StreamVersion1}, k.noncePrefix...)
+	source := bufio.NewReader(plaintext)
+	segment := make([]byte, streamSegmentSize)
+	index := uint64(0)
+
+	return &segmentReader{
+		buffer:   nil,
+		pending:  header,
+		finished: false,
+		next: func(out []byte) ([]byte, bool, error) {
+			size, last, err := readSegment(source, segment)
+			if err != nil {
+				return nil, false, err
+			}
+
+			sealed := k.aead.Seal(out, streamNonce(k.noncePrefix, index, last),
+				segment[:size], additionalData)
+			index++
+
+			return sealed, last, nil
+		},
+	}
+}
+
+// newStreamKey creates the stream key with the data key and the prefix of its nonces.
+func newStreamKey(key []byte, noncePrefix []byte) (*StreamKey, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("create stream cipher: %w", err)
+	}
+
+	return &StreamKey{
+		key:         key,
+		noncePrefix: noncePrefix,
+		aead:        aead,
+	}, nil
+}
+
+// StreamSize returns the size of the ciphertext of the plaintext of the size.
+//
+// Parameters:
+//   - size int64: size of the plaintext in bytes.
+func StreamSize(size int64) int64 {
+	segments := max((size+streamSegmentSize-1)/streamSegmentSize, 1)
+
+	return streamHeaderSize + size + segments*chacha20poly1305.Overhead
+}
+
+// WrapStreamKey encrypts the stream key with the current master key.
+//
+// The nonce prefix is kept in the header of the stream, so only the data key is wrapped.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - key *StreamKey: stream key;
+//   - additionalData []byte: data that binds the key to its stream, for example, its identifier.
+func (e *Encrypter) WrapStreamKey(
+	ctx context.Context,
+	key *StreamKey,
+	additionalData []byte,
+) (*WrappedKey, error) {
+	return e.wrap(ctx, key.key, additionalData)
+}
+
+// DecryptStream returns the reader of the plaintext of the stream encrypted with StreamKey.Encrypt.
+//
+// The ciphertext is read and authenticated segment by segment, a changed or truncated stream
+// fails with ErrInvalidStream on the segment that has been changed.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - wrapped *WrappedKey: stream key wrapped by WrapStreamKey;
+//   - ciphertext io.Reader: encrypted stream;
+//   - keyAdditionalData []byte: the same additional data as when wrapping the key;
+//   - additionalData []byte: the same additional data as when encrypting.
+func (e *Encrypter) DecryptStream(
+	ctx context.Context,
+	wrapped *WrappedKey,
+	ciphertext io.Reader,
+	keyAdditionalData []byte,
+	additionalData []byte,
+) (io.Reader, error) {
+	key, err := e.unwrap(ctx, wrapped, keyAdditionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("create stream cipher: %w", err)
+	}
+
+	source := bufio.NewReader(ciphertext)
+	segment := make([]byte, streamSegmentSize+chacha20poly1305.Overhead)
+	noncePrefix := []byte(nil)
+	index := uint64(0)
+
+	return &segmentReader{
+		buffer:   nil,
+		pending:  nil,
+		finished: false,
+		next: func(out []byte) ([]byte, bool, error) {
+			if noncePrefix == nil {
+				noncePrefix, err = readStreamHeader(source)
+				if err != nil {
+					return nil, false, err
+				}
+			}
+
+			size, last, err := readSegment(source, segment)
+			if err != nil {
+				return nil, false, err
+			}
+
+			plaintext, err := aead.Open(out, streamNonce(noncePrefix, index, last),
+				segment[:size], additionalData)
+			if err != nil {
+				return nil, false, fmt.Errorf("%w: segment %d", ErrInvalidStream, index)
+			}
+
+			index++
+
+			return plaintext, last, nil
+		},
+	}, nil
+}
+
+// segmentReader returns the content of the segments produced one by one.
+type segmentReader struct {
+	buffer   []byte // Buffer of the current segment reused for the next one.
+	pending  []byte // Content of the current segment not read yet.
+	finished bool   // The last segment has been produced.
+
+	// next produces the next segment reusing the buffer and tells whether it is the last one.
+	next func(out []byte) ([]byte, bool, error)
+}
+
+// Read reads the content of the segments.
+//
+// Implements the io.Reader interface.
+func (r *segmentReader) Read(data []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.finished {
+			return 0, io.EOF
+		}
+
+		segment, last, err := r.next(r.buffer[:0])
+		if err != nil {
+			return 0, err
+		}
+
+		r.buffer, r.pending, r.finished = segment, segment, last
+	}
+
+	read := copy(data, r.pending)
+	r.pending = r.pending[read:]
+
+	return read, nil
+}
+
+// readStreamHeader reads the header of the encrypted stream and returns its nonce prefix.
+func readStreamHeader(source io.Reader) ([]byte, error) {
+	header := make([]byte, streamHeaderSize)
+
+	_, err := io.ReadFull(source, header)
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("%w: header is truncated", ErrInvalidStream)
+		}
+
+		return nil, fmt.Errorf("read stream header: %w", err)
+	}
+
+	if header[0] != StreamVersion1 {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidStream, header[0])
+	}
+
+	return header[1:], nil
+}
+
+// readSegment fills the buffer from the source and tells whether the source has ended.
+func readSegment(source *bufio.Reader, segment []byte) (int, bool, error) {
+	size, err := io.ReadFull(source, segment)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return size, true, nil
+	}
+
+	if err != nil {
+		return 0, false, fmt.Errorf("read segment: %w", err)
+	}
+
+	_, err = source.Peek(1)
+	if errors.Is(err, io.EOF) {
+		return size, true, nil
+	}
+
+	if err != nil {
+		return 0, false, fmt.Errorf("read segment: %w", err)
+	}
+
+	return size, false, nil
+}
+
+// streamNonce returns the nonce of the segment of the stream.
+func streamNonce(noncePrefix []byte, index uint64, last bool) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	copy(nonce, noncePrefix)
+
+	counter := binary.BigEndian.AppendUint64(nil, index)
+	copy(nonce[streamIndexOffset:streamLastFlagOffset], counter[len(counter)-streamIndexSize:])
+
+	if last {
+		nonce[streamLastFlagOffset] = 1
+	}
+
+	return nonce
+}
@@ -0,0 +1,141 @@
+package atrest_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// segmentSize - size of the plaintext of a segment of encrypted streams.
+const segmentSize = 64 << 10
+
+// sealStream encrypts the plaintext as a stream and returns the ciphertext with the wrapped key.
+func sealStream(
+	t *testing.T,
+	encrypter *atrest.Encrypter,
+	plaintext []byte,
+) ([]byte, *atrest.WrappedKey) {
+	t.Helper()
+
+	key, err := atrest.NewStreamKey()
+	require.NoError(t, err)
+
+	ciphertext, err := io.ReadAll(key.Encrypt(bytes.NewReader(plaintext), []byte("blob")))
+	require.NoError(t, err)
+
+	wrapped, err := encrypter.WrapStreamKey(context.Background(), key, []byte("blob-id"))
+	require.NoError(t, err)
+
+	return ciphertext, wrapped
+}
+
+// openStream decrypts the stream sealed by sealStream.
+func openStream(
+	encrypter *atrest.Encrypter,
+	ciphertext []byte,
+	wrapped *atrest.WrappedKey,
+) ([]byte, error) {
+	plaintext, err := encrypter.DecryptStream(context.Background(), wrapped,
+		bytes.NewReader(ciphertext), []byte("blob-id"), []byte("blob"))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt stream: %w", err)
+	}
+
+	data, err := io.ReadAll(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("read stream: %w", err)
+	}
+
+	return data, nil
+}
+
+func TestStreamKey_EncryptDecrypt(t *testing.T) {
+	t.Parallel()
+
+	encrypter := newEncrypter(t, newMasterKey(t, "2026-10"))
+
+	for _, size := range []int{0, 1, segmentSize - 1, segmentSize, segmentSize + 1, 3 * segmentSize} {
+		plaintext := make([]byte, size)
+		_, _ = rand.Read(plaintext)
+
+		ciphertext, wrapped := sealStream(t, encrypter, plaintext)
+		assert.Len(t, ciphertext, int(atrest.StreamSize(int64(size))), "size %d", size)
+
+		decrypted, err := openStream(encrypter, ciphertext, wrapped)
+		require.NoError(t, err, "size %d", size)
+		assert.Equal(t, plaintext, decrypted, "size %d", size)
+	}
+}
+
+func TestStreamKey_EncryptIsRepeatable(t *testing.T) {
+	t.Parallel()
+
+	key, err := atrest.NewStreamKey()
+	require.NoError(t, err)
+
+	plaintext := bytes.Repeat([]byte("payload"), segmentSize)
+
+	first, err := io.ReadAll(key.Encrypt(bytes.NewReader(plaintext), []byte("blob")))
+	require.NoError(t, err)
+
+	second, err := io.ReadAll(key.Encrypt(bytes.NewReader(plaintext), []byte("blob")))
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second, "the ciphertext must be hashed and saved in two passes")
+	assert.NotContains(t, string(first), "payload")
+}
+
+func TestEncrypter_DecryptStreamRejectsChanges(t *testing.T) {
+	t.Parallel()
+
+	encrypter := newEncrypter(t, newMasterKey(t, "2026-10"))
+	ciphertext, wrapped := sealStream(t, encrypter, make([]byte, 2*segmentSize+10))
+
+	changed := bytes.Clone(ciphertext)
+	changed[len(changed)/2] ^= 1
+
+	_, err := openStream(encrypter, changed, wrapped)
+	require.ErrorIs(t, err, atrest.ErrInvalidStream, "changed segment")
+
+	_, err = openStream(encrypter, ciphertext[:len(ciphertext)-segmentSize], wrapped)
+	require.ErrorIs(t, err, atrest.ErrInvalidStream, "truncated stream")
+
+	_, err = openStream(encrypter, append(bytes.Clone(ciphertext), 0), wrapped)
+	require.ErrorIs(t, err, atrest.ErrInvalidStream, "appended data")
+
+	_, err = encrypter.DecryptStream(context.Background(), wrapped,
+		bytes.NewReader(ciphertext), []byte("other-blob-id"), []byte("blob"))
+	require.Error(t, err, "the key must be bound to its stream")
+}
+
+func TestEncrypter_DeriveStreamKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	masterKey := newMasterKey(t, "2026-10")
+	encrypter := newEncrypter(t, masterKey)
+	plaintext := []byte("payload")
+
+	encrypt := func(encrypter *atrest.Encrypter, contentHash string) []byte {
+		key, err := encrypter.DeriveStreamKey(ctx, []byte(contentHash))
+		require.NoError(t, err)
+
+		ciphertext, err := io.ReadAll(key.Encrypt(bytes.NewReader(plaintext), []byte("blob")))
+		require.NoError(t, err)
+
+		return ciphertext
+	}
+
+	first := encrypt(encrypter, "hash")
+	assert.Equal(t, first, encrypt(newEncrypter(t, masterKey), "hash"), "same content")
+	assert.NotEqual(t, first, encrypt(encrypter, "other hash"), "other content")
+	assert.NotEqual(t, first, encrypt(newEncrypter(t, newMasterKey(t, "2026-11")), "hash"),
+		"other master key")
+}
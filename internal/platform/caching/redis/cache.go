@@ -3,6 +3,7 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,6 +12,9 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// ErrKeyNotFound - there is no value for the key.
+var ErrKeyNotFound = errors.New("key not found")
+
 // Cacher describes the Cacher structure for communicating with the simple redis service.
 type Cacher struct {
 	logger logging.Logger
@@ -90,11 +94,197 @@ func (c *Cacher) SetValue(
 }
 
 // GetValue gets the value as a string by key.
+//
+// Returns ErrKeyNotFound if there is no value for the key.
 func (c *Cacher) GetValue(ctx context.Context, key string) (string, error) {
 	value, err := c.client.Get(ctx, key).Result()
 	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", ErrKeyNotFound
+		}
+
 		return "", fmt.Errorf("get value: %w", err)
 	}
 
 	return value, nil
 }
+
+// SetValueIfNotExists atomically stores the value by key only if the key does not exist.
+//
+// Returns true if the value was stored.
+func (c *Cacher) SetValueIfNotExists(
+	ctx context.Context,
+	key string,
+	value string,
+	expiration time.Duration,
+) (bool, error) {
+	isSet, err := c.client.SetNX(ctx, key, value, expiration).Result()
+	if err != nil {
+		return false, fmt.Errorf("set value if not exists: %w", err)
+	}
+
+	return isSet, nil
+}
+
+// SetValueIfExists atomically replaces the value by key only if the key exists.
+//
+// Returns true if the value was stored.
+func (c *Cacher) SetValueIfExists(
+	ctx context.Context,
+	key string,
+	value string,
+	expiration time.Duration,
+) (bool, error) {
+	isSet, err := c.client.SetXX(ctx, key, value, expiration).Result()
+	if err != nil {
+		return false, fmt.Errorf("set value if exists: %w", err)
+	}
+
+	return isSet, nil
+}
+
+// DeleteValues deletes the values by keys.
+func (c *Cacher) DeleteValues(ctx context.Context, keys ...string) error {
+	err := c.client.Del(ctx, keys...).Err()
+	if err != nil {
+		return fmt.Errorf("delete values: %w", err)
+	}
+
+	return nil
+}
+
+// AddSetMembers adds the members to the set and updates the expiration time of the set.
+func (c *Cacher) AddSetMembers(
+	ctx context.Context,
+	key string,
+	expiration time.Duration,
+	members ...string,
+) error {
+	values := make([]any, 0, len(members))
+	for _, member := range members {
+		values = append(values, member)
+	}
+
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, key, values...)
+		pipe.Expire(ctx, key, expiration)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("add set members: %w", err)
+	}
+
+	return nil
+}
+
+// GetSetMembers gets all members of the set.
+func (c *Cacher) GetSetMembers(ctx context.Context, key string) ([]string, error) {
+	members, err := c.client.SMembers(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("get set members: %w", err)
+	}
+
+	return members, nil
+}
+
+// RemoveSetMembers removes the members from the set.
+func (c *Cacher) RemoveSetMembers(ctx context.Context, key string, members ...string) error {
+	values := make([]any, 0, len(members))
+	for _, member := range members {
+		values = append(values, member)
+	}
+
+	err := c.client.SRem(ctx, key, values...).Err()
+	if err != nil {
+		return fmt.Errorf("remove set members: %w", err)
+	}
+
+	return nil
+}
+
+// Publish posts the message to the channel.
+func (c *Cacher) Publish(ctx context.Context, channel string, message string) error {
+	err := c.client.Publish(ctx, channel, message).Err()
+	if err != nil {
+		return fmt.Errorf("publish: %w", err)
+	}
+
+	return nil
+}
+
+// Subscribe subscribes to the channel and returns the stream of its messages.
+//
+// The subscription is confirmed before the method returns and is restored automatically
+// after the connection is lost. The stream is closed when ctx is done.
+func (c *Cacher) Subscribe(ctx context.Context, channel string) (<-chan string, error) {
+	pubsub := c.client.Subscribe(ctx, channel)
+
+	_, err := pubsub.Receive(ctx)
+	if err != nil {
+		_ = pubsub.Close()
+
+		return nil, fmt.Errorf("subscribe: %w", err)
+	}
+
+	messages := make(chan string)
+
+	go func() {
+		defer close(messages)
+
+		defer func() {
+			closeErr := pubsub.Close()
+			if closeErr != nil {
+				c.logger.Warn("Closing subscription error", closeErr, "channel", channel)
+			}
+		}()
+
+		source := pubsub.Channel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, open := <-source:
+				if !open {
+					return
+				}
+
+				select {
+				case messages <- message.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return messages, nil
+}
+
+// Shutdown gracefully closes the connection to redis.
+//
+// Implements the platform.IShutdowner interface.
+func (c *Cacher) Shutdown(_ context.Context) error {
+	return c.Close()
+}
+
+// Close closes the connection to redis.
+//
+// Implements the platform.IShutdowner interface.
+func (c *Cacher) Close() error {
+	c.logger.Info("Cacher close starting...")
+
+	if c.client == nil {
+		return nil
+	}
+
+	err := c.client.Close()
+	if err != nil {
+		return fmt.Errorf("cacher close: %w", err)
+	}
+
+	c.logger.Info("Cacher close is successful")
+
+	return nil
+}
@@ -0,0 +1,164 @@
+// Package crypto provides the client-side encryption model of the password keeper.
+//
+// The master password never leaves the client. Argon2id derives a master key from it,
+// which is split into an encryption key (wraps the random vault key) and an authentication
+// key (sent to the server instead of the password). Secret payloads are encrypted with
+// the vault key, so the server stores only ciphertext, the wrapped vault key
+// and the KDF parameters.
+package crypto
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidBlindIndex - the blind index has an unknown field or a malformed token.
+var ErrInvalidBlindIndex = errors.New("invalid blind index")
+
+// BlindIndexTokenLength - length of the blind index token (in base64url characters).
+const BlindIndexTokenLength = 43
+
+// infoBlindIndexKey separates the key of blind indexes from the other keys derived from the vault key.
+const infoBlindIndexKey = "go-password-keeper/v1/blind-index-key"
+
+// BlindIndexField - field of the secret a blind index is computed for.
+type BlindIndexField string
+
+// Fields of the secret blind indexes can be computed for.
+const (
+	BlindIndexDomain   BlindIndexField = "domain"   // Domain of the site the secret is used on.
+	BlindIndexUsername BlindIndexField = "username" // Login of the account.
+	BlindIndexTag      BlindIndexField = "tag"      // Label the user groups secrets by.
+)
+
+// IsValid checks whether the field is supported.
+func (f BlindIndexField) IsValid() bool {
+	switch f {
+	case BlindIndexDomain, BlindIndexUsername, BlindIndexTag:
+		return true
+	default:
+		return false
+	}
+}
+
+// BlindIndex describes a keyed hash of a field of the secret.
+//
+// The server finds secrets by exact match of the token without learning the value: computing
+// the token requires the key derived from the vault key. Equal values of the same field have
+// equal tokens, so the server can still see which secrets share a value.
+type BlindIndex struct {
+	Field BlindIndexField `json:"field"` // Field the index is computed for.
+	Token string          `json:"token"` // HMAC-SHA256 of the normalized value (base64url without padding).
+}
+
+// Validate checks the field and the format of the token.
+func (i *BlindIndex) Validate() error {
+	if !i.Field.IsValid() {
+		return fmt.Errorf("%w: unknown field %q", ErrInvalidBlindIndex, i.Field)
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(i.Token)
+	if err != nil || len(raw) != sha256.Size {
+		return fmt.Errorf("%w: token of %s must be %d base64url characters",
+			ErrInvalidBlindIndex, i.Field, BlindIndexTokenLength)
+	}
+
+	return nil
+}
+
+// BlindIndexer computes blind indexes of the secret fields with the key derived from the vault key.
+type BlindIndexer struct {
+	key []byte
+}
+
+// NewBlindIndexer creates a new *BlindIndexer instance.
+//
+// Parameters:
+//   - vaultKey []byte: vault key.
+func NewBlindIndexer(vaultKey []byte) (*BlindIndexer, error) {
+	if len(vaultKey) != KeySize {
+		return nil, fmt.Errorf("%w: vault key must be %d bytes", ErrInvalidKey, KeySize)
+	}
+
+	key, err := hkdf.Expand(sha256.New, vaultKey, infoBlindIndexKey, KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("derive blind index key: %w", err)
+	}
+
+	return &BlindIndexer{key: key}, nil
+}
+
+// Index returns the blind index of the normalized value of the field.
+//
+// Parameters:
+//   - field BlindIndexField: field of the secret;
+//   - value string: value of the field in plain form.
+func (b *BlindIndexer) Index(field BlindIndexField, value string) (BlindIndex, error) {
+	if !field.IsValid() {
+		return BlindIndex{Field: "", Token: ""}, fmt.Errorf("%w: unknown field %q",
+			ErrInvalidBlindIndex, field)
+	}
+
+	mac := hmac.New(sha256.New, b.key)
+
+	// The field is a part of the hashed data, so equal values of different fields
+	// have different tokens.
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(NormalizeBlindIndexValue(field, value)))
+
+	return BlindIndex{
+		Field: field,
+		Token: base64.RawURLEncoding.EncodeToString(mac.Sum(nil)),
+	}, nil
+}
+
+// NormalizeBlindIndexValue converts the value to the form the index is computed of,
+// so the spellings of the same value have the same index.
+//
+// Domains and usernames are case-insensitive, and the domain is taken from the URL
+// without the "www." prefix, so the entry of "https://www.Example.com/login" is found
+// by "example.com".
+//
+// Parameters:
+//   - field BlindIndexField: field of the secret;
+//   - value string: value of the field in plain form.
+func NormalizeBlindIndexValue(field BlindIndexField, value string) string {
+	value = strings.TrimSpace(value)
+
+	switch field {
+	case BlindIndexDomain:
+		return normalizeDomain(value)
+	case BlindIndexUsername:
+		return strings.ToLower(value)
+	case BlindIndexTag:
+		return value
+	}
+
+	return value
+}
+
+// normalizeDomain returns the lower-case host of the URL or domain without the "www." prefix.
+func normalizeDomain(value string) string {
+	host := value
+
+	address := value
+	if !strings.Contains(address, "://") {
+		address = "https://" + address
+	}
+
+	parsed, err := url.Parse(address)
+	if err == nil && parsed.Hostname() != "" {
+		host = parsed.Hostname()
+	}
+
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	return strings.TrimPrefix(host, "www.")
+}
@@ -0,0 +1,99 @@
+package crypto_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBlindIndexer(t *testing.T) *crypto.BlindIndexer {
+	t.Helper()
+
+	vaultKey, err := crypto.GenerateVaultKey()
+	require.NoError(t, err)
+
+	indexer, err := crypto.NewBlindIndexer(vaultKey)
+	require.NoError(t, err)
+
+	return indexer
+}
+
+func TestBlindIndexer_Index(t *testing.T) {
+	t.Parallel()
+
+	indexer := newBlindIndexer(t)
+
+	index, err := indexer.Index(crypto.BlindIndexDomain, "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, crypto.BlindIndexDomain, index.Field)
+	assert.Len(t, index.Token, crypto.BlindIndexTokenLength)
+	require.NoError(t, index.Validate())
+
+	for _, spelling := range []string{"https://www.Example.com/login?next=1", " EXAMPLE.COM. ", "example.com:8443"} {
+		other, err := indexer.Index(crypto.BlindIndexDomain, spelling)
+		require.NoError(t, err)
+		assert.Equal(t, index, other, "spelling %q", spelling)
+	}
+
+	tag, err := indexer.Index(crypto.BlindIndexTag, "example.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, index.Token, tag.Token, "fields must not share tokens")
+
+	foreign, err := newBlindIndexer(t).Index(crypto.BlindIndexDomain, "example.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, index.Token, foreign.Token, "tokens must depend on the vault key")
+
+	_, err = indexer.Index(crypto.BlindIndexField("password"), "p@ss")
+	require.ErrorIs(t, err, crypto.ErrInvalidBlindIndex)
+}
+
+func TestNormalizeBlindIndexValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		field crypto.BlindIndexField
+		value string
+		want  string
+	}{
+		{
+			field: crypto.BlindIndexDomain,
+			value: "https://www.Example.com/login",
+			want:  "example.com",
+		},
+		{field: crypto.BlindIndexDomain, value: "mail.example.com", want: "mail.example.com"},
+		{field: crypto.BlindIndexUsername, value: " Alice@Example.com", want: "alice@example.com"},
+		{field: crypto.BlindIndexTag, value: " Work ", want: "Work"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, crypto.NormalizeBlindIndexValue(tt.field, tt.value), tt.value)
+	}
+}
+
+func TestBlindIndex_Validate(t *testing.T) {
+	t.Parallel()
+
+	valid := strings.Repeat("A", crypto.BlindIndexTokenLength)
+
+	invalid := []crypto.BlindIndex{
+		{Field: "password", Token: valid},
+		{Field: crypto.BlindIndexDomain, Token: ""},
+		{Field: crypto.BlindIndexDomain, Token: valid[:20]},
+		{Field: crypto.BlindIndexDomain, Token: strings.Repeat("!", crypto.BlindIndexTokenLength)},
+	}
+	for _, index := range invalid {
+		require.ErrorIs(t, index.Validate(), crypto.ErrInvalidBlindIndex, index)
+	}
+
+	index := crypto.BlindIndex{Field: crypto.BlindIndexUsername, Token: valid}
+	require.NoError(t, index.Validate())
+}
+
+func TestNewBlindIndexer_InvalidKey(t *testing.T) {
+	t.Parallel()
+
+	_, err := crypto.NewBlindIndexer(make([]byte, 16))
+	require.ErrorIs(t, err, crypto.ErrInvalidKey)
+}
@@ -0,0 +1,213 @@
+// Package crypto provides the client-side encryption model of the password keeper.
+//
+// The master password never leaves the client. Argon2id derives a master key from it,
+// which is split into an encryption key (wraps the random vault key) and an authentication
+// key (sent to the server instead of the password). Secret payloads are encrypted with
+// the vault key, so the server stores only ciphertext, the wrapped vault key
+// and the KDF parameters.
+package crypto
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidEnvelope - the data is not an envelope of a supported format.
+var ErrInvalidEnvelope = errors.New("invalid envelope")
+
+// EnvelopeVersion1 - the only envelope format version.
+//
+// Layout of version 1 (all lengths in bytes):
+//
+//	version (1) | algorithm (1) | key ID length (1) | key ID | nonce | ciphertext | tag (16)
+//
+// The nonce length is defined by the algorithm. The header (everything before the nonce)
+// is authenticated together with the additional data of the caller.
+const EnvelopeVersion1 uint8 = 1
+
+const (
+	envelopeFixedHeaderSize = 3   // Version, algorithm and key ID length.
+	maxKeyIDLength          = 255 // Key ID length is stored in one byte.
+	tagSize                 = 16  // Tag size of all supported algorithms.
+)
+
+// Algorithm identifies the AEAD algorithm of an envelope.
+type Algorithm uint8
+
+// Supported algorithms. The values are stored in envelopes and must never change.
+const (
+	AlgorithmAES256GCM         Algorithm = 1 // AES-256-GCM with a 12-byte nonce.
+	AlgorithmXChaCha20Poly1305 Algorithm = 2 // XChaCha20-Poly1305 with a 24-byte nonce.
+)
+
+// DefaultAlgorithm - algorithm used for new envelopes.
+//
+// Changing it does not affect existing envelopes: they are opened with the algorithm
+// stored in their header.
+const DefaultAlgorithm = AlgorithmXChaCha20Poly1305
+
+// String returns the name of the algorithm.
+func (a Algorithm) String() string {
+	switch a {
+	case AlgorithmAES256GCM:
+		return "AES-256-GCM"
+	case AlgorithmXChaCha20Poly1305:
+		return "XChaCha20-Poly1305"
+	default:
+		return fmt.Sprintf("Algorithm(%d)", uint8(a))
+	}
+}
+
+// NonceSize returns the nonce size of the algorithm, or 0 if the algorithm is unknown.
+func (a Algorithm) NonceSize() int {
+	switch a {
+	case AlgorithmAES256GCM:
+		return 12 //nolint:mnd // The nonce size of AES-GCM.
+	case AlgorithmXChaCha20Poly1305:
+		return 24 //nolint:mnd // The nonce size of XChaCha20-Poly1305.
+	default:
+		return 0
+	}
+}
+
+// IsValid reports whether the algorithm is supported.
+func (a Algorithm) IsValid() bool {
+	return a.NonceSize() > 0
+}
+
+// Envelope describes self-describing authenticated ciphertext.
+type Envelope struct {
+	Version    uint8     // Format version.
+	Algorithm  Algorithm // AEAD algorithm.
+	KeyID      string    // Identifier of the key, empty if the owner has only one key.
+	Nonce      []byte    // Nonce of the algorithm size.
+	Ciphertext []byte    // Encrypted data without the tag.
+	Tag        []byte    // Authentication tag.
+}
+
+// EnvelopePrefixSize - number of the first bytes of an envelope enough to check its structure.
+const EnvelopePrefixSize = envelopeFixedHeaderSize
+
+// ParseEnvelope decodes the envelope from its binary form.
+//
+// Only the structure is checked; the envelope still has to be opened with the key.
+//
+// Parameters:
+//   - data []byte: encoded envelope.
+func ParseEnvelope(data []byte) (*Envelope, error) {
+	headerSize, algorithm, err := parseHeader(data, int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	body := data[headerSize:]
+	nonce, sealed := body[:algorithm.NonceSize()], body[algorithm.NonceSize():]
+
+	return &Envelope{
+		Version:    data[0],
+		Algorithm:  algorithm,
+		KeyID:      string(data[envelopeFixedHeaderSize:headerSize]),
+		Nonce:      clone(nonce),
+		Ciphertext: clone(sealed[:len(sealed)-tagSize]),
+		Tag:        clone(sealed[len(sealed)-tagSize:]),
+	}, nil
+}
+
+// ValidateEnvelopePrefix checks the structure of the envelope of the given size by its first bytes,
+// so a large envelope can be checked while it is streamed.
+//
+// Parameters:
+//   - prefix []byte: first EnvelopePrefixSize bytes of the envelope;
+//   - size int64: size of the whole envelope.
+func ValidateEnvelopePrefix(prefix []byte, size int64) error {
+	if int64(len(prefix)) > size {
+		return fmt.Errorf("%w: prefix is longer than the envelope", ErrInvalidEnvelope)
+	}
+
+	_, _, err := parseHeader(prefix, size)
+
+	return err
+}
+
+// parseHeader checks the header of the envelope of the given size
+// and returns the size of the header and the algorithm.
+func parseHeader(data []byte, size int64) (int, Algorithm, error) {
+	if len(data) < envelopeFixedHeaderSize {
+		return 0, 0, fmt.Errorf("%w: too short", ErrInvalidEnvelope)
+	}
+
+	version, algorithm, keyIDLength := data[0], Algorithm(data[1]), int(data[2])
+
+	if version != EnvelopeVersion1 {
+		return 0, 0, fmt.Errorf("%w: unsupported version %d", ErrInvalidEnvelope, version)
+	}
+
+	if !algorithm.IsValid() {
+		return 0, 0, fmt.Errorf(
+			"%w: unsupported algorithm %d",
+			ErrInvalidEnvelope,
+			uint8(algorithm),
+		)
+	}
+
+	headerSize := envelopeFixedHeaderSize + keyIDLength
+	if size < int64(headerSize+algorithm.NonceSize()+tagSize) {
+		return 0, 0, fmt.Errorf("%w: too short", ErrInvalidEnvelope)
+	}
+
+	return headerSize, algorithm, nil
+}
+
+// MarshalBinary encodes the envelope into its binary form.
+//
+// Implements the encoding.BinaryMarshaler interface.
+func (e *Envelope) MarshalBinary() ([]byte, error) {
+	err := e.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, e.size())
+	data = append(data, e.header()...)
+	data = append(data, e.Nonce...)
+	data = append(data, e.Ciphertext...)
+	data = append(data, e.Tag...)
+
+	return data, nil
+}
+
+// header returns the authenticated header of the envelope.
+func (e *Envelope) header() []byte {
+	header := make([]byte, 0, envelopeFixedHeaderSize+len(e.KeyID))
+	header = append(header, e.Version, byte(e.Algorithm), byte(len(e.KeyID)))
+
+	return append(header, e.KeyID...)
+}
+
+// size returns the length of the encoded envelope.
+func (e *Envelope) size() int {
+	return envelopeFixedHeaderSize + len(e.KeyID) + len(e.Nonce) + len(e.Ciphertext) + len(e.Tag)
+}
+
+// validate checks that the envelope can be encoded.
+func (e *Envelope) validate() error {
+	switch {
+	case e.Version != EnvelopeVersion1:
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidEnvelope, e.Version)
+	case !e.Algorithm.IsValid():
+		return fmt.Errorf("%w: unsupported algorithm %d", ErrInvalidEnvelope, uint8(e.Algorithm))
+	case len(e.KeyID) > maxKeyIDLength:
+		return fmt.Errorf("%w: key ID must be at most %d bytes", ErrInvalidEnvelope, maxKeyIDLength)
+	case len(e.Nonce) != e.Algorithm.NonceSize():
+		return fmt.Errorf("%w: nonce must be %d bytes", ErrInvalidEnvelope, e.Algorithm.NonceSize())
+	case len(e.Tag) != tagSize:
+		return fmt.Errorf("%w: tag must be %d bytes", ErrInvalidEnvelope, tagSize)
+	default:
+		return nil
+	}
+}
+
+// clone returns a copy of the slice that does not share memory with the original.
+func clone(data []byte) []byte {
+	return append([]byte{}, data...)
+}
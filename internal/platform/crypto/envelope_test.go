@@ -0,0 +1,170 @@
+package crypto_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// envelopeVector describes a golden test vector of the envelope format.
+//
+// The vectors are shared with other client implementations and must never change:
+// an envelope sealed once has to be readable forever.
+type envelopeVector struct {
+	Name           string `json:"name"`
+	Algorithm      uint8  `json:"algorithm"`
+	KeyID          string `json:"keyId"`
+	Key            string `json:"key"`   // Hex.
+	Nonce          string `json:"nonce"` // Hex.
+	AdditionalData string `json:"additionalData"`
+	Plaintext      string `json:"plaintext"`
+	Envelope       string `json:"envelope"` // Hex.
+}
+
+func loadEnvelopeVectors(t *testing.T) []envelopeVector {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "envelope_vectors.json"))
+	require.NoError(t, err)
+
+	var vectors []envelopeVector
+
+	require.NoError(t, json.Unmarshal(data, &vectors))
+	require.NotEmpty(t, vectors)
+
+	return vectors
+}
+
+func mustHex(t *testing.T, value string) []byte {
+	t.Helper()
+
+	data, err := hex.DecodeString(value)
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestEnvelope_GoldenVectors(t *testing.T) {
+	t.Parallel()
+
+	for _, vector := range loadEnvelopeVectors(t) {
+		t.Run(vector.Name, func(t *testing.T) {
+			t.Parallel()
+
+			golden := mustHex(t, vector.Envelope)
+
+			vault, err := crypto.NewVaultWithConfig(crypto.VaultConfig{
+				Key:       mustHex(t, vector.Key),
+				KeyID:     vector.KeyID,
+				Algorithm: crypto.Algorithm(vector.Algorithm),
+			})
+			require.NoError(t, err)
+
+			sealed, err := vault.SealWithNonce(mustHex(t, vector.Nonce), []byte(vector.Plaintext),
+				[]byte(vector.AdditionalData))
+			require.NoError(t, err)
+			assert.Equal(t, golden, sealed, "encoder must produce the golden envelope")
+
+			opened, err := vault.Open(golden, []byte(vector.AdditionalData))
+			require.NoError(t, err)
+			assert.Equal(t, vector.Plaintext, string(opened))
+
+			envelope, err := crypto.ParseEnvelope(golden)
+			require.NoError(t, err)
+			assert.Equal(t, crypto.EnvelopeVersion1, envelope.Version)
+			assert.Equal(t, crypto.Algorithm(vector.Algorithm), envelope.Algorithm)
+			assert.Equal(t, vector.KeyID, envelope.KeyID)
+			assert.Equal(t, mustHex(t, vector.Nonce), envelope.Nonce)
+
+			encoded, err := envelope.MarshalBinary()
+			require.NoError(t, err)
+			assert.Equal(t, golden, encoded)
+		})
+	}
+}
+
+func TestParseEnvelope_Errors(t *testing.T) {
+	t.Parallel()
+
+	vectors := loadEnvelopeVectors(t)
+	golden := mustHex(t, vectors[0].Envelope)
+
+	withByte := func(index int, value byte) []byte {
+		data := append([]byte(nil), golden...)
+		data[index] = value
+
+		return data
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "only header", data: golden[:3]},
+		{name: "truncated tag", data: golden[:len(golden)-1-len(`{"value":"note"}`)]},
+		{name: "unknown version", data: withByte(0, 2)},
+		{name: "unknown algorithm", data: withByte(1, 9)},
+		{name: "key ID longer than data", data: withByte(2, 200)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := crypto.ParseEnvelope(tt.data)
+			require.ErrorIs(t, err, crypto.ErrInvalidEnvelope)
+		})
+	}
+}
+
+func TestValidateEnvelopePrefix(t *testing.T) {
+	t.Parallel()
+
+	vectors := loadEnvelopeVectors(t)
+	golden := mustHex(t, vectors[0].Envelope)
+	prefix := golden[:crypto.EnvelopePrefixSize]
+
+	require.NoError(t, crypto.ValidateEnvelopePrefix(prefix, int64(len(golden))))
+	require.NoError(t, crypto.ValidateEnvelopePrefix(golden, int64(len(golden))))
+
+	tests := []struct {
+		name   string
+		prefix []byte
+		size   int64
+	}{
+		{name: "empty", prefix: nil, size: int64(len(golden))},
+		{name: "too short", prefix: prefix, size: 3},
+		{name: "prefix beyond size", prefix: golden, size: int64(len(golden)) - 1},
+		{
+			name:   "unknown version",
+			prefix: []byte{2, prefix[1], prefix[2]},
+			size:   int64(len(golden)),
+		},
+	}
+	for _, tt := range tests {
+		err := crypto.ValidateEnvelopePrefix(tt.prefix, tt.size)
+		require.ErrorIs(t, err, crypto.ErrInvalidEnvelope, tt.name)
+	}
+}
+
+func TestEnvelope_MarshalBinaryValidates(t *testing.T) {
+	t.Parallel()
+
+	envelope := crypto.Envelope{
+		Version:    crypto.EnvelopeVersion1,
+		Algorithm:  crypto.AlgorithmAES256GCM,
+		KeyID:      "",
+		Nonce:      make([]byte, 24),
+		Ciphertext: nil,
+		Tag:        make([]byte, 16),
+	}
+
+	_, err := envelope.MarshalBinary()
+	require.ErrorIs(t, err, crypto.ErrInvalidEnvelope, "nonce of another algorithm")
+}
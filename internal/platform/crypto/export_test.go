@@ -0,0 +1,10 @@
+package crypto
+
+// SealWithNonce exposes sealWithNonce to the golden vector tests.
+func (v *Vault) SealWithNonce(
+	nonce []byte,
+	plaintext []byte,
+	additionalData []byte,
+) ([]byte, error) {
+	return v.sealWithNonce(nonce, plaintext, additionalData)
+}
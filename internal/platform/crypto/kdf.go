@@ -0,0 +1,179 @@
+// Package crypto provides the client-side encryption model of the password keeper.
+//
+// The master password never leaves the client. Argon2id derives a master key from it,
+// which is split into an encryption key (wraps the random vault key) and an authentication
+// key (sent to the server instead of the password). Secret payloads are encrypted with
+// the vault key, so the server stores only ciphertext, the wrapped vault key
+// and the KDF parameters.
+package crypto
+
+import (
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// ErrInvalidKDFParams - the key derivation parameters are not supported or too weak.
+var ErrInvalidKDFParams = errors.New("invalid KDF parameters")
+
+// KDFAlgorithmArgon2id - identifier of the Argon2id key derivation function.
+const KDFAlgorithmArgon2id = "argon2id"
+
+// Default key derivation parameters (OWASP recommendations).
+const (
+	defaultKDFMemory      uint32 = 64 * 1024
+	defaultKDFIterations  uint32 = 3
+	defaultKDFParallelism uint8  = 4
+
+	// SaltSize - size of the generated salt (in bytes).
+	SaltSize = 16
+)
+
+// Restrictions on key derivation parameters.
+//
+// The lower bounds protect against weak keys, the upper ones protect clients from
+// parameters that cannot be computed in a reasonable time.
+const (
+	minKDFMemory      uint32 = 19 * 1024
+	maxKDFMemory      uint32 = 1024 * 1024
+	minKDFIterations  uint32 = 2
+	maxKDFIterations  uint32 = 20
+	minKDFParallelism uint8  = 1
+	maxKDFParallelism uint8  = 16
+	minSaltSize              = 16
+	maxSaltSize              = 64
+)
+
+// Info strings that separate the keys derived from the master key.
+const (
+	infoEncryptionKey = "go-password-keeper/v1/encryption-key"
+	infoAuthKey       = "go-password-keeper/v1/auth-key"
+)
+
+// KDFParams describes the parameters of deriving keys from the master password.
+type KDFParams struct {
+	Algorithm   string `json:"algorithm"`   // Key derivation function.
+	Memory      uint32 `json:"memory"`      // Amount of memory (in kibibytes).
+	Iterations  uint32 `json:"iterations"`  // Number of iterations over the memory.
+	Parallelism uint8  `json:"parallelism"` // Number of threads.
+	Salt        []byte `json:"salt"`        // Random salt of the user.
+}
+
+// NewSalt generates a random salt.
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+
+	return salt, nil
+}
+
+// DefaultKDFParams returns the recommended key derivation parameters with the salt.
+//
+// Parameters:
+//   - salt []byte: salt of the user (see NewSalt).
+func DefaultKDFParams(salt []byte) KDFParams {
+	return KDFParams{
+		Algorithm:   KDFAlgorithmArgon2id,
+		Memory:      defaultKDFMemory,
+		Iterations:  defaultKDFIterations,
+		Parallelism: defaultKDFParallelism,
+		Salt:        salt,
+	}
+}
+
+// Validate checks that the parameters are supported and strong enough.
+func (p *KDFParams) Validate() error {
+	if p.Algorithm != KDFAlgorithmArgon2id {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidKDFParams, p.Algorithm)
+	}
+
+	if p.Memory < minKDFMemory || p.Memory > maxKDFMemory {
+		return fmt.Errorf("%w: memory must be from %d to %d KiB",
+			ErrInvalidKDFParams, minKDFMemory, maxKDFMemory)
+	}
+
+	return p.validateCost()
+}
+
+// validateCost checks the iterations, the parallelism and the salt size.
+func (p *KDFParams) validateCost() error {
+	switch {
+	case p.Iterations < minKDFIterations || p.Iterations > maxKDFIterations:
+		return fmt.Errorf("%w: iterations must be from %d to %d",
+			ErrInvalidKDFParams, minKDFIterations, maxKDFIterations)
+	case p.Parallelism < minKDFParallelism || p.Parallelism > maxKDFParallelism:
+		return fmt.Errorf("%w: parallelism must be from %d to %d",
+			ErrInvalidKDFParams, minKDFParallelism, maxKDFParallelism)
+	case len(p.Salt) < minSaltSize || len(p.Salt) > maxSaltSize:
+		return fmt.Errorf("%w: salt must be from %d to %d bytes",
+			ErrInvalidKDFParams, minSaltSize, maxSaltSize)
+	default:
+		return nil
+	}
+}
+
+// MasterKeys describes the keys derived from the master password.
+type MasterKeys struct {
+	params        KDFParams
+	encryptionKey []byte
+	authKey       []byte
+}
+
+// DeriveMasterKeys derives the encryption and authentication keys from the master password.
+//
+// Parameters:
+//   - masterPassword string: master password in plain form;
+//   - params KDFParams: key derivation parameters of the user.
+func DeriveMasterKeys(masterPassword string, params KDFParams) (*MasterKeys, error) {
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	masterKey := argon2.IDKey(
+		[]byte(masterPassword),
+		params.Salt,
+		params.Iterations,
+		params.Memory,
+		params.Parallelism,
+		KeySize,
+	)
+
+	encryptionKey, err := hkdf.Expand(sha256.New, masterKey, infoEncryptionKey, KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("derive encryption key: %w", err)
+	}
+
+	authKey, err := hkdf.Expand(sha256.New, masterKey, infoAuthKey, KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("derive auth key: %w", err)
+	}
+
+	return &MasterKeys{
+		params:        params,
+		encryptionKey: encryptionKey,
+		authKey:       authKey,
+	}, nil
+}
+
+// Params returns the parameters the keys were derived with.
+func (k *MasterKeys) Params() KDFParams {
+	return k.params
+}
+
+// AuthPassword returns the password for authentication on the server.
+//
+// It is derived from the master password but does not allow to recover
+// the master password or the encryption key.
+func (k *MasterKeys) AuthPassword() string {
+	return base64.RawStdEncoding.EncodeToString(k.authKey)
+}
@@ -0,0 +1,80 @@
+package crypto_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFastParams returns the weakest allowed parameters to keep the tests fast.
+func newFastParams(t *testing.T) crypto.KDFParams {
+	t.Helper()
+
+	salt, err := crypto.NewSalt()
+	require.NoError(t, err)
+
+	return crypto.KDFParams{
+		Algorithm:   crypto.KDFAlgorithmArgon2id,
+		Memory:      19 * 1024,
+		Iterations:  2,
+		Parallelism: 1,
+		Salt:        salt,
+	}
+}
+
+func TestDeriveMasterKeys(t *testing.T) {
+	t.Parallel()
+
+	params := newFastParams(t)
+
+	keys, err := crypto.DeriveMasterKeys("master password", params)
+	require.NoError(t, err)
+
+	again, err := crypto.DeriveMasterKeys("master password", params)
+	require.NoError(t, err)
+	assert.Equal(t, keys.AuthPassword(), again.AuthPassword(), "derivation must be deterministic")
+	assert.Equal(t, params, keys.Params())
+
+	other, err := crypto.DeriveMasterKeys("other password", params)
+	require.NoError(t, err)
+	assert.NotEqual(t, keys.AuthPassword(), other.AuthPassword())
+
+	params.Salt = bytes.Repeat([]byte{1}, crypto.SaltSize)
+
+	salted, err := crypto.DeriveMasterKeys("master password", params)
+	require.NoError(t, err)
+	assert.NotEqual(t, keys.AuthPassword(), salted.AuthPassword())
+}
+
+func TestKDFParams_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		modify func(params *crypto.KDFParams)
+	}{
+		{name: "unknown algorithm", modify: func(p *crypto.KDFParams) { p.Algorithm = "scrypt" }},
+		{name: "too little memory", modify: func(p *crypto.KDFParams) { p.Memory = 1024 }},
+		{name: "too much memory", modify: func(p *crypto.KDFParams) { p.Memory = 1 << 30 }},
+		{name: "one iteration", modify: func(p *crypto.KDFParams) { p.Iterations = 1 }},
+		{name: "no parallelism", modify: func(p *crypto.KDFParams) { p.Parallelism = 0 }},
+		{name: "short salt", modify: func(p *crypto.KDFParams) { p.Salt = []byte("salt") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := crypto.DefaultKDFParams(bytes.Repeat([]byte{1}, crypto.SaltSize))
+			require.NoError(t, params.Validate())
+
+			tt.modify(&params)
+			require.ErrorIs(t, params.Validate(), crypto.ErrInvalidKDFParams)
+
+			_, err := crypto.DeriveMasterKeys("master password", params)
+			require.ErrorIs(t, err, crypto.ErrInvalidKDFParams)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Package crypto provides the client-side encryption model of the password keeper.
+//
+// The master password never leaves the client. Argon2id derives a master key from it,
+// which is split into an encryption key (wraps the random vault key) and an authentication
+// key (sent to the server instead of the password). Secret payloads are encrypted with
+// the vault key, so the server stores only ciphertext, the wrapped vault key
+// and the KDF parameters.
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidRecoveryCode - the recovery code has a wrong format.
+var ErrInvalidRecoveryCode = errors.New("invalid recovery code")
+
+// Parameters of the recovery codes.
+const (
+	// RecoveryCodeCount - number of recovery codes generated for an account.
+	RecoveryCodeCount = 10
+
+	// RecoveryCodeIDLength - length of the recovery code identifier (in hex characters).
+	RecoveryCodeIDLength = 32
+
+	recoveryCodeSize      = 20
+	recoveryCodeGroupSize = 4
+	recoveryCodeSeparator = "-"
+)
+
+// infoRecoveryCodeID separates the identifier of the recovery code from the keys derived from it.
+const infoRecoveryCodeID = "go-password-keeper/v1/recovery-code-id:"
+
+//nolint:gochecknoglobals // immutable encoding shared by all recovery codes
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// RecoveryKey describes a copy of the vault key protected by a recovery code.
+//
+// Like the master password, the recovery code never leaves the client: the server receives
+// only the identifier and the authentication password derived from the code.
+type RecoveryKey struct {
+	ID           string          `json:"id"`           // Identifier derived from the recovery code.
+	AuthPassword string          `json:"authPassword"` // Authentication password derived from the code.
+	VaultKey     WrappedVaultKey `json:"vaultKey"`     // Vault key wrapped with the key derived from the code.
+}
+
+// GenerateRecoveryCode generates a random recovery code
+// in the form of groups of characters separated by dashes.
+func GenerateRecoveryCode() (string, error) {
+	raw := make([]byte, recoveryCodeSize)
+
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", fmt.Errorf("generate recovery code: %w", err)
+	}
+
+	encoded := recoveryCodeEncoding.EncodeToString(raw)
+	groups := make([]string, 0, len(encoded)/recoveryCodeGroupSize)
+
+	for i := 0; i < len(encoded); i += recoveryCodeGroupSize {
+		groups = append(groups, encoded[i:i+recoveryCodeGroupSize])
+	}
+
+	return strings.Join(groups, recoveryCodeSeparator), nil
+}
+
+// NormalizeRecoveryCode removes separators from the recovery code entered by the user,
+// converts it to upper case and checks its format.
+//
+// Parameters:
+//   - code string: recovery code.
+func NormalizeRecoveryCode(code string) (string, error) {
+	normalized := strings.ToUpper(strings.NewReplacer(
+		recoveryCodeSeparator, "",
+		" ", "",
+	).Replace(code))
+
+	raw, err := recoveryCodeEncoding.DecodeString(normalized)
+	if err != nil || len(raw) != recoveryCodeSize {
+		return "", ErrInvalidRecoveryCode
+	}
+
+	return normalized, nil
+}
+
+// RecoveryCodeID returns the identifier by which the server finds the recovery code.
+//
+// Parameters:
+//   - code string: recovery code.
+func RecoveryCodeID(code string) (string, error) {
+	normalized, err := NormalizeRecoveryCode(code)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(infoRecoveryCodeID + normalized))
+
+	return hex.EncodeToString(sum[:RecoveryCodeIDLength/2]), nil
+}
+
+// DeriveRecoveryKeys derives the encryption and authentication keys from the recovery code.
+//
+// Parameters:
+//   - code string: recovery code;
+//   - params KDFParams: key derivation parameters of the code.
+func DeriveRecoveryKeys(code string, params KDFParams) (*MasterKeys, error) {
+	normalized, err := NormalizeRecoveryCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeriveMasterKeys(normalized, params)
+}
+
+// NewRecoveryKey wraps a copy of the vault key with the key derived from the recovery code.
+//
+// Parameters:
+//   - code string: recovery code;
+//   - vaultKey []byte: vault key;
+//   - params KDFParams: key derivation parameters with a unique salt of the code.
+func NewRecoveryKey(code string, vaultKey []byte, params KDFParams) (*RecoveryKey, error) {
+	codeID, err := RecoveryCodeID(code)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := DeriveRecoveryKeys(code, params)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := keys.WrapVaultKey(vaultKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecoveryKey{
+		ID:           codeID,
+		AuthPassword: keys.AuthPassword(),
+		VaultKey:     *wrapped,
+	}, nil
+}
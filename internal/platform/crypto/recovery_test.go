@@ -0,0 +1,80 @@
+package crypto_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	t.Parallel()
+
+	code, err := crypto.GenerateRecoveryCode()
+	require.NoError(t, err)
+	assert.Regexp(t, `^([A-Z2-7]{4}-){7}[A-Z2-7]{4}$`, code)
+
+	other, err := crypto.GenerateRecoveryCode()
+	require.NoError(t, err)
+	assert.NotEqual(t, code, other)
+}
+
+func TestRecoveryCodeID(t *testing.T) {
+	t.Parallel()
+
+	code, err := crypto.GenerateRecoveryCode()
+	require.NoError(t, err)
+
+	codeID, err := crypto.RecoveryCodeID(code)
+	require.NoError(t, err)
+	assert.Len(t, codeID, crypto.RecoveryCodeIDLength)
+
+	// The user may type the code without dashes and in lower case.
+	typed := strings.ToLower(strings.ReplaceAll(code, "-", " "))
+
+	typedID, err := crypto.RecoveryCodeID(typed)
+	require.NoError(t, err)
+	assert.Equal(t, codeID, typedID)
+
+	invalid := []string{"", "ABCD-EFGH", code + "-ABCD", strings.Repeat("1", 32)}
+	for _, value := range invalid {
+		_, err = crypto.RecoveryCodeID(value)
+		require.ErrorIs(t, err, crypto.ErrInvalidRecoveryCode, value)
+	}
+}
+
+func TestNewRecoveryKey(t *testing.T) {
+	t.Parallel()
+
+	vaultKey, err := crypto.GenerateVaultKey()
+	require.NoError(t, err)
+
+	code, err := crypto.GenerateRecoveryCode()
+	require.NoError(t, err)
+
+	recoveryKey, err := crypto.NewRecoveryKey(code, vaultKey, newFastParams(t))
+	require.NoError(t, err)
+
+	codeID, err := crypto.RecoveryCodeID(code)
+	require.NoError(t, err)
+	assert.Equal(t, codeID, recoveryKey.ID)
+
+	keys, err := crypto.DeriveRecoveryKeys(strings.ToLower(code), recoveryKey.VaultKey.KDF)
+	require.NoError(t, err)
+	assert.Equal(t, recoveryKey.AuthPassword, keys.AuthPassword())
+
+	unwrapped, err := keys.UnwrapVaultKey(&recoveryKey.VaultKey)
+	require.NoError(t, err)
+	assert.Equal(t, vaultKey, unwrapped)
+
+	other, err := crypto.GenerateRecoveryCode()
+	require.NoError(t, err)
+
+	otherKeys, err := crypto.DeriveRecoveryKeys(other, recoveryKey.VaultKey.KDF)
+	require.NoError(t, err)
+
+	_, err = otherKeys.UnwrapVaultKey(&recoveryKey.VaultKey)
+	require.ErrorIs(t, err, crypto.ErrDecrypt)
+}
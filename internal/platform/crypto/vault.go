@@ -0,0 +1,273 @@
+// Package crypto provides the client-side encryption model of the password keeper.
+//
+// The master password never leaves the client. Argon2id derives a master key from it,
+// which is split into an encryption key (wraps the random vault key) and an authentication
+// key (sent to the server instead of the password). Secret payloads are encrypted with
+// the vault key, so the server stores only ciphertext, the wrapped vault key
+// and the KDF parameters.
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Errors returned when working with keys and ciphertext.
+var (
+	// ErrInvalidKey - the key has a wrong size.
+	ErrInvalidKey = errors.New("invalid key")
+
+	// ErrDecrypt - the ciphertext is corrupted, was modified or the key is wrong.
+	ErrDecrypt = errors.New("decryption failed")
+
+	// ErrUnknownKey - the envelope was sealed with a key of another ID.
+	ErrUnknownKey = errors.New("unknown key")
+)
+
+// KeySize - size of all symmetric keys (AES-256, XChaCha20).
+const KeySize = 32
+
+// additionalDataVaultKey binds the wrapped vault key to its purpose.
+const additionalDataVaultKey = "go-password-keeper/v1/vault-key"
+
+// WrappedVaultKey describes the vault key encrypted with the key derived from
+// the master password, together with the parameters of that derivation.
+type WrappedVaultKey struct {
+	KDF        KDFParams `json:"kdf"`        // Key derivation parameters.
+	WrappedKey []byte    `json:"wrappedKey"` // Encrypted vault key.
+}
+
+// GenerateVaultKey generates a random vault key.
+func GenerateVaultKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+
+	_, err := rand.Read(key)
+	if err != nil {
+		return nil, fmt.Errorf("generate vault key: %w", err)
+	}
+
+	return key, nil
+}
+
+// WrapVaultKey encrypts the vault key with the encryption key.
+//
+// Parameters:
+//   - vaultKey []byte: vault key.
+func (k *MasterKeys) WrapVaultKey(vaultKey []byte) (*WrappedVaultKey, error) {
+	if len(vaultKey) != KeySize {
+		return nil, fmt.Errorf("%w: vault key must be %d bytes", ErrInvalidKey, KeySize)
+	}
+
+	kek, err := NewVault(k.encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := kek.Seal(vaultKey, []byte(additionalDataVaultKey))
+	if err != nil {
+		return nil, fmt.Errorf("wrap vault key: %w", err)
+	}
+
+	return &WrappedVaultKey{
+		KDF:        k.params,
+		WrappedKey: wrapped,
+	}, nil
+}
+
+// UnwrapVaultKey decrypts the vault key with the encryption key.
+//
+// Returns ErrDecrypt if the keys were derived from another master password.
+//
+// Parameters:
+//   - wrapped *WrappedVaultKey: encrypted vault key.
+func (k *MasterKeys) UnwrapVaultKey(wrapped *WrappedVaultKey) ([]byte, error) {
+	kek, err := NewVault(k.encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	vaultKey, err := kek.Open(wrapped.WrappedKey, []byte(additionalDataVaultKey))
+	if err != nil {
+		return nil, fmt.Errorf("unwrap vault key: %w", err)
+	}
+
+	return vaultKey, nil
+}
+
+// VaultConfig describes the parameters of a vault.
+type VaultConfig struct {
+	Key       []byte    // 32-byte key.
+	KeyID     string    // Key identifier written to envelopes, may be empty.
+	Algorithm Algorithm // Algorithm of new envelopes.
+}
+
+// Vault encrypts and decrypts data with a key.
+//
+// The ciphertext is an envelope (see EnvelopeVersion1). New envelopes use the configured
+// algorithm, while envelopes of any supported algorithm can be opened, so data sealed
+// before the algorithm change stays readable.
+type Vault struct {
+	keyID     string
+	algorithm Algorithm
+	aeads     map[Algorithm]cipher.AEAD
+}
+
+// NewVault creates a new *Vault instance with the default algorithm and an empty key ID.
+//
+// Parameters:
+//   - key []byte: 32-byte key.
+func NewVault(key []byte) (*Vault, error) {
+	return NewVaultWithConfig(VaultConfig{
+		Key:       key,
+		KeyID:     "",
+		Algorithm: DefaultAlgorithm,
+	})
+}
+
+// NewVaultWithConfig creates a new *Vault instance.
+//
+// Parameters:
+//   - conf VaultConfig: vault parameters.
+func NewVaultWithConfig(conf VaultConfig) (*Vault, error) {
+	if len(conf.Key) != KeySize {
+		return nil, fmt.Errorf("%w: key must be %d bytes", ErrInvalidKey, KeySize)
+	}
+
+	if len(conf.KeyID) > maxKeyIDLength {
+		return nil, fmt.Errorf("%w: key ID must be at most %d bytes", ErrInvalidKey, maxKeyIDLength)
+	}
+
+	if !conf.Algorithm.IsValid() {
+		return nil, fmt.Errorf("%w: unsupported algorithm %d", ErrInvalidKey, uint8(conf.Algorithm))
+	}
+
+	aesGCM, err := newAESGCM(conf.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	xChaCha, err := chacha20poly1305.NewX(conf.Key)
+	if err != nil {
+		return nil, fmt.Errorf("create xchacha20-poly1305: %w", err)
+	}
+
+	return &Vault{
+		keyID:     conf.KeyID,
+		algorithm: conf.Algorithm,
+		aeads: map[Algorithm]cipher.AEAD{
+			AlgorithmAES256GCM:         aesGCM,
+			AlgorithmXChaCha20Poly1305: xChaCha,
+		},
+	}, nil
+}
+
+// KeyID returns the identifier of the vault key.
+func (v *Vault) KeyID() string {
+	return v.keyID
+}
+
+// Seal encrypts and authenticates the plaintext with a random nonce.
+//
+// Parameters:
+//   - plaintext []byte: data to encrypt;
+//   - additionalData []byte: data that is authenticated but not encrypted.
+func (v *Vault) Seal(plaintext []byte, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, v.algorithm.NonceSize())
+
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+
+	return v.sealWithNonce(nonce, plaintext, additionalData)
+}
+
+// Open checks and decrypts the envelope produced by Seal.
+//
+// Returns ErrDecrypt if the envelope or the additional data was modified,
+// and ErrUnknownKey if the envelope was sealed with a key of another ID.
+//
+// Parameters:
+//   - sealed []byte: envelope;
+//   - additionalData []byte: the same additional data as when sealing.
+func (v *Vault) Open(sealed []byte, additionalData []byte) ([]byte, error) {
+	envelope, err := ParseEnvelope(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecrypt, err)
+	}
+
+	if envelope.KeyID != v.keyID {
+		return nil, fmt.Errorf(
+			"%w: envelope key %q, vault key %q",
+			ErrUnknownKey,
+			envelope.KeyID,
+			v.keyID,
+		)
+	}
+
+	ciphertext := make([]byte, 0, len(envelope.Ciphertext)+len(envelope.Tag))
+	ciphertext = append(ciphertext, envelope.Ciphertext...)
+	ciphertext = append(ciphertext, envelope.Tag...)
+
+	plaintext, err := v.aeads[envelope.Algorithm].Open(nil, envelope.Nonce, ciphertext,
+		authenticatedData(envelope, additionalData))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecrypt, err)
+	}
+
+	return plaintext, nil
+}
+
+// sealWithNonce encrypts the plaintext with the given nonce into an envelope.
+func (v *Vault) sealWithNonce(
+	nonce []byte,
+	plaintext []byte,
+	additionalData []byte,
+) ([]byte, error) {
+	envelope := &Envelope{
+		Version:    EnvelopeVersion1,
+		Algorithm:  v.algorithm,
+		KeyID:      v.keyID,
+		Nonce:      nonce,
+		Ciphertext: nil,
+		Tag:        nil,
+	}
+
+	sealed := v.aeads[v.algorithm].Seal(
+		nil,
+		nonce,
+		plaintext,
+		authenticatedData(envelope, additionalData),
+	)
+	envelope.Ciphertext, envelope.Tag = sealed[:len(sealed)-tagSize], sealed[len(sealed)-tagSize:]
+
+	return envelope.MarshalBinary()
+}
+
+// authenticatedData binds the envelope header to the additional data of the caller,
+// so the version, the algorithm and the key ID cannot be swapped unnoticed.
+func authenticatedData(envelope *Envelope, additionalData []byte) []byte {
+	header := envelope.header()
+
+	return append(header, additionalData...)
+}
+
+// newAESGCM creates the AES-256-GCM cipher.
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+
+	return aead, nil
+}
@@ -0,0 +1,164 @@
+package crypto_test
+
+import (
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVault(t *testing.T) *crypto.Vault {
+	t.Helper()
+
+	key, err := crypto.GenerateVaultKey()
+	require.NoError(t, err)
+
+	vault, err := crypto.NewVault(key)
+	require.NoError(t, err)
+
+	return vault
+}
+
+func TestVault_SealOpen(t *testing.T) {
+	t.Parallel()
+
+	vault := newTestVault(t)
+	plaintext := []byte(`{"login":"alice","password":"p@ss"}`)
+
+	sealed, err := vault.Seal(plaintext, []byte("ad"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "alice")
+
+	again, err := vault.Seal(plaintext, []byte("ad"))
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "nonce must be random")
+
+	opened, err := vault.Open(sealed, []byte("ad"))
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+}
+
+func TestVault_OpenRejectsTampering(t *testing.T) {
+	t.Parallel()
+
+	vault := newTestVault(t)
+
+	sealed, err := vault.Seal([]byte("secret"), []byte("ad"))
+	require.NoError(t, err)
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 0x01
+
+	tests := []struct {
+		name           string
+		sealed         []byte
+		additionalData []byte
+		vault          *crypto.Vault
+	}{
+		{name: "modified ciphertext", sealed: tampered, additionalData: []byte("ad"), vault: vault},
+		{name: "other additional data", sealed: sealed, additionalData: []byte("xx"), vault: vault},
+		{name: "other key", sealed: sealed, additionalData: []byte("ad"), vault: newTestVault(t)},
+		{name: "truncated", sealed: sealed[:8], additionalData: []byte("ad"), vault: vault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tt.vault.Open(tt.sealed, tt.additionalData)
+			require.ErrorIs(t, err, crypto.ErrDecrypt)
+		})
+	}
+}
+
+func TestVault_OpenAfterAlgorithmChange(t *testing.T) {
+	t.Parallel()
+
+	key, err := crypto.GenerateVaultKey()
+	require.NoError(t, err)
+
+	oldVault, err := crypto.NewVaultWithConfig(crypto.VaultConfig{
+		Key:       key,
+		KeyID:     "",
+		Algorithm: crypto.AlgorithmAES256GCM,
+	})
+	require.NoError(t, err)
+
+	sealed, err := oldVault.Seal([]byte("secret"), []byte("ad"))
+	require.NoError(t, err)
+
+	newVault, err := crypto.NewVault(key)
+	require.NoError(t, err)
+
+	opened, err := newVault.Open(sealed, []byte("ad"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), opened)
+
+	resealed, err := newVault.Seal(opened, []byte("ad"))
+	require.NoError(t, err)
+
+	envelope, err := crypto.ParseEnvelope(resealed)
+	require.NoError(t, err)
+	assert.Equal(t, crypto.DefaultAlgorithm, envelope.Algorithm)
+}
+
+func TestVault_OpenRejectsForeignKeyID(t *testing.T) {
+	t.Parallel()
+
+	key, err := crypto.GenerateVaultKey()
+	require.NoError(t, err)
+
+	newVault := func(keyID string) *crypto.Vault {
+		vault, err := crypto.NewVaultWithConfig(crypto.VaultConfig{
+			Key:       key,
+			KeyID:     keyID,
+			Algorithm: crypto.DefaultAlgorithm,
+		})
+		require.NoError(t, err)
+
+		return vault
+	}
+
+	sealed, err := newVault("2026-09").Seal([]byte("secret"), nil)
+	require.NoError(t, err)
+
+	_, err = newVault("2026-10").Open(sealed, nil)
+	require.ErrorIs(t, err, crypto.ErrUnknownKey)
+
+	// The key ID is authenticated, so it cannot be rewritten to pass the check.
+	tampered := append([]byte(nil), sealed...)
+	copy(tampered[3:], "2026-10")
+
+	_, err = newVault("2026-10").Open(tampered, nil)
+	require.ErrorIs(t, err, crypto.ErrDecrypt)
+}
+
+func TestMasterKeys_WrapVaultKey(t *testing.T) {
+	t.Parallel()
+
+	params := newFastParams(t)
+
+	keys, err := crypto.DeriveMasterKeys("master password", params)
+	require.NoError(t, err)
+
+	vaultKey, err := crypto.GenerateVaultKey()
+	require.NoError(t, err)
+
+	wrapped, err := keys.WrapVaultKey(vaultKey)
+	require.NoError(t, err)
+	assert.Equal(t, params, wrapped.KDF)
+	assert.NotContains(t, string(wrapped.WrappedKey), string(vaultKey))
+
+	unwrapped, err := keys.UnwrapVaultKey(wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, vaultKey, unwrapped)
+
+	wrongKeys, err := crypto.DeriveMasterKeys("wrong password", params)
+	require.NoError(t, err)
+
+	_, err = wrongKeys.UnwrapVaultKey(wrapped)
+	require.ErrorIs(t, err, crypto.ErrDecrypt)
+
+	_, err = keys.WrapVaultKey([]byte("short"))
+	require.ErrorIs(t, err, crypto.ErrInvalidKey)
+}
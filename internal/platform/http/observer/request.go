@@ -26,10 +26,20 @@ type RequestObserver struct {
 	routeFn  RouteFunc
 }
 
+// maxBufferedRequestBody - size of the beginning of the request body kept for logging.
 const maxBufferedRequestBody = 64 << 10
 
+// replayedBody - request body whose beginning has already been read by the observer.
+type replayedBody struct {
+	io.Reader
+	io.Closer
+}
+
 // NewRequestObserver creates a new *RequestObserver instance.
 //
+// Only the beginning of the body is buffered, the rest is streamed to the handler as is,
+// so large bodies such as upload chunks are neither truncated nor kept in memory.
+//
 // Parameters:
 //   - r *http.Request: request;
 //   - readBody bool: indicates whether the request body should be read.
@@ -49,33 +59,26 @@ func NewRequestObserver(r *http.Request, readBody bool, routeFn RouteFunc) *Requ
 		obs.bodySize = r.ContentLength
 	}
 
-	if readBody && r.Body == nil {
+	if !readBody || r.Body == nil {
 		return obs
 	}
 
-	defer func() {
-		_ = r.Body.Close()
-	}()
-
 	var buf bytes.Buffer
 
 	n, _ := io.CopyN(&buf, r.Body, maxBufferedRequestBody+1)
 
 	if obs.bodySize < 0 {
-		if n <= maxBufferedRequestBody {
-			obs.bodySize = n
-		} else {
-			obs.bodySize = int64(maxBufferedRequestBody) + 1
-		}
+		obs.bodySize = min(n, int64(maxBufferedRequestBody)+1)
 	}
 
-	if buf.Len() > maxBufferedRequestBody {
-		buf.Truncate(maxBufferedRequestBody)
-	}
+	head := buf.Bytes()
 
-	obs.bodyBuf = &buf
+	obs.bodyBuf = bytes.NewBuffer(head[:min(len(head), maxBufferedRequestBody)])
 
-	r.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
+	r.Body = replayedBody{
+		Reader: io.MultiReader(bytes.NewReader(head), r.Body),
+		Closer: r.Body,
+	}
 
 	return obs
 }
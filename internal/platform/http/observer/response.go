@@ -71,6 +71,22 @@ func (r *ResponseObserver) Write(bytes []byte) (int, error) {
 	return num, nil
 }
 
+// Flush sends the buffered data to the client, if the underlying writer supports it.
+//
+// Implements the http.Flusher interface, required for streaming responses.
+func (r *ResponseObserver) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying response writer.
+//
+// Used by http.ResponseController to reach the connection through the observer.
+func (r *ResponseObserver) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // GetStatus returns the response status.
 func (r *ResponseObserver) GetStatus() int {
 	return r.status
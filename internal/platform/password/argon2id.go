@@ -0,0 +1,179 @@
+// Package password provides functionality for hashing and verifying passwords.
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Errors returned when working with password hashes.
+var (
+	// ErrInvalidHash - the encoded hash has an invalid format.
+	ErrInvalidHash = errors.New("invalid hash format")
+
+	// ErrIncompatibleVersion - the hash was created by an unsupported version of the algorithm.
+	ErrIncompatibleVersion = errors.New("incompatible argon2 version")
+)
+
+// Default Argon2id parameters (OWASP recommendations).
+const (
+	defaultMemory      uint32 = 64 * 1024
+	defaultIterations  uint32 = 3
+	defaultParallelism uint8  = 2
+	defaultSaltLength  uint32 = 16
+	defaultKeyLength   uint32 = 32
+)
+
+// Argon2idParams describes the parameters of the Argon2id algorithm.
+type Argon2idParams struct {
+	Memory      uint32 // Amount of memory used by the algorithm (in kibibytes).
+	Iterations  uint32 // Number of iterations over the memory.
+	Parallelism uint8  // Number of threads used by the algorithm.
+	SaltLength  uint32 // Length of the random salt (in bytes).
+	KeyLength   uint32 // Length of the generated key (in bytes).
+}
+
+// DefaultArgon2idParams returns the recommended parameters for Argon2id.
+func DefaultArgon2idParams() Argon2idParams {
+	return Argon2idParams{
+		Memory:      defaultMemory,
+		Iterations:  defaultIterations,
+		Parallelism: defaultParallelism,
+		SaltLength:  defaultSaltLength,
+		KeyLength:   defaultKeyLength,
+	}
+}
+
+// Argon2idHasher hashes passwords using the Argon2id algorithm.
+//
+// Hashes are encoded in the PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>.
+type Argon2idHasher struct {
+	params Argon2idParams
+}
+
+// NewArgon2idHasher creates a new *Argon2idHasher instance.
+//
+// Parameters:
+//   - params Argon2idParams: algorithm parameters.
+func NewArgon2idHasher(params Argon2idParams) *Argon2idHasher {
+	return &Argon2idHasher{
+		params: params,
+	}
+}
+
+// Hash calculates the hash of the password with a random salt.
+//
+// Parameters:
+//   - password string: password in plain form.
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", fmt.Errorf("generate salt: %w", err)
+	}
+
+	key := argon2.IDKey(
+		[]byte(password),
+		salt,
+		h.params.Iterations,
+		h.params.Memory,
+		h.params.Parallelism,
+		h.params.KeyLength,
+	)
+
+	encoded := fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.params.Memory,
+		h.params.Iterations,
+		h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+
+	return encoded, nil
+}
+
+// Verify checks whether the password matches the encoded hash.
+//
+// The parameters of the algorithm are taken from the encoded hash,
+// so hashes created with other parameters remain verifiable.
+//
+// Parameters:
+//   - password string: password in plain form;
+//   - encoded string: hash in the PHC string format.
+func (h *Argon2idHasher) Verify(password string, encoded string) (bool, error) {
+	params, salt, key, err := decodeHash(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	otherKey := argon2.IDKey(
+		[]byte(password),
+		salt,
+		params.Iterations,
+		params.Memory,
+		params.Parallelism,
+		params.KeyLength,
+	)
+
+	return subtle.ConstantTimeCompare(key, otherKey) == 1, nil
+}
+
+const hashPartsCount = 6
+
+func decodeHash(encoded string) (Argon2idParams, []byte, []byte, error) {
+	var params Argon2idParams
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != hashPartsCount || parts[1] != "argon2id" {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("%w: %w", ErrInvalidHash, err)
+	}
+
+	if version != argon2.Version {
+		return params, nil, nil, ErrIncompatibleVersion
+	}
+
+	_, err = fmt.Sscanf(
+		parts[3],
+		"m=%d,t=%d,p=%d",
+		&params.Memory,
+		&params.Iterations,
+		&params.Parallelism,
+	)
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("%w: %w", ErrInvalidHash, err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("%w: %w", ErrInvalidHash, err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("%w: %w", ErrInvalidHash, err)
+	}
+
+	//nolint:gosec // lengths are limited by the size of the encoded hash
+	params.SaltLength = uint32(len(salt))
+	//nolint:gosec // lengths are limited by the size of the encoded hash
+	params.KeyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,98 @@
+package password_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/password"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHasher() *password.Argon2idHasher {
+	return password.NewArgon2idHasher(password.Argon2idParams{
+		Memory:      1024,
+		Iterations:  1,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	})
+}
+
+func TestArgon2idHasher_HashAndVerify(t *testing.T) {
+	t.Parallel()
+
+	hasher := newTestHasher()
+
+	encoded, err := hasher.Hash("secret")
+	require.NoError(t, err)
+
+	assert.True(
+		t,
+		strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$"),
+		"unexpected hash prefix",
+	)
+
+	valid, err := hasher.Verify("secret", encoded)
+	require.NoError(t, err)
+	assert.True(t, valid, "correct password must be verified")
+
+	valid, err = hasher.Verify("wrong", encoded)
+	require.NoError(t, err)
+	assert.False(t, valid, "wrong password must not be verified")
+}
+
+func TestArgon2idHasher_HashUsesRandomSalt(t *testing.T) {
+	t.Parallel()
+
+	hasher := newTestHasher()
+
+	first, err := hasher.Hash("secret")
+	require.NoError(t, err)
+
+	second, err := hasher.Hash("secret")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+}
+
+func TestArgon2idHasher_VerifyInvalidHash(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr error
+	}{
+		{
+			name:    "empty string",
+			encoded: "",
+			wantErr: password.ErrInvalidHash,
+		},
+		{
+			name:    "other algorithm",
+			encoded: "$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$a2V5",
+			wantErr: password.ErrInvalidHash,
+		},
+		{
+			name:    "other version",
+			encoded: "$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$a2V5",
+			wantErr: password.ErrIncompatibleVersion,
+		},
+		{
+			name:    "broken salt",
+			encoded: "$argon2id$v=19$m=1024,t=1,p=1$!!!$a2V5",
+			wantErr: password.ErrInvalidHash,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			valid, err := newTestHasher().Verify("secret", tt.encoded)
+
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.False(t, valid)
+		})
+	}
+}
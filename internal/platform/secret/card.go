@@ -0,0 +1,365 @@
+// Package secret describes the kinds of secrets stored in the password keeper and their payloads.
+//
+// The package is shared between the server and the clients.
+package secret
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CardNetwork - payment network of the bank card.
+type CardNetwork string
+
+// Constants - payment networks detected by the issuer identification number.
+const (
+	CardNetworkVisa       CardNetwork = "visa"
+	CardNetworkMastercard CardNetwork = "mastercard"
+	CardNetworkAmex       CardNetwork = "amex"
+	CardNetworkDiscover   CardNetwork = "discover"
+	CardNetworkJCB        CardNetwork = "jcb"
+	CardNetworkDiners     CardNetwork = "diners"
+	CardNetworkUnionPay   CardNetwork = "unionpay"
+	CardNetworkMaestro    CardNetwork = "maestro"
+	CardNetworkMir        CardNetwork = "mir"
+	CardNetworkUnknown    CardNetwork = "unknown"
+)
+
+// Keys of the secret metadata holding the masked card shown in the listings.
+const (
+	MetadataCardNetwork  = "card.network"
+	MetadataCardLastFour = "card.lastFour"
+)
+
+// Restrictions on the card fields.
+const (
+	minCardNumberLength = 12
+	maxCardNumberLength = 19
+	cardLastFourLength  = 4
+	cardExpiryLayout    = "01/06"
+	cvvLength           = 3
+	amexCVVLength       = 4
+	luhnBase            = 10
+)
+
+// iinRange describes the range of issuer identification numbers of the network.
+type iinRange struct {
+	first     int         // First prefix of the range.
+	last      int         // Last prefix of the range, has the same number of digits as the first.
+	network   CardNetwork // Network the range belongs to.
+	minLength int         // Minimum length of the card number.
+	maxLength int         // Maximum length of the card number.
+}
+
+// iinRanges - known ranges of the networks, narrower ranges go before the overlapping wider ones.
+//
+//nolint:gochecknoglobals,mnd // immutable table of the prefixes and the lengths defined by the networks
+var iinRanges = []iinRange{
+	{first: 34, last: 34, network: CardNetworkAmex, minLength: 15, maxLength: 15},
+	{first: 37, last: 37, network: CardNetworkAmex, minLength: 15, maxLength: 15},
+	{first: 300, last: 305, network: CardNetworkDiners, minLength: 14, maxLength: 19},
+	{first: 36, last: 36, network: CardNetworkDiners, minLength: 14, maxLength: 19},
+	{first: 38, last: 39, network: CardNetworkDiners, minLength: 14, maxLength: 19},
+	{first: 3528, last: 3589, network: CardNetworkJCB, minLength: 16, maxLength: 19},
+	{first: 622126, last: 622925, network: CardNetworkDiscover, minLength: 16, maxLength: 19},
+	{first: 6011, last: 6011, network: CardNetworkDiscover, minLength: 16, maxLength: 19},
+	{first: 644, last: 649, network: CardNetworkDiscover, minLength: 16, maxLength: 19},
+	{first: 65, last: 65, network: CardNetworkDiscover, minLength: 16, maxLength: 19},
+	{first: 62, last: 62, network: CardNetworkUnionPay, minLength: 16, maxLength: 19},
+	{first: 2200, last: 2204, network: CardNetworkMir, minLength: 16, maxLength: 19},
+	{first: 2221, last: 2720, network: CardNetworkMastercard, minLength: 16, maxLength: 16},
+	{first: 51, last: 55, network: CardNetworkMastercard, minLength: 16, maxLength: 16},
+	{first: 6304, last: 6304, network: CardNetworkMaestro, minLength: 12, maxLength: 19},
+	{first: 50, last: 50, network: CardNetworkMaestro, minLength: 12, maxLength: 19},
+	{first: 56, last: 58, network: CardNetworkMaestro, minLength: 12, maxLength: 19},
+	{first: 67, last: 67, network: CardNetworkMaestro, minLength: 12, maxLength: 19},
+	{first: 4, last: 4, network: CardNetworkVisa, minLength: 13, maxLength: 19},
+}
+
+// IsValid checks whether the network is supported.
+func (n CardNetwork) IsValid() bool {
+	switch n {
+	case CardNetworkVisa, CardNetworkMastercard, CardNetworkAmex, CardNetworkDiscover,
+		CardNetworkJCB, CardNetworkDiners, CardNetworkUnionPay, CardNetworkMaestro,
+		CardNetworkMir, CardNetworkUnknown:
+		return true
+	default:
+		return false
+	}
+}
+
+// String returns a string representation of the network.
+//
+// Implements the fmt.Stringer interface.
+func (n CardNetwork) String() string {
+	return string(n)
+}
+
+// DetectCardNetwork returns the network of the card number by its issuer identification number.
+//
+// Parameters:
+//   - number string: card number, may contain spaces and dashes.
+func DetectCardNetwork(number string) CardNetwork {
+	iin, ok := findIINRange(NormalizeCardNumber(number))
+	if !ok {
+		return CardNetworkUnknown
+	}
+
+	return iin.network
+}
+
+// NormalizeCardNumber returns the card number without the spaces and dashes between the digits.
+//
+// Parameters:
+//   - number string: card number.
+func NormalizeCardNumber(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(number)
+}
+
+// LuhnValid checks the check digit of the number with the Luhn algorithm.
+//
+// Parameters:
+//   - number string: number of digits only.
+func LuhnValid(number string) bool {
+	if number == "" || !isDigits(number) {
+		return false
+	}
+
+	sum := 0
+	double := false
+
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+
+		if double {
+			digit *= 2
+			if digit >= luhnBase {
+				digit -= luhnBase - 1
+			}
+		}
+
+		sum += digit
+		double = !double
+	}
+
+	return sum%luhnBase == 0
+}
+
+// Card - payload of the bank card.
+type Card struct {
+	Number string `json:"number"` // Card number.
+	Holder string `json:"holder"` // Cardholder name.
+	Expiry string `json:"expiry"` // Expiration date in the MM/YY format.
+	CVV    string `json:"cvv"`    // Card verification value.
+}
+
+// NewCard returns the payload of the card with the normalized number and validates it.
+//
+// Parameters:
+//   - number string: card number, may contain spaces and dashes;
+//   - holder string: cardholder name;
+//   - expiry string: expiration date in the MM/YY format;
+//   - cvv string: card verification value.
+func NewCard(number string, holder string, expiry string, cvv string) (*Card, error) {
+	card := &Card{
+		Number: NormalizeCardNumber(number),
+		Holder: strings.TrimSpace(holder),
+		Expiry: strings.TrimSpace(expiry),
+		CVV:    strings.TrimSpace(cvv),
+	}
+
+	err := card.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return card, nil
+}
+
+// Type returns the kind of secret.
+//
+// Implements the Payload interface.
+func (c *Card) Type() Type {
+	return TypeCard
+}
+
+// Validate checks the content of the secret.
+//
+// The number must pass the Luhn check and have the length of its network,
+// the CVV of American Express cards has 4 digits, of the other cards 3.
+//
+// Implements the Payload interface.
+func (c *Card) Validate() error {
+	err := validateCardNumber(NormalizeCardNumber(c.Number))
+	if err != nil {
+		return err
+	}
+
+	if c.Expiry != "" {
+		_, err = time.Parse(cardExpiryLayout, c.Expiry)
+		if err != nil || len(c.Expiry) != len(cardExpiryLayout) {
+			return fmt.Errorf("%w: expiry must be in the MM/YY format", ErrInvalidPayload)
+		}
+	}
+
+	if c.CVV != "" {
+		length := cvvLength
+		if c.Network() == CardNetworkAmex {
+			length = amexCVVLength
+		}
+
+		if len(c.CVV) != length || !isDigits(c.CVV) {
+			return fmt.Errorf("%w: cvv of %s card must be %d digits", ErrInvalidPayload,
+				c.Network(), length)
+		}
+	}
+
+	return nil
+}
+
+// Network returns the payment network of the card.
+func (c *Card) Network() CardNetwork {
+	return DetectCardNetwork(c.Number)
+}
+
+// Summary returns the masked card that can be stored without encryption.
+func (c *Card) Summary() CardSummary {
+	number := NormalizeCardNumber(c.Number)
+
+	return CardSummary{
+		Network:  c.Network(),
+		LastFour: number[max(len(number)-cardLastFourLength, 0):],
+	}
+}
+
+// CardSummary - masked card shown in the listings instead of the number: network and last four digits.
+type CardSummary struct {
+	Network  CardNetwork // Payment network.
+	LastFour string      // Last four digits of the number.
+}
+
+// Metadata returns the secret metadata entries of the masked card.
+func (s CardSummary) Metadata() map[string]string {
+	return map[string]string{
+		MetadataCardNetwork:  s.Network.String(),
+		MetadataCardLastFour: s.LastFour,
+	}
+}
+
+// String returns the masked number, e.g. "visa •••• 1111".
+//
+// Implements the fmt.Stringer interface.
+func (s CardSummary) String() string {
+	return fmt.Sprintf("%s •••• %s", s.Network, s.LastFour)
+}
+
+// ValidateCardMetadata checks the metadata of the card secret, which is stored without encryption:
+// the masked card entries are required and must be well-formed, and no value may contain
+// a card number.
+//
+// Parameters:
+//   - metadata map[string]string: free-form metadata of the card secret.
+func ValidateCardMetadata(metadata map[string]string) error {
+	if network := metadata[MetadataCardNetwork]; !CardNetwork(network).IsValid() {
+		return fmt.Errorf("%w: %s must be a known card network, got %q", ErrInvalidPayload,
+			MetadataCardNetwork, network)
+	}
+
+	if lastFour := metadata[MetadataCardLastFour]; len(lastFour) != cardLastFourLength ||
+		!isDigits(lastFour) {
+		return fmt.Errorf("%w: %s must be %d digits", ErrInvalidPayload,
+			MetadataCardLastFour, cardLastFourLength)
+	}
+
+	for key, value := range metadata {
+		if containsCardNumber(value) {
+			return fmt.Errorf("%w: metadata %s must not contain the card number",
+				ErrInvalidPayload, key)
+		}
+	}
+
+	return nil
+}
+
+// validateCardNumber checks the length and the check digit of the normalized card number.
+func validateCardNumber(number string) error {
+	if number == "" || !isDigits(number) {
+		return fmt.Errorf("%w: card number must contain only digits", ErrInvalidPayload)
+	}
+
+	minLength, maxLength := minCardNumberLength, maxCardNumberLength
+
+	iin, ok := findIINRange(number)
+	if ok {
+		minLength, maxLength = iin.minLength, iin.maxLength
+	}
+
+	if len(number) < minLength || len(number) > maxLength {
+		return fmt.Errorf("%w: number of %s card must be from %d to %d digits",
+			ErrInvalidPayload, DetectCardNetwork(number), minLength, maxLength)
+	}
+
+	if !LuhnValid(number) {
+		return fmt.Errorf("%w: card number fails the Luhn check", ErrInvalidPayload)
+	}
+
+	return nil
+}
+
+// findIINRange returns the range of issuer identification numbers the normalized number belongs to.
+func findIINRange(number string) (iinRange, bool) {
+	for _, iin := range iinRanges {
+		length := len(strconv.Itoa(iin.first))
+		if len(number) < length {
+			continue
+		}
+
+		prefix, err := strconv.Atoi(number[:length])
+		if err == nil && prefix >= iin.first && prefix <= iin.last {
+			return iin, true
+		}
+	}
+
+	return iinRange{
+		first:     0,
+		last:      0,
+		network:   CardNetworkUnknown,
+		minLength: 0,
+		maxLength: 0,
+	}, false
+}
+
+// containsCardNumber checks whether the text has a sequence of digits, possibly separated
+// by spaces and dashes, that looks like a card number.
+func containsCardNumber(text string) bool {
+	var digits strings.Builder
+
+	for _, r := range text + "." {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case (r == ' ' || r == '-') && digits.Len() > 0:
+		default:
+			if isCardNumber(digits.String()) {
+				return true
+			}
+
+			digits.Reset()
+		}
+	}
+
+	return false
+}
+
+// isCardNumber checks whether the digits have the length of a card number and pass the Luhn check.
+func isCardNumber(digits string) bool {
+	return len(digits) >= minCardNumberLength && len(digits) <= maxCardNumberLength &&
+		LuhnValid(digits)
+}
+
+// isDigits checks whether the string consists of ASCII digits only.
+func isDigits(value string) bool {
+	return strings.IndexFunc(value, func(r rune) bool { return r < '0' || r > '9' }) < 0
+}
@@ -0,0 +1,126 @@
+package secret_test
+
+import (
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLuhnValid(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, secret.LuhnValid("4111111111111111"))
+	assert.True(t, secret.LuhnValid("79927398713"))
+	assert.False(t, secret.LuhnValid("4111111111111112"))
+	assert.False(t, secret.LuhnValid("4111-1111"))
+	assert.False(t, secret.LuhnValid(""))
+}
+
+func TestDetectCardNetwork(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		number string
+		want   secret.CardNetwork
+	}{
+		{number: "4111 1111 1111 1111", want: secret.CardNetworkVisa},
+		{number: "5555555555554444", want: secret.CardNetworkMastercard},
+		{number: "2223003122003222", want: secret.CardNetworkMastercard},
+		{number: "378282246310005", want: secret.CardNetworkAmex},
+		{number: "6011111111111117", want: secret.CardNetworkDiscover},
+		{number: "6221260000000000", want: secret.CardNetworkDiscover},
+		{number: "6200000000000005", want: secret.CardNetworkUnionPay},
+		{number: "3530111333300000", want: secret.CardNetworkJCB},
+		{number: "30569309025904", want: secret.CardNetworkDiners},
+		{number: "2200000000000004", want: secret.CardNetworkMir},
+		{number: "6759649826438453", want: secret.CardNetworkMaestro},
+		{number: "9999999999999995", want: secret.CardNetworkUnknown},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, secret.DetectCardNetwork(tt.number), tt.number)
+	}
+}
+
+func TestNewCard(t *testing.T) {
+	t.Parallel()
+
+	card, err := secret.NewCard("3782-822463-10005", " ALICE ", "12/30", "1234")
+	require.NoError(t, err)
+	assert.Equal(t, &secret.Card{
+		Number: "378282246310005",
+		Holder: "ALICE",
+		Expiry: "12/30",
+		CVV:    "1234",
+	}, card)
+	assert.Equal(t, secret.CardSummary{Network: secret.CardNetworkAmex, LastFour: "0005"},
+		card.Summary())
+	assert.Equal(t, "amex •••• 0005", card.Summary().String())
+}
+
+func TestCard_ValidateInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		card secret.Card
+	}{
+		{name: "letters", card: secret.Card{Number: "4111-abc", Holder: "", Expiry: "", CVV: ""}},
+		{
+			name: "luhn",
+			card: secret.Card{Number: "4111111111111112", Holder: "", Expiry: "", CVV: ""},
+		},
+		{name: "short", card: secret.Card{Number: "42424242426", Holder: "", Expiry: "", CVV: ""}},
+		{name: "network length", card: secret.Card{
+			Number: "5555555555554444000", Holder: "", Expiry: "", CVV: "",
+		}},
+		{name: "expiry month", card: secret.Card{
+			Number: "4111111111111111", Holder: "", Expiry: "13/30", CVV: "",
+		}},
+		{name: "expiry format", card: secret.Card{
+			Number: "4111111111111111", Holder: "", Expiry: "12/2030", CVV: "",
+		}},
+		{name: "cvv digits", card: secret.Card{
+			Number: "4111111111111111", Holder: "", Expiry: "", CVV: "12a",
+		}},
+		{name: "cvv length", card: secret.Card{
+			Number: "4111111111111111", Holder: "", Expiry: "", CVV: "1234",
+		}},
+		{name: "amex cvv length", card: secret.Card{
+			Number: "378282246310005", Holder: "", Expiry: "", CVV: "123",
+		}},
+	}
+	for _, tt := range tests {
+		err := tt.card.Validate()
+		require.ErrorIs(t, err, secret.ErrInvalidPayload, tt.name)
+	}
+}
+
+func TestValidateCardMetadata(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, secret.ValidateCardMetadata(map[string]string{
+		secret.MetadataCardNetwork:  "visa",
+		secret.MetadataCardLastFour: "1111",
+		"bank":                      "Bank 2024",
+	}))
+
+	invalid := []map[string]string{
+		nil,
+		{secret.MetadataCardNetwork: "visa"},
+		{secret.MetadataCardLastFour: "1111"},
+		{secret.MetadataCardNetwork: "bankcard", secret.MetadataCardLastFour: "1111"},
+		{secret.MetadataCardNetwork: "visa", secret.MetadataCardLastFour: "111"},
+		{secret.MetadataCardNetwork: "visa", secret.MetadataCardLastFour: "11a1"},
+		{
+			secret.MetadataCardNetwork:  "visa",
+			secret.MetadataCardLastFour: "1111",
+			"note":                      "card 4111-1111-1111-1111, personal",
+		},
+	}
+	for _, metadata := range invalid {
+		err := secret.ValidateCardMetadata(metadata)
+		require.ErrorIs(t, err, secret.ErrInvalidPayload, metadata)
+	}
+}
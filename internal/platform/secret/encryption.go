@@ -0,0 +1,69 @@
+// Package secret describes the kinds of secrets stored in the password keeper and their payloads.
+//
+// The package is shared between the server and the clients.
+package secret
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// additionalDataPrefix binds the ciphertext of the payload to the kind of secret,
+// so the server cannot pass off a payload as a secret of another kind.
+const additionalDataPrefix = "go-password-keeper/v1/secret:"
+
+// Cipher describes authenticated encryption of payloads on the client.
+//
+// Implemented by crypto.Vault.
+type Cipher interface {
+	// Seal encrypts and authenticates the plaintext.
+	Seal(plaintext []byte, additionalData []byte) ([]byte, error)
+
+	// Open checks and decrypts the ciphertext.
+	Open(sealed []byte, additionalData []byte) ([]byte, error)
+}
+
+// EncryptPayload validates the payload and encrypts it for uploading to the server.
+//
+// Parameters:
+//   - cipher Cipher: cipher with the vault key;
+//   - payload Payload: secret payload.
+func EncryptPayload(cipher Cipher, payload Payload) ([]byte, error) {
+	err := payload.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("validate %s payload: %w", payload.Type(), err)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	sealed, err := cipher.Seal(data, additionalData(payload.Type()))
+	if err != nil {
+		return nil, fmt.Errorf("encrypt payload: %w", err)
+	}
+
+	return sealed, nil
+}
+
+// DecryptPayload decrypts the payload received from the server and validates it.
+//
+// Parameters:
+//   - cipher Cipher: cipher with the vault key;
+//   - secretType Type: kind of secret;
+//   - data []byte: encrypted payload.
+//
+//nolint:ireturn // the kind of payload is determined at runtime
+func DecryptPayload(cipher Cipher, secretType Type, data []byte) (Payload, error) {
+	plaintext, err := cipher.Open(data, additionalData(secretType))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt payload: %w", err)
+	}
+
+	return DecodePayload(secretType, plaintext)
+}
+
+func additionalData(secretType Type) []byte {
+	return []byte(additionalDataPrefix + secretType.String())
+}
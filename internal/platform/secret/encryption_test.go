@@ -0,0 +1,41 @@
+package secret_test
+
+import (
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptPayload(t *testing.T) {
+	t.Parallel()
+
+	key, err := crypto.GenerateVaultKey()
+	require.NoError(t, err)
+
+	vault, err := crypto.NewVault(key)
+	require.NoError(t, err)
+
+	payload := &secret.Credentials{Login: "alice", Password: "p@ss"}
+
+	data, err := secret.EncryptPayload(vault, payload)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "alice")
+
+	decrypted, err := secret.DecryptPayload(vault, secret.TypeCredentials, data)
+	require.NoError(t, err)
+	assert.Equal(t, payload, decrypted)
+
+	_, err = secret.DecryptPayload(vault, secret.TypeText, data)
+	require.ErrorIs(t, err, crypto.ErrDecrypt, "payload must be bound to its kind")
+
+	_, err = secret.EncryptPayload(vault, &secret.Card{
+		Number: "4111-abc",
+		Holder: "ALICE",
+		Expiry: "12/30",
+		CVV:    "123",
+	})
+	require.Error(t, err, "invalid payload must not be encrypted")
+}
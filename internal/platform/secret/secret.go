@@ -0,0 +1,260 @@
+// Package secret describes the kinds of secrets stored in the password keeper and their payloads.
+//
+// The package is shared between the server and the clients.
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/totp"
+)
+
+// Type - kind of secret.
+type Type string
+
+// Constants - supported kinds of secrets.
+const (
+	// TypeCredentials - login/password pair.
+	TypeCredentials Type = "credentials"
+
+	// TypeText - arbitrary text.
+	TypeText Type = "text"
+
+	// TypeBinary - arbitrary binary data.
+	TypeBinary Type = "binary"
+
+	// TypeCard - bank card.
+	TypeCard Type = "card"
+
+	// TypeTOTP - seed of time-based one-time passwords.
+	TypeTOTP Type = "totp"
+)
+
+// ErrInvalidPayload - the payload does not meet the requirements of its kind.
+var ErrInvalidPayload = errors.New("invalid secret payload")
+
+// IsValid checks whether the kind of secret is supported.
+func (t Type) IsValid() bool {
+	switch t {
+	case TypeCredentials, TypeText, TypeBinary, TypeCard, TypeTOTP:
+		return true
+	default:
+		return false
+	}
+}
+
+// String returns a string representation of the kind of secret.
+//
+// Implements the fmt.Stringer interface.
+func (t Type) String() string {
+	return string(t)
+}
+
+// Payload describes the content of a secret of a certain kind.
+type Payload interface {
+	// Type returns the kind of secret.
+	Type() Type
+
+	// Validate checks the content of the secret.
+	Validate() error
+}
+
+// Credentials - payload of the login/password pair.
+type Credentials struct {
+	Login    string `json:"login"`    // Login.
+	Password string `json:"password"` // Password.
+}
+
+// Type returns the kind of secret.
+//
+// Implements the Payload interface.
+func (c *Credentials) Type() Type {
+	return TypeCredentials
+}
+
+// Validate checks the content of the secret.
+//
+// Implements the Payload interface.
+func (c *Credentials) Validate() error {
+	if c.Login == "" && c.Password == "" {
+		return fmt.Errorf("%w: login and password are empty", ErrInvalidPayload)
+	}
+
+	return nil
+}
+
+// Text - payload of arbitrary text.
+type Text struct {
+	Value string `json:"value"` // Text.
+}
+
+// Type returns the kind of secret.
+//
+// Implements the Payload interface.
+func (t *Text) Type() Type {
+	return TypeText
+}
+
+// Validate checks the content of the secret.
+//
+// Implements the Payload interface.
+func (t *Text) Validate() error {
+	if t.Value == "" {
+		return fmt.Errorf("%w: text is empty", ErrInvalidPayload)
+	}
+
+	return nil
+}
+
+// Binary - payload of arbitrary binary data.
+type Binary struct {
+	FileName string `json:"fileName"` // Original file name (optional).
+	Data     []byte `json:"data"`     // Data (base64 in JSON).
+}
+
+// Type returns the kind of secret.
+//
+// Implements the Payload interface.
+func (b *Binary) Type() Type {
+	return TypeBinary
+}
+
+// Validate checks the content of the secret.
+//
+// Implements the Payload interface.
+func (b *Binary) Validate() error {
+	if len(b.Data) == 0 {
+		return fmt.Errorf("%w: data is empty", ErrInvalidPayload)
+	}
+
+	return nil
+}
+
+// TOTP - payload of the seed of time-based one-time passwords (RFC 6238).
+type TOTP struct {
+	Issuer    string         `json:"issuer"`    // Service the seed belongs to (optional).
+	Account   string         `json:"account"`   // Account name (optional).
+	Secret    string         `json:"secret"`    // Shared secret in the base32 form.
+	Algorithm totp.Algorithm `json:"algorithm"` // HMAC hash function.
+	Digits    int            `json:"digits"`    // Number of digits in the code.
+	Period    int            `json:"period"`    // Lifetime of a code in seconds.
+}
+
+// NewTOTP returns the payload of the key.
+//
+// Parameters:
+//   - key *totp.Key: key of one-time passwords.
+func NewTOTP(key *totp.Key) *TOTP {
+	return &TOTP{
+		Issuer:    key.Issuer,
+		Account:   key.Account,
+		Secret:    totp.EncodeSecret(key.Secret),
+		Algorithm: key.Algorithm,
+		Digits:    key.Digits,
+		Period:    int(key.Period / time.Second),
+	}
+}
+
+// ParseTOTPURI returns the payload of the key given by the otpauth:// URI.
+//
+// Parameters:
+//   - uri string: otpauth://totp/ URI.
+func ParseTOTPURI(uri string) (*TOTP, error) {
+	key, err := totp.ParseURI(uri)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+	}
+
+	return NewTOTP(key), nil
+}
+
+// Type returns the kind of secret.
+//
+// Implements the Payload interface.
+func (t *TOTP) Type() Type {
+	return TypeTOTP
+}
+
+// Validate checks the content of the secret.
+//
+// Implements the Payload interface.
+func (t *TOTP) Validate() error {
+	_, err := t.Key()
+
+	return err
+}
+
+// Key returns the key the codes are generated with.
+func (t *TOTP) Key() (*totp.Key, error) {
+	secret, err := totp.DecodeSecret(t.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+	}
+
+	key := &totp.Key{
+		Issuer:    t.Issuer,
+		Account:   t.Account,
+		Secret:    secret,
+		Algorithm: t.Algorithm,
+		Digits:    t.Digits,
+		Period:    time.Duration(t.Period) * time.Second,
+	}
+
+	err = key.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+	}
+
+	return key, nil
+}
+
+// DecodePayload decodes the JSON payload of the specified kind of secret and validates it.
+//
+// Parameters:
+//   - secretType Type: kind of secret;
+//   - data []byte: payload in JSON format.
+//
+//nolint:ireturn // the kind of payload is determined at runtime
+func DecodePayload(secretType Type, data []byte) (Payload, error) {
+	payload, err := newPayload(secretType)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	err = decoder.Decode(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+	}
+
+	err = payload.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("validate %s payload: %w", secretType, err)
+	}
+
+	return payload, nil
+}
+
+//nolint:ireturn // the kind of payload is determined at runtime
+func newPayload(secretType Type) (Payload, error) {
+	switch secretType {
+	case TypeCredentials:
+		return &Credentials{Login: "", Password: ""}, nil
+	case TypeText:
+		return &Text{Value: ""}, nil
+	case TypeBinary:
+		return &Binary{FileName: "", Data: nil}, nil
+	case TypeCard:
+		return &Card{Number: "", Holder: "", Expiry: "", CVV: ""}, nil
+	case TypeTOTP:
+		return &TOTP{Issuer: "", Account: "", Secret: "", Algorithm: "", Digits: 0, Period: 0}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidPayload, secretType)
+	}
+}
@@ -0,0 +1,59 @@
+package secret_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTOTPURI(t *testing.T) {
+	t.Parallel()
+
+	payload, err := secret.ParseTOTPURI(
+		"otpauth://totp/GitHub:bob?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&digits=8&issuer=GitHub")
+	require.NoError(t, err)
+	assert.Equal(t, &secret.TOTP{
+		Issuer:    "GitHub",
+		Account:   "bob",
+		Secret:    "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		Algorithm: totp.AlgorithmSHA1,
+		Digits:    8,
+		Period:    30,
+	}, payload)
+
+	key, err := payload.Key()
+	require.NoError(t, err)
+
+	// The seed of the RFC 6238 test vectors.
+	code, err := key.Code(time.Unix(59, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "94287082", code)
+
+	_, err = secret.ParseTOTPURI("otpauth://hotp/bob?secret=GEZDGNBV")
+	require.ErrorIs(t, err, secret.ErrInvalidPayload)
+}
+
+func TestDecodePayload_TOTP(t *testing.T) {
+	t.Parallel()
+
+	payload, err := secret.DecodePayload(secret.TypeTOTP, []byte(
+		`{"issuer":"","account":"alice","secret":"JBSWY3DP","algorithm":"SHA256","digits":6,"period":30}`,
+	))
+	require.NoError(t, err)
+	assert.Equal(t, secret.TypeTOTP, payload.Type())
+
+	invalid := []string{
+		`{"secret":"","algorithm":"SHA1","digits":6,"period":30}`,
+		`{"secret":"JBSWY3DP","algorithm":"MD5","digits":6,"period":30}`,
+		`{"secret":"JBSWY3DP","algorithm":"SHA1","digits":4,"period":30}`,
+		`{"secret":"JBSWY3DP","algorithm":"SHA1","digits":6,"period":0}`,
+	}
+	for _, data := range invalid {
+		_, err = secret.DecodePayload(secret.TypeTOTP, []byte(data))
+		require.ErrorIs(t, err, secret.ErrInvalidPayload, data)
+	}
+}
@@ -0,0 +1,184 @@
+// Package token provides functionality for issuing and verifying signed access tokens (JWT).
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Errors returned when working with tokens.
+var (
+	// ErrInvalidToken - the token is malformed, has an invalid signature or has expired.
+	ErrInvalidToken = errors.New("invalid token")
+
+	// ErrInvalidKey - the signing key is empty or has an unsupported format.
+	ErrInvalidKey = errors.New("invalid signing key")
+)
+
+const minHMACSecretLength = 32
+
+// Claims describes the data contained in the access token.
+type Claims struct {
+	jwt.RegisteredClaims
+
+	// Sid - identifier of the session the token was issued in.
+	Sid string `json:"sid,omitempty"`
+}
+
+// UserID returns the identifier of the user the token was issued to.
+func (c *Claims) UserID() string {
+	return c.Subject
+}
+
+// SessionID returns the identifier of the session the token was issued in.
+func (c *Claims) SessionID() string {
+	return c.Sid
+}
+
+// Manager issues and verifies access tokens.
+type Manager struct {
+	method    jwt.SigningMethod
+	signKey   crypto.PrivateKey
+	verifyKey crypto.PublicKey
+	issuer    string
+	ttl       time.Duration
+}
+
+// NewHMACManager creates a new *Manager instance that signs tokens with HMAC-SHA256.
+//
+// Parameters:
+//   - secret []byte: shared secret (at least 32 bytes);
+//   - issuer string: token issuer;
+//   - ttl time.Duration: token lifetime.
+func NewHMACManager(secret []byte, issuer string, ttl time.Duration) (*Manager, error) {
+	if len(secret) < minHMACSecretLength {
+		return nil, fmt.Errorf(
+			"%w: secret must be at least %d bytes",
+			ErrInvalidKey,
+			minHMACSecretLength,
+		)
+	}
+
+	return &Manager{
+		method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+		issuer:    issuer,
+		ttl:       ttl,
+	}, nil
+}
+
+// NewEd25519Manager creates a new *Manager instance that signs tokens with Ed25519 (EdDSA).
+//
+// Parameters:
+//   - privateKey ed25519.PrivateKey: private key;
+//   - issuer string: token issuer;
+//   - ttl time.Duration: token lifetime.
+func NewEd25519Manager(
+	privateKey ed25519.PrivateKey,
+	issuer string,
+	ttl time.Duration,
+) (*Manager, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w: wrong ed25519 private key size", ErrInvalidKey)
+	}
+
+	return &Manager{
+		method:    jwt.SigningMethodEdDSA,
+		signKey:   privateKey,
+		verifyKey: privateKey.Public(),
+		issuer:    issuer,
+		ttl:       ttl,
+	}, nil
+}
+
+// ParseEd25519PrivateKey parses an Ed25519 private key in the PEM (PKCS #8) format.
+//
+// Parameters:
+//   - data []byte: PEM-encoded key.
+func ParseEd25519PrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block found", ErrInvalidKey)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: key is not ed25519", ErrInvalidKey)
+	}
+
+	return privateKey, nil
+}
+
+// Issue creates a signed access token for the user session.
+//
+// Returns the token and its expiration time.
+//
+// Parameters:
+//   - userID string: user identifier;
+//   - sessionID string: session identifier.
+func (m *Manager) Issue(userID string, sessionID string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.ttl)
+
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.issuer,
+			Subject:   userID,
+			Audience:  nil,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			NotBefore: nil,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+		Sid: sessionID,
+	}
+
+	signed, err := jwt.NewWithClaims(m.method, claims).SignedString(m.signKey)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("sign token: %w", err)
+	}
+
+	return signed, expiresAt, nil
+}
+
+// Verify checks the signature and lifetime of the token and returns its claims.
+//
+// Parameters:
+//   - tokenString string: signed token.
+func (m *Manager) Verify(tokenString string) (*Claims, error) {
+	claims := new(Claims)
+
+	_, err := jwt.ParseWithClaims(
+		tokenString,
+		claims,
+		func(_ *jwt.Token) (any, error) {
+			return m.verifyKey, nil
+		},
+		jwt.WithValidMethods([]string{m.method.Alg()}),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: subject is empty", ErrInvalidToken)
+	}
+
+	return claims, nil
+}
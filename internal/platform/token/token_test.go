@@ -0,0 +1,125 @@
+package token_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIssuer = "test"
+
+func newHMACManager(t *testing.T, secret string, ttl time.Duration) *token.Manager {
+	t.Helper()
+
+	manager, err := token.NewHMACManager([]byte(secret), testIssuer, ttl)
+	require.NoError(t, err)
+
+	return manager
+}
+
+func TestManager_IssueAndVerify(t *testing.T) {
+	t.Parallel()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	edManager, err := token.NewEd25519Manager(privateKey, testIssuer, time.Minute)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		manager *token.Manager
+	}{
+		{
+			name:    "hmac",
+			manager: newHMACManager(t, "0123456789abcdef0123456789abcdef", time.Minute),
+		},
+		{
+			name:    "ed25519",
+			manager: edManager,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			signed, expiresAt, err := tt.manager.Issue("user-1", "session-1")
+			require.NoError(t, err)
+			assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, time.Second)
+
+			claims, err := tt.manager.Verify(signed)
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", claims.UserID())
+			assert.Equal(t, "session-1", claims.SessionID())
+		})
+	}
+}
+
+func TestManager_VerifyRejectsInvalidTokens(t *testing.T) {
+	t.Parallel()
+
+	manager := newHMACManager(t, "0123456789abcdef0123456789abcdef", time.Minute)
+	otherManager := newHMACManager(t, "fedcba9876543210fedcba9876543210", time.Minute)
+	expiredManager := newHMACManager(t, "0123456789abcdef0123456789abcdef", -time.Minute)
+
+	foreign, _, err := otherManager.Issue("user-1", "session-1")
+	require.NoError(t, err)
+
+	expired, _, err := expiredManager.Issue("user-1", "session-1")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "garbage", token: "not.a.token"},
+		{name: "foreign signature", token: foreign},
+		{name: "expired", token: expired},
+		{
+			name:  "none algorithm",
+			token: "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJpc3MiOiJ0ZXN0Iiwic3ViIjoidXNlci0xIn0.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := manager.Verify(tt.token)
+			require.ErrorIs(t, err, token.ErrInvalidToken)
+		})
+	}
+}
+
+func TestNewHMACManager_ShortSecret(t *testing.T) {
+	t.Parallel()
+
+	_, err := token.NewHMACManager([]byte("short"), testIssuer, time.Minute)
+	require.ErrorIs(t, err, token.ErrInvalidKey)
+}
+
+func TestParseEd25519PrivateKey(t *testing.T) {
+	t.Parallel()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Headers: nil, Bytes: der})
+
+	parsed, err := token.ParseEd25519PrivateKey(data)
+	require.NoError(t, err)
+	assert.True(t, privateKey.Equal(parsed))
+
+	_, err = token.ParseEd25519PrivateKey([]byte("not a pem"))
+	require.ErrorIs(t, err, token.ErrInvalidKey)
+}
@@ -0,0 +1,352 @@
+// Package totp provides time-based one-time passwords (RFC 6238)
+// compatible with authenticator applications.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // SHA-1 is the default algorithm of RFC 6238 and authenticator apps
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors returned when working with one-time passwords.
+var (
+	// ErrInvalidKey - the key has an empty secret or unsupported parameters.
+	ErrInvalidKey = errors.New("invalid TOTP key")
+
+	// ErrInvalidSecret - the secret is not a valid base32 string.
+	ErrInvalidSecret = errors.New("invalid TOTP secret")
+
+	// ErrInvalidURI - the URI is not an otpauth:// URI of a time-based key.
+	ErrInvalidURI = errors.New("invalid otpauth URI")
+)
+
+// Algorithm - HMAC hash function used to calculate codes.
+type Algorithm string
+
+// Supported algorithms.
+const (
+	AlgorithmSHA1   Algorithm = "SHA1"
+	AlgorithmSHA256 Algorithm = "SHA256"
+	AlgorithmSHA512 Algorithm = "SHA512"
+)
+
+// Default parameters supported by all authenticator applications.
+const (
+	DefaultAlgorithm = AlgorithmSHA1
+	DefaultDigits    = 6
+	DefaultPeriod    = 30 * time.Second
+
+	// SecretSize - size of the generated secret (in bytes).
+	SecretSize = 20
+)
+
+// Restrictions on key parameters.
+const (
+	minDigits = 6
+	maxDigits = 8
+)
+
+// Parts of the otpauth:// URI of a time-based key.
+const (
+	uriScheme = "otpauth"
+	uriType   = "totp"
+)
+
+// Masks of the dynamic truncation (RFC 4226, section 5.3).
+const (
+	offsetMask = 0x0f
+	valueMask  = 0x7fffffff
+)
+
+//nolint:gochecknoglobals // immutable encoding of secrets
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Key describes the shared secret and the parameters of code generation.
+type Key struct {
+	Issuer    string        // Service the key belongs to.
+	Account   string        // Account name shown in the authenticator.
+	Secret    []byte        // Shared secret.
+	Algorithm Algorithm     // HMAC hash function.
+	Digits    int           // Number of digits in the code.
+	Period    time.Duration // Lifetime of a code.
+}
+
+// NewKey generates a key with a random secret and the default parameters.
+//
+// Parameters:
+//   - issuer string: service the key belongs to;
+//   - account string: account name.
+func NewKey(issuer string, account string) (*Key, error) {
+	secret := make([]byte, SecretSize)
+
+	_, err := rand.Read(secret)
+	if err != nil {
+		return nil, fmt.Errorf("generate secret: %w", err)
+	}
+
+	return &Key{
+		Issuer:    issuer,
+		Account:   account,
+		Secret:    secret,
+		Algorithm: DefaultAlgorithm,
+		Digits:    DefaultDigits,
+		Period:    DefaultPeriod,
+	}, nil
+}
+
+// EncodeSecret returns the secret in the base32 form used by authenticator applications.
+func EncodeSecret(secret []byte) string {
+	return secretEncoding.EncodeToString(secret)
+}
+
+// DecodeSecret parses the base32 secret, ignoring spaces, padding and letter case.
+//
+// Parameters:
+//   - encoded string: secret in the base32 form.
+func DecodeSecret(encoded string) ([]byte, error) {
+	normalized := strings.ToUpper(strings.NewReplacer(" ", "", "=", "").Replace(encoded))
+
+	secret, err := secretEncoding.DecodeString(normalized)
+	if err != nil || len(secret) == 0 {
+		return nil, ErrInvalidSecret
+	}
+
+	return secret, nil
+}
+
+// ParseURI parses the otpauth:// URI of the key shown by a service as a QR code
+// or exported from an authenticator application.
+//
+// Omitted parameters take the default values. The issuer parameter takes precedence
+// over the issuer prefix of the label.
+//
+// Parameters:
+//   - uri string: otpauth://totp/ URI.
+func ParseURI(uri string) (*Key, error) {
+	parsed, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidURI, err)
+	}
+
+	if !strings.EqualFold(parsed.Scheme, uriScheme) || !strings.EqualFold(parsed.Host, uriType) {
+		return nil, fmt.Errorf(
+			"%w: only %s://%s/ URIs are supported",
+			ErrInvalidURI,
+			uriScheme,
+			uriType,
+		)
+	}
+
+	query := parsed.Query()
+
+	secret, err := DecodeSecret(query.Get("secret"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidURI, err)
+	}
+
+	key := &Key{
+		Issuer:    query.Get("issuer"),
+		Account:   "",
+		Secret:    secret,
+		Algorithm: DefaultAlgorithm,
+		Digits:    DefaultDigits,
+		Period:    DefaultPeriod,
+	}
+
+	label := strings.TrimPrefix(parsed.Path, "/")
+
+	issuer, account, found := strings.Cut(label, ":")
+	if !found {
+		account = label
+	} else if key.Issuer == "" {
+		key.Issuer = issuer
+	}
+
+	key.Account = strings.TrimSpace(account)
+
+	err = parseURIParams(key, query)
+	if err != nil {
+		return nil, err
+	}
+
+	err = key.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Validate checks that the key can generate codes.
+func (k *Key) Validate() error {
+	switch {
+	case len(k.Secret) == 0:
+		return fmt.Errorf("%w: empty secret", ErrInvalidKey)
+	case newHash(k.Algorithm) == nil:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidKey, k.Algorithm)
+	case k.Digits < minDigits || k.Digits > maxDigits:
+		return fmt.Errorf("%w: digits must be from %d to %d", ErrInvalidKey, minDigits, maxDigits)
+	case k.Period < time.Second:
+		return fmt.Errorf("%w: period must be at least one second", ErrInvalidKey)
+	default:
+		return nil
+	}
+}
+
+// Step returns the number of the time step the moment belongs to.
+//
+// Parameters:
+//   - moment time.Time: moment of time.
+func (k *Key) Step(moment time.Time) int64 {
+	return moment.Unix() / int64(k.Period/time.Second)
+}
+
+// Code returns the code for the moment of time.
+//
+// Parameters:
+//   - moment time.Time: moment of time.
+func (k *Key) Code(moment time.Time) (string, error) {
+	err := k.Validate()
+	if err != nil {
+		return "", err
+	}
+
+	return k.codeAt(k.Step(moment)), nil
+}
+
+// Remaining returns the time left until the code of the moment expires.
+//
+// Parameters:
+//   - moment time.Time: moment of time.
+func (k *Key) Remaining(moment time.Time) time.Duration {
+	next := time.Unix((k.Step(moment)+1)*int64(k.Period/time.Second), 0)
+
+	return next.Sub(moment)
+}
+
+// Verify checks the code within the allowed clock skew and returns the time step it belongs to.
+//
+// Parameters:
+//   - code string: code entered by the user;
+//   - moment time.Time: current time;
+//   - skew int: number of neighbouring time steps accepted in each direction.
+func (k *Key) Verify(code string, moment time.Time, skew int) (int64, bool) {
+	if k.Validate() != nil || len(code) != k.Digits {
+		return 0, false
+	}
+
+	current := k.Step(moment)
+
+	for step := current - int64(skew); step <= current+int64(skew); step++ {
+		if subtle.ConstantTimeCompare([]byte(k.codeAt(step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// URI returns the otpauth:// URI for adding the key to an authenticator application.
+func (k *Key) URI() string {
+	query := url.Values{}
+	query.Set("secret", EncodeSecret(k.Secret))
+	query.Set("algorithm", string(k.Algorithm))
+	query.Set("digits", strconv.Itoa(k.Digits))
+	query.Set("period", strconv.Itoa(int(k.Period/time.Second)))
+
+	label := k.Account
+	if k.Issuer != "" {
+		query.Set("issuer", k.Issuer)
+		label = k.Issuer + ":" + k.Account
+	}
+
+	uri := url.URL{
+		Scheme:      "otpauth",
+		Opaque:      "",
+		User:        nil,
+		Host:        "totp",
+		Path:        "/" + label,
+		RawPath:     "",
+		OmitHost:    false,
+		ForceQuery:  false,
+		RawQuery:    query.Encode(),
+		Fragment:    "",
+		RawFragment: "",
+	}
+
+	return uri.String()
+}
+
+// parseURIParams sets the algorithm, digits and period given by the parameters of the URI.
+func parseURIParams(key *Key, query url.Values) error {
+	if value := query.Get("algorithm"); value != "" {
+		key.Algorithm = Algorithm(strings.ToUpper(value))
+	}
+
+	if value := query.Get("digits"); value != "" {
+		digits, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%w: digits must be a number", ErrInvalidURI)
+		}
+
+		key.Digits = digits
+	}
+
+	if value := query.Get("period"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			return fmt.Errorf("%w: period must be a positive number of seconds", ErrInvalidURI)
+		}
+
+		key.Period = time.Duration(seconds) * time.Second
+	}
+
+	return nil
+}
+
+// codeAt calculates the code of the time step (RFC 4226, section 5.3).
+func (k *Key) codeAt(step int64) string {
+	var counter [8]byte
+
+	binary.BigEndian.PutUint64(counter[:], uint64(step)) //nolint:gosec // steps are never negative
+
+	mac := hmac.New(newHash(k.Algorithm), k.Secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & offsetMask
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & valueMask
+
+	modulo := uint32(1)
+	for range k.Digits {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", k.Digits, value%modulo)
+}
+
+// newHash returns the constructor of the hash function, nil for unsupported algorithms.
+func newHash(algorithm Algorithm) func() hash.Hash {
+	switch algorithm {
+	case AlgorithmSHA1:
+		return sha1.New
+	case AlgorithmSHA256:
+		return sha256.New
+	case AlgorithmSHA512:
+		return sha512.New
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,169 @@
+package totp_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRFCKey returns the key of the RFC 6238 test vectors.
+func newRFCKey(algorithm totp.Algorithm, secret string) *totp.Key {
+	return &totp.Key{
+		Issuer:    "",
+		Account:   "",
+		Secret:    []byte(secret),
+		Algorithm: algorithm,
+		Digits:    8,
+		Period:    totp.DefaultPeriod,
+	}
+}
+
+func TestKey_CodeRFC6238(t *testing.T) {
+	t.Parallel()
+
+	sha1Key := newRFCKey(totp.AlgorithmSHA1, "12345678901234567890")
+	sha256Key := newRFCKey(totp.AlgorithmSHA256, "12345678901234567890123456789012")
+	sha512Key := newRFCKey(totp.AlgorithmSHA512,
+		"1234567890123456789012345678901234567890123456789012345678901234")
+
+	tests := []struct {
+		key  *totp.Key
+		unix int64
+		want string
+	}{
+		{key: sha1Key, unix: 59, want: "94287082"},
+		{key: sha256Key, unix: 59, want: "46119246"},
+		{key: sha512Key, unix: 59, want: "90693936"},
+		{key: sha1Key, unix: 1111111109, want: "07081804"},
+		{key: sha256Key, unix: 1234567890, want: "91819424"},
+		{key: sha512Key, unix: 20000000000, want: "47863826"},
+	}
+
+	for _, tt := range tests {
+		code, err := tt.key.Code(time.Unix(tt.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, code, "%s at %d", tt.key.Algorithm, tt.unix)
+	}
+}
+
+func TestKey_Verify(t *testing.T) {
+	t.Parallel()
+
+	key, err := totp.NewKey("Keeper", "alice")
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	code, err := key.Code(now.Add(-totp.DefaultPeriod))
+	require.NoError(t, err)
+
+	step, valid := key.Verify(code, now, 1)
+	assert.True(t, valid, "previous code must be accepted within the skew")
+	assert.Equal(t, key.Step(now)-1, step)
+
+	_, valid = key.Verify(code, now.Add(totp.DefaultPeriod), 1)
+	assert.False(t, valid, "outdated code must be rejected")
+
+	_, valid = key.Verify("12345", now, 1)
+	assert.False(t, valid)
+}
+
+func TestKey_URI(t *testing.T) {
+	t.Parallel()
+
+	key, err := totp.NewKey("Password Keeper", "alice@example.com")
+	require.NoError(t, err)
+
+	uri, err := url.Parse(key.URI())
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Password Keeper:alice@example.com", uri.Path)
+	assert.Equal(t, "Password Keeper", uri.Query().Get("issuer"))
+	assert.Equal(t, "6", uri.Query().Get("digits"))
+
+	secret, err := totp.DecodeSecret(uri.Query().Get("secret"))
+	require.NoError(t, err)
+	assert.Equal(t, key.Secret, secret)
+}
+
+func TestKey_Validate(t *testing.T) {
+	t.Parallel()
+
+	key, err := totp.NewKey("Keeper", "alice")
+	require.NoError(t, err)
+
+	key.Algorithm = "MD5"
+
+	_, err = key.Code(time.Now())
+	require.ErrorIs(t, err, totp.ErrInvalidKey)
+
+	_, err = totp.DecodeSecret("not base32!")
+	require.ErrorIs(t, err, totp.ErrInvalidSecret)
+}
+
+func TestParseURI(t *testing.T) {
+	t.Parallel()
+
+	key, err := totp.NewKey("Password Keeper", "alice@example.com")
+	require.NoError(t, err)
+
+	parsed, err := totp.ParseURI(key.URI())
+	require.NoError(t, err)
+	assert.Equal(t, key, parsed, "the URI of a key must give the same key")
+
+	parsed, err = totp.ParseURI(
+		"otpauth://totp/GitHub:bob?secret=jbsw+y3dp&algorithm=sha256&digits=8&period=60",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, &totp.Key{
+		Issuer:    "GitHub",
+		Account:   "bob",
+		Secret:    []byte("Hello"),
+		Algorithm: totp.AlgorithmSHA256,
+		Digits:    8,
+		Period:    time.Minute,
+	}, parsed)
+
+	parsed, err = totp.ParseURI("otpauth://totp/carol?secret=JBSWY3DP")
+	require.NoError(t, err)
+	assert.Empty(t, parsed.Issuer)
+	assert.Equal(t, "carol", parsed.Account)
+	assert.Equal(t, totp.DefaultAlgorithm, parsed.Algorithm)
+	assert.Equal(t, totp.DefaultDigits, parsed.Digits)
+	assert.Equal(t, totp.DefaultPeriod, parsed.Period)
+}
+
+func TestParseURI_Invalid(t *testing.T) {
+	t.Parallel()
+
+	invalid := []string{
+		"https://example.com/?secret=JBSWY3DP",
+		"otpauth://hotp/alice?secret=JBSWY3DP&counter=1",
+		"otpauth://totp/alice",
+		"otpauth://totp/alice?secret=not+base32!",
+		"otpauth://totp/alice?secret=JBSWY3DP&digits=six",
+		"otpauth://totp/alice?secret=JBSWY3DP&period=0",
+	}
+	for _, uri := range invalid {
+		_, err := totp.ParseURI(uri)
+		require.ErrorIs(t, err, totp.ErrInvalidURI, uri)
+	}
+
+	_, err := totp.ParseURI("otpauth://totp/alice?secret=JBSWY3DP&algorithm=MD5")
+	require.ErrorIs(t, err, totp.ErrInvalidKey)
+}
+
+func TestKey_Remaining(t *testing.T) {
+	t.Parallel()
+
+	key := newRFCKey(totp.AlgorithmSHA1, "12345678901234567890")
+
+	assert.Equal(t, 30*time.Second, key.Remaining(time.Unix(60, 0)))
+	assert.Equal(t, time.Second, key.Remaining(time.Unix(89, 0)))
+	assert.Equal(t, 500*time.Millisecond, key.Remaining(time.Unix(89, int64(500*time.Millisecond))))
+}
@@ -0,0 +1,239 @@
+// Package server provides general functionality for running a server application.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/server/config"
+	"github.com/mr-filatik/go-password-keeper/internal/server/http"
+)
+
+const (
+	// adminRequestTimeout - timeout of a request to the admin API of the running instance.
+	adminRequestTimeout = 30 * time.Second
+
+	// keyRotationPollInterval - interval between the requests of the state of the rotation.
+	keyRotationPollInterval = time.Second
+
+	// maxAdminErrorSize - maximum size of the error response of the admin API read by the commands.
+	maxAdminErrorSize = 1 << 16
+
+	// pathKeyRotation - path of the rotation of the master key in the admin API.
+	pathKeyRotation = "/api/v1/admin/keys/rotation"
+
+	// pathBlobCheck - path of the consistency check of the blob storage in the admin API.
+	pathBlobCheck = "/api/v1/admin/blobs/check"
+)
+
+var (
+	// errAdminTokenNotSet - the command requires the token of the administrator.
+	errAdminTokenNotSet = errors.New("admin token is not set")
+
+	// errAdminRequest - the running instance has rejected the request of the command.
+	errAdminRequest = errors.New("admin request failed")
+
+	// errKeyRotationFailed - the rotation of the master key has stopped with an error.
+	errKeyRotationFailed = errors.New("master key rotation failed")
+
+	// errBlobsInconsistent - the check has found dangling references or unreferenced blobs.
+	errBlobsInconsistent = errors.New("blob storage is inconsistent")
+)
+
+// newAdminConfig creates the configuration of the maintenance endpoints of the instance.
+func newAdminConfig(conf *config.Config, stores *secretStores) http.AdminConfig {
+	admin := http.AdminConfig{
+		Token:       conf.AdminToken,
+		KeyRotator:  nil,
+		BlobChecker: stores.blobs,
+	}
+
+	// A nil service in the interface would enable the endpoints without encryption at rest.
+	if stores.keyRotation != nil {
+		admin.KeyRotator = stores.keyRotation
+	}
+
+	return admin
+}
+
+// adminClient - client of the admin API of the running instance used by the maintenance commands.
+type adminClient struct {
+	baseURL string
+	token   string
+	client  *nethttp.Client
+}
+
+// newAdminClient creates the client of the admin API of the instance listening on the address
+// of the configuration, an address without a host refers to the local host.
+func newAdminClient(conf *config.Config) (*adminClient, error) {
+	if conf.AdminToken == "" {
+		return nil, errAdminTokenNotSet
+	}
+
+	address := conf.Address
+	if strings.HasPrefix(address, ":") {
+		address = "localhost" + address
+	}
+
+	return &adminClient{
+		baseURL: "http://" + address,
+		token:   conf.AdminToken,
+		client: &nethttp.Client{
+			Transport:     nil,
+			CheckRedirect: nil,
+			Jar:           nil,
+			Timeout:       adminRequestTimeout,
+		},
+	}, nil
+}
+
+// do executes the request and decodes the response body into dst if the status is expected.
+func (c *adminClient) do(
+	ctx context.Context,
+	method string,
+	path string,
+	dst any,
+	statuses ...int,
+) error {
+	target, err := url.JoinPath(c.baseURL, path)
+	if err != nil {
+		return fmt.Errorf("build admin URL: %w", err)
+	}
+
+	req, err := nethttp.NewRequestWithContext(ctx, method, target, nethttp.NoBody)
+	if err != nil {
+		return fmt.Errorf("create admin request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send admin request: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if !slices.Contains(statuses, resp.StatusCode) {
+		var errResp http.ErrorResponse
+
+		_ = json.NewDecoder(io.LimitReader(resp.Body, maxAdminErrorSize)).Decode(&errResp)
+
+		return fmt.Errorf("%w: %s %s: %d %s",
+			errAdminRequest, method, path, resp.StatusCode, errResp.Message)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(dst)
+	if err != nil {
+		return fmt.Errorf("decode admin response: %w", err)
+	}
+
+	return nil
+}
+
+// runKeyRotation starts the rotation of the master key on the running instance
+// and waits for its completion.
+//
+// The new master key must be put first in the keyring file of the instance beforehand,
+// the instance reads the file again when the rotation is started.
+func runKeyRotation(ctx context.Context, conf *config.Config, logger logging.Logger) error {
+	client, err := newAdminClient(conf)
+	if err != nil {
+		return err
+	}
+
+	var status http.KeyRotationResponse
+
+	err = client.do(ctx, nethttp.MethodPost, pathKeyRotation, &status, nethttp.StatusAccepted)
+	if err != nil {
+		return fmt.Errorf("start rotation: %w", err)
+	}
+
+	logger.Info("Master key rotation started", "address", client.baseURL)
+
+	for status.Running {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait for rotation: %w", ctx.Err())
+		case <-time.After(keyRotationPollInterval):
+		}
+
+		err = client.do(ctx, nethttp.MethodGet, pathKeyRotation, &status, nethttp.StatusOK)
+		if err != nil {
+			return fmt.Errorf("get rotation status: %w", err)
+		}
+	}
+
+	if status.Error != "" {
+		return fmt.Errorf("%w: %s", errKeyRotationFailed, status.Error)
+	}
+
+	logger.Info("Master key rotation completed",
+		"found", status.Found,
+		"rewrapped", status.Rewrapped,
+		"skipped", status.Skipped,
+	)
+
+	return nil
+}
+
+// runBlobCheck requests the consistency check of the blob storage from the running instance
+// and reports the inconsistencies found.
+//
+// The check runs against the live references of the instance. Nothing is changed: unreferenced
+// blobs are deleted by the collector, dangling references need the payloads to be uploaded again
+// by their owners.
+func runBlobCheck(ctx context.Context, conf *config.Config, logger logging.Logger) error {
+	client, err := newAdminClient(conf)
+	if err != nil {
+		return err
+	}
+
+	var report http.BlobCheckResponse
+
+	err = client.do(ctx, nethttp.MethodGet, pathBlobCheck, &report,
+		nethttp.StatusOK, nethttp.StatusConflict)
+	if err != nil {
+		return fmt.Errorf("check blobs: %w", err)
+	}
+
+	for _, reference := range report.Dangling {
+		logger.Warn("Dangling blob reference", nil,
+			"blob_id", reference.BlobID,
+			"user_id", reference.UserID,
+			"secret_id", reference.SecretID,
+			"revision", reference.Revision,
+		)
+	}
+
+	for _, blob := range report.Unreferenced {
+		logger.Warn("Unreferenced blob", nil,
+			"blob_id", blob.ID, "size", blob.Size, "saved_at", blob.SavedAt)
+	}
+
+	if !report.Consistent {
+		return fmt.Errorf("%w: %d dangling references, %d unreferenced blobs",
+			errBlobsInconsistent, len(report.Dangling), len(report.Unreferenced))
+	}
+
+	logger.Info(
+		"Blob storage is consistent",
+		"blobs",
+		report.Blobs,
+		"references",
+		report.References,
+	)
+
+	return nil
+}
@@ -1,15 +1,144 @@
 // Package config provides functionality for loading configuration from command-line flags and environment variables.
 package config
 
+import "time"
+
 // Constants are default values.
 const (
-	defaultAddress string = ":8080"
+	defaultAddress         string        = ":8080"
+	defaultAccessTokenTTL  time.Duration = 15 * time.Minute
+	defaultRefreshTokenTTL time.Duration = 30 * 24 * time.Hour
+
+	defaultSecretVersionsLimit int           = 10
+	defaultTrashRetention      time.Duration = 30 * 24 * time.Hour
+
+	defaultUploadDir     string        = "data/uploads"
+	defaultUploadTTL     time.Duration = 24 * time.Hour
+	defaultUploadMaxSize int           = 1 << 30
+
+	defaultBlobStorage string = BlobStorageFilesystem
+	defaultBlobDir     string = "data/blobs"
+	defaultS3Region    string = "us-east-1"
+)
+
+// Kinds of the storage of the payloads of binary secrets.
+const (
+	BlobStorageFilesystem string = "filesystem" // Directory of the local file system.
+	BlobStorageS3         string = "s3"         // Bucket of an S3-compatible storage.
 )
 
 // Config is a structure containing the main parameters of the application.
 type Config struct {
 	// Address - server startup address.
 	Address string
+
+	// TokenSecret - shared secret for signing access tokens with HMAC-SHA256.
+	//
+	// If empty and TokenKeyFile is not set, a random secret is generated at startup.
+	TokenSecret string
+
+	// TokenKeyFile - path to the Ed25519 private key (PEM, PKCS #8) for signing access tokens.
+	//
+	// Takes precedence over TokenSecret.
+	TokenKeyFile string
+
+	// AccessTokenTTL - lifetime of access tokens.
+	AccessTokenTTL time.Duration
+
+	// RefreshTokenTTL - lifetime of refresh tokens.
+	RefreshTokenTTL time.Duration
+
+	// RedisAddress - address of the redis server for storing shared state.
+	//
+	// If empty, the state is stored in memory of the instance.
+	RedisAddress string
+
+	// RedisPassword - password of the redis server.
+	RedisPassword string
+
+	// RedisDB - number of the redis database.
+	RedisDB int
+
+	// EncryptionKeys - keyring of master keys for encryption at rest
+	// in the "<id>:<base64 key>,..." form, the first key is the current one.
+	//
+	// Set only by the environment variable, so the keys do not get into the process list.
+	EncryptionKeys string
+
+	// EncryptionKeysFile - path to the file with the keyring of master keys for encryption at rest.
+	//
+	// Takes precedence over EncryptionKeys. If neither is set, the data is stored without
+	// encryption at rest.
+	EncryptionKeysFile string
+
+	// AdminToken - bearer token of the administrator for the maintenance endpoints and commands,
+	// such as the rotation of the master key. The endpoints are disabled if it is empty.
+	//
+	// Set only by the environment variable, so the token does not get into the process list.
+	AdminToken string
+
+	// SecretVersionsLimit - maximum number of previous revisions kept in the history of a secret.
+	//
+	// Zero disables the history.
+	SecretVersionsLimit int
+
+	// SecretVersionsMaxAge - time a replaced revision is kept in the history of a secret.
+	//
+	// If zero, revisions are removed only by SecretVersionsLimit.
+	SecretVersionsMaxAge time.Duration
+
+	// TrashRetention - time a deleted secret is kept in the trash before it is purged.
+	TrashRetention time.Duration
+
+	// UploadDir - directory of resumable uploads of large secret payloads.
+	UploadDir string
+
+	// UploadTTL - time an upload is kept after its last chunk, abandoned uploads are deleted after it.
+	UploadTTL time.Duration
+
+	// UploadMaxSize - maximum size of an uploaded payload in bytes.
+	UploadMaxSize int
+
+	// BlobStorage - kind of the storage of the payloads of binary secrets: filesystem or s3.
+	//
+	// Orphaned payloads are always collected in the filesystem storage, which belongs to one instance,
+	// and in the S3 storage if S3Prefix or BlobCollect is set.
+	BlobStorage string
+
+	// BlobCollect - whether the instance owns the bucket of the S3 storage and collects the orphaned
+	// payloads in it without S3Prefix.
+	//
+	// The references to the payloads are known only to the instance that has saved them,
+	// so it must be set only if no other instance saves payloads to the bucket.
+	BlobCollect bool
+
+	// BlobDir - directory of the payloads of binary secrets for the filesystem storage.
+	BlobDir string
+
+	// S3Endpoint - base URL of the S3-compatible storage, the bucket is addressed in the path.
+	S3Endpoint string
+
+	// S3Region - region the requests to the S3-compatible storage are signed for.
+	S3Region string
+
+	// S3Bucket - bucket of the payloads of binary secrets.
+	S3Bucket string
+
+	// S3Prefix - prefix of the keys of the payloads of the instance in the bucket.
+	//
+	// The instances sharing the bucket with their own prefixes collect the orphaned payloads
+	// each under its prefix.
+	S3Prefix string
+
+	// S3AccessKey - access key identifier of the S3-compatible storage.
+	//
+	// Set only by the environment variable, like S3SecretKey.
+	S3AccessKey string
+
+	// S3SecretKey - secret access key of the S3-compatible storage.
+	//
+	// Set only by the environment variable, so the key does not get into the process list.
+	S3SecretKey string
 }
 
 // Initialize creates and initializes a *Config object.
@@ -18,9 +147,12 @@ type Config struct {
 // - default values;
 // - values ​​from command-line flags;
 // - values ​​from environment variables.
-func Initialize() *Config {
+//
+// Parameters:
+//   - args []string: command-line arguments without the program name and the command.
+func Initialize(args []string) *Config {
 	envsConf := getEnvsConfigFromOS()
-	flagsConf, _ := getFlagsConfigFromOS()
+	flagsConf, _ := getFlagsConfigFromArgs(args)
 
 	config := createAndOverrideConfig(flagsConf, envsConf)
 
@@ -29,7 +161,36 @@ func Initialize() *Config {
 
 func createAndOverrideConfig(flagsConf *configFlags, envsConf *configEnvs) *Config {
 	config := &Config{
-		Address: defaultAddress,
+		Address:         defaultAddress,
+		TokenSecret:     "",
+		TokenKeyFile:    "",
+		AccessTokenTTL:  defaultAccessTokenTTL,
+		RefreshTokenTTL: defaultRefreshTokenTTL,
+		RedisAddress:    "",
+		RedisPassword:   "",
+		RedisDB:         0,
+
+		EncryptionKeys:     "",
+		EncryptionKeysFile: "",
+		AdminToken:         "",
+
+		SecretVersionsLimit:  defaultSecretVersionsLimit,
+		SecretVersionsMaxAge: 0,
+		TrashRetention:       defaultTrashRetention,
+
+		UploadDir:     defaultUploadDir,
+		UploadTTL:     defaultUploadTTL,
+		UploadMaxSize: defaultUploadMaxSize,
+
+		BlobStorage: defaultBlobStorage,
+		BlobCollect: false,
+		BlobDir:     defaultBlobDir,
+		S3Endpoint:  "",
+		S3Region:    defaultS3Region,
+		S3Bucket:    "",
+		S3Prefix:    "",
+		S3AccessKey: "",
+		S3SecretKey: "",
 	}
 
 	config.overrideConfigFromFlags(flagsConf)
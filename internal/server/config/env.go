@@ -1,16 +1,104 @@
 // Package config provides functionality for loading configuration from command-line flags and environment variables.
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"time"
+)
 
+//nolint:gosec // names of environment variables, not credentials
 const (
-	envNameServerAddress string = "SERVER_ADDRESS"
+	envNameServerAddress   string = "SERVER_ADDRESS"
+	envNameTokenSecret     string = "AUTH_TOKEN_SECRET"
+	envNameTokenKeyFile    string = "AUTH_TOKEN_KEY_FILE"
+	envNameAccessTokenTTL  string = "AUTH_ACCESS_TOKEN_TTL"
+	envNameRefreshTokenTTL string = "AUTH_REFRESH_TOKEN_TTL"
+	envNameRedisAddress    string = "REDIS_ADDRESS"
+	envNameRedisPassword   string = "REDIS_PASSWORD"
+	envNameRedisDB         string = "REDIS_DB"
+
+	envNameEncryptionKeys     string = "ENCRYPTION_KEYS"
+	envNameEncryptionKeysFile string = "ENCRYPTION_KEYS_FILE"
+	envNameAdminToken         string = "ADMIN_TOKEN"
+
+	envNameSecretVersionsLimit  string = "SECRET_VERSIONS_LIMIT"
+	envNameSecretVersionsMaxAge string = "SECRET_VERSIONS_MAX_AGE"
+	envNameTrashRetention       string = "TRASH_RETENTION"
+
+	envNameUploadDir     string = "UPLOAD_DIR"
+	envNameUploadTTL     string = "UPLOAD_TTL"
+	envNameUploadMaxSize string = "UPLOAD_MAX_SIZE"
+
+	envNameBlobStorage string = "BLOB_STORAGE"
+	envNameBlobCollect string = "BLOB_COLLECT"
+	envNameBlobDir     string = "BLOB_DIR"
+	envNameS3Endpoint  string = "S3_ENDPOINT"
+	envNameS3Region    string = "S3_REGION"
+	envNameS3Bucket    string = "S3_BUCKET"
+	envNameS3Prefix    string = "S3_PREFIX"
+	envNameS3AccessKey string = "S3_ACCESS_KEY"
+	envNameS3SecretKey string = "S3_SECRET_KEY"
 )
 
 // configEnvs - a structure containing the main environment variables for the application.
 type configEnvs struct {
-	serverAddress        string
-	serverAddressIsValue bool
+	serverAddress         string
+	serverAddressIsValue  bool
+	tokenSecret           string
+	tokenSecretIsValue    bool
+	tokenKeyFile          string
+	tokenKeyFileIsValue   bool
+	accessTokenTTL        time.Duration
+	accessTokenTTLIsValue bool
+	refreshTokenTTL       time.Duration
+	refreshTTLIsValue     bool
+	redisAddress          string
+	redisAddressIsValue   bool
+	redisPassword         string
+	redisPasswordIsValue  bool
+	redisDB               int
+	redisDBIsValue        bool
+
+	encryptionKeys            string
+	encryptionKeysIsValue     bool
+	encryptionKeysFile        string
+	encryptionKeysFileIsValue bool
+	adminToken                string
+	adminTokenIsValue         bool
+
+	secretVersionsLimit         int
+	secretVersionsLimitIsValue  bool
+	secretVersionsMaxAge        time.Duration
+	secretVersionsMaxAgeIsValue bool
+	trashRetention              time.Duration
+	trashRetentionIsValue       bool
+
+	uploadDir            string
+	uploadDirIsValue     bool
+	uploadTTL            time.Duration
+	uploadTTLIsValue     bool
+	uploadMaxSize        int
+	uploadMaxSizeIsValue bool
+
+	blobStorage        string
+	blobStorageIsValue bool
+	blobCollect        bool
+	blobCollectIsValue bool
+	blobDir            string
+	blobDirIsValue     bool
+	s3Endpoint         string
+	s3EndpointIsValue  bool
+	s3Region           string
+	s3RegionIsValue    bool
+	s3Bucket           string
+	s3BucketIsValue    bool
+	s3Prefix           string
+	s3PrefixIsValue    bool
+	s3AccessKey        string
+	s3AccessKeyIsValue bool
+	s3SecretKey        string
+	s3SecretKeyIsValue bool
 }
 
 // envReader is an interface for reading environment variables.
@@ -18,18 +106,194 @@ type envReader func(key string) (string, bool)
 
 // getEnvsConfig gets values ​​from the store.
 func getEnvsConfig(getenv envReader) *configEnvs {
-	config := &configEnvs{
-		serverAddress:        "",
-		serverAddressIsValue: false,
+	config := newConfigEnvs()
+
+	config.serverAddress, config.serverAddressIsValue = lookupString(getenv, envNameServerAddress)
+	config.tokenSecret, config.tokenSecretIsValue = lookupString(getenv, envNameTokenSecret)
+	config.tokenKeyFile, config.tokenKeyFileIsValue = lookupString(getenv, envNameTokenKeyFile)
+	config.accessTokenTTL, config.accessTokenTTLIsValue = lookupDuration(
+		getenv,
+		envNameAccessTokenTTL,
+	)
+	config.refreshTokenTTL, config.refreshTTLIsValue = lookupDuration(
+		getenv,
+		envNameRefreshTokenTTL,
+	)
+	config.redisAddress, config.redisAddressIsValue = lookupString(getenv, envNameRedisAddress)
+	config.redisPassword, config.redisPasswordIsValue = lookupString(getenv, envNameRedisPassword)
+	config.redisDB, config.redisDBIsValue = lookupNonNegativeInt(getenv, envNameRedisDB)
+	config.lookupEncryption(getenv)
+	config.lookupVersions(getenv)
+	config.lookupUploads(getenv)
+	config.lookupBlobs(getenv)
+
+	return config
+}
+
+// newConfigEnvs creates the config without values of environment variables.
+func newConfigEnvs() *configEnvs {
+	return &configEnvs{
+		serverAddress:         "",
+		serverAddressIsValue:  false,
+		tokenSecret:           "",
+		tokenSecretIsValue:    false,
+		tokenKeyFile:          "",
+		tokenKeyFileIsValue:   false,
+		accessTokenTTL:        0,
+		accessTokenTTLIsValue: false,
+		refreshTokenTTL:       0,
+		refreshTTLIsValue:     false,
+		redisAddress:          "",
+		redisAddressIsValue:   false,
+		redisPassword:         "",
+		redisPasswordIsValue:  false,
+		redisDB:               0,
+		redisDBIsValue:        false,
+
+		encryptionKeys:            "",
+		encryptionKeysIsValue:     false,
+		encryptionKeysFile:        "",
+		encryptionKeysFileIsValue: false,
+		adminToken:                "",
+		adminTokenIsValue:         false,
+
+		secretVersionsLimit:         0,
+		secretVersionsLimitIsValue:  false,
+		secretVersionsMaxAge:        0,
+		secretVersionsMaxAgeIsValue: false,
+		trashRetention:              0,
+		trashRetentionIsValue:       false,
+
+		uploadDir:            "",
+		uploadDirIsValue:     false,
+		uploadTTL:            0,
+		uploadTTLIsValue:     false,
+		uploadMaxSize:        0,
+		uploadMaxSizeIsValue: false,
+
+		blobStorage:        "",
+		blobStorageIsValue: false,
+		blobCollect:        false,
+		blobCollectIsValue: false,
+		blobDir:            "",
+		blobDirIsValue:     false,
+		s3Endpoint:         "",
+		s3EndpointIsValue:  false,
+		s3Region:           "",
+		s3RegionIsValue:    false,
+		s3Bucket:           "",
+		s3BucketIsValue:    false,
+		s3Prefix:           "",
+		s3PrefixIsValue:    false,
+		s3AccessKey:        "",
+		s3AccessKeyIsValue: false,
+		s3SecretKey:        "",
+		s3SecretKeyIsValue: false,
 	}
+}
 
-	envAddress, ok := getenv(envNameServerAddress)
-	if ok && envAddress != "" {
-		config.serverAddress = envAddress
-		config.serverAddressIsValue = true
+// lookupEncryption gets the settings of the encryption keys and the token of the administrator.
+func (c *configEnvs) lookupEncryption(getenv envReader) {
+	c.encryptionKeys, c.encryptionKeysIsValue = lookupString(getenv, envNameEncryptionKeys)
+	c.encryptionKeysFile, c.encryptionKeysFileIsValue = lookupString(
+		getenv,
+		envNameEncryptionKeysFile,
+	)
+	c.adminToken, c.adminTokenIsValue = lookupString(getenv, envNameAdminToken)
+}
+
+// lookupVersions gets the retention settings of secret versions and the trash.
+func (c *configEnvs) lookupVersions(getenv envReader) {
+	c.secretVersionsLimit, c.secretVersionsLimitIsValue = lookupNonNegativeInt(
+		getenv,
+		envNameSecretVersionsLimit,
+	)
+	c.secretVersionsMaxAge, c.secretVersionsMaxAgeIsValue = lookupDuration(
+		getenv,
+		envNameSecretVersionsMaxAge,
+	)
+	c.trashRetention, c.trashRetentionIsValue = lookupDuration(getenv, envNameTrashRetention)
+}
+
+// lookupUploads gets the settings of resumable uploads.
+func (c *configEnvs) lookupUploads(getenv envReader) {
+	c.uploadDir, c.uploadDirIsValue = lookupString(getenv, envNameUploadDir)
+	c.uploadTTL, c.uploadTTLIsValue = lookupDuration(getenv, envNameUploadTTL)
+	c.uploadMaxSize, c.uploadMaxSizeIsValue = lookupNonNegativeInt(getenv, envNameUploadMaxSize)
+}
+
+// lookupBlobs gets the settings of the storage of binary payloads.
+func (c *configEnvs) lookupBlobs(getenv envReader) {
+	c.blobStorage, c.blobStorageIsValue = lookupString(getenv, envNameBlobStorage)
+	c.blobDir, c.blobDirIsValue = lookupString(getenv, envNameBlobDir)
+	c.s3Endpoint, c.s3EndpointIsValue = lookupString(getenv, envNameS3Endpoint)
+	c.s3Region, c.s3RegionIsValue = lookupString(getenv, envNameS3Region)
+	c.s3Bucket, c.s3BucketIsValue = lookupString(getenv, envNameS3Bucket)
+	c.s3Prefix, c.s3PrefixIsValue = lookupString(getenv, envNameS3Prefix)
+	c.blobCollect, c.blobCollectIsValue = lookupBool(getenv, envNameBlobCollect)
+	c.s3AccessKey, c.s3AccessKeyIsValue = lookupString(getenv, envNameS3AccessKey)
+	c.s3SecretKey, c.s3SecretKeyIsValue = lookupString(getenv, envNameS3SecretKey)
+}
+
+// lookupString gets a non-empty string value of the environment variable.
+func lookupString(getenv envReader, name string) (string, bool) {
+	value, found := getenv(name)
+	if !found || value == "" {
+		return "", false
 	}
 
-	return config
+	return value, true
+}
+
+// lookupBool gets a boolean value of the environment variable.
+//
+// Invalid values are ignored.
+func lookupBool(getenv envReader, name string) (bool, bool) {
+	value, found := lookupString(getenv, name)
+	if !found {
+		return false, false
+	}
+
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, false
+	}
+
+	return flag, true
+}
+
+// lookupDuration gets a positive duration value of the environment variable.
+//
+// Invalid values are ignored.
+func lookupDuration(getenv envReader, name string) (time.Duration, bool) {
+	value, found := lookupString(getenv, name)
+	if !found {
+		return 0, false
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, false
+	}
+
+	return duration, true
+}
+
+// lookupNonNegativeInt gets a non-negative integer value of the environment variable.
+//
+// Invalid values are ignored.
+func lookupNonNegativeInt(getenv envReader, name string) (int, bool) {
+	value, found := lookupString(getenv, name)
+	if !found {
+		return 0, false
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return 0, false
+	}
+
+	return number, true
 }
 
 // getEnvsConfigFromOS gets values ​​from environment variables.
@@ -50,4 +314,121 @@ func (c *Config) overrideConfigFromEnvs(conf *configEnvs) {
 	if conf.serverAddressIsValue {
 		c.Address = conf.serverAddress
 	}
+
+	if conf.tokenSecretIsValue {
+		c.TokenSecret = conf.tokenSecret
+	}
+
+	if conf.tokenKeyFileIsValue {
+		c.TokenKeyFile = conf.tokenKeyFile
+	}
+
+	if conf.accessTokenTTLIsValue {
+		c.AccessTokenTTL = conf.accessTokenTTL
+	}
+
+	if conf.refreshTTLIsValue {
+		c.RefreshTokenTTL = conf.refreshTokenTTL
+	}
+
+	c.overrideStorageFromEnvs(conf)
+	c.overrideVersionsFromEnvs(conf)
+	c.overrideUploadsFromEnvs(conf)
+	c.overrideBlobsFromEnvs(conf)
+}
+
+// overrideStorageFromEnvs overrides the storage settings with new values.
+func (c *Config) overrideStorageFromEnvs(conf *configEnvs) {
+	if conf.redisAddressIsValue {
+		c.RedisAddress = conf.redisAddress
+	}
+
+	if conf.redisPasswordIsValue {
+		c.RedisPassword = conf.redisPassword
+	}
+
+	if conf.redisDBIsValue {
+		c.RedisDB = conf.redisDB
+	}
+
+	if conf.encryptionKeysIsValue {
+		c.EncryptionKeys = conf.encryptionKeys
+	}
+
+	if conf.encryptionKeysFileIsValue {
+		c.EncryptionKeysFile = conf.encryptionKeysFile
+	}
+
+	if conf.adminTokenIsValue {
+		c.AdminToken = conf.adminToken
+	}
+}
+
+// overrideVersionsFromEnvs overrides the retention settings of secret versions and the trash with new values.
+func (c *Config) overrideVersionsFromEnvs(conf *configEnvs) {
+	if conf.secretVersionsLimitIsValue {
+		c.SecretVersionsLimit = conf.secretVersionsLimit
+	}
+
+	if conf.secretVersionsMaxAgeIsValue {
+		c.SecretVersionsMaxAge = conf.secretVersionsMaxAge
+	}
+
+	if conf.trashRetentionIsValue {
+		c.TrashRetention = conf.trashRetention
+	}
+}
+
+// overrideUploadsFromEnvs overrides the settings of resumable uploads with new values.
+func (c *Config) overrideUploadsFromEnvs(conf *configEnvs) {
+	if conf.uploadDirIsValue {
+		c.UploadDir = conf.uploadDir
+	}
+
+	if conf.uploadTTLIsValue {
+		c.UploadTTL = conf.uploadTTL
+	}
+
+	if conf.uploadMaxSizeIsValue && conf.uploadMaxSize > 0 {
+		c.UploadMaxSize = conf.uploadMaxSize
+	}
+}
+
+// overrideBlobsFromEnvs overrides the settings of the storage of binary payloads with new values.
+func (c *Config) overrideBlobsFromEnvs(conf *configEnvs) {
+	if conf.blobStorageIsValue {
+		c.BlobStorage = conf.blobStorage
+	}
+
+	if conf.blobDirIsValue {
+		c.BlobDir = conf.blobDir
+	}
+
+	if conf.s3EndpointIsValue {
+		c.S3Endpoint = conf.s3Endpoint
+	}
+
+	if conf.s3RegionIsValue {
+		c.S3Region = conf.s3Region
+	}
+
+	if conf.s3BucketIsValue {
+		c.S3Bucket = conf.s3Bucket
+	}
+
+	if conf.s3PrefixIsValue {
+		c.S3Prefix = conf.s3Prefix
+	}
+
+	if conf.blobCollectIsValue {
+		c.BlobCollect = conf.blobCollect
+	}
+
+	if conf.s3AccessKeyIsValue {
+		c.S3AccessKey = conf.s3AccessKey
+	}
+
+	if conf.s3SecretKeyIsValue {
+		c.S3SecretKey = conf.s3SecretKey
+	}
 }
@@ -5,45 +5,395 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 )
 
+//nolint:gosec // names of command-line flags, not credentials
 const (
-	flagNameServerAddress string = "server-address"
+	flagNameServerAddress   string = "server-address"
+	flagNameTokenSecret     string = "auth-token-secret"
+	flagNameTokenKeyFile    string = "auth-token-key-file"
+	flagNameAccessTokenTTL  string = "auth-access-token-ttl"
+	flagNameRefreshTokenTTL string = "auth-refresh-token-ttl"
+	flagNameRedisAddress    string = "redis-address"
+	flagNameRedisPassword   string = "redis-password"
+	flagNameRedisDB         string = "redis-db"
+
+	flagNameEncryptionKeysFile string = "encryption-keys-file"
+
+	flagNameSecretVersionsLimit  string = "secret-versions-limit"
+	flagNameSecretVersionsMaxAge string = "secret-versions-max-age"
+	flagNameTrashRetention       string = "trash-retention"
+
+	flagNameUploadDir     string = "upload-dir"
+	flagNameUploadTTL     string = "upload-ttl"
+	flagNameUploadMaxSize string = "upload-max-size"
+
+	flagNameBlobStorage string = "blob-storage"
+	flagNameBlobCollect string = "blob-collect"
+	flagNameBlobDir     string = "blob-dir"
+	flagNameS3Endpoint  string = "s3-endpoint"
+	flagNameS3Region    string = "s3-region"
+	flagNameS3Bucket    string = "s3-bucket"
+	flagNameS3Prefix    string = "s3-prefix"
 )
 
 // configFlags - a structure containing the main application flags.
 type configFlags struct {
-	serverAddress        string
-	serverAddressIsValue bool
+	serverAddress         string
+	serverAddressIsValue  bool
+	tokenSecret           string
+	tokenSecretIsValue    bool
+	tokenKeyFile          string
+	tokenKeyFileIsValue   bool
+	accessTokenTTL        time.Duration
+	accessTokenTTLIsValue bool
+	refreshTokenTTL       time.Duration
+	refreshTTLIsValue     bool
+	redisAddress          string
+	redisAddressIsValue   bool
+	redisPassword         string
+	redisPasswordIsValue  bool
+	redisDB               int
+	redisDBIsValue        bool
+
+	encryptionKeysFile        string
+	encryptionKeysFileIsValue bool
+
+	secretVersionsLimit         int
+	secretVersionsLimitIsValue  bool
+	secretVersionsMaxAge        time.Duration
+	secretVersionsMaxAgeIsValue bool
+	trashRetention              time.Duration
+	trashRetentionIsValue       bool
+
+	uploadDir            string
+	uploadDirIsValue     bool
+	uploadTTL            time.Duration
+	uploadTTLIsValue     bool
+	uploadMaxSize        int
+	uploadMaxSizeIsValue bool
+
+	blobStorage        string
+	blobStorageIsValue bool
+	blobCollect        bool
+	blobCollectIsValue bool
+	blobDir            string
+	blobDirIsValue     bool
+	s3Endpoint         string
+	s3EndpointIsValue  bool
+	s3Region           string
+	s3RegionIsValue    bool
+	s3Bucket           string
+	s3BucketIsValue    bool
+	s3Prefix           string
+	s3PrefixIsValue    bool
 }
 
 // getFlagsConfig gets the config from the specified arguments.
 func getFlagsConfig(fs *flag.FlagSet, args []string) (*configFlags, error) {
-	config := &configFlags{
-		serverAddress:        "",
-		serverAddressIsValue: false,
-	}
+	config := newConfigFlags()
 
 	argAddress := fs.String(flagNameServerAddress, "", "HTTP server endpoint")
+	argTokenSecret := fs.String(flagNameTokenSecret, "", "HMAC secret for signing access tokens")
+	argTokenKeyFile, argEncryptionKeysFile := defineKeyFileFlags(fs)
+	argAccessTokenTTL := fs.Duration(flagNameAccessTokenTTL, 0, "Access token lifetime")
+	argRefreshTokenTTL := fs.Duration(flagNameRefreshTokenTTL, 0, "Refresh token lifetime")
+	argRedisAddress := fs.String(flagNameRedisAddress, "", "Redis server address")
+	argRedisPassword := fs.String(flagNameRedisPassword, "", "Redis server password")
+	argRedisDB := fs.Int(flagNameRedisDB, -1, "Redis database number")
+	argSecretVersionsLimit, argSecretVersionsMaxAge := defineVersionsFlags(fs)
+	argTrashRetention := fs.Duration(
+		flagNameTrashRetention,
+		0,
+		"Time a deleted secret is kept in the trash",
+	)
+	argUploadDir, argUploadTTL, argUploadMaxSize := defineUploadFlags(fs)
+	argBlobs := defineBlobFlags(fs)
 
 	err := fs.Parse(args)
 	if err != nil {
 		return nil, fmt.Errorf("parse argument %w", err)
 	}
 
-	if argAddress != nil && *argAddress != "" {
-		config.serverAddress = *argAddress
-		config.serverAddressIsValue = true
-	}
+	config.setServerFlags(argAddress, argTokenSecret, argTokenKeyFile, argAccessTokenTTL)
+	config.setRedisFlags(argRefreshTokenTTL, argRedisAddress, argRedisPassword, argRedisDB)
+	config.setEncryptionFlags(argEncryptionKeysFile)
+	config.setVersionsFlags(argSecretVersionsLimit, argSecretVersionsMaxAge)
+	config.setTrashFlags(argTrashRetention)
+	config.setUploadFlags(argUploadDir, argUploadTTL, argUploadMaxSize)
+	config.setBlobFlags(argBlobs)
 
 	return config, nil
 }
 
-// getFlagsConfigFromOS gets the flag values ​​from the application's startup arguments in the OS.
-func getFlagsConfigFromOS() (*configFlags, error) {
+// newConfigFlags creates the config without values of flags.
+func newConfigFlags() *configFlags {
+	return &configFlags{
+		serverAddress:         "",
+		serverAddressIsValue:  false,
+		tokenSecret:           "",
+		tokenSecretIsValue:    false,
+		tokenKeyFile:          "",
+		tokenKeyFileIsValue:   false,
+		accessTokenTTL:        0,
+		accessTokenTTLIsValue: false,
+		refreshTokenTTL:       0,
+		refreshTTLIsValue:     false,
+		redisAddress:          "",
+		redisAddressIsValue:   false,
+		redisPassword:         "",
+		redisPasswordIsValue:  false,
+		redisDB:               0,
+		redisDBIsValue:        false,
+
+		encryptionKeysFile:        "",
+		encryptionKeysFileIsValue: false,
+
+		secretVersionsLimit:         0,
+		secretVersionsLimitIsValue:  false,
+		secretVersionsMaxAge:        0,
+		secretVersionsMaxAgeIsValue: false,
+		trashRetention:              0,
+		trashRetentionIsValue:       false,
+
+		uploadDir:            "",
+		uploadDirIsValue:     false,
+		uploadTTL:            0,
+		uploadTTLIsValue:     false,
+		uploadMaxSize:        0,
+		uploadMaxSizeIsValue: false,
+
+		blobStorage:        "",
+		blobStorageIsValue: false,
+		blobCollect:        false,
+		blobCollectIsValue: false,
+		blobDir:            "",
+		blobDirIsValue:     false,
+		s3Endpoint:         "",
+		s3EndpointIsValue:  false,
+		s3Region:           "",
+		s3RegionIsValue:    false,
+		s3Bucket:           "",
+		s3BucketIsValue:    false,
+		s3Prefix:           "",
+		s3PrefixIsValue:    false,
+	}
+}
+
+// defineKeyFileFlags defines the flags of the files with the signing and encryption keys.
+func defineKeyFileFlags(fs *flag.FlagSet) (*string, *string) {
+	tokenKeyFile := fs.String(
+		flagNameTokenKeyFile,
+		"",
+		"Ed25519 private key file for signing access tokens",
+	)
+	encryptionKeysFile := fs.String(
+		flagNameEncryptionKeysFile,
+		"",
+		"Keyring file with master keys for encryption at rest",
+	)
+
+	return tokenKeyFile, encryptionKeysFile
+}
+
+// defineVersionsFlags defines the retention flags of secret versions.
+func defineVersionsFlags(fs *flag.FlagSet) (*int, *time.Duration) {
+	limit := fs.Int(
+		flagNameSecretVersionsLimit,
+		-1,
+		"Maximum number of previous revisions kept for a secret",
+	)
+	maxAge := fs.Duration(
+		flagNameSecretVersionsMaxAge,
+		0,
+		"Time a replaced revision of a secret is kept",
+	)
+
+	return limit, maxAge
+}
+
+// defineUploadFlags defines the flags of resumable uploads.
+func defineUploadFlags(fs *flag.FlagSet) (*string, *time.Duration, *int) {
+	dir := fs.String(flagNameUploadDir, "", "Directory of resumable uploads")
+	ttl := fs.Duration(
+		flagNameUploadTTL,
+		0,
+		"Time an abandoned upload is kept after its last chunk",
+	)
+	maxSize := fs.Int(flagNameUploadMaxSize, 0, "Maximum size of an uploaded payload in bytes")
+
+	return dir, ttl, maxSize
+}
+
+// blobFlags - values of the flags of the storage of binary payloads.
+type blobFlags struct {
+	storage  *string
+	collect  *bool
+	dir      *string
+	endpoint *string
+	region   *string
+	bucket   *string
+	prefix   *string
+}
+
+// defineBlobFlags defines the flags of the storage of binary payloads.
+func defineBlobFlags(fs *flag.FlagSet) *blobFlags {
+	return &blobFlags{
+		storage: fs.String(
+			flagNameBlobStorage,
+			"",
+			"Storage of binary payloads: filesystem or s3",
+		),
+		dir: fs.String(
+			flagNameBlobDir,
+			"",
+			"Directory of binary payloads for the filesystem storage",
+		),
+		endpoint: fs.String(flagNameS3Endpoint, "", "Base URL of the S3-compatible storage"),
+		region:   fs.String(flagNameS3Region, "", "Region of the S3-compatible storage"),
+		bucket:   fs.String(flagNameS3Bucket, "", "Bucket of binary payloads"),
+		prefix:   fs.String(flagNameS3Prefix, "", "Key prefix of binary payloads of the instance"),
+		collect: fs.Bool(
+			flagNameBlobCollect,
+			false,
+			"Collect orphaned payloads in the S3 bucket owned by the instance",
+		),
+	}
+}
+
+// setServerFlags sets the values of the server address and access token flags.
+func (c *configFlags) setServerFlags(
+	address *string,
+	tokenSecret *string,
+	tokenKeyFile *string,
+	accessTokenTTL *time.Duration,
+) {
+	if address != nil && *address != "" {
+		c.serverAddress = *address
+		c.serverAddressIsValue = true
+	}
+
+	if tokenSecret != nil && *tokenSecret != "" {
+		c.tokenSecret = *tokenSecret
+		c.tokenSecretIsValue = true
+	}
+
+	if tokenKeyFile != nil && *tokenKeyFile != "" {
+		c.tokenKeyFile = *tokenKeyFile
+		c.tokenKeyFileIsValue = true
+	}
+
+	if accessTokenTTL != nil && *accessTokenTTL > 0 {
+		c.accessTokenTTL = *accessTokenTTL
+		c.accessTokenTTLIsValue = true
+	}
+}
+
+// setRedisFlags sets the values of the refresh token and redis flags.
+func (c *configFlags) setRedisFlags(
+	refreshTokenTTL *time.Duration,
+	redisAddress *string,
+	redisPassword *string,
+	redisDB *int,
+) {
+	if refreshTokenTTL != nil && *refreshTokenTTL > 0 {
+		c.refreshTokenTTL = *refreshTokenTTL
+		c.refreshTTLIsValue = true
+	}
+
+	if redisAddress != nil && *redisAddress != "" {
+		c.redisAddress = *redisAddress
+		c.redisAddressIsValue = true
+	}
+
+	if redisPassword != nil && *redisPassword != "" {
+		c.redisPassword = *redisPassword
+		c.redisPasswordIsValue = true
+	}
+
+	if redisDB != nil && *redisDB >= 0 {
+		c.redisDB = *redisDB
+		c.redisDBIsValue = true
+	}
+}
+
+// setEncryptionFlags sets the values of the encryption at rest flags.
+func (c *configFlags) setEncryptionFlags(keysFile *string) {
+	if keysFile != nil && *keysFile != "" {
+		c.encryptionKeysFile = *keysFile
+		c.encryptionKeysFileIsValue = true
+	}
+}
+
+// setVersionsFlags sets the values of the retention flags of secret versions.
+func (c *configFlags) setVersionsFlags(limit *int, maxAge *time.Duration) {
+	if limit != nil && *limit >= 0 {
+		c.secretVersionsLimit = *limit
+		c.secretVersionsLimitIsValue = true
+	}
+
+	if maxAge != nil && *maxAge > 0 {
+		c.secretVersionsMaxAge = *maxAge
+		c.secretVersionsMaxAgeIsValue = true
+	}
+}
+
+// setTrashFlags sets the value of the retention flag of the trash.
+func (c *configFlags) setTrashFlags(retention *time.Duration) {
+	if retention != nil && *retention > 0 {
+		c.trashRetention = *retention
+		c.trashRetentionIsValue = true
+	}
+}
+
+// setUploadFlags sets the values of the flags of resumable uploads.
+func (c *configFlags) setUploadFlags(dir *string, ttl *time.Duration, maxSize *int) {
+	if dir != nil && *dir != "" {
+		c.uploadDir = *dir
+		c.uploadDirIsValue = true
+	}
+
+	if ttl != nil && *ttl > 0 {
+		c.uploadTTL = *ttl
+		c.uploadTTLIsValue = true
+	}
+
+	if maxSize != nil && *maxSize > 0 {
+		c.uploadMaxSize = *maxSize
+		c.uploadMaxSizeIsValue = true
+	}
+}
+
+// setBlobFlags sets the values of the flags of the storage of binary payloads.
+func (c *configFlags) setBlobFlags(flags *blobFlags) {
+	c.blobStorage, c.blobStorageIsValue = nonEmptyFlag(flags.storage)
+	c.blobDir, c.blobDirIsValue = nonEmptyFlag(flags.dir)
+	c.s3Endpoint, c.s3EndpointIsValue = nonEmptyFlag(flags.endpoint)
+	c.s3Region, c.s3RegionIsValue = nonEmptyFlag(flags.region)
+	c.s3Bucket, c.s3BucketIsValue = nonEmptyFlag(flags.bucket)
+	c.s3Prefix, c.s3PrefixIsValue = nonEmptyFlag(flags.prefix)
+
+	if flags.collect != nil && *flags.collect {
+		c.blobCollect = true
+		c.blobCollectIsValue = true
+	}
+}
+
+// nonEmptyFlag returns the value of the string flag and whether it is set.
+func nonEmptyFlag(value *string) (string, bool) {
+	if value == nil || *value == "" {
+		return "", false
+	}
+
+	return *value, true
+}
+
+// getFlagsConfigFromArgs gets the flag values ​​from the arguments of the command.
+func getFlagsConfigFromArgs(args []string) (*configFlags, error) {
 	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 
-	config, err := getFlagsConfig(fs, os.Args[1:])
+	config, err := getFlagsConfig(fs, args)
 	if err != nil {
 		return nil, fmt.Errorf("get flag config %w", err)
 	}
@@ -60,4 +410,105 @@ func (c *Config) overrideConfigFromFlags(conf *configFlags) {
 	if conf.serverAddressIsValue {
 		c.Address = conf.serverAddress
 	}
+
+	if conf.tokenSecretIsValue {
+		c.TokenSecret = conf.tokenSecret
+	}
+
+	if conf.tokenKeyFileIsValue {
+		c.TokenKeyFile = conf.tokenKeyFile
+	}
+
+	if conf.accessTokenTTLIsValue {
+		c.AccessTokenTTL = conf.accessTokenTTL
+	}
+
+	if conf.refreshTTLIsValue {
+		c.RefreshTokenTTL = conf.refreshTokenTTL
+	}
+
+	c.overrideStorageFromFlags(conf)
+	c.overrideVersionsFromFlags(conf)
+	c.overrideUploadsFromFlags(conf)
+	c.overrideBlobsFromFlags(conf)
+}
+
+// overrideStorageFromFlags overrides the storage settings with new values.
+func (c *Config) overrideStorageFromFlags(conf *configFlags) {
+	if conf.redisAddressIsValue {
+		c.RedisAddress = conf.redisAddress
+	}
+
+	if conf.redisPasswordIsValue {
+		c.RedisPassword = conf.redisPassword
+	}
+
+	if conf.redisDBIsValue {
+		c.RedisDB = conf.redisDB
+	}
+
+	if conf.encryptionKeysFileIsValue {
+		c.EncryptionKeysFile = conf.encryptionKeysFile
+	}
+}
+
+// overrideVersionsFromFlags overrides the retention settings of secret versions and the trash with new values.
+func (c *Config) overrideVersionsFromFlags(conf *configFlags) {
+	if conf.secretVersionsLimitIsValue {
+		c.SecretVersionsLimit = conf.secretVersionsLimit
+	}
+
+	if conf.secretVersionsMaxAgeIsValue {
+		c.SecretVersionsMaxAge = conf.secretVersionsMaxAge
+	}
+
+	if conf.trashRetentionIsValue {
+		c.TrashRetention = conf.trashRetention
+	}
+}
+
+// overrideUploadsFromFlags overrides the settings of resumable uploads with new values.
+func (c *Config) overrideUploadsFromFlags(conf *configFlags) {
+	if conf.uploadDirIsValue {
+		c.UploadDir = conf.uploadDir
+	}
+
+	if conf.uploadTTLIsValue {
+		c.UploadTTL = conf.uploadTTL
+	}
+
+	if conf.uploadMaxSizeIsValue {
+		c.UploadMaxSize = conf.uploadMaxSize
+	}
+}
+
+// overrideBlobsFromFlags overrides the settings of the storage of binary payloads with new values.
+func (c *Config) overrideBlobsFromFlags(conf *configFlags) {
+	if conf.blobStorageIsValue {
+		c.BlobStorage = conf.blobStorage
+	}
+
+	if conf.blobDirIsValue {
+		c.BlobDir = conf.blobDir
+	}
+
+	if conf.s3EndpointIsValue {
+		c.S3Endpoint = conf.s3Endpoint
+	}
+
+	if conf.s3RegionIsValue {
+		c.S3Region = conf.s3Region
+	}
+
+	if conf.s3BucketIsValue {
+		c.S3Bucket = conf.s3Bucket
+	}
+
+	if conf.s3PrefixIsValue {
+		c.S3Prefix = conf.s3Prefix
+	}
+
+	if conf.blobCollectIsValue {
+		c.BlobCollect = conf.blobCollect
+	}
 }
@@ -0,0 +1,77 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/server/http/middleware"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+)
+
+// AccountService describes the functionality of managing the user's account.
+type AccountService interface {
+	// ChangePassword replaces the authentication password and the wrapped vault key
+	// and revokes all the user's sessions except the current one.
+	ChangePassword(
+		ctx context.Context,
+		userID string,
+		currentSessionID string,
+		input service.ChangePasswordInput,
+	) (int, error)
+}
+
+// ChangePasswordRequest describes the body of the master password change request.
+type ChangePasswordRequest struct {
+	CurrentPassword string                 `json:"currentPassword"` // Current authentication password.
+	NewPassword     string                 `json:"newPassword"`     // New authentication password.
+	VaultKey        crypto.WrappedVaultKey `json:"vaultKey"`        // Vault key wrapped with the new master password.
+}
+
+func (s *Server) changePassword(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	var req ChangePasswordRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	revoked, err := s.account.ChangePassword(
+		r.Context(),
+		userID,
+		middleware.SessionIDFromContext(r.Context()),
+		service.ChangePasswordInput{
+			CurrentPassword: req.CurrentPassword,
+			NewPassword:     req.NewPassword,
+			VaultKey:        req.VaultKey,
+		},
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidPassword), errors.Is(err, service.ErrInvalidVaultKey):
+			s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+		case errors.Is(err, service.ErrInvalidCredentials):
+			s.writeError(
+				w,
+				http.StatusForbidden,
+				errCodeInvalidCredentials,
+				"current password is incorrect",
+			)
+		default:
+			s.writeInternalError(w, err)
+		}
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, RevokeSessionsResponse{Revoked: revoked})
+}
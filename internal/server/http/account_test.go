@@ -0,0 +1,94 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	server "github.com/mr-filatik/go-password-keeper/internal/server/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newChangePasswordRequest creates a request that sets the password "battery-staple"
+// and re-wraps the vault key for it.
+func newChangePasswordRequest(t *testing.T, currentPassword string) server.ChangePasswordRequest {
+	t.Helper()
+
+	register := newRegisterRequest(t, "", "battery-staple")
+
+	return server.ChangePasswordRequest{
+		CurrentPassword: currentPassword,
+		NewPassword:     register.Password,
+		VaultKey:        register.VaultKey,
+	}
+}
+
+func TestServer_ChangePassword(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	phone := loginTokens(t, handler, "alice")
+	laptop := loginDevice(t, handler, "laptop")
+
+	body := newChangePasswordRequest(t, "correct-horse")
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/account/password", body, phone.AccessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp server.RevokeSessionsResponse
+
+	decodeBody(t, rec, &resp)
+	assert.Equal(t, 1, resp.Revoked)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets", nil, laptop.AccessToken)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "other sessions must be revoked")
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets", nil, phone.AccessToken)
+	assert.Equal(t, http.StatusOK, rec.Code, "current session must stay active")
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/login",
+		server.CredentialsRequest{Login: "alice", Password: "correct-horse"}, "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "old password must stop working")
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/login",
+		server.CredentialsRequest{Login: "alice", Password: "battery-staple"}, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var login server.LoginResponse
+
+	decodeBody(t, rec, &login)
+	assert.Equal(t, body.VaultKey, login.VaultKey)
+}
+
+func TestServer_ChangePasswordErrors(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	wrongPassword := newChangePasswordRequest(t, "wrong-password")
+
+	rec := doJSON(
+		t,
+		handler,
+		http.MethodPost,
+		"/api/v1/account/password",
+		wrongPassword,
+		accessToken,
+	)
+	assert.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+
+	invalidKey := newChangePasswordRequest(t, "correct-horse")
+	invalidKey.VaultKey.WrappedKey = []byte("not an envelope")
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/account/password", invalidKey, accessToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/account/password",
+		newChangePasswordRequest(t, "correct-horse"), "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/login",
+		server.CredentialsRequest{Login: "alice", Password: "correct-horse"}, "")
+	assert.Equal(t, http.StatusOK, rec.Code, "failed changes must keep the password")
+}
@@ -0,0 +1,196 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mr-filatik/go-password-keeper/internal/server/http/middleware"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+)
+
+// errCodeRotationInProgress - the rotation of the master key has already been started.
+const errCodeRotationInProgress = "rotation_in_progress"
+
+// BlobChecker describes the functionality of the consistency check of the blob storage.
+type BlobChecker interface {
+	// Check compares the references of the revisions to blobs with the content of the blob storage.
+	Check(ctx context.Context) (*service.BlobCheckReport, error)
+}
+
+// KeyRotator describes the functionality of the rotation of the master key of encryption at rest.
+type KeyRotator interface {
+	// Start reloads the keyring and starts re-wrapping the data keys in the background.
+	Start(ctx context.Context) error
+
+	// Status returns the state of the last rotation.
+	Status() service.KeyRotationStatus
+}
+
+// AdminConfig - configuration of the maintenance endpoints of the administrator.
+type AdminConfig struct {
+	// Token - bearer token of the administrator, the endpoints are disabled if it is empty.
+	Token string
+
+	// KeyRotator - rotation of the master key, nil if encryption at rest is disabled.
+	KeyRotator KeyRotator
+
+	// BlobChecker - consistency check of the blob storage, nil to disable the endpoint.
+	BlobChecker BlobChecker
+}
+
+// KeyRotationResponse describes the state of the last rotation of the master key.
+type KeyRotationResponse struct {
+	Running    bool       `json:"running"`              // The rotation is in progress.
+	StartedAt  *time.Time `json:"startedAt,omitempty"`  // Start time, omitted if no rotation has been started.
+	FinishedAt *time.Time `json:"finishedAt,omitempty"` // Completion time, omitted while in progress.
+	Found      int        `json:"found"`                // Data keys wrapped with old master keys.
+	Rewrapped  int        `json:"rewrapped"`            // Data keys re-wrapped with the current master key.
+	Skipped    int        `json:"skipped"`              // Data keys changed concurrently and skipped.
+	Error      string     `json:"error,omitempty"`      // Error the rotation has stopped with.
+}
+
+// DanglingBlobReference describes a revision referring to a missing blob.
+type DanglingBlobReference struct {
+	BlobID   string `json:"blobId"`   // Identifier of the missing blob.
+	UserID   string `json:"userId"`   // Identifier of the owner of the secret.
+	SecretID string `json:"secretId"` // Secret identifier.
+	Revision int64  `json:"revision"` // Number of the revision, current or from the history.
+}
+
+// UnreferencedBlob describes a blob no revision refers to.
+type UnreferencedBlob struct {
+	ID      string    `json:"id"`      // Identifier of the blob.
+	Size    int64     `json:"size"`    // Size of the content in bytes.
+	SavedAt time.Time `json:"savedAt"` // Time the content was saved.
+}
+
+// BlobCheckResponse describes the inconsistencies between the secrets and the blob storage.
+type BlobCheckResponse struct {
+	Consistent   bool                    `json:"consistent"`   // No inconsistencies are found.
+	Blobs        int                     `json:"blobs"`        // Number of blobs in the storage.
+	References   int                     `json:"references"`   // Number of revisions referring to blobs.
+	Dangling     []DanglingBlobReference `json:"dangling"`     // References to missing blobs.
+	Unreferenced []UnreferencedBlob      `json:"unreferenced"` // Blobs no revision refers to.
+}
+
+// registerAdminHandlers registers the maintenance endpoints if the token of the administrator is set.
+func (s *Server) registerAdminHandlers(router chi.Router) {
+	if s.admin.Token == "" {
+		return
+	}
+
+	router.Route("/admin", func(routes chi.Router) {
+		routes.Use(middleware.AdminToken(s.admin.Token))
+
+		if s.admin.KeyRotator != nil {
+			routes.Post("/keys/rotation", s.startKeyRotation)
+			routes.Get("/keys/rotation", s.getKeyRotation)
+		}
+
+		if s.admin.BlobChecker != nil {
+			routes.Get("/blobs/check", s.checkBlobs)
+		}
+	})
+}
+
+// newKeyRotationResponse converts the state of the rotation to the response.
+func newKeyRotationResponse(status service.KeyRotationStatus) KeyRotationResponse {
+	resp := KeyRotationResponse{
+		Running:    status.Running,
+		StartedAt:  nil,
+		FinishedAt: nil,
+		Found:      status.Result.Found,
+		Rewrapped:  status.Result.Rewrapped,
+		Skipped:    status.Result.Skipped,
+		Error:      "",
+	}
+
+	if !status.StartedAt.IsZero() {
+		resp.StartedAt = &status.StartedAt
+	}
+
+	if !status.FinishedAt.IsZero() {
+		resp.FinishedAt = &status.FinishedAt
+	}
+
+	if status.Err != nil {
+		resp.Error = status.Err.Error()
+	}
+
+	return resp
+}
+
+func (s *Server) startKeyRotation(w http.ResponseWriter, r *http.Request) {
+	// The rotation outlives the request, it is stopped only with the instance.
+	err := s.admin.KeyRotator.Start(context.WithoutCancel(r.Context()))
+	if err != nil {
+		if errors.Is(err, service.ErrRotationInProgress) {
+			s.writeError(w, http.StatusConflict, errCodeRotationInProgress,
+				"master key rotation is in progress")
+
+			return
+		}
+
+		s.writeInternalError(w, err)
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusAccepted, newKeyRotationResponse(s.admin.KeyRotator.Status()))
+}
+
+func (s *Server) getKeyRotation(w http.ResponseWriter, _ *http.Request) {
+	s.writeJSON(w, http.StatusOK, newKeyRotationResponse(s.admin.KeyRotator.Status()))
+}
+
+// newBlobCheckResponse converts the report of the consistency check to the response.
+func newBlobCheckResponse(report *service.BlobCheckReport) BlobCheckResponse {
+	resp := BlobCheckResponse{
+		Consistent:   report.Consistent(),
+		Blobs:        report.Blobs,
+		References:   report.References,
+		Dangling:     make([]DanglingBlobReference, 0, len(report.Dangling)),
+		Unreferenced: make([]UnreferencedBlob, 0, len(report.Unreferenced)),
+	}
+
+	for _, reference := range report.Dangling {
+		resp.Dangling = append(resp.Dangling, DanglingBlobReference{
+			BlobID:   reference.BlobID,
+			UserID:   reference.UserID,
+			SecretID: reference.SecretID,
+			Revision: reference.Revision,
+		})
+	}
+
+	for _, blob := range report.Unreferenced {
+		resp.Unreferenced = append(resp.Unreferenced, UnreferencedBlob{
+			ID:      blob.ID,
+			Size:    blob.Size,
+			SavedAt: blob.SavedAt,
+		})
+	}
+
+	return resp
+}
+
+// checkBlobs returns the report of the consistency check of the blob storage,
+// with the code 409 if inconsistencies are found.
+func (s *Server) checkBlobs(w http.ResponseWriter, r *http.Request) {
+	report, err := s.admin.BlobChecker.Check(r.Context())
+	if err != nil {
+		s.writeInternalError(w, err)
+
+		return
+	}
+
+	status := http.StatusOK
+	if !report.Consistent() {
+		status = http.StatusConflict
+	}
+
+	s.writeJSON(w, status, newBlobCheckResponse(report))
+}
@@ -0,0 +1,157 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	server "github.com/mr-filatik/go-password-keeper/internal/server/http"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/filesystem"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/memory"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAdminToken = "admin-token"
+
+// fakeKeyRotator - rotation of the master key that completes when the test finishes it.
+type fakeKeyRotator struct {
+	mu     sync.Mutex
+	status service.KeyRotationStatus
+}
+
+func (r *fakeKeyRotator) Start(_ context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.status.Running {
+		return service.ErrRotationInProgress
+	}
+
+	r.status.Running = true
+	r.status.StartedAt = time.Now().UTC()
+
+	return nil
+}
+
+func (r *fakeKeyRotator) Status() service.KeyRotationStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.status
+}
+
+// finish completes the rotation with the totals.
+func (r *fakeKeyRotator) finish(result atrest.RewrapResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.status.Running = false
+	r.status.FinishedAt = time.Now().UTC()
+	r.status.Result = result
+}
+
+func TestServer_AdminKeyRotation(t *testing.T) {
+	t.Parallel()
+
+	rotator := &fakeKeyRotator{
+		mu: sync.Mutex{},
+		status: service.KeyRotationStatus{
+			Running:    false,
+			StartedAt:  time.Time{},
+			FinishedAt: time.Time{},
+			Result:     atrest.RewrapResult{Found: 0, Rewrapped: 0, Skipped: 0},
+			Err:        nil,
+		},
+	}
+	handler := newTestServerWithAdmin(t, t.TempDir(), server.AdminConfig{
+		Token:       testAdminToken,
+		KeyRotator:  rotator,
+		BlobChecker: nil,
+	})
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/admin/keys/rotation", nil, "wrong")
+	require.Equal(t, http.StatusUnauthorized, rec.Code, rec.Body.String())
+
+	// The access token of a user does not grant access to the admin API.
+	userToken := registerAndLogin(t, handler, "alice")
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/admin/keys/rotation", nil, userToken)
+	require.Equal(t, http.StatusUnauthorized, rec.Code, rec.Body.String())
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/admin/keys/rotation", nil, testAdminToken)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+
+	var started server.KeyRotationResponse
+	decodeBody(t, rec, &started)
+	assert.True(t, started.Running)
+	assert.NotNil(t, started.StartedAt)
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/admin/keys/rotation", nil, testAdminToken)
+	require.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+
+	rotator.finish(atrest.RewrapResult{Found: 3, Rewrapped: 2, Skipped: 1})
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/admin/keys/rotation", nil, testAdminToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var finished server.KeyRotationResponse
+	decodeBody(t, rec, &finished)
+	assert.False(t, finished.Running)
+	assert.NotNil(t, finished.FinishedAt)
+	assert.Equal(t, 2, finished.Rewrapped)
+	assert.Equal(t, 1, finished.Skipped)
+}
+
+func TestServer_AdminDisabledWithoutToken(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/admin/keys/rotation", nil, "")
+	require.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
+
+func TestServer_AdminBlobCheck(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := newTestLogger(t)
+
+	blobs, err := filesystem.NewBlobStore(t.TempDir())
+	require.NoError(t, err)
+
+	handler := newTestServerWithAdmin(t, t.TempDir(), server.AdminConfig{
+		Token:       testAdminToken,
+		KeyRotator:  nil,
+		BlobChecker: service.NewBlobService(blobs, memory.NewSecretRepository(), nil, logger),
+	})
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/admin/blobs/check", nil, testAdminToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var report server.BlobCheckResponse
+	decodeBody(t, rec, &report)
+	assert.True(t, report.Consistent)
+
+	// A blob no secret refers to, for example, left by a failed change.
+	content := []byte("orphaned content")
+	hash := sha256.Sum256(content)
+	blobID := hex.EncodeToString(hash[:])
+	require.NoError(t, blobs.PutBlob(ctx, blobID, bytes.NewReader(content), int64(len(content))))
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/admin/blobs/check", nil, testAdminToken)
+	require.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+
+	decodeBody(t, rec, &report)
+	assert.False(t, report.Consistent)
+	require.Len(t, report.Unreferenced, 1)
+	assert.Equal(t, blobID, report.Unreferenced[0].ID)
+	assert.Empty(t, report.Dangling)
+}
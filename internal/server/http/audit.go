@@ -0,0 +1,84 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+)
+
+// AuditService describes the functionality of reading the audit log of the user's account.
+type AuditService interface {
+	// ListPage returns a page of the events of the user's account sorted by time.
+	ListPage(
+		ctx context.Context,
+		userID string,
+		query model.PageQuery,
+	) (*service.Page[*model.AuditEvent], error)
+}
+
+// AuditEventResponse describes the audit event returned to the client.
+type AuditEventResponse struct {
+	ID            string            `json:"id"`            // Event identifier.
+	Type          string            `json:"type"`          // Event type.
+	ClientName    string            `json:"clientName"`    // Client application name.
+	ClientVersion string            `json:"clientVersion"` // Client application version.
+	IP            string            `json:"ip"`            // Client IP address.
+	Details       map[string]string `json:"details"`       // Additional event attributes.
+	CreatedAt     time.Time         `json:"createdAt"`     // Event time.
+}
+
+// AuditLogResponse describes a page of the audit log returned to the client.
+type AuditLogResponse struct {
+	Items   []AuditEventResponse `json:"items"`            // Events sorted by time.
+	Cursor  string               `json:"cursor,omitempty"` // Opaque cursor of the next page, omitted on the last page.
+	HasMore bool                 `json:"hasMore"`          // More events are available with the cursor.
+}
+
+func newAuditEventResponse(event *model.AuditEvent) AuditEventResponse {
+	return AuditEventResponse{
+		ID:            event.ID,
+		Type:          event.Type,
+		ClientName:    event.Client.Name,
+		ClientVersion: event.Client.Version,
+		IP:            event.Client.IP,
+		Details:       event.Details,
+		CreatedAt:     event.CreatedAt,
+	}
+}
+
+func (s *Server) listAuditEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	query, err := parsePageQuery(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	page, err := s.audit.ListPage(r.Context(), userID, *query)
+	if err != nil {
+		s.writeInternalError(w, err)
+
+		return
+	}
+
+	resp := AuditLogResponse{
+		Items:   make([]AuditEventResponse, 0, len(page.Items)),
+		Cursor:  encodePageCursor(page.Next),
+		HasMore: page.Next != nil,
+	}
+
+	for _, event := range page.Items {
+		resp.Items = append(resp.Items, newAuditEventResponse(event))
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
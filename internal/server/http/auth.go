@@ -0,0 +1,339 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+)
+
+// AuthService describes the functionality of user registration and login.
+type AuthService interface {
+	// Register creates a new user account with its set of recovery codes.
+	Register(ctx context.Context, input service.RegisterInput) (*model.User, error)
+
+	// KDFParams returns the key derivation parameters of the user.
+	KDFParams(ctx context.Context, login string) (crypto.KDFParams, error)
+
+	// Login checks the user's credentials and issues a new pair of tokens.
+	Login(
+		ctx context.Context,
+		login string,
+		password string,
+		client model.ClientInfo,
+	) (*service.LoginResult, error)
+
+	// LoginTwoFactor completes the login with the one-time code and issues a new pair of tokens.
+	LoginTwoFactor(
+		ctx context.Context,
+		challengeToken string,
+		code string,
+		client model.ClientInfo,
+	) (*service.LoginResult, error)
+
+	// Refresh exchanges the refresh token for a new pair of tokens.
+	Refresh(
+		ctx context.Context,
+		refreshToken string,
+		client model.ClientInfo,
+	) (*service.TokenPair, error)
+}
+
+// Headers describing the client application.
+//
+// If HeaderClientName is not set, the User-Agent header is used.
+const (
+	HeaderClientName    = "X-Client-Name"
+	HeaderClientVersion = "X-Client-Version"
+)
+
+const maxClientInfoLength = 128
+
+// CredentialsRequest describes the body of the login request.
+type CredentialsRequest struct {
+	Login    string `json:"login"`    // User login.
+	Password string `json:"password"` // Authentication password derived from the master password.
+}
+
+// RegisterRequest describes the body of the registration request.
+type RegisterRequest struct {
+	Login        string                 `json:"login"`        // User login.
+	Password     string                 `json:"password"`     // Authentication password.
+	VaultKey     crypto.WrappedVaultKey `json:"vaultKey"`     // Vault key wrapped on the client.
+	RecoveryKeys []crypto.RecoveryKey   `json:"recoveryKeys"` // Vault key copies wrapped with recovery codes.
+}
+
+// PreloginRequest describes the body of the request for key derivation parameters.
+type PreloginRequest struct {
+	Login string `json:"login"` // User login.
+}
+
+// PreloginResponse describes the key derivation parameters of the user.
+type PreloginResponse struct {
+	KDF crypto.KDFParams `json:"kdf"` // Parameters for deriving keys from the master password.
+}
+
+// UserResponse describes the user data returned to the client.
+type UserResponse struct {
+	ID        string    `json:"id"`        // User identifier.
+	Login     string    `json:"login"`     // User login.
+	CreatedAt time.Time `json:"createdAt"` // Registration time.
+}
+
+// RefreshRequest describes the body of the token refresh request.
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"` // Refresh token received earlier.
+}
+
+// TokenResponse describes the issued pair of tokens.
+type TokenResponse struct {
+	SessionID        string    `json:"sessionId"`        // Identifier of the session.
+	AccessToken      string    `json:"accessToken"`      // Signed access token.
+	TokenType        string    `json:"tokenType"`        // Token type for the Authorization header.
+	ExpiresAt        time.Time `json:"expiresAt"`        // Expiration time of the access token.
+	RefreshToken     string    `json:"refreshToken"`     // Opaque single-use refresh token.
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"` // Expiration time of the refresh token.
+}
+
+// LoginResponse describes the result of a successful login.
+type LoginResponse struct {
+	TokenResponse
+
+	User     UserResponse           `json:"user"`     // Authenticated user.
+	VaultKey crypto.WrappedVaultKey `json:"vaultKey"` // Vault key wrapped on the client.
+}
+
+// TwoFactorChallengeResponse describes the login waiting for the one-time code.
+type TwoFactorChallengeResponse struct {
+	ChallengeToken string    `json:"challengeToken"` // Token for completing the login with the code.
+	ExpiresAt      time.Time `json:"expiresAt"`      // Expiration time of the challenge.
+}
+
+// LoginTwoFactorRequest describes the body of the request completing the login with the one-time code.
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challengeToken"` // Token of the login challenge.
+	Code           string `json:"code"`           // One-time code from the authenticator.
+}
+
+const tokenTypeBearer = "Bearer"
+
+func newUserResponse(user *model.User) UserResponse {
+	return UserResponse{
+		ID:        user.ID,
+		Login:     user.Login,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+func newTokenResponse(tokens *service.TokenPair) TokenResponse {
+	return TokenResponse{
+		SessionID:        tokens.SessionID,
+		AccessToken:      tokens.AccessToken,
+		TokenType:        tokenTypeBearer,
+		ExpiresAt:        tokens.AccessTokenExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshTokenExpiresAt,
+	}
+}
+
+func (s *Server) register(w http.ResponseWriter, r *http.Request) {
+	var req RegisterRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	user, err := s.auth.Register(r.Context(), service.RegisterInput{
+		Login:        req.Login,
+		Password:     req.Password,
+		VaultKey:     req.VaultKey,
+		RecoveryKeys: req.RecoveryKeys,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidLogin),
+			errors.Is(err, service.ErrInvalidPassword),
+			errors.Is(err, service.ErrInvalidVaultKey),
+			errors.Is(err, service.ErrInvalidRecoveryCodes):
+			s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+		case errors.Is(err, service.ErrUserAlreadyExists):
+			s.writeError(w, http.StatusConflict, errCodeUserAlreadyExists,
+				"user with this login already exists")
+		default:
+			s.writeInternalError(w, err)
+		}
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, newUserResponse(user))
+}
+
+func (s *Server) prelogin(w http.ResponseWriter, r *http.Request) {
+	var req PreloginRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	params, err := s.auth.KDFParams(r.Context(), req.Login)
+	if err != nil {
+		s.writeInternalError(w, err)
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, PreloginResponse{KDF: params})
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	var req CredentialsRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	result, err := s.auth.Login(r.Context(), req.Login, req.Password, clientInfo(r))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			s.writeError(
+				w,
+				http.StatusUnauthorized,
+				errCodeInvalidCredentials,
+				"invalid login or password",
+			)
+
+			return
+		}
+
+		s.writeInternalError(w, err)
+
+		return
+	}
+
+	if result.Challenge != nil {
+		s.writeJSON(w, http.StatusAccepted, TwoFactorChallengeResponse{
+			ChallengeToken: result.Challenge.Token,
+			ExpiresAt:      result.Challenge.ExpiresAt,
+		})
+
+		return
+	}
+
+	s.writeLoginResult(w, result)
+}
+
+func (s *Server) loginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req LoginTwoFactorRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	result, err := s.auth.LoginTwoFactor(r.Context(), req.ChallengeToken, req.Code, clientInfo(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidLoginChallenge),
+			errors.Is(err, service.ErrTwoFactorNotEnabled):
+			s.writeError(w, http.StatusUnauthorized, errCodeInvalidLoginChallenge,
+				"login challenge is invalid, expired or has already been used")
+		case errors.Is(err, service.ErrInvalidTwoFactorCode):
+			s.writeError(w, http.StatusUnauthorized, errCodeInvalidTwoFactorCode,
+				"one-time code is invalid, the login must be started again")
+		case errors.Is(err, service.ErrTwoFactorLocked):
+			s.writeError(w, http.StatusTooManyRequests, errCodeTwoFactorLocked,
+				"too many invalid one-time codes, try again later")
+		default:
+			s.writeInternalError(w, err)
+		}
+
+		return
+	}
+
+	s.writeLoginResult(w, result)
+}
+
+func (s *Server) refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	tokens, err := s.auth.Refresh(r.Context(), req.RefreshToken, clientInfo(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRefreshToken):
+			s.writeError(w, http.StatusUnauthorized, errCodeInvalidRefreshToken,
+				"refresh token is invalid, expired or revoked")
+		case errors.Is(err, service.ErrRefreshTokenReused):
+			s.writeError(w, http.StatusUnauthorized, errCodeRefreshTokenReused,
+				"refresh token has already been used, the session is revoked")
+		default:
+			s.writeInternalError(w, err)
+		}
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, newTokenResponse(tokens))
+}
+
+// writeLoginResult writes the issued tokens and the data the client needs to unlock the vault.
+func (s *Server) writeLoginResult(w http.ResponseWriter, result *service.LoginResult) {
+	s.writeJSON(w, http.StatusOK, LoginResponse{
+		TokenResponse: newTokenResponse(&result.Tokens),
+		User:          newUserResponse(result.User),
+		VaultKey:      result.User.VaultKey,
+	})
+}
+
+// clientInfo returns the description of the client application that made the request.
+func clientInfo(r *http.Request) model.ClientInfo {
+	name := r.Header.Get(HeaderClientName)
+	if name == "" {
+		name = r.UserAgent()
+	}
+
+	clientIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		clientIP = r.RemoteAddr
+	}
+
+	return model.ClientInfo{
+		Name:    truncate(name, maxClientInfoLength),
+		Version: truncate(r.Header.Get(HeaderClientVersion), maxClientInfoLength),
+		IP:      clientIP,
+	}
+}
+
+// truncate cuts the string to the specified number of characters.
+func truncate(value string, maxLength int) string {
+	if utf8.RuneCountInString(value) <= maxLength {
+		return value
+	}
+
+	return string([]rune(value)[:maxLength])
+}
@@ -0,0 +1,301 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	server "github.com/mr-filatik/go-password-keeper/internal/server/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_RegisterAndLogin(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+
+	creds := server.CredentialsRequest{Login: "alice", Password: "correct-horse"}
+	registerReq := newRegisterRequest(t, creds.Login, creds.Password)
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/register", registerReq, "")
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var registered server.UserResponse
+
+	decodeBody(t, rec, &registered)
+
+	assert.NotEmpty(t, registered.ID)
+	assert.Equal(t, "alice", registered.Login)
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/login", creds, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var logged server.LoginResponse
+
+	decodeBody(t, rec, &logged)
+
+	assert.Equal(t, registered.ID, logged.User.ID)
+	assert.NotEmpty(t, logged.AccessToken)
+	assert.Equal(t, "Bearer", logged.TokenType)
+	assert.NotEmpty(t, logged.RefreshToken)
+	assert.True(t, logged.RefreshExpiresAt.After(logged.ExpiresAt))
+	assert.Equal(t, registerReq.VaultKey, logged.VaultKey)
+}
+
+type registerErrorTestCase struct {
+	name       string
+	body       any
+	wantStatus int
+	wantCode   string
+}
+
+func getTestsRegisterErrors(t *testing.T) []registerErrorTestCase {
+	t.Helper()
+
+	weakKDF := newRegisterRequest(t, "bob", "correct-horse")
+	weakKDF.VaultKey.KDF.Iterations = 1
+
+	emptyWrappedKey := newRegisterRequest(t, "bob", "correct-horse")
+	emptyWrappedKey.VaultKey.WrappedKey = nil
+
+	noRecoveryCodes := newRegisterRequest(t, "bob", "correct-horse")
+	noRecoveryCodes.RecoveryKeys = nil
+
+	return []registerErrorTestCase{
+		{
+			name:       "duplicate login",
+			body:       newRegisterRequest(t, "alice", "correct-horse"),
+			wantStatus: http.StatusConflict,
+			wantCode:   "user_already_exists",
+		},
+		{
+			name:       "short password",
+			body:       newRegisterRequest(t, "bob", "short"),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_request",
+		},
+		{
+			name:       "weak KDF parameters",
+			body:       weakKDF,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_request",
+		},
+		{
+			name:       "no wrapped vault key",
+			body:       emptyWrappedKey,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_request",
+		},
+		{
+			name:       "no recovery codes",
+			body:       noRecoveryCodes,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_request",
+		},
+		{
+			name: "unknown field",
+			body: map[string]string{
+				"login":    "bob",
+				"password": "correct-horse",
+				"role":     "admin",
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_request",
+		},
+	}
+}
+
+func TestServer_RegisterErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := getTestsRegisterErrors(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := newTestServer(t)
+
+			rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/register",
+				newRegisterRequest(t, "alice", "correct-horse"), "")
+			require.Equal(t, http.StatusCreated, rec.Code)
+
+			rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/register", tt.body, "")
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+
+			var resp server.ErrorResponse
+
+			decodeBody(t, rec, &resp)
+			assert.Equal(t, tt.wantCode, resp.Code)
+		})
+	}
+}
+
+func TestServer_Prelogin(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	registerReq := newRegisterRequest(t, "alice", "correct-horse")
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/register", registerReq, "")
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	prelogin := func(login string) server.PreloginResponse {
+		rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/prelogin",
+			server.PreloginRequest{Login: login}, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var resp server.PreloginResponse
+
+		decodeBody(t, rec, &resp)
+
+		return resp
+	}
+
+	assert.Equal(t, registerReq.VaultKey.KDF, prelogin("alice").KDF)
+
+	unknown := prelogin("nobody")
+	require.NoError(t, unknown.KDF.Validate(), "fake parameters must look real")
+	assert.Equal(t, unknown, prelogin("nobody"), "fake parameters must be stable")
+	assert.NotEqual(t, unknown, prelogin("somebody"))
+}
+
+func TestServer_LoginInvalidCredentials(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/register",
+		newRegisterRequest(t, "alice", "correct-horse"), "")
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	for _, creds := range []server.CredentialsRequest{
+		{Login: "alice", Password: "wrong-password"},
+		{Login: "nobody", Password: "correct-horse"},
+	} {
+		rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/login", creds, "")
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		var resp server.ErrorResponse
+
+		decodeBody(t, rec, &resp)
+		assert.Equal(t, "invalid_credentials", resp.Code)
+	}
+}
+
+func TestServer_AuthMethodNotAllowed(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/auth/login", nil, "")
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+// loginTokens registers the user and returns the tokens issued at login.
+func loginTokens(t *testing.T, handler http.Handler, login string) server.LoginResponse {
+	t.Helper()
+
+	creds := server.CredentialsRequest{Login: login, Password: "correct-horse"}
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/register",
+		newRegisterRequest(t, creds.Login, creds.Password), "")
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/login", creds, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp server.LoginResponse
+
+	decodeBody(t, rec, &resp)
+
+	return resp
+}
+
+// refreshTokens exchanges the refresh token and returns the response.
+func refreshTokens(
+	t *testing.T,
+	handler http.Handler,
+	refreshToken string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	return doJSON(t, handler, http.MethodPost, "/api/v1/auth/refresh",
+		server.RefreshRequest{RefreshToken: refreshToken}, "")
+}
+
+func TestServer_RefreshRotatesTokens(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	logged := loginTokens(t, handler, "alice")
+
+	rec := refreshTokens(t, handler, logged.RefreshToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var rotated server.TokenResponse
+
+	decodeBody(t, rec, &rotated)
+	assert.NotEmpty(t, rotated.AccessToken)
+	assert.Equal(t, "Bearer", rotated.TokenType)
+	assert.NotEqual(t, logged.RefreshToken, rotated.RefreshToken)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets", nil, rotated.AccessToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = refreshTokens(t, handler, rotated.RefreshToken)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+func TestServer_RefreshReuseRevokesSession(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	logged := loginTokens(t, handler, "alice")
+
+	rec := refreshTokens(t, handler, logged.RefreshToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var rotated server.TokenResponse
+
+	decodeBody(t, rec, &rotated)
+
+	rec = refreshTokens(t, handler, logged.RefreshToken)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	var resp server.ErrorResponse
+
+	decodeBody(t, rec, &resp)
+	assert.Equal(t, "refresh_token_reused", resp.Code)
+
+	rec = refreshTokens(t, handler, rotated.RefreshToken)
+	require.Equal(t, http.StatusUnauthorized, rec.Code, "rotated token must be revoked")
+
+	decodeBody(t, rec, &resp)
+	assert.Equal(t, "invalid_refresh_token", resp.Code)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets", nil, rotated.AccessToken)
+	require.Equal(t, http.StatusUnauthorized, rec.Code, "session access must be revoked")
+
+	other := loginTokens(t, handler, "bob")
+
+	rec = refreshTokens(t, handler, other.RefreshToken)
+	assert.Equal(t, http.StatusOK, rec.Code, "other families must not be affected")
+}
+
+func TestServer_RefreshInvalidToken(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+
+	for _, refreshToken := range []string{"", "unknown-token"} {
+		rec := refreshTokens(t, handler, refreshToken)
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		var resp server.ErrorResponse
+
+		decodeBody(t, rec, &resp)
+		assert.Equal(t, "invalid_refresh_token", resp.Code)
+	}
+}
@@ -0,0 +1,282 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+)
+
+// ConflictService describes the functionality of the detection and resolution of conflicts of offline edits.
+type ConflictService interface {
+	// Push applies the edit made offline against the base revision or records a conflict.
+	Push(
+		ctx context.Context,
+		userID string,
+		secretID string,
+		baseRevision int64,
+		input service.SecretInput,
+	) (*service.PushResult, error)
+
+	// Get returns the user's conflict.
+	Get(ctx context.Context, userID string, conflictID string) (*model.SecretConflict, error)
+
+	// ListPage returns a page of the unresolved conflicts of the user.
+	ListPage(
+		ctx context.Context,
+		userID string,
+		query model.PageQuery,
+	) (*service.Page[*model.SecretConflict], error)
+
+	// Resolve applies the version chosen by the user and deletes the conflict,
+	// returns the secret recreated in place of the deleted one.
+	Resolve(
+		ctx context.Context,
+		userID string,
+		conflictID string,
+		resolution service.Resolution,
+		merged *service.SecretInput,
+		match service.RevisionMatch,
+	) (*model.Secret, error)
+}
+
+// PushSecretRequest describes the body of the request with an edit made offline.
+type PushSecretRequest struct {
+	BaseRevision int64             `json:"baseRevision"` // Revision the edit is based on.
+	Type         secret.Type       `json:"type"`         // Kind of secret.
+	Metadata     map[string]string `json:"metadata"`     // Free-form metadata.
+	Tags         []string          `json:"tags"`         // Labels the secret is grouped by.
+	Data         []byte            `json:"data"`         // Payload encrypted on the client (base64).
+
+	// Indexes - blind indexes of the fields of the secret the search finds it by.
+	Indexes []crypto.BlindIndex `json:"indexes,omitempty"`
+}
+
+// ConflictResponse describes the unresolved conflict returned to the client.
+type ConflictResponse struct {
+	ID             string            `json:"id"`             // Conflict identifier.
+	SecretID       string            `json:"secretId"`       // Identifier of the conflicting secret.
+	BaseRevision   int64             `json:"baseRevision"`   // Revision the client edit is based on.
+	ServerRevision int64             `json:"serverRevision"` // Revision of the secret at detection time.
+	ServerDeleted  bool              `json:"serverDeleted"`  // The secret has been deleted on the server.
+	Type           secret.Type       `json:"type"`           // Kind of secret of the client edit.
+	Metadata       map[string]string `json:"metadata"`       // Metadata of the client edit.
+	Tags           []string          `json:"tags"`           // Tags of the client edit.
+	Data           []byte            `json:"data"`           // Payload of the client edit (base64).
+	CreatedAt      time.Time         `json:"createdAt"`      // Time the conflict was detected.
+
+	// Indexes - blind indexes of the client edit.
+	Indexes []crypto.BlindIndex `json:"indexes"`
+}
+
+// ConflictListResponse describes a page of the list of unresolved conflicts.
+type ConflictListResponse struct {
+	Items   []ConflictResponse `json:"items"`            // Conflicts sorted by detection time.
+	Cursor  string             `json:"cursor,omitempty"` // Opaque cursor of the next page, omitted on the last page.
+	HasMore bool               `json:"hasMore"`          // More conflicts are available with the cursor.
+}
+
+// ResolveConflictRequest describes the body of the conflict resolution request.
+type ResolveConflictRequest struct {
+	Resolution service.Resolution `json:"resolution"`       // Version to keep: server, client or merged.
+	Merged     *SecretRequest     `json:"merged,omitempty"` // Merged content, only for the merged resolution.
+}
+
+func newConflictResponse(conflict *model.SecretConflict) ConflictResponse {
+	resp := ConflictResponse{
+		ID:             conflict.ID,
+		SecretID:       conflict.SecretID,
+		BaseRevision:   conflict.BaseRevision,
+		ServerRevision: conflict.ServerRevision,
+		ServerDeleted:  conflict.ServerDeleted,
+		Type:           conflict.Type,
+		Metadata:       conflict.Metadata,
+		Tags:           conflict.Tags,
+		Data:           conflict.Data,
+		CreatedAt:      conflict.CreatedAt,
+		Indexes:        conflict.Indexes,
+	}
+
+	if resp.Metadata == nil {
+		resp.Metadata = map[string]string{}
+	}
+
+	if resp.Tags == nil {
+		resp.Tags = []string{}
+	}
+
+	if resp.Indexes == nil {
+		resp.Indexes = []crypto.BlindIndex{}
+	}
+
+	return resp
+}
+
+func (s *Server) writeConflictError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrSecretConflictNotFound):
+		s.writeError(w, http.StatusNotFound, errCodeNotFound, "conflict not found")
+	case errors.Is(err, service.ErrInvalidResolution):
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+	default:
+		s.writeSecretError(w, err)
+	}
+}
+
+func (s *Server) pushSecret(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	var req PushSecretRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil || req.BaseRevision <= 0 {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	result, err := s.conflicts.Push(
+		r.Context(),
+		userID,
+		chi.URLParam(r, "id"),
+		req.BaseRevision,
+		service.SecretInput{
+			FolderID: "",
+			Type:     req.Type,
+			Metadata: req.Metadata,
+			Tags:     req.Tags,
+			Data:     req.Data,
+			UploadID: "",
+			Indexes:  req.Indexes,
+		},
+	)
+	if err != nil {
+		s.writeConflictError(w, err)
+
+		return
+	}
+
+	if result.Conflict != nil {
+		s.writeJSON(w, http.StatusConflict, newConflictResponse(result.Conflict))
+
+		return
+	}
+
+	s.writeSecret(w, http.StatusOK, result.Secret)
+}
+
+func (s *Server) listConflicts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	query, err := parsePageQuery(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	page, err := s.conflicts.ListPage(r.Context(), userID, *query)
+	if err != nil {
+		s.writeConflictError(w, err)
+
+		return
+	}
+
+	resp := ConflictListResponse{
+		Items:   make([]ConflictResponse, 0, len(page.Items)),
+		Cursor:  encodePageCursor(page.Next),
+		HasMore: page.Next != nil,
+	}
+
+	for _, conflict := range page.Items {
+		resp.Items = append(resp.Items, newConflictResponse(conflict))
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) getConflict(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	conflict, err := s.conflicts.Get(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeConflictError(w, err)
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, newConflictResponse(conflict))
+}
+
+func (s *Server) resolveConflict(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	var req ResolveConflictRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	// Overwriting the current revision requires the proof that the user has seen it.
+	var match service.RevisionMatch
+
+	if req.Resolution != service.ResolutionServer {
+		var present bool
+
+		match, present = s.revisionMatch(w, r)
+		if !present {
+			return
+		}
+	}
+
+	var merged *service.SecretInput
+
+	if req.Merged != nil {
+		input := req.Merged.toInput()
+		merged = &input
+	}
+
+	recreated, err := s.conflicts.Resolve(
+		r.Context(),
+		userID,
+		chi.URLParam(r, "id"),
+		req.Resolution,
+		merged,
+		match,
+	)
+	if err != nil {
+		s.writeConflictError(w, err)
+
+		return
+	}
+
+	if recreated != nil {
+		s.writeSecret(w, http.StatusCreated, recreated)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
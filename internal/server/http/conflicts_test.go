@@ -0,0 +1,289 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	server "github.com/mr-filatik/go-password-keeper/internal/server/http"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pushConflict pushes an offline edit of the secret based on the first revision
+// after the secret has been changed online, and returns the recorded conflict.
+func pushConflict(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+	secretID string,
+	data []byte,
+) server.ConflictResponse {
+	t.Helper()
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/secrets/"+secretID+"/push",
+		server.PushSecretRequest{
+			BaseRevision: 1,
+			Type:         secret.TypeText,
+			Metadata:     map[string]string{"device": "laptop"},
+			Tags:         nil,
+			Data:         data,
+			Indexes:      nil,
+		}, accessToken)
+	require.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+
+	var conflict server.ConflictResponse
+
+	decodeBody(t, rec, &conflict)
+
+	return conflict
+}
+
+// resolveConflict resolves the conflict and returns the response.
+func resolveConflict(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+	conflictID string,
+	req server.ResolveConflictRequest,
+	tags string,
+) int {
+	t.Helper()
+
+	headers := map[string]string{}
+	if tags != "" {
+		headers = ifMatch(tags)
+	}
+
+	rec := doJSONWithHeaders(
+		t,
+		handler,
+		http.MethodPost,
+		"/api/v1/conflicts/"+conflictID+"/resolve",
+		req,
+		accessToken,
+		headers,
+	)
+
+	return rec.Code
+}
+
+// listConflicts returns the unresolved conflicts of the user.
+func listConflicts(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+) []server.ConflictResponse {
+	t.Helper()
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/conflicts", nil, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp server.ConflictListResponse
+
+	decodeBody(t, rec, &resp)
+
+	return resp.Items
+}
+
+// getSecret returns the current revision of the user's secret.
+func getSecret(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+	secretID string,
+) server.SecretResponse {
+	t.Helper()
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/secrets/"+secretID, nil, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp server.SecretResponse
+
+	decodeBody(t, rec, &resp)
+
+	return resp
+}
+
+// resolve returns the resolution request without merged content.
+func resolve(resolution service.Resolution) server.ResolveConflictRequest {
+	return server.ResolveConflictRequest{Resolution: resolution, Merged: nil}
+}
+
+func TestServer_PushSecret(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+	created := createTextSecret(t, handler, accessToken, "revision 1")
+
+	edit := encryptPayload(t, &secret.Text{Value: "edited offline"})
+
+	rec := doJSON(
+		t,
+		handler,
+		http.MethodPost,
+		"/api/v1/secrets/"+created.ID+"/push",
+		server.PushSecretRequest{
+			BaseRevision: 1,
+			Type:         secret.TypeText,
+			Metadata:     nil,
+			Tags:         nil,
+			Data:         edit,
+			Indexes:      nil,
+		},
+		accessToken,
+	)
+	require.Equal(t, http.StatusOK, rec.Code, "edit of the current revision must be applied")
+	assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+
+	// The edit made on a plane is based on the revision replaced in the meantime.
+	offline := encryptPayload(t, &secret.Text{Value: "edited on a plane"})
+	conflict := pushConflict(t, handler, accessToken, created.ID, offline)
+	assert.Equal(t, created.ID, conflict.SecretID)
+	assert.Equal(t, int64(1), conflict.BaseRevision)
+	assert.Equal(t, int64(2), conflict.ServerRevision)
+	assert.Equal(t, offline, conflict.Data)
+
+	current := getSecret(t, handler, accessToken, created.ID)
+	assert.Equal(t, edit, current.Data, "conflicting edit must not be applied")
+	assert.Equal(t, []server.ConflictResponse{conflict}, listConflicts(t, handler, accessToken))
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/conflicts/"+conflict.ID, nil,
+		registerAndLogin(t, handler, "bob"))
+	assert.Equal(t, http.StatusNotFound, rec.Code, "conflicts of other users must be hidden")
+
+	rec = doJSON(
+		t,
+		handler,
+		http.MethodPost,
+		"/api/v1/secrets/"+created.ID+"/push",
+		server.PushSecretRequest{
+			BaseRevision: 0,
+			Type:         secret.TypeText,
+			Metadata:     nil,
+			Tags:         nil,
+			Data:         edit,
+			Indexes:      nil,
+		},
+		accessToken,
+	)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestServer_ResolveConflict(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+	created := createTextSecret(t, handler, accessToken, "revision 1")
+	online := encryptPayload(t, &secret.Text{Value: "edited online"})
+	updateSecretText(t, handler, accessToken, created.ID, online)
+
+	offline := encryptPayload(t, &secret.Text{Value: "edited offline"})
+	keepServer := pushConflict(t, handler, accessToken, created.ID, offline)
+	keepClient := pushConflict(t, handler, accessToken, created.ID, offline)
+	merge := pushConflict(t, handler, accessToken, created.ID, offline)
+
+	merged := encryptPayload(t, &secret.Text{Value: "merged"})
+	mergedSecret := textSecretRequest(merged)
+	mergedReq := server.ResolveConflictRequest{
+		Resolution: service.ResolutionMerged,
+		Merged:     &mergedSecret,
+	}
+
+	steps := []struct {
+		name       string
+		conflictID string
+		req        server.ResolveConflictRequest
+		tags       string
+		want       int
+	}{
+		{"unknown resolution", keepServer.ID, resolve("mine"), `"2"`, http.StatusBadRequest},
+		{
+			"client without If-Match", keepClient.ID, resolve(service.ResolutionClient), "",
+			http.StatusPreconditionRequired,
+		},
+		{
+			"client over unseen revision", keepClient.ID, resolve(service.ResolutionClient), `"1"`,
+			http.StatusPreconditionFailed,
+		},
+		{
+			"merged without content", merge.ID, resolve(service.ResolutionMerged), `"2"`,
+			http.StatusBadRequest,
+		},
+		{"server", keepServer.ID, resolve(service.ResolutionServer), "", http.StatusNoContent},
+		{"repeated", keepServer.ID, resolve(service.ResolutionServer), "", http.StatusNotFound},
+		{"client", keepClient.ID, resolve(service.ResolutionClient), `"2"`, http.StatusNoContent},
+		{"merged", merge.ID, mergedReq, `"3"`, http.StatusNoContent},
+	}
+	for _, step := range steps {
+		code := resolveConflict(t, handler, accessToken, step.conflictID, step.req, step.tags)
+		assert.Equal(t, step.want, code, step.name)
+	}
+
+	assert.Empty(t, listConflicts(t, handler, accessToken))
+
+	// Every version is kept: the merged one is current, the others are in the history.
+	versions := listSecretVersions(t, handler, accessToken, created.ID)
+	require.Len(t, versions, testSecretVersionsLimit)
+	assert.Equal(t, offline, versions[0].Data)
+	assert.Equal(t, online, versions[1].Data)
+	assert.Equal(t, merged, getSecret(t, handler, accessToken, created.ID).Data)
+}
+
+func TestServer_PushDeletedSecret(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+	created := createTextSecret(t, handler, accessToken, "revision 1")
+	moveToTrash(t, handler, accessToken, created.ID)
+
+	// The edit made offline is kept although the secret has been deleted in the meantime.
+	offline := encryptPayload(t, &secret.Text{Value: "edited offline"})
+	conflict := pushConflict(t, handler, accessToken, created.ID, offline)
+	assert.True(t, conflict.ServerDeleted)
+	assert.Equal(t, int64(0), conflict.ServerRevision)
+	assert.Equal(t, offline, conflict.Data)
+
+	discarded := pushConflict(t, handler, accessToken, created.ID, offline)
+	code := resolveConflict(t, handler, accessToken, discarded.ID,
+		resolve(service.ResolutionServer), "")
+	assert.Equal(t, http.StatusNoContent, code, "the deletion must be kept")
+
+	rec := doJSONWithHeaders(t, handler, http.MethodPost,
+		"/api/v1/conflicts/"+conflict.ID+"/resolve",
+		resolve(service.ResolutionClient), accessToken, ifMatch("*"))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var recreated server.SecretResponse
+
+	decodeBody(t, rec, &recreated)
+	assert.NotEqual(t, created.ID, recreated.ID, "the deleted secret must stay in the trash")
+	assert.Equal(t, offline, getSecret(t, handler, accessToken, recreated.ID).Data)
+	assert.Len(t, listTrash(t, handler, accessToken), 1)
+	assert.Empty(t, listConflicts(t, handler, accessToken))
+}
+
+func TestServer_PushRestoredSecret(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+	created := createTextSecret(t, handler, accessToken, "revision 1")
+	moveToTrash(t, handler, accessToken, created.ID)
+
+	offline := encryptPayload(t, &secret.Text{Value: "edited offline"})
+	conflict := pushConflict(t, handler, accessToken, created.ID, offline)
+
+	rec := restoreFromTrash(t, handler, accessToken, created.ID)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// The secret restored before the resolution is updated instead of being duplicated.
+	code := resolveConflict(t, handler, accessToken, conflict.ID,
+		resolve(service.ResolutionClient), rec.Header().Get("ETag"))
+	require.Equal(t, http.StatusNoContent, code)
+	assert.Equal(t, offline, getSecret(t, handler, accessToken, created.ID).Data)
+}
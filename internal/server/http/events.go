@@ -0,0 +1,132 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+)
+
+// Parameters of the event stream.
+const (
+	eventsKeepAliveInterval = 15 * time.Second // Interval of comments that keep idle connections open.
+	eventsRetryInterval     = 3 * time.Second  // Reconnection delay suggested to the client.
+)
+
+var errStreamingUnsupported = errors.New("response writer does not support streaming")
+
+// EventSubscriber describes the subscription to the change events of the user's vault.
+type EventSubscriber interface {
+	// Subscribe returns the stream of the events of the user until ctx is done.
+	Subscribe(ctx context.Context, userID string) (<-chan model.VaultEvent, error)
+}
+
+// VaultEventResponse describes the change event sent in the data field of the stream.
+type VaultEventResponse struct {
+	Type       string    `json:"type"`       // Event type, repeated in the event field.
+	SecretID   string    `json:"secretId"`   // Identifier of the changed secret.
+	Revision   int64     `json:"revision"`   // Revision written by the change.
+	OccurredAt time.Time `json:"occurredAt"` // Time of the change.
+}
+
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	flusher, canFlush := w.(http.Flusher)
+	if !canFlush {
+		s.writeInternalError(w, errStreamingUnsupported)
+
+		return
+	}
+
+	events, err := s.events.Subscribe(r.Context(), userID)
+	if err != nil {
+		s.writeInternalError(w, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = fmt.Fprintf(w, "retry: %d\n\n", eventsRetryInterval.Milliseconds())
+	if err != nil {
+		return
+	}
+
+	flusher.Flush()
+
+	s.relayEvents(r.Context(), w, flusher, events)
+}
+
+// relayEvents writes the events to the stream until the client disconnects or the server shuts down.
+//
+// The stream also ends if the client cannot keep up with the events:
+// it reconnects and catches up with the sync protocol.
+func (s *Server) relayEvents(
+	ctx context.Context,
+	stream io.Writer,
+	flusher http.Flusher,
+	events <-chan model.VaultEvent,
+) {
+	keepAlive := time.NewTicker(eventsKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		var err error
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.shuttingDown:
+			return
+		case event, open := <-events:
+			if !open {
+				return
+			}
+
+			err = writeEvent(stream, event)
+		case <-keepAlive.C:
+			_, err = io.WriteString(stream, ": keep-alive\n\n")
+		}
+
+		if err != nil {
+			s.logger.Debug("Event stream closed", "error", err.Error())
+
+			return
+		}
+
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes the event in the Server-Sent Events format.
+func writeEvent(stream io.Writer, event model.VaultEvent) error {
+	data, err := json.Marshal(VaultEventResponse{
+		Type:       event.Type,
+		SecretID:   event.SecretID,
+		Revision:   event.Revision,
+		OccurredAt: event.OccurredAt,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	_, err = fmt.Fprintf(stream, "event: %s\ndata: %s\n\n", event.Type, data)
+	if err != nil {
+		return fmt.Errorf("write event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package http_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	server "github.com/mr-filatik/go-password-keeper/internal/server/http"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readEvent reads the next event of the stream skipping the comments.
+func readEvent(t *testing.T, reader *bufio.Reader) (string, server.VaultEventResponse) {
+	t.Helper()
+
+	var (
+		eventType string
+		event     server.VaultEventResponse
+	)
+
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			eventType = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+		case line == "" && eventType != "":
+			return eventType, event
+		}
+	}
+}
+
+func TestServer_StreamEvents(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+	stranger := registerAndLogin(t, handler, "bob")
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		srv.URL+"/api/v1/events",
+		http.NoBody,
+	)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "retry: 3000\n", line)
+
+	createTextSecret(t, handler, stranger, "not for alice")
+	created := createTextSecret(t, handler, accessToken, "note")
+
+	eventType, event := readEvent(t, reader)
+	assert.Equal(t, model.VaultEventSecretCreated, eventType)
+	assert.Equal(t, created.ID, event.SecretID, "events of other users must not be streamed")
+	assert.Equal(t, int64(1), event.Revision)
+
+	updateSecretText(t, handler, accessToken, created.ID, created.Data)
+
+	eventType, event = readEvent(t, reader)
+	assert.Equal(t, model.VaultEventSecretUpdated, eventType)
+	assert.Equal(t, int64(2), event.Revision)
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/events", nil, "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,224 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+)
+
+// FolderService describes the functionality of organizing the user's secrets into folders.
+type FolderService interface {
+	// Create creates a new folder for the user.
+	Create(ctx context.Context, userID string, name []byte, parentID string) (*model.Folder, error)
+
+	// Rename replaces the name of the user's folder.
+	Rename(ctx context.Context, userID string, folderID string, name []byte) (*model.Folder, error)
+
+	// Move places the user's folder with its contents into another folder.
+	Move(
+		ctx context.Context,
+		userID string,
+		folderID string,
+		parentID string,
+	) (*model.Folder, error)
+
+	// Delete deletes the user's folder with its subfolders and moves their secrets to the trash.
+	Delete(ctx context.Context, userID string, folderID string) error
+
+	// Tree returns the folders of the user's vault with their secrets.
+	Tree(ctx context.Context, userID string) (*service.FolderTree, error)
+}
+
+// FolderRequest describes the body of the folder creation request.
+type FolderRequest struct {
+	Name     []byte `json:"name"`               // Name encrypted on the client (base64).
+	ParentID string `json:"parentId,omitempty"` // Parent folder, empty for the top level.
+}
+
+// RenameFolderRequest describes the body of the folder rename request.
+type RenameFolderRequest struct {
+	Name []byte `json:"name"` // New name encrypted on the client (base64).
+}
+
+// MoveFolderRequest describes the body of the folder move request.
+type MoveFolderRequest struct {
+	ParentID string `json:"parentId"` // New parent folder, empty for the top level.
+}
+
+// MoveSecretRequest describes the body of the secret move request.
+type MoveSecretRequest struct {
+	FolderID string `json:"folderId"` // New folder of the secret, empty for the top level.
+}
+
+// FolderResponse describes the folder returned to the client.
+type FolderResponse struct {
+	ID        string    `json:"id"`                 // Folder identifier.
+	ParentID  string    `json:"parentId,omitempty"` // Parent folder, omitted at the top level.
+	Name      []byte    `json:"name"`               // Name encrypted on the client (base64).
+	CreatedAt time.Time `json:"createdAt"`          // Creation time.
+	UpdatedAt time.Time `json:"updatedAt"`          // Time of the last rename or move.
+}
+
+// FolderTreeResponse describes a folder with its subfolders and secrets.
+type FolderTreeResponse struct {
+	Folder  *FolderResponse      `json:"folder,omitempty"` // Folder, omitted for the top level of the vault.
+	Folders []FolderTreeResponse `json:"folders"`          // Subfolders sorted by creation time.
+	Secrets []SecretResponse     `json:"secrets"`          // Secrets without payload sorted by creation time.
+}
+
+func newFolderResponse(folder *model.Folder) FolderResponse {
+	return FolderResponse{
+		ID:        folder.ID,
+		ParentID:  folder.ParentID,
+		Name:      folder.Name,
+		CreatedAt: folder.CreatedAt,
+		UpdatedAt: folder.UpdatedAt,
+	}
+}
+
+func newFolderTreeResponse(tree *service.FolderTree) FolderTreeResponse {
+	resp := FolderTreeResponse{
+		Folder:  nil,
+		Folders: make([]FolderTreeResponse, 0, len(tree.Folders)),
+		Secrets: make([]SecretResponse, 0, len(tree.Secrets)),
+	}
+
+	if tree.Folder != nil {
+		folder := newFolderResponse(tree.Folder)
+		resp.Folder = &folder
+	}
+
+	for _, child := range tree.Folders {
+		resp.Folders = append(resp.Folders, newFolderTreeResponse(child))
+	}
+
+	for _, item := range tree.Secrets {
+		resp.Secrets = append(resp.Secrets, newSecretResponse(item, false))
+	}
+
+	return resp
+}
+
+func (s *Server) writeFolderError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrFolderNotFound):
+		s.writeError(w, http.StatusNotFound, errCodeNotFound, "folder not found")
+	case errors.Is(err, service.ErrInvalidFolder):
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+	default:
+		s.writeSecretError(w, err)
+	}
+}
+
+func (s *Server) createFolder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	var req FolderRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	folder, err := s.folders.Create(r.Context(), userID, req.Name, req.ParentID)
+	if err != nil {
+		s.writeFolderError(w, err)
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, newFolderResponse(folder))
+}
+
+func (s *Server) getFolderTree(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	tree, err := s.folders.Tree(r.Context(), userID)
+	if err != nil {
+		s.writeFolderError(w, err)
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, newFolderTreeResponse(tree))
+}
+
+func (s *Server) renameFolder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	var req RenameFolderRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	folder, err := s.folders.Rename(r.Context(), userID, chi.URLParam(r, "id"), req.Name)
+	if err != nil {
+		s.writeFolderError(w, err)
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, newFolderResponse(folder))
+}
+
+func (s *Server) moveFolder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	var req MoveFolderRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	folder, err := s.folders.Move(r.Context(), userID, chi.URLParam(r, "id"), req.ParentID)
+	if err != nil {
+		s.writeFolderError(w, err)
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, newFolderResponse(folder))
+}
+
+func (s *Server) deleteFolder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.folders.Delete(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeFolderError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,217 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	server "github.com/mr-filatik/go-password-keeper/internal/server/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createFolder creates a folder of the user with the encrypted name inside the parent.
+func createFolder(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+	name string,
+	parentID string,
+) server.FolderResponse {
+	t.Helper()
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/folders", server.FolderRequest{
+		Name:     encryptPayload(t, &secret.Text{Value: name}),
+		ParentID: parentID,
+	}, accessToken)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created server.FolderResponse
+
+	decodeBody(t, rec, &created)
+
+	return created
+}
+
+// createSecretInFolder creates a text secret of the user inside the folder.
+func createSecretInFolder(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+	folderID string,
+) server.SecretResponse {
+	t.Helper()
+
+	req := textSecretRequest(encryptPayload(t, &secret.Text{Value: "note"}))
+	req.FolderID = folderID
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/secrets", req, accessToken)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created server.SecretResponse
+
+	decodeBody(t, rec, &created)
+
+	return created
+}
+
+// getFolderTree returns the folder tree of the user's vault.
+func getFolderTree(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+) server.FolderTreeResponse {
+	t.Helper()
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/folders/tree", nil, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var tree server.FolderTreeResponse
+
+	decodeBody(t, rec, &tree)
+
+	return tree
+}
+
+func TestServer_FolderTree(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	work := createFolder(t, handler, accessToken, "work", "")
+	projects := createFolder(t, handler, accessToken, "projects", work.ID)
+	nested := createSecretInFolder(t, handler, accessToken, projects.ID)
+	top := createTextSecret(t, handler, accessToken, "top")
+
+	assert.Equal(t, projects.ID, nested.FolderID)
+
+	tree := getFolderTree(t, handler, accessToken)
+	assert.Nil(t, tree.Folder)
+	require.Len(t, tree.Folders, 1)
+	require.Len(t, tree.Secrets, 1)
+	assert.Equal(t, top.ID, tree.Secrets[0].ID)
+	assert.Nil(t, tree.Secrets[0].Data, "tree must not carry payloads")
+
+	require.NotNil(t, tree.Folders[0].Folder)
+	assert.Equal(t, work.ID, tree.Folders[0].Folder.ID)
+	assert.Equal(t, work.Name, tree.Folders[0].Folder.Name)
+	require.Len(t, tree.Folders[0].Folders, 1)
+	assert.Empty(t, tree.Folders[0].Secrets)
+
+	child := tree.Folders[0].Folders[0]
+	assert.Equal(t, projects.ID, child.Folder.ID)
+	assert.Equal(t, work.ID, child.Folder.ParentID)
+	require.Len(t, child.Secrets, 1)
+	assert.Equal(t, nested.ID, child.Secrets[0].ID)
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/folders", server.FolderRequest{
+		Name:     []byte("plain name"),
+		ParentID: "",
+	}, accessToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "name must be encrypted")
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/folders", server.FolderRequest{
+		Name:     encryptPayload(t, &secret.Text{Value: "lost"}),
+		ParentID: "missing",
+	}, accessToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_FolderRenameAndMove(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	work := createFolder(t, handler, accessToken, "work", "")
+	projects := createFolder(t, handler, accessToken, "projects", work.ID)
+	note := createSecretInFolder(t, handler, accessToken, projects.ID)
+
+	name := encryptPayload(t, &secret.Text{Value: "job"})
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/folders/"+work.ID+"/rename",
+		server.RenameFolderRequest{Name: name}, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/folders/"+work.ID+"/move",
+		server.MoveFolderRequest{ParentID: projects.ID}, accessToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "folder must not be moved into its subfolder")
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/folders/"+projects.ID+"/move",
+		server.MoveFolderRequest{ParentID: ""}, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doJSONWithHeaders(t, handler, http.MethodPost, "/api/v1/secrets/"+note.ID+"/move",
+		server.MoveSecretRequest{FolderID: work.ID}, accessToken, ifMatch(`"1"`))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var moved server.SecretResponse
+
+	decodeBody(t, rec, &moved)
+	assert.Equal(t, work.ID, moved.FolderID)
+	assert.Equal(t, int64(2), moved.Revision, "move must reach the other devices")
+
+	tree := getFolderTree(t, handler, accessToken)
+	require.Len(t, tree.Folders, 2)
+	assert.Equal(t, name, tree.Folders[0].Folder.Name)
+	require.Len(t, tree.Folders[0].Secrets, 1)
+	assert.Equal(t, note.ID, tree.Folders[0].Secrets[0].ID)
+	assert.Equal(t, projects.ID, tree.Folders[1].Folder.ID)
+	assert.Empty(t, tree.Folders[1].Secrets)
+}
+
+func TestServer_DeleteFolderWithContents(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	work := createFolder(t, handler, accessToken, "work", "")
+	projects := createFolder(t, handler, accessToken, "projects", work.ID)
+	createSecretInFolder(t, handler, accessToken, work.ID)
+	createSecretInFolder(t, handler, accessToken, projects.ID)
+	kept := createTextSecret(t, handler, accessToken, "kept")
+
+	rec := doJSON(t, handler, http.MethodDelete, "/api/v1/folders/"+work.ID, nil, accessToken)
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	tree := getFolderTree(t, handler, accessToken)
+	assert.Empty(t, tree.Folders)
+	require.Len(t, tree.Secrets, 1)
+	assert.Equal(t, kept.ID, tree.Secrets[0].ID)
+
+	assert.Len(t, listTrash(t, handler, accessToken), 2, "contents must stay recoverable")
+
+	rec = doJSON(t, handler, http.MethodDelete, "/api/v1/folders/"+projects.ID, nil, accessToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "subfolders must be deleted too")
+}
+
+func TestServer_FoldersArePerUser(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	aliceToken := registerAndLogin(t, handler, "alice")
+	bobToken := registerAndLogin(t, handler, "bob")
+
+	work := createFolder(t, handler, aliceToken, "work", "")
+	note := createTextSecret(t, handler, bobToken, "note")
+
+	assert.Empty(t, getFolderTree(t, handler, bobToken).Folders)
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/folders", server.FolderRequest{
+		Name:     encryptPayload(t, &secret.Text{Value: "intruder"}),
+		ParentID: work.ID,
+	}, bobToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/folders/"+work.ID+"/rename",
+		server.RenameFolderRequest{Name: encryptPayload(t, &secret.Text{Value: "x"})}, bobToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doJSONWithHeaders(t, handler, http.MethodPost, "/api/v1/secrets/"+note.ID+"/move",
+		server.MoveSecretRequest{FolderID: work.ID}, bobToken, ifMatch("*"))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doJSON(t, handler, http.MethodDelete, "/api/v1/folders/"+work.ID, nil, bobToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,41 @@
+// Package middleware provides functionality for HTTP middleware.
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// AdminToken creates a middleware that authenticates the requests of the administrator
+// by the bearer token configured for the instance.
+//
+// The tokens are compared in constant time. Requests without the token are rejected with the code 401.
+//
+// Parameters:
+//   - token string: token of the administrator, not empty.
+func AdminToken(token string) Middleware {
+	want := sha256.Sum256([]byte(token))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get(HeaderAuthorization)
+			if len(header) <= len(bearerPrefix) ||
+				!strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+				writeUnauthorized(w, "missing bearer token")
+
+				return
+			}
+
+			got := sha256.Sum256([]byte(strings.TrimSpace(header[len(bearerPrefix):])))
+			if subtle.ConstantTimeCompare(got[:], want[:]) != 1 {
+				writeUnauthorized(w, "invalid admin token")
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Package middleware provides functionality for HTTP middleware.
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/token"
+)
+
+// HeaderAuthorization is the name of the HTTP header "Authorization".
+const HeaderAuthorization = "Authorization"
+
+const bearerPrefix = "Bearer "
+
+// TokenVerifier describes the functionality of verifying access tokens.
+type TokenVerifier interface {
+	// Verify checks the token and returns its claims.
+	Verify(tokenString string) (*token.Claims, error)
+}
+
+// SessionChecker describes the functionality of checking user sessions.
+type SessionChecker interface {
+	// IsActive checks whether the user's session has not been revoked or expired.
+	IsActive(ctx context.Context, userID string, sessionID string) (bool, error)
+}
+
+// Auth creates a middleware that authenticates requests by the bearer access token.
+//
+// The token is accepted only while its session is active, so revoking a session
+// cuts off its access tokens immediately.
+// The identifiers of the authenticated user and session are stored in the context
+// by the CtxKeyUserID and CtxKeySessionID keys.
+// Requests without a valid token are rejected with the code 401.
+//
+// Parameters:
+//   - verifier TokenVerifier: access token verifier;
+//   - sessions SessionChecker: session checker;
+//   - logger logging.Logger: logger.
+func Auth(verifier TokenVerifier, sessions SessionChecker, logger logging.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get(HeaderAuthorization)
+			if len(header) <= len(bearerPrefix) ||
+				!strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+				writeUnauthorized(w, "missing bearer token")
+
+				return
+			}
+
+			claims, err := verifier.Verify(strings.TrimSpace(header[len(bearerPrefix):]))
+			if err != nil {
+				logger.Debug("Access token rejected",
+					"request_id", r.Header.Get(HeaderRequestID),
+					"error", err.Error(),
+				)
+
+				writeUnauthorized(w, "invalid access token")
+
+				return
+			}
+
+			active, err := sessions.IsActive(r.Context(), claims.UserID(), claims.SessionID())
+			if err != nil {
+				logger.Error("Checking session error", err,
+					"request_id", r.Header.Get(HeaderRequestID),
+				)
+
+				writeJSONError(w, http.StatusInternalServerError,
+					`{"code":"internal_error","message":"internal server error"}`)
+
+				return
+			}
+
+			if !active {
+				writeUnauthorized(w, "session has been revoked or expired")
+
+				return
+			}
+
+			ctx := withUserID(r.Context(), claims.UserID())
+			ctx = withSessionID(ctx, claims.SessionID())
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// writeUnauthorized writes a response with the code 401 in the same format as the server handlers.
+func writeUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	writeJSONError(w, http.StatusUnauthorized, `{"code":"unauthorized","message":"`+message+`"}`)
+}
+
+// writeJSONError writes an error response with the prepared JSON body.
+func writeJSONError(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write([]byte(body + "\n"))
+}
@@ -0,0 +1,191 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/mocks"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/token"
+	"github.com/mr-filatik/go-password-keeper/internal/server/http/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTokenManager(t *testing.T) *token.Manager {
+	t.Helper()
+
+	manager, err := token.NewHMACManager(
+		[]byte("0123456789abcdef0123456789abcdef"),
+		"test",
+		time.Minute,
+	)
+	require.NoError(t, err)
+
+	return manager
+}
+
+// sessionCheckerStub considers active only the sessions from the set.
+type sessionCheckerStub map[string]bool
+
+var errSessionStorage = errors.New("session storage is unavailable")
+
+func (s sessionCheckerStub) IsActive(_ context.Context, _ string, sessionID string) (bool, error) {
+	if sessionID == "broken" {
+		return false, errSessionStorage
+	}
+
+	return s[sessionID], nil
+}
+
+type authTestCase struct {
+	name       string
+	header     string
+	wantStatus int
+	wantUserID string
+}
+
+func getTestsAuth(validToken, revokedToken, brokenToken string) []authTestCase {
+	return []authTestCase{
+		{
+			name:       "valid token",
+			header:     "Bearer " + validToken,
+			wantStatus: http.StatusOK,
+			wantUserID: "user-1",
+		},
+		{
+			name:       "revoked session",
+			header:     "Bearer " + revokedToken,
+			wantStatus: http.StatusUnauthorized,
+			wantUserID: "",
+		},
+		{
+			name:       "session check error",
+			header:     "Bearer " + brokenToken,
+			wantStatus: http.StatusInternalServerError,
+			wantUserID: "",
+		},
+		{
+			name:       "lowercase scheme",
+			header:     "bearer " + validToken,
+			wantStatus: http.StatusOK,
+			wantUserID: "user-1",
+		},
+		{
+			name:       "missing header",
+			header:     "",
+			wantStatus: http.StatusUnauthorized,
+			wantUserID: "",
+		},
+		{
+			name:       "other scheme",
+			header:     "Basic dXNlcjpwYXNz",
+			wantStatus: http.StatusUnauthorized,
+			wantUserID: "",
+		},
+		{
+			name:       "invalid token",
+			header:     "Bearer invalid",
+			wantStatus: http.StatusUnauthorized,
+			wantUserID: "",
+		},
+	}
+}
+
+func TestAuth(t *testing.T) {
+	t.Parallel()
+
+	manager := newTokenManager(t)
+
+	validToken, _, err := manager.Issue("user-1", "session-1")
+	require.NoError(t, err)
+
+	revokedToken, _, err := manager.Issue("user-1", "session-2")
+	require.NoError(t, err)
+
+	brokenToken, _, err := manager.Issue("user-1", "broken")
+	require.NoError(t, err)
+
+	sessions := sessionCheckerStub{"session-1": true}
+
+	tests := getTestsAuth(validToken, revokedToken, brokenToken)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger, err := logging.NewZapSugarLogger(
+				logging.LevelError,
+				mocks.NewMockWriter(),
+				logging.FormatJSON,
+			)
+			require.NoError(t, err)
+
+			var gotUserID, gotSessionID string
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUserID = middleware.UserIDFromContext(r.Context())
+				gotSessionID = middleware.SessionIDFromContext(r.Context())
+
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			if tt.header != "" {
+				req.Header.Set(middleware.HeaderAuthorization, tt.header)
+			}
+
+			rec := httptest.NewRecorder()
+			middleware.Auth(manager, sessions, logger)(next).ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantUserID, gotUserID)
+
+			if tt.wantUserID != "" {
+				assert.Equal(t, "session-1", gotSessionID)
+			}
+
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestLogging_ContainsUserID(t *testing.T) {
+	t.Parallel()
+
+	manager := newTokenManager(t)
+
+	accessToken, _, err := manager.Issue("user-42", "session-1")
+	require.NoError(t, err)
+
+	mockWriter := mocks.NewMockWriter()
+
+	logger, err := logging.NewZapSugarLogger(logging.LevelInfo, mockWriter, logging.FormatJSON)
+	require.NoError(t, err)
+
+	mockWriter.MarkDataAsRead()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	handler := middleware.Logging(logger, middleware.LoggingOpts{
+		EnableRequestBodyLogging:  false,
+		EnableResponseBodyLogging: false,
+		RouteFn:                   nil,
+	})(middleware.Auth(manager, sessionCheckerStub{"session-1": true}, logger)(next))
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set(middleware.HeaderAuthorization, "Bearer "+accessToken)
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	accessLog, ok := mockWriter.GetUnreadedData()
+	require.True(t, ok, "access log was not written")
+	assert.Contains(t, string(accessLog), `"user_id":"user-42"`)
+}
@@ -29,6 +29,7 @@ func Logging(logger logging.Logger, options LoggingOpts) Middleware {
 		duration time.Duration,
 		reqObs *observer.RequestObserver,
 		respObs *observer.ResponseObserver,
+		userID string,
 	) {
 		fields := []any{
 			"duration_ms", duration.Milliseconds(),
@@ -44,6 +45,7 @@ func Logging(logger logging.Logger, options LoggingOpts) Middleware {
 			"request_id", reqObs.GetHeader(HeaderRequestID),
 			"span_id", reqObs.GetHeader("Span-ID"),
 			"trace_id", reqObs.GetHeader("Trace-ID"),
+			"user_id", userID,
 		}
 
 		if options.EnableRequestBodyLogging {
@@ -67,6 +69,9 @@ func Logging(logger logging.Logger, options LoggingOpts) Middleware {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 
+			ctx, holder := withUserHolder(r.Context())
+			r = r.WithContext(ctx)
+
 			reqObs := observer.NewRequestObserver(r,
 				options.EnableRequestBodyLogging, options.RouteFn)
 			respObs := observer.NewResponseObserver(w, options.EnableResponseBodyLogging)
@@ -78,6 +83,7 @@ func Logging(logger logging.Logger, options LoggingOpts) Middleware {
 						time.Since(start),
 						reqObs,
 						respObs,
+						holder.userID,
 					)
 
 					panic(rec)
@@ -93,6 +99,7 @@ func Logging(logger logging.Logger, options LoggingOpts) Middleware {
 					time.Since(start),
 					reqObs,
 					respObs,
+					holder.userID,
 				)
 			}()
 
@@ -0,0 +1,39 @@
+// Package middleware provides functionality for HTTP middleware.
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+)
+
+// Streaming lifts the read and write timeouts of the server for long-lived requests
+// and responses such as Server-Sent Events and chunks of resumable uploads.
+//
+// The timeouts of the server protect ordinary requests, but they would cut a stream off,
+// so they are removed for the connection of the streaming request only.
+//
+// Parameters:
+//   - logger logging.Logger: logger.
+func Streaming(logger logging.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			controller := http.NewResponseController(w)
+
+			err := controller.SetWriteDeadline(time.Time{})
+			if err != nil {
+				logger.Warn("Lifting write timeout error", err,
+					"request_id", r.Header.Get(HeaderRequestID))
+			}
+
+			err = controller.SetReadDeadline(time.Time{})
+			if err != nil {
+				logger.Warn("Lifting read timeout error", err,
+					"request_id", r.Header.Get(HeaderRequestID))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
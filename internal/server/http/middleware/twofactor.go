@@ -0,0 +1,73 @@
+// Package middleware provides functionality for HTTP middleware.
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+)
+
+// HeaderTwoFactorCode is the name of the HTTP header with the one-time code of the second factor.
+const HeaderTwoFactorCode = "X-Totp-Code"
+
+// ErrTwoFactorLocked - too many one-time codes of the user have been rejected, the check is locked for a while.
+var ErrTwoFactorLocked = errors.New("two-factor authentication locked")
+
+// TwoFactorVerifier describes the functionality of checking one-time codes of sensitive operations.
+type TwoFactorVerifier interface {
+	// CheckCode returns true if the user has not enabled two-factor authentication or the code is valid.
+	//
+	// The request identifies the client the rejected codes are recorded for.
+	// Returns ErrTwoFactorLocked if too many codes have been rejected.
+	CheckCode(r *http.Request, userID string, code string) (bool, error)
+}
+
+// TwoFactor creates a middleware that protects sensitive operations with the second factor.
+//
+// Must be used after the Auth middleware. If the user has enabled two-factor authentication,
+// the request must carry a valid one-time code in the HeaderTwoFactorCode header,
+// otherwise it is rejected with the code 403, or 429 while the check is locked after too many rejected codes.
+//
+// Parameters:
+//   - verifier TwoFactorVerifier: one-time code verifier;
+//   - logger logging.Logger: logger.
+func TwoFactor(verifier TwoFactorVerifier, logger logging.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			code := strings.TrimSpace(r.Header.Get(HeaderTwoFactorCode))
+
+			valid, err := verifier.CheckCode(r, UserIDFromContext(r.Context()), code)
+			if errors.Is(err, ErrTwoFactorLocked) {
+				writeJSONError(
+					w,
+					http.StatusTooManyRequests,
+					`{"code":"two_factor_locked","message":"too many invalid one-time codes, try again later"}`,
+				)
+
+				return
+			}
+
+			if err != nil {
+				logger.Error("Checking two-factor code error", err,
+					"request_id", r.Header.Get(HeaderRequestID),
+				)
+
+				writeJSONError(w, http.StatusInternalServerError,
+					`{"code":"internal_error","message":"internal server error"}`)
+
+				return
+			}
+
+			if !valid {
+				writeJSONError(w, http.StatusForbidden,
+					`{"code":"two_factor_required","message":"valid one-time code is required"}`)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Package middleware provides functionality for HTTP middleware.
+package middleware
+
+import (
+	"context"
+
+	platformctx "github.com/mr-filatik/go-password-keeper/internal/platform/context"
+)
+
+// CtxKeyUserID - key for the identifier of the authenticated user.
+//
+//nolint:gochecknoglobals // context keys are compared by pointer
+var CtxKeyUserID = &platformctx.CtxKey{Name: "user-id"}
+
+// CtxKeySessionID - key for the identifier of the authenticated session.
+//
+//nolint:gochecknoglobals // context keys are compared by pointer
+var CtxKeySessionID = &platformctx.CtxKey{Name: "session-id"}
+
+// ctxKeyUserHolder - key for the holder of the user identifier.
+//
+// Outer middlewares (for example, logging) place the holder in the context
+// in order to find out the identifier set by the inner authentication middleware.
+//
+//nolint:gochecknoglobals // context keys are compared by pointer
+var ctxKeyUserHolder = &platformctx.CtxKey{Name: "user-holder"}
+
+// userHolder stores the identifier of the authenticated user for outer middlewares.
+type userHolder struct {
+	userID string
+}
+
+// UserIDFromContext returns the identifier of the authenticated user.
+//
+// Returns an empty string if the request is not authenticated.
+//
+// Parameters:
+//   - ctx context.Context: request context.
+func UserIDFromContext(ctx context.Context) string {
+	return platformctx.GetValue(ctx, CtxKeyUserID)
+}
+
+// SessionIDFromContext returns the identifier of the authenticated session.
+//
+// Returns an empty string if the request is not authenticated.
+//
+// Parameters:
+//   - ctx context.Context: request context.
+func SessionIDFromContext(ctx context.Context) string {
+	return platformctx.GetValue(ctx, CtxKeySessionID)
+}
+
+// withUserHolder places an empty holder of the user identifier in the context.
+func withUserHolder(ctx context.Context) (context.Context, *userHolder) {
+	holder := &userHolder{userID: ""}
+
+	return context.WithValue(ctx, ctxKeyUserHolder, holder), holder
+}
+
+// withUserID sets the identifier of the authenticated user in the context
+// and passes it to the holder, if there is one.
+func withUserID(ctx context.Context, userID string) context.Context {
+	if holder, ok := ctx.Value(ctxKeyUserHolder).(*userHolder); ok {
+		holder.userID = userID
+	}
+
+	return platformctx.WithValue(ctx, CtxKeyUserID, userID)
+}
+
+// withSessionID sets the identifier of the authenticated session in the context.
+func withSessionID(ctx context.Context, sessionID string) context.Context {
+	return platformctx.WithValue(ctx, CtxKeySessionID, sessionID)
+}
@@ -0,0 +1,74 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+)
+
+// Headers of the optimistic concurrency control of secrets.
+const (
+	headerETag    = "ETag"
+	headerIfMatch = "If-Match"
+)
+
+// secretETag returns the entity tag of the secret derived from its revision.
+func secretETag(item *model.Secret) string {
+	return strconv.Quote(strconv.FormatInt(item.Revision, 10))
+}
+
+// parseIfMatch converts the If-Match header to the revisions the change is allowed on.
+//
+// The wildcard allows any revision. Weak and malformed tags never match,
+// so a header consisting only of them allows no revision at all.
+func parseIfMatch(header string) service.RevisionMatch {
+	if strings.TrimSpace(header) == "*" {
+		return nil
+	}
+
+	match := service.RevisionMatch{}
+
+	for tag := range strings.SplitSeq(header, ",") {
+		value, err := strconv.Unquote(strings.TrimSpace(tag))
+		if err != nil {
+			continue
+		}
+
+		revision, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || revision <= 0 {
+			continue
+		}
+
+		match = append(match, revision)
+	}
+
+	return match
+}
+
+// revisionMatch returns the revisions the change of the secret is allowed on.
+//
+// If the request has no If-Match header, it writes a response with the code 428.
+func (s *Server) revisionMatch(
+	w http.ResponseWriter,
+	r *http.Request,
+) (service.RevisionMatch, bool) {
+	header := r.Header.Get(headerIfMatch)
+	if header == "" {
+		s.writeError(w, http.StatusPreconditionRequired, errCodePreconditionRequired,
+			"If-Match header is required")
+
+		return nil, false
+	}
+
+	return parseIfMatch(header), true
+}
+
+// writeSecret writes the secret with its entity tag.
+func (s *Server) writeSecret(w http.ResponseWriter, status int, item *model.Secret) {
+	w.Header().Set(headerETag, secretETag(item))
+	s.writeJSON(w, status, newSecretResponse(item, true))
+}
@@ -0,0 +1,187 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+)
+
+// Query parameters shared by the list endpoints.
+const (
+	queryCursor = "cursor"
+	queryLimit  = "limit"
+	querySort   = "sort"
+	queryOrder  = "order"
+)
+
+// Values of the order parameter.
+const (
+	orderAsc  = "asc"
+	orderDesc = "desc"
+)
+
+// Format of the cursor of the listings ordered by time: time of the item and its identifier.
+const (
+	pageCursorSeparator = ":"
+	pageCursorParts     = 2
+)
+
+// errInvalidQuery - a query parameter of the list endpoint has an invalid value.
+var errInvalidQuery = errors.New("invalid query parameter")
+
+// listQuery describes the query parameters of a list endpoint.
+//
+// All list endpoints page with an opaque cursor and a limit, and the sorted ones
+// take the field in sort and the direction in order. The filters are specific
+// to the endpoint and are read by name.
+type listQuery struct {
+	values     url.Values // All query parameters.
+	cursor     string     // Opaque cursor of the page, empty for the first page.
+	limit      int        // Maximum number of items, zero for the default of the endpoint.
+	sort       string     // Field the items are sorted by, empty for the default of the endpoint.
+	descending bool       // The items are sorted in descending order.
+}
+
+// parseListQuery parses the cursor, limit, sort and order parameters of the list request.
+//
+// The sort must be one of the sorts supported by the endpoint, an endpoint without sorts
+// accepts neither sort nor order.
+//
+// Parameters:
+//   - r *http.Request: list request;
+//   - sorts ...string: fields the endpoint can sort by.
+func parseListQuery(r *http.Request, sorts ...string) (*listQuery, error) {
+	values := r.URL.Query()
+
+	query := &listQuery{
+		values:     values,
+		cursor:     values.Get(queryCursor),
+		limit:      0,
+		sort:       values.Get(querySort),
+		descending: false,
+	}
+
+	if value := values.Get(queryLimit); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("%w: %s must be a positive integer", errInvalidQuery, queryLimit)
+		}
+
+		query.limit = limit
+	}
+
+	if query.sort != "" && !slices.Contains(sorts, query.sort) {
+		return nil, fmt.Errorf("%w: %s must be one of %v", errInvalidQuery, querySort, sorts)
+	}
+
+	if len(sorts) == 0 && values.Has(queryOrder) {
+		return nil, fmt.Errorf("%w: the list is not sortable", errInvalidQuery)
+	}
+
+	descending, err := parseOrder(values.Get(queryOrder))
+	if err != nil {
+		return nil, err
+	}
+
+	query.descending = descending
+
+	return query, nil
+}
+
+// parseOrder checks the value of the order parameter and reports whether it is descending.
+func parseOrder(value string) (bool, error) {
+	switch value {
+	case "", orderAsc:
+		return false, nil
+	case orderDesc:
+		return true, nil
+	default:
+		return false, fmt.Errorf("%w: %s must be %s or %s",
+			errInvalidQuery, queryOrder, orderAsc, orderDesc)
+	}
+}
+
+// filter returns the value of the filter parameter, empty if it is omitted.
+func (q *listQuery) filter(name string) string {
+	return q.values.Get(name)
+}
+
+// timeFilter returns the RFC 3339 time of the filter parameter, zero if it is omitted.
+func (q *listQuery) timeFilter(name string) (time.Time, error) {
+	value := q.values.Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be an RFC 3339 time", errInvalidQuery, name)
+	}
+
+	return parsed, nil
+}
+
+// parsePageQuery converts the query parameters of the listing ordered by time to the query of the page.
+//
+// Such listings have a fixed order, so they accept neither sort nor order.
+func parsePageQuery(r *http.Request) (*model.PageQuery, error) {
+	params, err := parseListQuery(r)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &model.PageQuery{After: nil, Limit: params.limit}
+
+	if params.cursor != "" {
+		query.After, err = decodePageCursor(params.cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return query, nil
+}
+
+// encodePageCursor converts the position after the last item of the page to the opaque cursor.
+//
+// Returns an empty cursor on the last page.
+func encodePageCursor(next *model.PageCursor) string {
+	if next == nil {
+		return ""
+	}
+
+	raw := strconv.FormatInt(next.Time.UnixNano(), 10) + pageCursorSeparator + next.ID
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodePageCursor converts the opaque cursor to the position in the listing ordered by time.
+func decodePageCursor(cursor string) (*model.PageCursor, error) {
+	malformed := fmt.Errorf("%w: %s is malformed", errInvalidQuery, queryCursor)
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, malformed
+	}
+
+	parts := strings.SplitN(string(raw), pageCursorSeparator, pageCursorParts)
+	if len(parts) != pageCursorParts || parts[1] == "" {
+		return nil, malformed
+	}
+
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, malformed
+	}
+
+	return &model.PageCursor{Time: time.Unix(0, nanos).UTC(), ID: parts[1]}, nil
+}
@@ -0,0 +1,47 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_ListQueryValidation(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	// A cursor issued for the list sorted by update time in ascending order.
+	updatedCursor := "dXBkYXRlZEF0OmFzYzowOmlk"
+
+	tests := []struct {
+		name   string
+		target string
+	}{
+		{name: "zero limit", target: "/api/v1/secrets?limit=0"},
+		{name: "non-numeric limit", target: "/api/v1/secrets?limit=many"},
+		{name: "unknown sort", target: "/api/v1/secrets?sort=name"},
+		{name: "unknown order", target: "/api/v1/secrets?order=up"},
+		{name: "unknown type", target: "/api/v1/secrets?type=unknown"},
+		{name: "malformed time", target: "/api/v1/secrets?updatedSince=yesterday"},
+		{name: "malformed cursor", target: "/api/v1/secrets?cursor=garbage"},
+		{name: "cursor of another sort", target: "/api/v1/secrets?cursor=" + updatedCursor},
+		{name: "negative sync limit", target: "/api/v1/sync?limit=-1"},
+		{name: "sorted sync", target: "/api/v1/sync?order=desc"},
+		{name: "sorted trash", target: "/api/v1/trash?sort=deletedAt"},
+		{name: "malformed trash cursor", target: "/api/v1/trash?cursor=garbage"},
+		{name: "ordered conflicts", target: "/api/v1/conflicts?order=desc"},
+		{name: "zero audit log limit", target: "/api/v1/account/audit-log?limit=0"},
+		{name: "malformed sessions cursor", target: "/api/v1/sessions?cursor=garbage"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := doJSON(t, handler, http.MethodGet, tt.target, nil, accessToken)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+)
+
+// RecoveryService describes the functionality of the account recovery with recovery codes.
+type RecoveryService interface {
+	// RecoveryKey returns the copy of the vault key wrapped with the recovery code.
+	RecoveryKey(ctx context.Context, login string, codeID string) (*crypto.WrappedVaultKey, error)
+
+	// Recover sets a new master password with the recovery code.
+	Recover(
+		ctx context.Context,
+		input service.RecoverInput,
+		client model.ClientInfo,
+	) (*service.RecoverResult, error)
+
+	// Regenerate replaces the set of recovery codes of the user.
+	Regenerate(
+		ctx context.Context,
+		userID string,
+		password string,
+		keys []crypto.RecoveryKey,
+		client model.ClientInfo,
+	) error
+
+	// Status returns the state of the user's set of recovery codes.
+	Status(ctx context.Context, userID string) (*service.RecoveryCodesStatus, error)
+}
+
+// RecoveryPreloginRequest describes the body of the request for the vault key copy of the recovery code.
+type RecoveryPreloginRequest struct {
+	Login  string `json:"login"`  // User login.
+	CodeID string `json:"codeId"` // Identifier derived from the recovery code.
+}
+
+// RecoveryPreloginResponse describes the vault key copy of the recovery code.
+type RecoveryPreloginResponse struct {
+	VaultKey crypto.WrappedVaultKey `json:"vaultKey"` // Vault key wrapped with the key derived from the code.
+}
+
+// RecoverRequest describes the body of the account recovery request.
+type RecoverRequest struct {
+	Login        string                 `json:"login"`        // User login.
+	CodeID       string                 `json:"codeId"`       // Identifier derived from the recovery code.
+	CodePassword string                 `json:"codePassword"` // Authentication password derived from the code.
+	NewPassword  string                 `json:"newPassword"`  // New authentication password.
+	VaultKey     crypto.WrappedVaultKey `json:"vaultKey"`     // Vault key wrapped with the new master password.
+}
+
+// RecoverResponse describes the result of the account recovery.
+type RecoverResponse struct {
+	RevokedSessions int `json:"revokedSessions"` // Number of revoked sessions.
+	RemainingCodes  int `json:"remainingCodes"`  // Number of unused recovery codes left.
+}
+
+// RegenerateRecoveryCodesRequest describes the body of the request for a new set of recovery codes.
+type RegenerateRecoveryCodesRequest struct {
+	Password     string               `json:"password"`     // Current authentication password.
+	RecoveryKeys []crypto.RecoveryKey `json:"recoveryKeys"` // Vault key copies wrapped with the new codes.
+}
+
+// RecoveryCodesResponse describes the state of the user's set of recovery codes.
+type RecoveryCodesResponse struct {
+	Total     int       `json:"total"`     // Number of codes in the set.
+	Remaining int       `json:"remaining"` // Number of unused codes.
+	CreatedAt time.Time `json:"createdAt"` // Generation time of the set.
+}
+
+func (s *Server) recoveryPrelogin(w http.ResponseWriter, r *http.Request) {
+	var req RecoveryPreloginRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	vaultKey, err := s.recovery.RecoveryKey(r.Context(), req.Login, req.CodeID)
+	if err != nil {
+		s.writeRecoveryError(w, err)
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, RecoveryPreloginResponse{VaultKey: *vaultKey})
+}
+
+func (s *Server) recover(w http.ResponseWriter, r *http.Request) {
+	var req RecoverRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	result, err := s.recovery.Recover(r.Context(), service.RecoverInput{
+		Login:        req.Login,
+		CodeID:       req.CodeID,
+		CodePassword: req.CodePassword,
+		NewPassword:  req.NewPassword,
+		VaultKey:     req.VaultKey,
+	}, clientInfo(r))
+	if err != nil {
+		s.writeRecoveryError(w, err)
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, RecoverResponse{
+		RevokedSessions: result.RevokedSessions,
+		RemainingCodes:  result.RemainingCodes,
+	})
+}
+
+func (s *Server) getRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	s.writeRecoveryCodes(w, r, userID)
+}
+
+func (s *Server) regenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	var req RegenerateRecoveryCodesRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	err = s.recovery.Regenerate(r.Context(), userID, req.Password, req.RecoveryKeys, clientInfo(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRecoveryCodes):
+			s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+		case errors.Is(err, service.ErrInvalidCredentials):
+			s.writeError(
+				w,
+				http.StatusForbidden,
+				errCodeInvalidCredentials,
+				"current password is incorrect",
+			)
+		default:
+			s.writeInternalError(w, err)
+		}
+
+		return
+	}
+
+	s.writeRecoveryCodes(w, r, userID)
+}
+
+// writeRecoveryCodes writes the state of the user's set of recovery codes.
+func (s *Server) writeRecoveryCodes(w http.ResponseWriter, r *http.Request, userID string) {
+	status, err := s.recovery.Status(r.Context(), userID)
+	if err != nil {
+		s.writeInternalError(w, err)
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, RecoveryCodesResponse{
+		Total:     status.Total,
+		Remaining: status.Remaining,
+		CreatedAt: status.CreatedAt,
+	})
+}
+
+func (s *Server) writeRecoveryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPassword), errors.Is(err, service.ErrInvalidVaultKey):
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidCredentials):
+		s.writeError(w, http.StatusUnauthorized, errCodeInvalidCredentials,
+			"invalid login or recovery code")
+	case errors.Is(err, service.ErrRecoveryCodeUsed):
+		s.writeError(w, http.StatusUnauthorized, errCodeRecoveryCodeUsed,
+			"recovery code has already been used")
+	default:
+		s.writeInternalError(w, err)
+	}
+}
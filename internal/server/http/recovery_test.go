@@ -0,0 +1,289 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	server "github.com/mr-filatik/go-password-keeper/internal/server/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRecoveryCode generates a random recovery code.
+func newRecoveryCode(t *testing.T) string {
+	t.Helper()
+
+	code, err := crypto.GenerateRecoveryCode()
+	require.NoError(t, err)
+
+	return code
+}
+
+// newRecoveryKey creates the vault key copy of the recovery code.
+//
+// Like the vault key in newRegisterRequest, the copy is wrapped with a random key,
+// and the authentication password is derived from the code without the slow KDF.
+func newRecoveryKey(t *testing.T, code string) crypto.RecoveryKey {
+	t.Helper()
+
+	codeID, err := crypto.RecoveryCodeID(code)
+	require.NoError(t, err)
+
+	return crypto.RecoveryKey{
+		ID:           codeID,
+		AuthPassword: recoveryCodePassword(code),
+		VaultKey:     newWrappedVaultKey(t),
+	}
+}
+
+// recoveryCodePassword returns the authentication password of the recovery code.
+func recoveryCodePassword(code string) string {
+	return "recovery:" + code
+}
+
+// registerWithRecoveryCodes registers the user "alice" with the recovery codes
+// and returns the vault key copies of the codes.
+func registerWithRecoveryCodes(
+	t *testing.T,
+	handler http.Handler,
+	codes ...string,
+) []crypto.RecoveryKey {
+	t.Helper()
+
+	req := newRegisterRequest(t, "alice", "correct-horse")
+	req.RecoveryKeys = make([]crypto.RecoveryKey, 0, len(codes))
+
+	for _, code := range codes {
+		req.RecoveryKeys = append(req.RecoveryKeys, newRecoveryKey(t, code))
+	}
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/register", req, "")
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	return req.RecoveryKeys
+}
+
+// newRecoverRequest creates a request that sets the password "battery-staple" with the recovery code.
+func newRecoverRequest(t *testing.T, code string) server.RecoverRequest {
+	t.Helper()
+
+	codeID, err := crypto.RecoveryCodeID(code)
+	require.NoError(t, err)
+
+	return server.RecoverRequest{
+		Login:        "alice",
+		CodeID:       codeID,
+		CodePassword: recoveryCodePassword(code),
+		NewPassword:  "battery-staple",
+		VaultKey:     newWrappedVaultKey(t),
+	}
+}
+
+// requireErrorCode checks the status and the error code of the response.
+func requireErrorCode(
+	t *testing.T,
+	rec *httptest.ResponseRecorder,
+	wantStatus int,
+	wantCode string,
+) {
+	t.Helper()
+
+	require.Equal(t, wantStatus, rec.Code, rec.Body.String())
+
+	var resp server.ErrorResponse
+
+	decodeBody(t, rec, &resp)
+	require.Equal(t, wantCode, resp.Code)
+}
+
+// listAuditEvents returns the audit log of the user.
+func listAuditEvents(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+) []server.AuditEventResponse {
+	t.Helper()
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/account/audit-log", nil, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp server.AuditLogResponse
+
+	decodeBody(t, rec, &resp)
+
+	return resp.Items
+}
+
+func TestServer_RecoverAccount(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	code, spare := newRecoveryCode(t), newRecoveryCode(t)
+	keys := registerWithRecoveryCodes(t, handler, code, spare)
+	phone := loginDevice(t, handler, "phone")
+
+	body := newRecoverRequest(t, code)
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/recovery/prelogin",
+		server.RecoveryPreloginRequest{Login: "alice", CodeID: body.CodeID}, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var prelogin server.RecoveryPreloginResponse
+
+	decodeBody(t, rec, &prelogin)
+	assert.Equal(t, keys[0].VaultKey, prelogin.VaultKey)
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/recovery", body, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp server.RecoverResponse
+
+	decodeBody(t, rec, &resp)
+	assert.Equal(t, server.RecoverResponse{RevokedSessions: 1, RemainingCodes: 1}, resp)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets", nil, phone.AccessToken)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "all sessions must be revoked")
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/login",
+		server.CredentialsRequest{Login: "alice", Password: "correct-horse"}, "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "old password must stop working")
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/login",
+		server.CredentialsRequest{Login: "alice", Password: "battery-staple"}, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var login server.LoginResponse
+
+	decodeBody(t, rec, &login)
+	assert.Equal(t, body.VaultKey, login.VaultKey)
+
+	events := listAuditEvents(t, handler, login.AccessToken)
+	require.Len(t, events, 1)
+	assert.Equal(t, "account.recovered", events[0].Type)
+	assert.Equal(t, map[string]string{"recovery_code_id": body.CodeID}, events[0].Details)
+}
+
+func TestServer_RecoveryCodeIsSingleUse(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	code := newRecoveryCode(t)
+	registerWithRecoveryCodes(t, handler, code)
+
+	body := newRecoverRequest(t, code)
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/recovery", body, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/recovery/prelogin",
+		server.RecoveryPreloginRequest{Login: "alice", CodeID: body.CodeID}, "")
+	requireErrorCode(t, rec, http.StatusUnauthorized, "recovery_code_used")
+
+	body.NewPassword = "another-password"
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/recovery", body, "")
+	requireErrorCode(t, rec, http.StatusUnauthorized, "recovery_code_used")
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/login",
+		server.CredentialsRequest{Login: "alice", Password: "battery-staple"}, "")
+	assert.Equal(t, http.StatusOK, rec.Code, "used code must not change the password")
+}
+
+func TestServer_RecoverErrors(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	code := newRecoveryCode(t)
+	registerWithRecoveryCodes(t, handler, code)
+
+	wrongPassword := newRecoverRequest(t, code)
+	wrongPassword.CodePassword = recoveryCodePassword(newRecoveryCode(t))
+
+	unknownCode := newRecoverRequest(t, newRecoveryCode(t))
+
+	unknownLogin := newRecoverRequest(t, code)
+	unknownLogin.Login = "bob"
+
+	for _, body := range []server.RecoverRequest{wrongPassword, unknownCode, unknownLogin} {
+		rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/recovery", body, "")
+		requireErrorCode(t, rec, http.StatusUnauthorized, "invalid_credentials")
+	}
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/recovery/prelogin",
+		server.RecoveryPreloginRequest{Login: "alice", CodeID: unknownCode.CodeID}, "")
+	requireErrorCode(t, rec, http.StatusUnauthorized, "invalid_credentials")
+
+	invalidKey := newRecoverRequest(t, code)
+	invalidKey.VaultKey.WrappedKey = []byte("not an envelope")
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/recovery", invalidKey, "")
+	requireErrorCode(t, rec, http.StatusBadRequest, "invalid_request")
+
+	// Failed attempts do not consume the code.
+	rec = doJSON(
+		t,
+		handler,
+		http.MethodPost,
+		"/api/v1/auth/recovery",
+		newRecoverRequest(t, code),
+		"",
+	)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+func TestServer_RegenerateRecoveryCodes(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	oldCode := newRecoveryCode(t)
+	registerWithRecoveryCodes(t, handler, oldCode)
+	accessToken := loginDevice(t, handler, "laptop").AccessToken
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/account/recovery-codes", nil, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var status server.RecoveryCodesResponse
+
+	decodeBody(t, rec, &status)
+	assert.Equal(t, 1, status.Total)
+	assert.Equal(t, 1, status.Remaining)
+
+	newCodes := []string{newRecoveryCode(t), newRecoveryCode(t), newRecoveryCode(t)}
+	body := server.RegenerateRecoveryCodesRequest{
+		Password:     "wrong-password",
+		RecoveryKeys: make([]crypto.RecoveryKey, 0, len(newCodes)),
+	}
+
+	for _, code := range newCodes {
+		body.RecoveryKeys = append(body.RecoveryKeys, newRecoveryKey(t, code))
+	}
+
+	rec = doJSON(t, handler, http.MethodPut, "/api/v1/account/recovery-codes", body, accessToken)
+	requireErrorCode(t, rec, http.StatusForbidden, "invalid_credentials")
+
+	body.Password = "correct-horse"
+	body.RecoveryKeys = append(body.RecoveryKeys, body.RecoveryKeys[0])
+
+	rec = doJSON(t, handler, http.MethodPut, "/api/v1/account/recovery-codes", body, accessToken)
+	requireErrorCode(t, rec, http.StatusBadRequest, "invalid_request")
+
+	body.RecoveryKeys = body.RecoveryKeys[:len(newCodes)]
+
+	rec = doJSON(t, handler, http.MethodPut, "/api/v1/account/recovery-codes", body, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	decodeBody(t, rec, &status)
+	assert.Equal(t, 3, status.Total)
+	assert.Equal(t, 3, status.Remaining)
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/recovery",
+		newRecoverRequest(t, oldCode), "")
+	requireErrorCode(t, rec, http.StatusUnauthorized, "invalid_credentials")
+
+	events := listAuditEvents(t, handler, accessToken)
+	require.Len(t, events, 1)
+	assert.Equal(t, "recovery_codes.regenerated", events[0].Type)
+	assert.NotEmpty(t, events[0].IP)
+}
@@ -0,0 +1,89 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Error codes returned in the body of error responses.
+const (
+	errCodeInvalidRequest     = "invalid_request"
+	errCodeInternal           = "internal_error"
+	errCodeUnauthorized       = "unauthorized"
+	errCodeNotFound           = "not_found"
+	errCodeUserAlreadyExists  = "user_already_exists"
+	errCodeInvalidCredentials = "invalid_credentials" //nolint:gosec // error code, not a credential
+
+	errCodeInvalidRefreshToken = "invalid_refresh_token"
+	errCodeRefreshTokenReused  = "refresh_token_reused"
+	errCodeRecoveryCodeUsed    = "recovery_code_used"
+
+	errCodeInvalidLoginChallenge = "invalid_login_challenge"
+	errCodeInvalidTwoFactorCode  = "invalid_two_factor_code"
+	errCodeTwoFactorEnabled      = "two_factor_already_enabled"
+	errCodeTwoFactorNotEnrolled  = "two_factor_not_enrolled"
+	errCodeTwoFactorLocked       = "two_factor_locked"
+
+	errCodePreconditionFailed   = "precondition_failed"
+	errCodePreconditionRequired = "precondition_required"
+)
+
+// ErrorResponse describes the body of all error responses.
+type ErrorResponse struct {
+	Code    string `json:"code"`    // Machine-readable error code.
+	Message string `json:"message"` // Human-readable error description.
+}
+
+const maxRequestBodySize = 1 << 20
+
+var errUnexpectedData = errors.New("unexpected data after JSON object")
+
+// decodeJSON reads the request body into the dst structure.
+//
+// Unknown fields and data after the JSON object are considered an error.
+func decodeJSON(r *http.Request, dst any) error {
+	decoder := json.NewDecoder(io.LimitReader(r.Body, maxRequestBodySize))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(dst)
+	if err != nil {
+		return fmt.Errorf("decode json: %w", err)
+	}
+
+	if decoder.More() {
+		return errUnexpectedData
+	}
+
+	return nil
+}
+
+// writeJSON writes the value as a JSON response with the specified status code.
+func (s *Server) writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(value)
+	if err != nil {
+		s.logger.Error("Write JSON response error", err)
+	}
+}
+
+// writeError writes the error as a JSON response with the specified status code.
+func (s *Server) writeError(w http.ResponseWriter, status int, code string, message string) {
+	s.writeJSON(w, status, ErrorResponse{
+		Code:    code,
+		Message: message,
+	})
+}
+
+// writeInternalError logs the error and writes a response with the code 500.
+//
+// The error details are not passed to the client.
+func (s *Server) writeInternalError(w http.ResponseWriter, err error) {
+	s.logger.Error("Internal server error (code 500)", err)
+	s.writeError(w, http.StatusInternalServerError, errCodeInternal, "internal server error")
+}
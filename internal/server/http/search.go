@@ -0,0 +1,42 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"net/http"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+)
+
+// SearchSecretsRequest describes the body of the request for the secrets with the blind indexes.
+//
+// The tokens are sent in the body, not in the query, so they are not written to access logs.
+type SearchSecretsRequest struct {
+	Indexes []crypto.BlindIndex `json:"indexes"` // Blind indexes the secrets must have, all of them.
+}
+
+func (s *Server) searchSecrets(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	var req SearchSecretsRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil || len(req.Indexes) == 0 {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	query, err := parseSecretQuery(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	query.Filter.Indexes = req.Indexes
+
+	s.writeSecretPage(w, r, userID, query)
+}
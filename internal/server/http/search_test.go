@@ -0,0 +1,169 @@
+package http_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	server "github.com/mr-filatik/go-password-keeper/internal/server/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blindIndexes computes the blind indexes of the pairs of field and value.
+func blindIndexes(t *testing.T, indexer *crypto.BlindIndexer, pairs ...string) []crypto.BlindIndex {
+	t.Helper()
+
+	indexes := make([]crypto.BlindIndex, 0, len(pairs)/2)
+
+	for i := 0; i < len(pairs); i += 2 {
+		index, err := indexer.Index(crypto.BlindIndexField(pairs[i]), pairs[i+1])
+		require.NoError(t, err)
+
+		indexes = append(indexes, index)
+	}
+
+	return indexes
+}
+
+// createIndexedSecret creates a credentials secret of the user with the blind indexes.
+func createIndexedSecret(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+	indexes []crypto.BlindIndex,
+) server.SecretResponse {
+	t.Helper()
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/secrets", server.SecretRequest{
+		FolderID: "",
+		Type:     secret.TypeCredentials,
+		Metadata: nil,
+		Tags:     nil,
+		Data:     encryptPayload(t, &secret.Credentials{Login: "alice", Password: "p@ss"}),
+		UploadID: "",
+		Indexes:  indexes,
+	}, accessToken)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created server.SecretResponse
+
+	decodeBody(t, rec, &created)
+
+	return created
+}
+
+// searchSecrets requests the secrets of the user with all the blind indexes.
+func searchSecrets(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+	query string,
+	indexes []crypto.BlindIndex,
+) server.SecretListResponse {
+	t.Helper()
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/secrets/search"+query,
+		server.SearchSecretsRequest{Indexes: indexes}, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp server.SecretListResponse
+
+	decodeBody(t, rec, &resp)
+
+	return resp
+}
+
+func TestServer_SearchSecrets(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	vaultKey, err := crypto.GenerateVaultKey()
+	require.NoError(t, err)
+
+	indexer, err := crypto.NewBlindIndexer(vaultKey)
+	require.NoError(t, err)
+
+	personal := createIndexedSecret(t, handler, accessToken, blindIndexes(t, indexer,
+		"username", "alice", "domain", "example.com", "domain", "https://www.example.com/"))
+	work := createIndexedSecret(t, handler, accessToken, blindIndexes(t, indexer,
+		"domain", "example.com", "username", "alice@work.example"))
+	createIndexedSecret(
+		t,
+		handler,
+		accessToken,
+		blindIndexes(t, indexer, "domain", "other.example"),
+	)
+
+	assert.Len(t, personal.Indexes, 2, "equal indexes must be stored once")
+
+	// Autofill knows only the address of the page.
+	found := searchSecrets(t, handler, accessToken, "",
+		blindIndexes(t, indexer, "domain", "https://example.com/login"))
+	assert.ElementsMatch(t, []string{personal.ID, work.ID}, secretIDs(found.Items))
+	assert.Empty(t, found.Items[0].Data, "search must not return payloads")
+
+	found = searchSecrets(t, handler, accessToken, "",
+		blindIndexes(t, indexer, "domain", "example.com", "username", "Alice@Work.example"))
+	assert.Equal(t, []string{work.ID}, secretIDs(found.Items))
+
+	found = searchSecrets(t, handler, accessToken, "?limit=1", blindIndexes(t, indexer,
+		"domain", "example.com"))
+	require.Len(t, found.Items, 1)
+	assert.True(t, found.HasMore)
+
+	// The same value of another field has another token.
+	found = searchSecrets(t, handler, accessToken, "",
+		blindIndexes(t, indexer, "tag", "example.com"))
+	assert.Empty(t, found.Items)
+
+	// Tokens of another vault key match nothing.
+	foreignKey, err := crypto.GenerateVaultKey()
+	require.NoError(t, err)
+
+	foreign, err := crypto.NewBlindIndexer(foreignKey)
+	require.NoError(t, err)
+
+	found = searchSecrets(t, handler, accessToken, "",
+		blindIndexes(t, foreign, "domain", "example.com"))
+	assert.Empty(t, found.Items)
+}
+
+func TestServer_SearchSecretsValidation(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+	token := strings.Repeat("A", crypto.BlindIndexTokenLength)
+
+	tests := []struct {
+		name    string
+		indexes []crypto.BlindIndex
+	}{
+		{name: "no indexes", indexes: nil},
+		{name: "unknown field", indexes: []crypto.BlindIndex{{Field: "password", Token: token}}},
+		{
+			name:    "plaintext value",
+			indexes: []crypto.BlindIndex{{Field: "domain", Token: "example.com"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := doJSON(t, handler, http.MethodPost, "/api/v1/secrets/search",
+				server.SearchSecretsRequest{Indexes: tt.indexes}, accessToken)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		})
+	}
+
+	req := textSecretRequest(encryptPayload(t, &secret.Text{Value: "note"}))
+	req.Indexes = []crypto.BlindIndex{{Field: "domain", Token: "example.com"}}
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/secrets", req, accessToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "indexes must not hold plaintext")
+}
@@ -0,0 +1,598 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	"github.com/mr-filatik/go-password-keeper/internal/server/http/middleware"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+)
+
+// SecretService describes the functionality of managing the user's secrets.
+type SecretService interface {
+	// Create creates a new secret for the user.
+	Create(ctx context.Context, userID string, input service.SecretInput) (*model.Secret, error)
+
+	// Get returns the user's secret.
+	Get(ctx context.Context, userID string, secretID string) (*model.Secret, error)
+
+	// Update replaces the content of the user's secret.
+	Update(
+		ctx context.Context,
+		userID string,
+		secretID string,
+		input service.SecretInput,
+		match service.RevisionMatch,
+	) (*model.Secret, error)
+
+	// Move places the user's secret into the folder.
+	Move(
+		ctx context.Context,
+		userID string,
+		secretID string,
+		folderID string,
+		match service.RevisionMatch,
+	) (*model.Secret, error)
+
+	// Delete moves the user's secret to the trash.
+	Delete(ctx context.Context, userID string, secretID string, match service.RevisionMatch) error
+
+	// ListPage returns a page of the user's secrets that match the filter.
+	ListPage(
+		ctx context.Context,
+		userID string,
+		query model.SecretQuery,
+	) (*service.SecretPage, error)
+
+	// ListVersions returns the previous revisions of the user's secret, the newest first.
+	ListVersions(
+		ctx context.Context,
+		userID string,
+		secretID string,
+	) ([]*model.SecretVersion, error)
+
+	// Restore makes the content of the previous revision current again.
+	Restore(
+		ctx context.Context,
+		userID string,
+		secretID string,
+		revision int64,
+		match service.RevisionMatch,
+	) (*model.Secret, error)
+
+	// OpenBlob returns the user's secret with the reader of its payload kept in the blob storage.
+	OpenBlob(
+		ctx context.Context,
+		userID string,
+		secretID string,
+	) (*model.Secret, io.ReadCloser, error)
+}
+
+// SecretRequest describes the body of the secret creation and update requests.
+type SecretRequest struct {
+	FolderID string            `json:"folderId,omitempty"` // Folder of a new secret, updates keep the folder.
+	Type     secret.Type       `json:"type"`               // Kind of secret.
+	Metadata map[string]string `json:"metadata"`           // Free-form metadata, cards only masked.
+	Tags     []string          `json:"tags,omitempty"`     // Labels the secrets are grouped by.
+	Data     []byte            `json:"data"`               // Payload encrypted on the client (base64).
+	UploadID string            `json:"uploadId,omitempty"` // Completed upload used as the binary payload.
+
+	// Indexes - blind indexes of the fields of the secret the search finds it by.
+	Indexes []crypto.BlindIndex `json:"indexes,omitempty"`
+}
+
+// SecretResponse describes the secret returned to the client.
+type SecretResponse struct {
+	ID        string            `json:"id"`                 // Secret identifier.
+	FolderID  string            `json:"folderId,omitempty"` // Folder of the secret, omitted at the top level.
+	Type      secret.Type       `json:"type"`               // Kind of secret.
+	Metadata  map[string]string `json:"metadata"`           // Free-form metadata.
+	Tags      []string          `json:"tags"`               // Labels the secret is grouped by, sorted.
+	Data      []byte            `json:"data,omitempty"`     // Encrypted payload (base64), omitted in lists.
+	BlobSize  int64             `json:"blobSize,omitempty"` // Size of the binary payload served by /blob.
+	Revision  int64             `json:"revision"`           // Number of the current revision.
+	CreatedAt time.Time         `json:"createdAt"`          // Creation time.
+	UpdatedAt time.Time         `json:"updatedAt"`          // Last modification time.
+
+	// Indexes - blind indexes of the fields of the secret, sorted by field and token.
+	Indexes []crypto.BlindIndex `json:"indexes"`
+}
+
+// SecretListResponse describes a page of the list of secrets returned to the client.
+type SecretListResponse struct {
+	Items   []SecretResponse `json:"items"`            // Secrets without payload.
+	Cursor  string           `json:"cursor,omitempty"` // Opaque cursor of the next page, omitted on the last page.
+	HasMore bool             `json:"hasMore"`          // More secrets are available with the cursor.
+}
+
+// SecretVersionResponse describes a previous revision of the secret.
+type SecretVersionResponse struct {
+	Revision   int64             `json:"revision"`   // Number of the revision.
+	Type       secret.Type       `json:"type"`       // Kind of secret.
+	Metadata   map[string]string `json:"metadata"`   // Free-form metadata.
+	Tags       []string          `json:"tags"`       // Labels the secret is grouped by, sorted.
+	Data       []byte            `json:"data"`       // Encrypted payload (base64).
+	UpdatedAt  time.Time         `json:"updatedAt"`  // Time the revision was written.
+	ArchivedAt time.Time         `json:"archivedAt"` // Time the revision was replaced.
+
+	// Indexes - blind indexes of the fields of the revision, sorted by field and token.
+	Indexes []crypto.BlindIndex `json:"indexes"`
+}
+
+// SecretVersionListResponse describes the history of the secret.
+type SecretVersionListResponse struct {
+	Items []SecretVersionResponse `json:"items"` // Previous revisions, the newest first.
+}
+
+func newSecretResponse(item *model.Secret, withData bool) SecretResponse {
+	resp := SecretResponse{
+		ID:        item.ID,
+		FolderID:  item.FolderID,
+		Type:      item.Type,
+		Metadata:  item.Metadata,
+		Tags:      item.Tags,
+		Data:      nil,
+		BlobSize:  item.BlobSize,
+		Revision:  item.Revision,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+		Indexes:   item.Indexes,
+	}
+
+	if resp.Metadata == nil {
+		resp.Metadata = map[string]string{}
+	}
+
+	if resp.Tags == nil {
+		resp.Tags = []string{}
+	}
+
+	if resp.Indexes == nil {
+		resp.Indexes = []crypto.BlindIndex{}
+	}
+
+	if withData {
+		resp.Data = item.Data
+	}
+
+	return resp
+}
+
+func newSecretVersionResponse(version *model.SecretVersion) SecretVersionResponse {
+	resp := SecretVersionResponse{
+		Revision:   version.Revision,
+		Type:       version.Type,
+		Metadata:   version.Metadata,
+		Tags:       version.Tags,
+		Data:       version.Data,
+		UpdatedAt:  version.UpdatedAt,
+		ArchivedAt: version.ArchivedAt,
+		Indexes:    version.Indexes,
+	}
+
+	if resp.Metadata == nil {
+		resp.Metadata = map[string]string{}
+	}
+
+	if resp.Tags == nil {
+		resp.Tags = []string{}
+	}
+
+	if resp.Indexes == nil {
+		resp.Indexes = []crypto.BlindIndex{}
+	}
+
+	return resp
+}
+
+func (req *SecretRequest) toInput() service.SecretInput {
+	return service.SecretInput{
+		FolderID: req.FolderID,
+		Type:     req.Type,
+		Metadata: req.Metadata,
+		Tags:     req.Tags,
+		Data:     req.Data,
+		UploadID: req.UploadID,
+		Indexes:  req.Indexes,
+	}
+}
+
+// Filters of the list of secrets.
+const (
+	queryType         = "type"
+	queryTag          = "tag"
+	queryFolderID     = "folderId"
+	queryUpdatedSince = "updatedSince"
+)
+
+// Format of the cursor of the list of secrets: sort, order, time of the sort field and identifier.
+const (
+	secretCursorSeparator = ":"
+	secretCursorParts     = 4
+)
+
+// parseSecretQuery converts the query parameters of the list of secrets to the query of the page.
+func parseSecretQuery(r *http.Request) (*model.SecretQuery, error) {
+	params, err := parseListQuery(
+		r,
+		string(model.SecretSortCreatedAt),
+		string(model.SecretSortUpdatedAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedSince, err := params.timeFilter(queryUpdatedSince)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &model.SecretQuery{
+		Filter: model.SecretFilter{
+			Type:         secret.Type(params.filter(queryType)),
+			Tag:          params.filter(queryTag),
+			FolderID:     params.filter(queryFolderID),
+			UpdatedSince: updatedSince,
+			Indexes:      nil,
+		},
+		Sort:       model.SecretSort(params.sort),
+		Descending: params.descending,
+		After:      nil,
+		Limit:      params.limit,
+	}
+
+	if query.Sort == "" {
+		query.Sort = model.SecretSortCreatedAt
+	}
+
+	if params.cursor != "" {
+		query.After, err = decodeSecretCursor(query, params.cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return query, nil
+}
+
+// encodeSecretCursor converts the position after the last secret of the page to the opaque cursor.
+//
+// The cursor keeps the sort it was issued for, so it cannot be used with another one.
+func encodeSecretCursor(query *model.SecretQuery, next *model.SecretCursor) string {
+	order := orderAsc
+	if query.Descending {
+		order = orderDesc
+	}
+
+	raw := strings.Join([]string{
+		string(query.Sort),
+		order,
+		strconv.FormatInt(next.Time.UnixNano(), 10),
+		next.ID,
+	}, secretCursorSeparator)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeSecretCursor converts the opaque cursor to the position in the listing of the query.
+func decodeSecretCursor(query *model.SecretQuery, cursor string) (*model.SecretCursor, error) {
+	malformed := fmt.Errorf("%w: %s is malformed", errInvalidQuery, queryCursor)
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, malformed
+	}
+
+	parts := strings.SplitN(string(raw), secretCursorSeparator, secretCursorParts)
+	if len(parts) != secretCursorParts || parts[3] == "" {
+		return nil, malformed
+	}
+
+	nanos, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, malformed
+	}
+
+	if parts[0] != string(query.Sort) || (parts[1] == orderDesc) != query.Descending {
+		return nil, fmt.Errorf("%w: %s was issued for another sort", errInvalidQuery, queryCursor)
+	}
+
+	return &model.SecretCursor{Time: time.Unix(0, nanos).UTC(), ID: parts[3]}, nil
+}
+
+// userID returns the identifier of the authenticated user.
+//
+// If the request is not authenticated, it writes a response with the code 401.
+func (s *Server) userID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		s.writeError(w, http.StatusUnauthorized, errCodeUnauthorized, "authentication required")
+
+		return "", false
+	}
+
+	return userID, true
+}
+
+func (s *Server) writeSecretError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidSecret), errors.Is(err, service.ErrInvalidSecretQuery):
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+	case errors.Is(err, service.ErrSecretNotFound):
+		s.writeError(w, http.StatusNotFound, errCodeNotFound, "secret not found")
+	case errors.Is(err, service.ErrSecretHasNoBlob):
+		s.writeError(w, http.StatusNotFound, errCodeNotFound, "secret has no binary payload")
+	case errors.Is(err, service.ErrSecretVersionNotFound):
+		s.writeError(w, http.StatusNotFound, errCodeNotFound, "secret version not found")
+	case errors.Is(err, service.ErrSecretModified):
+		s.writeError(w, http.StatusPreconditionFailed, errCodePreconditionFailed,
+			"secret has been modified")
+	default:
+		s.writeInternalError(w, err)
+	}
+}
+
+func (s *Server) createSecret(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	var req SecretRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	item, err := s.secrets.Create(r.Context(), userID, req.toInput())
+	if err != nil {
+		s.writeSecretError(w, err)
+
+		return
+	}
+
+	s.writeSecret(w, http.StatusCreated, item)
+}
+
+func (s *Server) getSecret(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := s.secrets.Get(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeSecretError(w, err)
+
+		return
+	}
+
+	s.writeSecret(w, http.StatusOK, item)
+}
+
+func (s *Server) downloadSecretBlob(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	item, content, err := s.secrets.OpenBlob(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeSecretError(w, err)
+
+		return
+	}
+
+	defer func() {
+		closeErr := content.Close()
+		if closeErr != nil {
+			s.logger.Error("Close blob error", closeErr, "secretID", item.ID)
+		}
+	}()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(item.BlobSize, 10))
+	w.Header().Set(headerETag, secretETag(item))
+	w.WriteHeader(http.StatusOK)
+
+	// The status has been sent, a failure can only be logged and the client sees a short body.
+	_, err = io.Copy(w, content)
+	if err != nil {
+		s.logger.Error("Stream blob error", err, "secretID", item.ID)
+	}
+}
+
+func (s *Server) updateSecret(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	match, present := s.revisionMatch(w, r)
+	if !present {
+		return
+	}
+
+	var req SecretRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	item, err := s.secrets.Update(r.Context(), userID, chi.URLParam(r, "id"), req.toInput(), match)
+	if err != nil {
+		s.writeSecretError(w, err)
+
+		return
+	}
+
+	s.writeSecret(w, http.StatusOK, item)
+}
+
+func (s *Server) moveSecret(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	var req MoveSecretRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	match, present := s.revisionMatch(w, r)
+	if !present {
+		return
+	}
+
+	item, err := s.secrets.Move(r.Context(), userID, chi.URLParam(r, "id"), req.FolderID, match)
+	if err != nil {
+		s.writeFolderError(w, err)
+
+		return
+	}
+
+	s.writeSecret(w, http.StatusOK, item)
+}
+
+func (s *Server) deleteSecret(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	match, present := s.revisionMatch(w, r)
+	if !present {
+		return
+	}
+
+	err := s.secrets.Delete(r.Context(), userID, chi.URLParam(r, "id"), match)
+	if err != nil {
+		s.writeSecretError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listSecrets(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	query, err := parseSecretQuery(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	s.writeSecretPage(w, r, userID, query)
+}
+
+// writeSecretPage writes the page of the user's secrets found by the query.
+func (s *Server) writeSecretPage(
+	w http.ResponseWriter,
+	r *http.Request,
+	userID string,
+	query *model.SecretQuery,
+) {
+	page, err := s.secrets.ListPage(r.Context(), userID, *query)
+	if err != nil {
+		s.writeSecretError(w, err)
+
+		return
+	}
+
+	resp := SecretListResponse{
+		Items:   make([]SecretResponse, 0, len(page.Items)),
+		Cursor:  "",
+		HasMore: page.Next != nil,
+	}
+
+	for _, item := range page.Items {
+		resp.Items = append(resp.Items, newSecretResponse(item, false))
+	}
+
+	if page.Next != nil {
+		resp.Cursor = encodeSecretCursor(query, page.Next)
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) listSecretVersions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	versions, err := s.secrets.ListVersions(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeSecretError(w, err)
+
+		return
+	}
+
+	resp := SecretVersionListResponse{
+		Items: make([]SecretVersionResponse, 0, len(versions)),
+	}
+
+	for _, version := range versions {
+		resp.Items = append(resp.Items, newSecretVersionResponse(version))
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) restoreSecretVersion(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	revision, err := strconv.ParseInt(chi.URLParam(r, "rev"), 10, 64)
+	if err != nil || revision <= 0 {
+		s.writeSecretError(w, service.ErrSecretVersionNotFound)
+
+		return
+	}
+
+	match, present := s.revisionMatch(w, r)
+	if !present {
+		return
+	}
+
+	item, err := s.secrets.Restore(r.Context(), userID, chi.URLParam(r, "id"), revision, match)
+	if err != nil {
+		s.writeSecretError(w, err)
+
+		return
+	}
+
+	s.writeSecret(w, http.StatusOK, item)
+}
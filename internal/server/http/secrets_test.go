@@ -0,0 +1,669 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	server "github.com/mr-filatik/go-password-keeper/internal/server/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustJSON(t *testing.T, value any) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+
+	return data
+}
+
+// encryptPayload encrypts the payload the way the client does before sending it to the server.
+func encryptPayload(t *testing.T, payload secret.Payload) []byte {
+	t.Helper()
+
+	data, err := secret.EncryptPayload(newTestVault(t), payload)
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestServer_SecretsCRUD(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	createReq := server.SecretRequest{
+		FolderID: "",
+		Type:     secret.TypeCredentials,
+		Metadata: map[string]string{"site": "example.com"},
+		Tags:     nil,
+		Data:     encryptPayload(t, &secret.Credentials{Login: "alice", Password: "p@ss"}),
+		UploadID: "",
+		Indexes:  nil,
+	}
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/secrets", createReq, accessToken)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created server.SecretResponse
+
+	decodeBody(t, rec, &created)
+	require.NotEmpty(t, created.ID)
+	assert.Equal(t, createReq.Data, created.Data)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets/"+created.ID, nil, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var fetched server.SecretResponse
+
+	decodeBody(t, rec, &fetched)
+	assert.Equal(t, created, fetched)
+
+	updateReq := textSecretRequest(encryptPayload(t, &secret.Text{Value: "note"}))
+
+	rec = doJSONWithHeaders(t, handler, http.MethodPut, "/api/v1/secrets/"+created.ID, updateReq,
+		accessToken, ifMatch(rec.Header().Get("ETag")))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	etag := rec.Header().Get("ETag")
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets", nil, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var list server.SecretListResponse
+
+	decodeBody(t, rec, &list)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, secret.TypeText, list.Items[0].Type)
+	assert.Empty(t, list.Items[0].Data, "list must not contain payloads")
+
+	rec = doJSONWithHeaders(t, handler, http.MethodDelete, "/api/v1/secrets/"+created.ID, nil,
+		accessToken, ifMatch(etag))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets/"+created.ID, nil, accessToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_SecretsScopedToUser(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	owner := registerAndLogin(t, handler, "owner")
+	stranger := registerAndLogin(t, handler, "stranger")
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/secrets", server.SecretRequest{
+		FolderID: "",
+		Type:     secret.TypeText,
+		Metadata: nil,
+		Tags:     nil,
+		Data:     encryptPayload(t, &secret.Text{Value: "owner note"}),
+		UploadID: "",
+		Indexes:  nil,
+	}, owner)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var created server.SecretResponse
+
+	decodeBody(t, rec, &created)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets/"+created.ID, nil, stranger)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doJSONWithHeaders(t, handler, http.MethodDelete, "/api/v1/secrets/"+created.ID, nil,
+		stranger, ifMatch("*"))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets/"+created.ID, nil, owner)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestServer_SecretsRequireAuthentication(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+
+	tests := []struct {
+		name        string
+		accessToken string
+	}{
+		{name: "no token", accessToken: ""},
+		{name: "invalid token", accessToken: "not.a.token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := doJSON(t, handler, http.MethodGet, "/api/v1/secrets", nil, tt.accessToken)
+			require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+			var resp server.ErrorResponse
+
+			decodeBody(t, rec, &resp)
+			assert.Equal(t, "unauthorized", resp.Code)
+		})
+	}
+}
+
+func TestServer_CreateSecretValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		req  server.SecretRequest
+	}{
+		{
+			name: "unknown type",
+			req: server.SecretRequest{
+				FolderID: "",
+				Type:     secret.Type("unknown"),
+				Metadata: nil,
+				Tags:     nil,
+				Data:     []byte(`{}`),
+				UploadID: "",
+				Indexes:  nil,
+			},
+		},
+		{
+			name: "empty data",
+			req: server.SecretRequest{
+				FolderID: "",
+				Type:     secret.TypeCard,
+				Metadata: nil,
+				Tags:     nil,
+				Data:     nil,
+				UploadID: "",
+				Indexes:  nil,
+			},
+		},
+		{
+			name: "plaintext instead of envelope",
+			req: server.SecretRequest{
+				FolderID: "",
+				Type:     secret.TypeText,
+				Metadata: nil,
+				Tags:     nil,
+				Data:     mustJSON(t, secret.Text{Value: "note"}),
+				UploadID: "",
+				Indexes:  nil,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := newTestServer(t)
+			accessToken := registerAndLogin(t, handler, "alice")
+
+			rec := doJSON(t, handler, http.MethodPost, "/api/v1/secrets", tt.req, accessToken)
+			require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+// ifMatch returns the If-Match header with the entity tags.
+func ifMatch(tags string) map[string]string {
+	return map[string]string{"If-Match": tags}
+}
+
+// textSecretRequest returns the request of a text secret with the encrypted payload.
+func textSecretRequest(data []byte) server.SecretRequest {
+	return server.SecretRequest{
+		FolderID: "",
+		Type:     secret.TypeText,
+		Metadata: nil,
+		Tags:     nil,
+		Data:     data,
+		UploadID: "",
+		Indexes:  nil,
+	}
+}
+
+// updateSecretText replaces the secret of the user with a text note and returns the response.
+func updateSecretText(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+	secretID string,
+	data []byte,
+) server.SecretResponse {
+	t.Helper()
+
+	rec := doJSONWithHeaders(
+		t,
+		handler,
+		http.MethodPut,
+		"/api/v1/secrets/"+secretID,
+		textSecretRequest(data),
+		accessToken,
+		ifMatch("*"),
+	)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp server.SecretResponse
+
+	decodeBody(t, rec, &resp)
+
+	return resp
+}
+
+// listSecretVersions returns the history of the secret.
+func listSecretVersions(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+	secretID string,
+) []server.SecretVersionResponse {
+	t.Helper()
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/secrets/"+secretID+"/versions", nil,
+		accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp server.SecretVersionListResponse
+
+	decodeBody(t, rec, &resp)
+
+	return resp.Items
+}
+
+func TestServer_SecretVersions(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	original := encryptPayload(t, &secret.Credentials{Login: "alice", Password: "p@ss"})
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/secrets", server.SecretRequest{
+		FolderID: "",
+		Type:     secret.TypeCredentials,
+		Metadata: map[string]string{"site": "example.com"},
+		Tags:     nil,
+		Data:     original,
+		UploadID: "",
+		Indexes:  nil,
+	}, accessToken)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created server.SecretResponse
+
+	decodeBody(t, rec, &created)
+	assert.Equal(t, int64(1), created.Revision)
+	assert.Empty(t, listSecretVersions(t, handler, accessToken, created.ID))
+
+	mistake := encryptPayload(t, &secret.Text{Value: "overwritten by mistake"})
+	updated := updateSecretText(t, handler, accessToken, created.ID, mistake)
+	assert.Equal(t, int64(2), updated.Revision)
+
+	versions := listSecretVersions(t, handler, accessToken, created.ID)
+	require.Len(t, versions, 1)
+	assert.Equal(t, int64(1), versions[0].Revision)
+	assert.Equal(t, secret.TypeCredentials, versions[0].Type)
+	assert.Equal(t, map[string]string{"site": "example.com"}, versions[0].Metadata)
+	assert.Equal(t, original, versions[0].Data)
+	assert.False(t, versions[0].ArchivedAt.Before(versions[0].UpdatedAt))
+
+	rec = doJSONWithHeaders(t, handler, http.MethodPost,
+		"/api/v1/secrets/"+created.ID+"/versions/1/restore", nil, accessToken, ifMatch(`"2"`))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var restored server.SecretResponse
+
+	decodeBody(t, rec, &restored)
+	assert.Equal(t, int64(3), restored.Revision)
+	assert.Equal(t, secret.TypeCredentials, restored.Type)
+	assert.Equal(t, original, restored.Data)
+
+	versions = listSecretVersions(t, handler, accessToken, created.ID)
+	require.Len(t, versions, 2)
+	assert.Equal(t, int64(2), versions[0].Revision, "newest revision must go first")
+	assert.Equal(t, mistake, versions[0].Data, "restore must keep the replaced revision")
+}
+
+func TestServer_SecretVersionsRetention(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+	stranger := registerAndLogin(t, handler, "bob")
+
+	created := createTextSecret(t, handler, accessToken, "revision 1")
+
+	for range testSecretVersionsLimit + 1 {
+		updateSecretText(t, handler, accessToken, created.ID,
+			encryptPayload(t, &secret.Text{Value: "next revision"}))
+	}
+
+	versions := listSecretVersions(t, handler, accessToken, created.ID)
+	require.Len(t, versions, testSecretVersionsLimit)
+	assert.Equal(t, int64(3), versions[0].Revision)
+	assert.Equal(t, int64(2), versions[1].Revision)
+
+	tests := []struct {
+		name        string
+		target      string
+		accessToken string
+	}{
+		{name: "pruned revision", target: "/versions/1/restore", accessToken: accessToken},
+		{name: "current revision", target: "/versions/4/restore", accessToken: accessToken},
+		{name: "invalid revision", target: "/versions/latest/restore", accessToken: accessToken},
+		{name: "foreign secret", target: "/versions/2/restore", accessToken: stranger},
+	}
+	for _, tt := range tests {
+		rec := doJSONWithHeaders(
+			t,
+			handler,
+			http.MethodPost,
+			"/api/v1/secrets/"+created.ID+tt.target,
+			nil,
+			tt.accessToken,
+			ifMatch("*"),
+		)
+		assert.Equal(t, http.StatusNotFound, rec.Code, tt.name)
+	}
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/secrets/"+created.ID+"/versions", nil,
+		stranger)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doJSONWithHeaders(t, handler, http.MethodDelete, "/api/v1/secrets/"+created.ID, nil,
+		accessToken, ifMatch(`"4"`))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets/"+created.ID+"/versions", nil,
+		accessToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "history must be deleted with the secret")
+}
+
+func TestServer_SecretPreconditions(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/secrets",
+		textSecretRequest(encryptPayload(t, &secret.Text{Value: "revision 1"})), accessToken)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, `"1"`, rec.Header().Get("ETag"))
+
+	var created server.SecretResponse
+
+	decodeBody(t, rec, &created)
+
+	target := "/api/v1/secrets/" + created.ID
+	updateReq := textSecretRequest(encryptPayload(t, &secret.Text{Value: "revision 2"}))
+
+	rec = doJSON(t, handler, http.MethodPut, target, updateReq, accessToken)
+	assert.Equal(t, http.StatusPreconditionRequired, rec.Code, "update without If-Match")
+
+	rec = doJSON(t, handler, http.MethodDelete, target, nil, accessToken)
+	assert.Equal(t, http.StatusPreconditionRequired, rec.Code, "delete without If-Match")
+
+	rec = doJSONWithHeaders(t, handler, http.MethodPut, target, updateReq, accessToken,
+		ifMatch(`"7", "1"`))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+
+	for _, tags := range []string{`"1"`, `W/"2"`, "2", "garbage"} {
+		rec = doJSONWithHeaders(t, handler, http.MethodPut, target, updateReq, accessToken,
+			ifMatch(tags))
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code, "update with If-Match %s", tags)
+
+		rec = doJSONWithHeaders(t, handler, http.MethodDelete, target, nil, accessToken,
+			ifMatch(tags))
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code, "delete with If-Match %s", tags)
+	}
+
+	rec = doJSONWithHeaders(t, handler, http.MethodPost, target+"/versions/1/restore", nil,
+		accessToken, ifMatch(`"1"`))
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code, "restore over a stale revision")
+
+	rec = doJSON(t, handler, http.MethodGet, target, nil, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+
+	rec = doJSONWithHeaders(t, handler, http.MethodDelete, target, nil, accessToken,
+		ifMatch(rec.Header().Get("ETag")))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+// listSecrets requests a page of the list of the user's secrets with the query parameters.
+func listSecrets(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+	query url.Values,
+) server.SecretListResponse {
+	t.Helper()
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/secrets?"+query.Encode(), nil, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp server.SecretListResponse
+
+	decodeBody(t, rec, &resp)
+
+	return resp
+}
+
+// createTaggedSecret creates a text note of the user with the tags.
+func createTaggedSecret(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+	tags ...string,
+) server.SecretResponse {
+	t.Helper()
+
+	req := textSecretRequest(encryptPayload(t, &secret.Text{Value: "note"}))
+	req.Tags = tags
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/secrets", req, accessToken)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created server.SecretResponse
+
+	decodeBody(t, rec, &created)
+
+	return created
+}
+
+// secretIDs returns the identifiers of the listed secrets in the order of the list.
+func secretIDs(items []server.SecretResponse) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+
+	return ids
+}
+
+func TestServer_ListSecretsPagination(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	for range 5 {
+		createTaggedSecret(t, handler, accessToken)
+	}
+
+	all := listSecrets(t, handler, accessToken, url.Values{})
+	require.Len(t, all.Items, 5)
+	assert.False(t, all.HasMore)
+	assert.Empty(t, all.Cursor)
+
+	for _, order := range []string{"asc", "desc"} {
+		var paged []string
+
+		query := url.Values{"limit": {"2"}, "order": {order}}
+		for pages := 1; ; pages++ {
+			page := listSecrets(t, handler, accessToken, query)
+			require.LessOrEqual(t, len(page.Items), 2)
+			paged = append(paged, secretIDs(page.Items)...)
+
+			if !page.HasMore {
+				assert.Equal(t, 3, pages)
+
+				break
+			}
+
+			query.Set("cursor", page.Cursor)
+		}
+
+		want := secretIDs(all.Items)
+		if order == "desc" {
+			slices.Reverse(want)
+		}
+
+		assert.Equal(t, want, paged, "pages must neither skip nor repeat secrets, order %s", order)
+	}
+}
+
+func TestServer_ListSecretsFilters(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	work := createTaggedSecret(t, handler, accessToken, "work", "email", "work")
+	home := createTaggedSecret(t, handler, accessToken, "home")
+	folder := createFolder(t, handler, accessToken, "archive", "")
+	archived := createSecretInFolder(t, handler, accessToken, folder.ID)
+	assert.Equal(t, []string{"email", "work"}, work.Tags, "tags must be sorted and unique")
+
+	homeUpdated := updateSecretText(t, handler, accessToken, home.ID,
+		encryptPayload(t, &secret.Text{Value: "edited"}))
+
+	tests := []struct {
+		name  string
+		query url.Values
+		want  []string
+	}{
+		{name: "tag", query: url.Values{"tag": {"work"}}, want: []string{work.ID}},
+		{name: "folder", query: url.Values{"folderId": {folder.ID}}, want: []string{archived.ID}},
+		{name: "type", query: url.Values{"type": {"card"}}, want: []string{}},
+		{
+			name:  "updated since",
+			query: url.Values{"updatedSince": {homeUpdated.UpdatedAt.Format(time.RFC3339Nano)}},
+			want:  []string{home.ID},
+		},
+		{
+			name:  "sorted by update",
+			query: url.Values{"sort": {"updatedAt"}, "order": {"desc"}, "limit": {"1"}},
+			want:  []string{home.ID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			page := listSecrets(t, handler, accessToken, tt.query)
+			assert.Equal(t, tt.want, secretIDs(page.Items))
+		})
+	}
+}
+
+func TestServer_SecretTagsValidation(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	for _, tags := range [][]string{{"work", ""}, {strings.Repeat("t", 65)}} {
+		req := textSecretRequest(encryptPayload(t, &secret.Text{Value: "note"}))
+		req.Tags = tags
+
+		rec := doJSON(t, handler, http.MethodPost, "/api/v1/secrets", req, accessToken)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "tags %q", tags)
+	}
+}
+
+func TestServer_CardSecretMetadata(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	card := &secret.Card{Number: "4111111111111111", Holder: "ALICE", Expiry: "12/30", CVV: "123"}
+
+	req := textSecretRequest(encryptPayload(t, card))
+	req.Type = secret.TypeCard
+	req.Metadata = card.Summary().Metadata()
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/secrets", req, accessToken)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created server.SecretResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, map[string]string{
+		secret.MetadataCardNetwork:  "visa",
+		secret.MetadataCardLastFour: "1111",
+	}, created.Metadata)
+
+	invalid := []map[string]string{
+		nil,
+		{secret.MetadataCardNetwork: "visa"},
+		{secret.MetadataCardLastFour: "1111"},
+		{secret.MetadataCardNetwork: "visa", secret.MetadataCardLastFour: "11111"},
+		{secret.MetadataCardNetwork: "bankcard", secret.MetadataCardLastFour: "1111"},
+		{
+			secret.MetadataCardNetwork:  "visa",
+			secret.MetadataCardLastFour: "1111",
+			"note":                      "4111 1111 1111 1111",
+		},
+	}
+	for _, metadata := range invalid {
+		req.Metadata = metadata
+
+		rec = doJSON(t, handler, http.MethodPost, "/api/v1/secrets", req, accessToken)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "metadata %v", metadata)
+	}
+}
+
+func TestServer_MoveAndRestorePreconditions(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	work := createFolder(t, handler, accessToken, "work", "")
+	created := createTextSecret(t, handler, accessToken, "revision 1")
+	updateSecretText(t, handler, accessToken, created.ID,
+		encryptPayload(t, &secret.Text{Value: "revision 2"}))
+
+	target := "/api/v1/secrets/" + created.ID
+	moveReq := server.MoveSecretRequest{FolderID: work.ID}
+
+	rec := doJSON(t, handler, http.MethodPost, target+"/move", moveReq, accessToken)
+	assert.Equal(t, http.StatusPreconditionRequired, rec.Code, "move without If-Match")
+
+	rec = doJSON(t, handler, http.MethodPost, target+"/versions/1/restore", nil, accessToken)
+	assert.Equal(t, http.StatusPreconditionRequired, rec.Code, "restore without If-Match")
+
+	rec = doJSONWithHeaders(t, handler, http.MethodPost, target+"/move", moveReq, accessToken,
+		ifMatch(`"1"`))
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code, "move over a stale revision")
+
+	current := getSecret(t, handler, accessToken, created.ID)
+	assert.Empty(t, current.FolderID, "rejected move must not change the folder")
+	assert.Equal(t, int64(2), current.Revision)
+
+	rec = doJSONWithHeaders(t, handler, http.MethodPost, target+"/move", moveReq, accessToken,
+		ifMatch(`"2"`))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+
+	rec = doJSONWithHeaders(t, handler, http.MethodPost, target+"/versions/1/restore", nil,
+		accessToken, ifMatch(rec.Header().Get("ETag")))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
@@ -9,6 +9,7 @@ import (
 	"math/rand/v2"
 	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -24,14 +25,49 @@ type Server struct {
 	router          *chi.Mux
 	server          *http.Server
 	metricsProvider *metrics.Provider
+	auth            AuthService
+	secrets         SecretService
+	trash           TrashService
+	folders         FolderService
+	sync            SyncService
+	conflicts       ConflictService
+	events          EventSubscriber
+	uploads         UploadService
+	sessions        SessionService
+	account         AccountService
+	recovery        RecoveryService
+	audit           AuditService
+	twoFactor       TwoFactorService
+	tokenVerifier   middleware.TokenVerifier
+	sessionChecker  middleware.SessionChecker
+	admin           AdminConfig
 	logger          logging.Logger
 	address         string
+
+	// shuttingDown is closed when the server starts shutting down to end long-lived streams.
+	shuttingDown chan struct{}
 }
 
 // ServerConfig - HTTP server configuration.
 type ServerConfig struct {
-	Address         string // Address
-	MetricsProvider *metrics.Provider
+	Address          string // Address
+	MetricsProvider  *metrics.Provider
+	AuthService      AuthService
+	SecretService    SecretService
+	TrashService     TrashService
+	FolderService    FolderService
+	SyncService      SyncService
+	ConflictService  ConflictService
+	EventSubscriber  EventSubscriber
+	UploadService    UploadService
+	SessionService   SessionService
+	AccountService   AccountService
+	RecoveryService  RecoveryService
+	AuditService     AuditService
+	TwoFactorService TwoFactorService
+	TokenVerifier    middleware.TokenVerifier
+	SessionChecker   middleware.SessionChecker
+	Admin            AdminConfig
 }
 
 const (
@@ -50,7 +86,24 @@ func NewServer(conf ServerConfig, logger logging.Logger) *Server {
 	srvr := &Server{
 		address:         conf.Address,
 		metricsProvider: conf.MetricsProvider,
+		auth:            conf.AuthService,
+		secrets:         conf.SecretService,
+		trash:           conf.TrashService,
+		folders:         conf.FolderService,
+		sync:            conf.SyncService,
+		conflicts:       conf.ConflictService,
+		events:          conf.EventSubscriber,
+		uploads:         conf.UploadService,
+		sessions:        conf.SessionService,
+		account:         conf.AccountService,
+		recovery:        conf.RecoveryService,
+		audit:           conf.AuditService,
+		twoFactor:       conf.TwoFactorService,
+		tokenVerifier:   conf.TokenVerifier,
+		sessionChecker:  conf.SessionChecker,
+		admin:           conf.Admin,
 		logger:          logger,
+		shuttingDown:    make(chan struct{}),
 		router:          chi.NewRouter(),
 		server: &http.Server{
 			Addr:                         conf.Address,
@@ -72,6 +125,12 @@ func NewServer(conf ServerConfig, logger logging.Logger) *Server {
 		},
 	}
 
+	// The timeouts do not apply to streaming endpoints: they are lifted by middleware.Streaming,
+	// and the streams are ended here when the server shuts down, so Shutdown does not wait for them.
+	srvr.server.RegisterOnShutdown(sync.OnceFunc(func() {
+		close(srvr.shuttingDown)
+	}))
+
 	srvr.registerMiddlewares()
 
 	srvr.registerHandlers()
@@ -142,6 +201,11 @@ func (s *Server) Close() error {
 	return nil
 }
 
+// Handler returns the root HTTP handler of the server.
+func (s *Server) Handler() http.Handler {
+	return s.server.Handler
+}
+
 func routeFromChiContext(r *http.Request) string {
 	return chi.RouteContext(r.Context()).RoutePattern()
 }
@@ -176,9 +240,100 @@ func (s *Server) registerHandlers() {
 
 	s.router.Handle("/swagger/*", httpSwagger.WrapHandler)
 
+	s.router.Route("/api/v1", func(router chi.Router) {
+		router.Post("/auth/register", s.register)
+		router.Post("/auth/prelogin", s.prelogin)
+		router.Post("/auth/login", s.login)
+		router.Post("/auth/login/2fa", s.loginTwoFactor)
+		router.Post("/auth/refresh", s.refresh)
+		router.Post("/auth/recovery/prelogin", s.recoveryPrelogin)
+		router.Post("/auth/recovery", s.recover)
+		router.Options("/uploads", s.describeUploads)
+
+		router.Group(s.registerProtectedHandlers)
+
+		s.registerAdminHandlers(router)
+	})
+
 	s.server.Handler = s.router
 }
 
+// registerProtectedHandlers registers the handlers available only to authenticated users.
+func (s *Server) registerProtectedHandlers(router chi.Router) {
+	router.Use(middleware.Auth(s.tokenVerifier, s.sessionChecker, s.logger))
+
+	// Sensitive operations require the one-time code if the user has enabled two-factor authentication.
+	requireTwoFactor := middleware.TwoFactor(twoFactorCheck{twoFactor: s.twoFactor}, s.logger)
+
+	s.registerVaultHandlers(router)
+
+	router.Get("/sync", s.syncSecrets)
+	router.With(middleware.Streaming(s.logger)).Get("/events", s.streamEvents)
+
+	// Resumable uploads of large payloads, the chunks are not limited by the server timeouts.
+	uploads := router.With(s.tusResumable)
+	uploads.Post("/uploads", s.createUpload)
+	uploads.Head("/uploads/{id}", s.headUpload)
+	uploads.With(middleware.Streaming(s.logger)).Patch("/uploads/{id}", s.patchUpload)
+	uploads.Delete("/uploads/{id}", s.terminateUpload)
+
+	router.Route("/conflicts", func(routes chi.Router) {
+		routes.Get("/", s.listConflicts)
+		routes.Get("/{id}", s.getConflict)
+		routes.Post("/{id}/resolve", s.resolveConflict)
+	})
+
+	router.Route("/account", func(routes chi.Router) {
+		routes.With(requireTwoFactor).Post("/password", s.changePassword)
+		routes.Get("/recovery-codes", s.getRecoveryCodes)
+		routes.With(requireTwoFactor).Put("/recovery-codes", s.regenerateRecoveryCodes)
+		routes.Get("/audit-log", s.listAuditEvents)
+		routes.Get("/2fa", s.getTwoFactor)
+		routes.Post("/2fa", s.enrollTwoFactor)
+		routes.Post("/2fa/confirm", s.confirmTwoFactor)
+		routes.With(requireTwoFactor).Delete("/2fa", s.disableTwoFactor)
+	})
+
+	// Ending the own session needs no one-time code: it only takes the access away.
+	router.Post("/auth/logout", s.logout)
+
+	router.Route("/sessions", func(routes chi.Router) {
+		routes.Get("/", s.listSessions)
+		routes.With(requireTwoFactor).Delete("/others", s.revokeOtherSessions)
+		routes.With(requireTwoFactor).Delete("/{id}", s.revokeSession)
+	})
+}
+
+// registerVaultHandlers registers the handlers of the secrets, folders and trash of the user's vault.
+func (s *Server) registerVaultHandlers(router chi.Router) {
+	router.Route("/secrets", func(routes chi.Router) {
+		routes.Get("/", s.listSecrets)
+		routes.Post("/", s.createSecret)
+		routes.Post("/search", s.searchSecrets)
+		routes.Get("/{id}", s.getSecret)
+		routes.With(middleware.Streaming(s.logger)).Get("/{id}/blob", s.downloadSecretBlob)
+		routes.Put("/{id}", s.updateSecret)
+		routes.Delete("/{id}", s.deleteSecret)
+		routes.Get("/{id}/versions", s.listSecretVersions)
+		routes.Post("/{id}/versions/{rev}/restore", s.restoreSecretVersion)
+		routes.Post("/{id}/push", s.pushSecret)
+		routes.Post("/{id}/move", s.moveSecret)
+	})
+
+	router.Route("/folders", func(routes chi.Router) {
+		routes.Post("/", s.createFolder)
+		routes.Get("/tree", s.getFolderTree)
+		routes.Post("/{id}/rename", s.renameFolder)
+		routes.Post("/{id}/move", s.moveFolder)
+		routes.Delete("/{id}", s.deleteFolder)
+	})
+
+	router.Route("/trash", func(routes chi.Router) {
+		routes.Get("/", s.listTrash)
+		routes.Post("/{id}/restore", s.restoreTrashedSecret)
+	})
+}
+
 const tempRandValue = 400
 
 func (s *Server) ping(w http.ResponseWriter, r *http.Request) {
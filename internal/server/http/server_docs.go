@@ -3,11 +3,747 @@
 package server
 
 // Ping godoc
-// @Summary      Пинг сервиса
-// @Description  Возвращает "pong" для проверки доступности сервиса.
-// @Tags         health
-// @Produce      plain
-// @Success      200  {string}  string  "pong"
-// @Failure      405  {string}  string  "method not allowed"
-// @Failure      500  {string}  string  "internal server error"
-// @Router       /ping [get]
+//	@Summary		Пинг сервиса
+//	@Description	Возвращает "pong" для проверки доступности сервиса.
+//	@Tags			health
+//	@Produce		plain
+//	@Success		200	{string}	string	"pong"
+//	@Failure		405	{string}	string	"method not allowed"
+//	@Failure		500	{string}	string	"internal server error"
+//	@Router			/ping [get]
+
+// Register godoc
+//	@Summary		Регистрация пользователя
+//	@Description	Создаёт учётную запись с обёрнутым на клиенте ключом хранилища.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		RegisterRequest	true	"Учётные данные и ключ хранилища"
+//	@Success		201		{object}	UserResponse
+//	@Failure		400		{object}	ErrorResponse	"invalid request"
+//	@Failure		409		{object}	ErrorResponse	"user already exists"
+//	@Failure		500		{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/auth/register [post]
+
+// Prelogin godoc
+//	@Summary		Параметры KDF
+//	@Description	Возвращает параметры вывода мастер-ключа для логина.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		PreloginRequest	true	"Логин"
+//	@Success		200		{object}	PreloginResponse
+//	@Failure		400		{object}	ErrorResponse	"invalid request"
+//	@Failure		500		{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/auth/prelogin [post]
+
+// Login godoc
+//	@Summary		Вход пользователя
+//	@Description	Проверяет учётные данные пользователя и выдаёт пару токенов доступа и обновления.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request				body		CredentialsRequest	true	"Учётные данные"
+//	@Param			X-Client-Name		header		string				false	"Название клиента"
+//	@Param			X-Client-Version	header		string				false	"Версия клиента"
+//	@Success		200					{object}	LoginResponse
+//	@Success		202					{object}	TwoFactorChallengeResponse	"one-time code required"
+//	@Failure		400					{object}	ErrorResponse				"invalid request"
+//	@Failure		401					{object}	ErrorResponse				"invalid credentials"
+//	@Failure		500					{object}	ErrorResponse				"internal server error"
+//	@Router			/api/v1/auth/login [post]
+
+// LoginTwoFactor godoc
+//	@Summary		Вход с одноразовым кодом
+//	@Description	Завершает вход по одноразовому коду 2FA и выдаёт пару токенов.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request				body		LoginTwoFactorRequest	true	"Токен входа и код"
+//	@Param			X-Client-Name		header		string					false	"Название клиента"
+//	@Param			X-Client-Version	header		string					false	"Версия клиента"
+//	@Success		200					{object}	LoginResponse
+//	@Failure		400					{object}	ErrorResponse	"invalid request"
+//	@Failure		401					{object}	ErrorResponse	"invalid challenge or code"
+//	@Failure		429					{object}	ErrorResponse	"too many invalid one-time codes"
+//	@Failure		500					{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/auth/login/2fa [post]
+
+// Refresh godoc
+//	@Summary		Обновление токенов
+//	@Description	Обменивает одноразовый токен обновления на новую пару токенов.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request				body		RefreshRequest	true	"Токен обновления"
+//	@Param			X-Client-Name		header		string			false	"Название клиента"
+//	@Param			X-Client-Version	header		string			false	"Версия клиента"
+//	@Success		200					{object}	TokenResponse
+//	@Failure		400					{object}	ErrorResponse	"invalid request"
+//	@Failure		401					{object}	ErrorResponse	"invalid or reused refresh token"
+//	@Failure		500					{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/auth/refresh [post]
+
+// Logout godoc
+//	@Summary		Выход
+//	@Description	Отзывает текущую сессию. Её токены перестают действовать сразу.
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Success		204
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/auth/logout [post]
+
+// RecoveryPrelogin godoc
+//	@Summary		Копия ключа для кода восстановления
+//	@Description	Возвращает ключ хранилища, обёрнутый кодом восстановления.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		RecoveryPreloginRequest	true	"Логин и идентификатор кода"
+//	@Success		200		{object}	RecoveryPreloginResponse
+//	@Failure		400		{object}	ErrorResponse	"invalid request"
+//	@Failure		401		{object}	ErrorResponse	"invalid or used recovery code"
+//	@Failure		500		{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/auth/recovery/prelogin [post]
+
+// Recover godoc
+//	@Summary		Восстановление доступа
+//	@Description	Задаёт новый мастер-пароль по коду восстановления.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request				body		RecoverRequest	true	"Код и новые учётные данные"
+//	@Param			X-Client-Name		header		string			false	"Название клиента"
+//	@Param			X-Client-Version	header		string			false	"Версия клиента"
+//	@Success		200					{object}	RecoverResponse
+//	@Failure		400					{object}	ErrorResponse	"invalid request"
+//	@Failure		401					{object}	ErrorResponse	"invalid or used recovery code"
+//	@Failure		500					{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/auth/recovery [post]
+
+// ListSecrets godoc
+//	@Summary		Список секретов
+//	@Description	Возвращает страницу секретов пользователя без содержимого с фильтрами и сортировкой.
+//	@Tags			secrets
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			cursor			query		string	false	"Курсор следующей страницы из предыдущего ответа"
+//	@Param			limit			query		integer	false	"Размер страницы (по умолчанию 100, не более 500)"
+//	@Param			sort			query		string	false	"Поле сортировки"			Enums(createdAt, updatedAt)	default(createdAt)
+//	@Param			order			query		string	false	"Направление сортировки"	Enums(asc, desc)			default(asc)
+//	@Param			type			query		string	false	"Тип секрета"				Enums(credentials, text, binary, card, totp)
+//	@Param			tag				query		string	false	"Тег секрета"
+//	@Param			folderId		query		string	false	"Идентификатор папки"
+//	@Param			updatedSince	query		string	false	"Изменённые не раньше этого времени (RFC 3339)"	Format(date-time)
+//	@Success		200				{object}	SecretListResponse
+//	@Failure		400				{object}	ErrorResponse	"invalid query parameter"
+//	@Failure		401				{object}	ErrorResponse	"unauthorized"
+//	@Failure		500				{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/secrets [get]
+
+// SearchSecrets godoc
+//	@Summary		Поиск секретов по слепым индексам
+//	@Description	Возвращает секреты пользователя без содержимого со всеми указанными индексами.
+//	@Tags			secrets
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		SearchSecretsRequest	true	"Слепые индексы, вычисленные на клиенте"
+//	@Param			cursor	query		string					false	"Курсор следующей страницы из предыдущего ответа"
+//	@Param			limit	query		integer					false	"Размер страницы (по умолчанию 100, не более 500)"
+//	@Param			sort	query		string					false	"Поле сортировки"			Enums(createdAt, updatedAt)	default(createdAt)
+//	@Param			order	query		string					false	"Направление сортировки"	Enums(asc, desc)			default(asc)
+//	@Success		200		{object}	SecretListResponse
+//	@Failure		400		{object}	ErrorResponse	"invalid request"
+//	@Failure		401		{object}	ErrorResponse	"unauthorized"
+//	@Failure		500		{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/secrets/search [post]
+
+// CreateSecret godoc
+//	@Summary		Создание секрета
+//	@Description	Создаёт секрет с зашифрованным на клиенте содержимым.
+//	@Tags			secrets
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		SecretRequest	true	"Секрет"
+//	@Success		201		{object}	SecretResponse
+//	@Header			201		{string}	ETag			"Номер ревизии секрета"
+//	@Failure		400		{object}	ErrorResponse	"invalid request"
+//	@Failure		401		{object}	ErrorResponse	"unauthorized"
+//	@Failure		500		{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/secrets [post]
+
+// GetSecret godoc
+//	@Summary		Получение секрета
+//	@Description	Возвращает секрет пользователя вместе с зашифрованным содержимым.
+//	@Tags			secrets
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		string	true	"Идентификатор секрета"
+//	@Success		200	{object}	SecretResponse
+//	@Header			200	{string}	ETag			"Номер ревизии секрета"
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		404	{object}	ErrorResponse	"secret not found"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/secrets/{id} [get]
+
+// DownloadSecretBlob godoc
+//	@Summary		Скачивание бинарного секрета
+//	@Description	Потоково отдаёт зашифрованное на клиенте содержимое бинарного секрета.
+//	@Tags			secrets
+//	@Security		BearerAuth
+//	@Produce		octet-stream
+//	@Param			id	path		string	true	"Идентификатор секрета"
+//	@Success		200	{file}		binary
+//	@Header			200	{string}	ETag			"Номер ревизии секрета"
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		404	{object}	ErrorResponse	"secret or payload not found"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/secrets/{id}/blob [get]
+
+// UpdateSecret godoc
+//	@Summary		Изменение секрета
+//	@Description	Заменяет секрет, если он не изменился с ревизии из If-Match.
+//	@Tags			secrets
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string			true	"Идентификатор секрета"
+//	@Param			If-Match	header		string			true	"ETag ожидаемой ревизии или *"
+//	@Param			request		body		SecretRequest	true	"Секрет"
+//	@Success		200			{object}	SecretResponse
+//	@Header			200			{string}	ETag			"Номер ревизии секрета"
+//	@Failure		400			{object}	ErrorResponse	"invalid request"
+//	@Failure		401			{object}	ErrorResponse	"unauthorized"
+//	@Failure		404			{object}	ErrorResponse	"secret not found"
+//	@Failure		412			{object}	ErrorResponse	"secret has been modified"
+//	@Failure		428			{object}	ErrorResponse	"If-Match header is required"
+//	@Failure		500			{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/secrets/{id} [put]
+
+// DeleteSecret godoc
+//	@Summary		Удаление секрета
+//	@Description	Перемещает секрет в корзину, если он не изменился с ревизии из If-Match.
+//	@Tags			secrets
+//	@Security		BearerAuth
+//	@Param			id			path	string	true	"Идентификатор секрета"
+//	@Param			If-Match	header	string	true	"ETag ожидаемой ревизии или *"
+//	@Success		204
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		404	{object}	ErrorResponse	"secret not found"
+//	@Failure		412	{object}	ErrorResponse	"secret has been modified"
+//	@Failure		428	{object}	ErrorResponse	"If-Match header is required"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/secrets/{id} [delete]
+
+// ListSecretVersions godoc
+//	@Summary		История секрета
+//	@Description	Возвращает предыдущие ревизии секрета, новые первыми.
+//	@Tags			secrets
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		string	true	"Идентификатор секрета"
+//	@Success		200	{object}	SecretVersionListResponse
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		404	{object}	ErrorResponse	"secret not found"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/secrets/{id}/versions [get]
+
+// RestoreSecretVersion godoc
+//	@Summary		Восстановление ревизии
+//	@Description	Делает предыдущую ревизию текущей, заменённая остаётся в истории.
+//	@Tags			secrets
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id			path		string	true	"Идентификатор секрета"
+//	@Param			rev			path		integer	true	"Номер ревизии"
+//	@Param			If-Match	header		string	true	"ETag ожидаемой текущей ревизии или *"
+//	@Success		200			{object}	SecretResponse
+//	@Header			200			{string}	ETag			"Номер ревизии секрета"
+//	@Failure		401			{object}	ErrorResponse	"unauthorized"
+//	@Failure		404			{object}	ErrorResponse	"secret or revision not found"
+//	@Failure		412			{object}	ErrorResponse	"secret has been modified"
+//	@Failure		428			{object}	ErrorResponse	"If-Match header is required"
+//	@Failure		500			{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/secrets/{id}/versions/{rev}/restore [post]
+
+// ListTrash godoc
+//	@Summary		Корзина
+//	@Description	Возвращает страницу удалённых секретов пользователя, недавно удалённые первыми.
+//	@Tags			trash
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			cursor	query		string	false	"Курсор следующей страницы из предыдущего ответа"
+//	@Param			limit	query		integer	false	"Размер страницы (по умолчанию 100, не более 500)"
+//	@Success		200		{object}	TrashListResponse
+//	@Failure		400		{object}	ErrorResponse	"invalid query parameter"
+//	@Failure		401		{object}	ErrorResponse	"unauthorized"
+//	@Failure		500		{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/trash [get]
+
+// RestoreTrashedSecret godoc
+//	@Summary		Восстановление из корзины
+//	@Description	Возвращает удалённый секрет вместе с историей из корзины.
+//	@Tags			trash
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		string	true	"Идентификатор секрета"
+//	@Success		200	{object}	SecretResponse
+//	@Header			200	{string}	ETag			"Номер ревизии секрета"
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		404	{object}	ErrorResponse	"secret not found in trash"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/trash/{id}/restore [post]
+
+// MoveSecret godoc
+//	@Summary		Перемещение секрета
+//	@Description	Перемещает секрет в папку, если он не изменился с ревизии из If-Match.
+//	@Tags			folders
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string				true	"Идентификатор секрета"
+//	@Param			If-Match	header		string				true	"ETag ожидаемой ревизии или *"
+//	@Param			request		body		MoveSecretRequest	true	"Новая папка, пустая для корня"
+//	@Success		200			{object}	SecretResponse
+//	@Header			200			{string}	ETag			"Номер ревизии секрета"
+//	@Failure		400			{object}	ErrorResponse	"invalid request"
+//	@Failure		401			{object}	ErrorResponse	"unauthorized"
+//	@Failure		404			{object}	ErrorResponse	"secret or folder not found"
+//	@Failure		412			{object}	ErrorResponse	"secret has been modified"
+//	@Failure		428			{object}	ErrorResponse	"If-Match header is required"
+//	@Failure		500			{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/secrets/{id}/move [post]
+
+// CreateFolder godoc
+//	@Summary		Создание папки
+//	@Description	Создаёт папку с зашифрованным на клиенте именем.
+//	@Tags			folders
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		FolderRequest	true	"Папка"
+//	@Success		201		{object}	FolderResponse
+//	@Failure		400		{object}	ErrorResponse	"invalid request"
+//	@Failure		401		{object}	ErrorResponse	"unauthorized"
+//	@Failure		404		{object}	ErrorResponse	"folder not found"
+//	@Failure		500		{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/folders [post]
+
+// GetFolderTree godoc
+//	@Summary		Дерево папок
+//	@Description	Возвращает папки пользователя с секретами без содержимого.
+//	@Tags			folders
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{object}	FolderTreeResponse
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/folders/tree [get]
+
+// RenameFolder godoc
+//	@Summary		Переименование папки
+//	@Description	Заменяет зашифрованное имя папки.
+//	@Tags			folders
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"Идентификатор папки"
+//	@Param			request	body		RenameFolderRequest	true	"Новое имя"
+//	@Success		200		{object}	FolderResponse
+//	@Failure		400		{object}	ErrorResponse	"invalid request"
+//	@Failure		401		{object}	ErrorResponse	"unauthorized"
+//	@Failure		404		{object}	ErrorResponse	"folder not found"
+//	@Failure		500		{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/folders/{id}/rename [post]
+
+// MoveFolder godoc
+//	@Summary		Перемещение папки
+//	@Description	Перемещает папку с содержимым в другую папку или в корень.
+//	@Tags			folders
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"Идентификатор папки"
+//	@Param			request	body		MoveFolderRequest	true	"Новая родительская папка"
+//	@Success		200		{object}	FolderResponse
+//	@Failure		400		{object}	ErrorResponse	"invalid request"
+//	@Failure		401		{object}	ErrorResponse	"unauthorized"
+//	@Failure		404		{object}	ErrorResponse	"folder not found"
+//	@Failure		500		{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/folders/{id}/move [post]
+
+// DeleteFolder godoc
+//	@Summary		Удаление папки
+//	@Description	Удаляет папку с вложенными папками и перемещает их секреты в корзину.
+//	@Tags			folders
+//	@Security		BearerAuth
+//	@Param			id	path	string	true	"Идентификатор папки"
+//	@Success		204
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		404	{object}	ErrorResponse	"folder not found"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/folders/{id} [delete]
+
+// PushSecret godoc
+//	@Summary		Отправка офлайн-правки
+//	@Description	Применяет правку или сохраняет конфликт, даже если секрет удалён.
+//	@Tags			conflicts
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"Идентификатор секрета"
+//	@Param			request	body		PushSecretRequest	true	"Правка и её базовая ревизия"
+//	@Success		200		{object}	SecretResponse
+//	@Header			200		{string}	ETag			"Номер ревизии секрета"
+//	@Failure		400		{object}	ErrorResponse	"invalid request"
+//	@Failure		401		{object}	ErrorResponse	"unauthorized"
+//	@Failure		409		{object}	ConflictResponse
+//	@Failure		500		{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/secrets/{id}/push [post]
+
+// ListConflicts godoc
+//	@Summary		Список конфликтов
+//	@Description	Возвращает страницу неразрешённых конфликтов офлайн-правок по времени обнаружения.
+//	@Tags			conflicts
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			cursor	query		string	false	"Курсор следующей страницы из предыдущего ответа"
+//	@Param			limit	query		integer	false	"Размер страницы (по умолчанию 100, не более 500)"
+//	@Success		200		{object}	ConflictListResponse
+//	@Failure		400		{object}	ErrorResponse	"invalid query parameter"
+//	@Failure		401		{object}	ErrorResponse	"unauthorized"
+//	@Failure		500		{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/conflicts [get]
+
+// GetConflict godoc
+//	@Summary		Получение конфликта
+//	@Description	Возвращает конфликт вместе с офлайн-правкой клиента.
+//	@Tags			conflicts
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		string	true	"Идентификатор конфликта"
+//	@Success		200	{object}	ConflictResponse
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		404	{object}	ErrorResponse	"conflict not found"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/conflicts/{id} [get]
+
+// ResolveConflict godoc
+//	@Summary		Разрешение конфликта
+//	@Description	Оставляет версию сервера, клиента или объединённую версию.
+//	@Tags			conflicts
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string					true	"Идентификатор конфликта"
+//	@Param			If-Match	header		string					false	"ETag текущей ревизии, кроме resolution=server"
+//	@Param			request		body		ResolveConflictRequest	true	"Выбранная версия"
+//	@Success		201			{object}	SecretResponse			"удалённый секрет создан заново"
+//	@Success		204
+//	@Failure		400	{object}	ErrorResponse	"invalid request"
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		404	{object}	ErrorResponse	"conflict or secret not found"
+//	@Failure		412	{object}	ErrorResponse	"secret has been modified"
+//	@Failure		428	{object}	ErrorResponse	"If-Match header is required"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/conflicts/{id}/resolve [post]
+
+// SyncSecrets godoc
+//	@Summary		Синхронизация хранилища
+//	@Description	Возвращает секреты, созданные, изменённые или удалённые после курсора.
+//	@Tags			secrets
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			cursor	query		string	false	"Курсор предыдущего ответа, пустой для полной выгрузки"
+//	@Param			limit	query		integer	false	"Максимальное число изменений (по умолчанию 100, не более 500)"
+//	@Success		200		{object}	SyncResponse
+//	@Failure		400		{object}	ErrorResponse	"invalid cursor or limit"
+//	@Failure		401		{object}	ErrorResponse	"unauthorized"
+//	@Failure		500		{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/sync [get]
+
+// StreamEvents godoc
+//	@Summary		Поток изменений хранилища
+//	@Description	Передаёт события изменения секретов пользователя в формате Server-Sent Events.
+//	@Tags			secrets
+//	@Security		BearerAuth
+//	@Produce		text/event-stream
+//	@Success		200	{object}	VaultEventResponse	"поток событий, data каждого события"
+//	@Failure		401	{object}	ErrorResponse		"unauthorized"
+//	@Failure		500	{object}	ErrorResponse		"internal server error"
+//	@Router			/api/v1/events [get]
+
+// DescribeUploads godoc
+//	@Summary		Параметры загрузок
+//	@Description	Возвращает версию и расширения протокола tus и максимальный размер загрузки.
+//	@Tags			uploads
+//	@Header			204	{string}	Tus-Version		"Поддерживаемые версии протокола"
+//	@Header			204	{string}	Tus-Extension	"Поддерживаемые расширения"
+//	@Header			204	{integer}	Tus-Max-Size	"Максимальный размер загрузки в байтах"
+//	@Success		204
+//	@Router			/api/v1/uploads [options]
+
+// CreateUpload godoc
+//	@Summary		Создание загрузки
+//	@Description	Начинает возобновляемую загрузку большого содержимого двоичного секрета.
+//	@Tags			uploads
+//	@Security		BearerAuth
+//	@Param			Tus-Resumable	header		string			true	"Версия протокола tus (1.0.0)"
+//	@Param			Upload-Length	header		integer			true	"Размер содержимого в байтах"
+//	@Param			Upload-Metadata	header		string			false	"Метаданные: пары ключа и значения base64"
+//	@Header			201				{string}	Location		"Адрес загрузки"
+//	@Header			201				{string}	Upload-Expires	"Время удаления незавершённой загрузки"
+//	@Success		201
+//	@Failure		400	{object}	ErrorResponse	"invalid Upload-Length or Upload-Metadata"
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		412	{object}	ErrorResponse	"unsupported version of the tus protocol"
+//	@Failure		413	{object}	ErrorResponse	"upload too large"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/uploads [post]
+
+// HeadUpload godoc
+//	@Summary		Состояние загрузки
+//	@Description	Возвращает число полученных байтов, с которого продолжается загрузка.
+//	@Tags			uploads
+//	@Security		BearerAuth
+//	@Param			id				path		string			true	"Идентификатор загрузки"
+//	@Param			Tus-Resumable	header		string			true	"Версия протокола tus (1.0.0)"
+//	@Header			200				{integer}	Upload-Offset	"Число полученных байтов"
+//	@Header			200				{integer}	Upload-Length	"Размер содержимого в байтах"
+//	@Success		200
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		404	{object}	ErrorResponse	"upload not found"
+//	@Failure		412	{object}	ErrorResponse	"unsupported version of the tus protocol"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/uploads/{id} [head]
+
+// PatchUpload godoc
+//	@Summary		Отправка части загрузки
+//	@Description	Дописывает часть содержимого с позиции Upload-Offset.
+//	@Tags			uploads
+//	@Security		BearerAuth
+//	@Accept			application/offset+octet-stream
+//	@Param			id				path		string			true	"Идентификатор загрузки"
+//	@Param			Tus-Resumable	header		string			true	"Версия протокола tus (1.0.0)"
+//	@Param			Upload-Offset	header		integer			true	"Число уже полученных сервером байтов"
+//	@Header			204				{integer}	Upload-Offset	"Число полученных байтов"
+//	@Header			204				{string}	Upload-Expires	"Время удаления незавершённой загрузки"
+//	@Success		204
+//	@Failure		400	{object}	ErrorResponse	"invalid Upload-Offset"
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		404	{object}	ErrorResponse	"upload not found"
+//	@Failure		409	{object}	ErrorResponse	"offset does not match the received content"
+//	@Failure		412	{object}	ErrorResponse	"unsupported version of the tus protocol"
+//	@Failure		413	{object}	ErrorResponse	"chunk exceeds the upload length"
+//	@Failure		415	{object}	ErrorResponse	"unsupported content type"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/uploads/{id} [patch]
+
+// TerminateUpload godoc
+//	@Summary		Отмена загрузки
+//	@Description	Удаляет загрузку вместе с полученными байтами.
+//	@Tags			uploads
+//	@Security		BearerAuth
+//	@Param			id				path	string	true	"Идентификатор загрузки"
+//	@Param			Tus-Resumable	header	string	true	"Версия протокола tus (1.0.0)"
+//	@Success		204
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		404	{object}	ErrorResponse	"upload not found"
+//	@Failure		412	{object}	ErrorResponse	"unsupported version of the tus protocol"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/uploads/{id} [delete]
+
+// ChangePassword godoc
+//	@Summary		Смена мастер-пароля
+//	@Description	Заменяет пароль и обёрнутый ключ хранилища, отзывает другие сессии.
+//	@Tags			account
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request		body		ChangePasswordRequest	true	"Текущий и новый пароли"
+//	@Param			X-Totp-Code	header		string					false	"Одноразовый код 2FA"
+//	@Success		200			{object}	RevokeSessionsResponse
+//	@Failure		400			{object}	ErrorResponse	"invalid request"
+//	@Failure		401			{object}	ErrorResponse	"unauthorized"
+//	@Failure		403			{object}	ErrorResponse	"invalid current password or one-time code"
+//	@Failure		429			{object}	ErrorResponse	"too many invalid one-time codes"
+//	@Failure		500			{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/account/password [post]
+
+// GetRecoveryCodes godoc
+//	@Summary		Состояние кодов восстановления
+//	@Description	Возвращает число выданных и неиспользованных кодов.
+//	@Tags			account
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{object}	RecoveryCodesResponse
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/account/recovery-codes [get]
+
+// RegenerateRecoveryCodes godoc
+//	@Summary		Новые коды восстановления
+//	@Description	Заменяет набор кодов восстановления, старые коды перестают работать.
+//	@Tags			account
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request		body		RegenerateRecoveryCodesRequest	true	"Пароль и копии ключа"
+//	@Param			X-Totp-Code	header		string							false	"Одноразовый код 2FA"
+//	@Success		200			{object}	RecoveryCodesResponse
+//	@Failure		400			{object}	ErrorResponse	"invalid request"
+//	@Failure		401			{object}	ErrorResponse	"unauthorized"
+//	@Failure		403			{object}	ErrorResponse	"invalid current password or one-time code"
+//	@Failure		429			{object}	ErrorResponse	"too many invalid one-time codes"
+//	@Failure		500			{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/account/recovery-codes [put]
+
+// ListAuditEvents godoc
+//	@Summary		Журнал аудита
+//	@Description	Возвращает страницу событий безопасности аккаунта по времени.
+//	@Tags			account
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			cursor	query		string	false	"Курсор следующей страницы из предыдущего ответа"
+//	@Param			limit	query		integer	false	"Размер страницы (по умолчанию 100, не более 500)"
+//	@Success		200		{object}	AuditLogResponse
+//	@Failure		400		{object}	ErrorResponse	"invalid query parameter"
+//	@Failure		401		{object}	ErrorResponse	"unauthorized"
+//	@Failure		500		{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/account/audit-log [get]
+
+// GetTwoFactor godoc
+//	@Summary		Состояние 2FA
+//	@Description	Сообщает, требуется ли одноразовый код для входа и важных операций.
+//	@Tags			account
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{object}	TwoFactorStatusResponse
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/account/2fa [get]
+
+// EnrollTwoFactor godoc
+//	@Summary		Подключение 2FA
+//	@Description	Генерирует секрет TOTP и otpauth:// URI для приложения-аутентификатора.
+//	@Tags			account
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		EnrollTwoFactorRequest	true	"Текущий пароль"
+//	@Success		200		{object}	EnrollTwoFactorResponse
+//	@Failure		400		{object}	ErrorResponse	"invalid request"
+//	@Failure		401		{object}	ErrorResponse	"unauthorized"
+//	@Failure		403		{object}	ErrorResponse	"invalid current password"
+//	@Failure		409		{object}	ErrorResponse	"two-factor authentication already enabled"
+//	@Failure		500		{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/account/2fa [post]
+
+// ConfirmTwoFactor godoc
+//	@Summary		Подтверждение 2FA
+//	@Description	Включает 2FA после проверки кода из приложения-аутентификатора.
+//	@Tags			account
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Param			request	body	ConfirmTwoFactorRequest	true	"Одноразовый код"
+//	@Success		204
+//	@Failure		400	{object}	ErrorResponse	"invalid request or code"
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		409	{object}	ErrorResponse	"not enrolled or already enabled"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/account/2fa/confirm [post]
+
+// DisableTwoFactor godoc
+//	@Summary		Отключение 2FA
+//	@Description	Отключает 2FA, требует действующий одноразовый код.
+//	@Tags			account
+//	@Security		BearerAuth
+//	@Param			X-Totp-Code	header	string	true	"Одноразовый код 2FA"
+//	@Success		204
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		403	{object}	ErrorResponse	"one-time code required"
+//	@Failure		409	{object}	ErrorResponse	"two-factor authentication not enabled"
+//	@Failure		429	{object}	ErrorResponse	"too many invalid one-time codes"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/account/2fa [delete]
+
+// ListSessions godoc
+//	@Summary		Список сессий
+//	@Description	Возвращает страницу активных сессий (устройств) пользователя по времени входа.
+//	@Tags			sessions
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			cursor	query		string	false	"Курсор следующей страницы из предыдущего ответа"
+//	@Param			limit	query		integer	false	"Размер страницы (по умолчанию 100, не более 500)"
+//	@Success		200		{object}	SessionListResponse
+//	@Failure		400		{object}	ErrorResponse	"invalid query parameter"
+//	@Failure		401		{object}	ErrorResponse	"unauthorized"
+//	@Failure		500		{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/sessions [get]
+
+// RevokeOtherSessions godoc
+//	@Summary		Отзыв остальных сессий
+//	@Description	Отзывает все сессии пользователя, кроме текущей.
+//	@Tags			sessions
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			X-Totp-Code	header		string	false	"Одноразовый код 2FA"
+//	@Success		200			{object}	RevokeSessionsResponse
+//	@Failure		401			{object}	ErrorResponse	"unauthorized"
+//	@Failure		403			{object}	ErrorResponse	"one-time code required"
+//	@Failure		429			{object}	ErrorResponse	"too many invalid one-time codes"
+//	@Failure		500			{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/sessions/others [delete]
+
+// RevokeSession godoc
+//	@Summary		Отзыв сессии
+//	@Description	Отзывает сессию пользователя. Её токены перестают действовать сразу.
+//	@Tags			sessions
+//	@Security		BearerAuth
+//	@Param			id			path	string	true	"Идентификатор сессии"
+//	@Param			X-Totp-Code	header	string	false	"Одноразовый код 2FA"
+//	@Success		204
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		403	{object}	ErrorResponse	"one-time code required"
+//	@Failure		404	{object}	ErrorResponse	"session not found"
+//	@Failure		429	{object}	ErrorResponse	"too many invalid one-time codes"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/sessions/{id} [delete]
+
+// StartKeyRotation godoc
+//	@Summary		Ротация мастер-ключа
+//	@Description	Перечитывает ключи и перешифровывает ключи данных.
+//	@Description	Требует токен администратора.
+//	@Tags			admin
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		202	{object}	KeyRotationResponse
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Failure		409	{object}	ErrorResponse	"rotation in progress"
+//	@Failure		500	{object}	ErrorResponse	"internal server error"
+//	@Router			/api/v1/admin/keys/rotation [post]
+
+// GetKeyRotation godoc
+//	@Summary		Состояние ротации мастер-ключа
+//	@Description	Возвращает состояние последней ротации. Требует токен администратора.
+//	@Tags			admin
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{object}	KeyRotationResponse
+//	@Failure		401	{object}	ErrorResponse	"unauthorized"
+//	@Router			/api/v1/admin/keys/rotation [get]
+
+// CheckBlobs godoc
+//	@Summary		Проверка хранилища вложений
+//	@Description	Сверяет ссылки на вложения с содержимым хранилища. Требует токен администратора.
+//	@Tags			admin
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{object}	BlobCheckResponse
+//	@Failure		401	{object}	ErrorResponse		"unauthorized"
+//	@Failure		409	{object}	BlobCheckResponse	"inconsistencies found"
+//	@Failure		500	{object}	ErrorResponse		"internal server error"
+//	@Router			/api/v1/admin/blobs/check [get]
@@ -0,0 +1,333 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/metrics"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/password"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/token"
+	server "github.com/mr-filatik/go-password-keeper/internal/server/http"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/filesystem"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/memory"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+// Restrictions of the test server.
+const (
+	testSecretVersionsLimit = 2         // Number of previous revisions kept for a secret.
+	testUploadMaxSize       = 1 << 20   // Maximum size of an uploaded payload.
+	testTrashRetention      = time.Hour // Time a deleted secret is kept in the trash.
+)
+
+// newTestServer creates an HTTP server with in-memory storages.
+func newTestServer(t *testing.T) http.Handler {
+	t.Helper()
+
+	return newTestServerWithBlobDir(t, t.TempDir())
+}
+
+// newTestServerWithBlobDir creates an HTTP server with in-memory storages
+// keeping binary payloads in the directory.
+func newTestServerWithBlobDir(t *testing.T, blobDir string) http.Handler {
+	t.Helper()
+
+	return newTestServerWithAdmin(
+		t,
+		blobDir,
+		server.AdminConfig{Token: "", KeyRotator: nil, BlobChecker: nil},
+	)
+}
+
+// newTestServerWithAdmin creates an HTTP server with in-memory storages
+// keeping binary payloads in the directory and the maintenance endpoints of the administrator.
+func newTestServerWithAdmin(t *testing.T, blobDir string, admin server.AdminConfig) http.Handler {
+	t.Helper()
+
+	logger := newTestLogger(t)
+	hasher := newTestHasher()
+	tokens := newTestTokenManager(t)
+	sessionStore := memory.NewSessionStore()
+	sessionService := service.NewSessionService(sessionStore, logger)
+	users := memory.NewUserRepository()
+	recoveryCodes := memory.NewRecoveryCodeRepository()
+	audit := service.NewAuditService(memory.NewAuditLog(), logger)
+	secrets := memory.NewSecretRepository()
+	folders := memory.NewFolderRepository()
+	uploads := newTestUploadService(t, logger)
+	secretService, events := newTestSecretService(t, secrets, folders, blobDir, uploads, logger)
+	conflicts := service.NewConflictService(
+		memory.NewSecretConflictRepository(),
+		secretService,
+		logger,
+	)
+	twoFactor := newTestTwoFactorService(users, hasher, audit, logger)
+
+	return server.NewServer(server.ServerConfig{
+		Address:         ":0",
+		MetricsProvider: newTestMetrics(),
+		AuthService: service.NewAuthService(
+			users,
+			recoveryCodes,
+			hasher,
+			tokens,
+			service.RefreshTokenConfig{Store: sessionStore, TTL: time.Hour},
+			twoFactor,
+			logger,
+		),
+		SecretService:   secretService,
+		TrashService:    service.NewTrashService(secrets, testTrashRetention, events, logger),
+		FolderService:   service.NewFolderService(folders, secretService, logger),
+		SyncService:     service.NewSyncService(secrets, logger),
+		EventSubscriber: events,
+		UploadService:   uploads,
+		ConflictService: conflicts,
+		SessionService:  sessionService,
+		AccountService:  service.NewAccountService(users, hasher, sessionService, logger),
+		RecoveryService: service.NewRecoveryService(
+			users,
+			recoveryCodes,
+			hasher,
+			sessionService,
+			twoFactor,
+			audit,
+			logger,
+		),
+		AuditService:     audit,
+		TwoFactorService: twoFactor,
+		TokenVerifier:    tokens,
+		SessionChecker:   sessionService,
+		Admin:            admin,
+	}, logger).Handler()
+}
+
+// newTestLogger creates a logger that discards the records.
+func newTestLogger(t *testing.T) *logging.ZapSugarLogger {
+	t.Helper()
+
+	logger, err := logging.NewZapSugarLogger(logging.LevelError, io.Discard, logging.FormatJSON)
+	require.NoError(t, err)
+
+	return logger
+}
+
+// newTestSecretService creates the secret service over the storages with a short history,
+// binary payloads in the directory and the in-process broker its change events are published to.
+func newTestSecretService(
+	t *testing.T,
+	secrets *memory.SecretRepository,
+	folders *memory.FolderRepository,
+	blobDir string,
+	uploads *service.UploadService,
+	logger logging.Logger,
+) (*service.SecretService, *memory.EventBroker) {
+	t.Helper()
+
+	blobs, err := filesystem.NewBlobStore(blobDir)
+	require.NoError(t, err)
+
+	events := memory.NewEventBroker()
+
+	return service.NewSecretService(
+		secrets,
+		folders,
+		service.SecretRetention{Limit: testSecretVersionsLimit, MaxAge: time.Hour},
+		service.NewBlobService(blobs, secrets, nil, logger),
+		uploads,
+		events,
+		logger,
+	), events
+}
+
+// newTestTwoFactorService creates the two-factor authentication service of the users.
+func newTestTwoFactorService(
+	users *memory.UserRepository,
+	hasher *password.Argon2idHasher,
+	audit *service.AuditService,
+	logger logging.Logger,
+) *service.TwoFactorService {
+	return service.NewTwoFactorService(
+		memory.NewTwoFactorRepository(),
+		users,
+		hasher,
+		audit,
+		logger,
+	)
+}
+
+// newTestUploadService creates the upload service over a temporary directory.
+func newTestUploadService(t *testing.T, logger logging.Logger) *service.UploadService {
+	t.Helper()
+
+	uploads, err := filesystem.NewUploadRepository(t.TempDir())
+	require.NoError(t, err)
+
+	return service.NewUploadService(
+		uploads,
+		service.UploadConfig{MaxSize: testUploadMaxSize, TTL: time.Hour},
+		logger,
+	)
+}
+
+// newTestTokenManager creates an access token manager with a fixed secret.
+func newTestTokenManager(t *testing.T) *token.Manager {
+	t.Helper()
+
+	tokens, err := token.NewHMACManager(
+		[]byte("0123456789abcdef0123456789abcdef"),
+		"test",
+		time.Minute,
+	)
+	require.NoError(t, err)
+
+	return tokens
+}
+
+// newTestMetrics creates a metrics provider with its own registry.
+func newTestMetrics() *metrics.Provider {
+	base := metrics.NewBaseMetrics("test", nil).SetRegisterer(prometheus.NewRegistry())
+
+	return &metrics.Provider{
+		HTTP:       metrics.NewHTTPMetrics(*base),
+		Experiment: metrics.NewExperimentMetrics(*base),
+	}
+}
+
+// newTestHasher creates a password hasher with minimal parameters to keep the tests fast.
+func newTestHasher() *password.Argon2idHasher {
+	return password.NewArgon2idHasher(password.Argon2idParams{
+		Memory:      1024,
+		Iterations:  1,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	})
+}
+
+// newRegisterRequest creates a registration request with a wrapped vault key
+// and a single recovery code.
+func newRegisterRequest(t *testing.T, login string, password string) server.RegisterRequest {
+	t.Helper()
+
+	return server.RegisterRequest{
+		Login:        login,
+		Password:     password,
+		VaultKey:     newWrappedVaultKey(t),
+		RecoveryKeys: []crypto.RecoveryKey{newRecoveryKey(t, newRecoveryCode(t))},
+	}
+}
+
+// newWrappedVaultKey creates a wrapped vault key.
+//
+// The server cannot unwrap the key, so it is wrapped with a random key instead of
+// the one derived from the master password to keep the tests fast.
+func newWrappedVaultKey(t *testing.T) crypto.WrappedVaultKey {
+	t.Helper()
+
+	salt, err := crypto.NewSalt()
+	require.NoError(t, err)
+
+	vaultKey, err := crypto.GenerateVaultKey()
+	require.NoError(t, err)
+
+	wrappedKey, err := newTestVault(t).Seal(vaultKey, nil)
+	require.NoError(t, err)
+
+	return crypto.WrappedVaultKey{
+		KDF:        crypto.DefaultKDFParams(salt),
+		WrappedKey: wrappedKey,
+	}
+}
+
+// newTestVault creates a vault with a random key, like the client does with the vault key.
+func newTestVault(t *testing.T) *crypto.Vault {
+	t.Helper()
+
+	key, err := crypto.GenerateVaultKey()
+	require.NoError(t, err)
+
+	vault, err := crypto.NewVault(key)
+	require.NoError(t, err)
+
+	return vault
+}
+
+// doJSON executes the request with the JSON body and returns the response.
+//
+// If accessToken is not empty, it is passed in the Authorization header.
+func doJSON(
+	t *testing.T,
+	handler http.Handler,
+	method string,
+	target string,
+	body any,
+	accessToken string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	return doJSONWithHeaders(t, handler, method, target, body, accessToken, nil)
+}
+
+// doJSONWithHeaders executes the request with the JSON body and additional headers.
+func doJSONWithHeaders(
+	t *testing.T,
+	handler http.Handler,
+	method string,
+	target string,
+	body any,
+	accessToken string,
+	headers map[string]string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reader io.Reader = http.NoBody
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, target, reader)
+	req.Header.Set("Content-Type", "application/json")
+
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+// decodeBody decodes the JSON response body into dst.
+func decodeBody(t *testing.T, rec *httptest.ResponseRecorder, dst any) {
+	t.Helper()
+
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), dst), "body: %s", rec.Body.String())
+}
+
+// registerAndLogin registers the user and returns the access token.
+func registerAndLogin(t *testing.T, handler http.Handler, login string) string {
+	t.Helper()
+
+	resp := loginTokens(t, handler, login)
+	require.NotEmpty(t, resp.AccessToken)
+
+	return resp.AccessToken
+}
@@ -0,0 +1,158 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mr-filatik/go-password-keeper/internal/server/http/middleware"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+)
+
+// SessionService describes the functionality of managing the user's sessions.
+type SessionService interface {
+	// ListPage returns a page of the active sessions of the user.
+	ListPage(
+		ctx context.Context,
+		userID string,
+		query model.PageQuery,
+	) (*service.Page[*model.Session], error)
+
+	// Revoke revokes the user's session.
+	Revoke(ctx context.Context, userID string, sessionID string) error
+
+	// RevokeOthers revokes all the user's sessions except the current one.
+	RevokeOthers(ctx context.Context, userID string, currentSessionID string) (int, error)
+}
+
+// SessionResponse describes the session (logged-in device) returned to the client.
+type SessionResponse struct {
+	ID            string    `json:"id"`            // Session identifier.
+	ClientName    string    `json:"clientName"`    // Client application name.
+	ClientVersion string    `json:"clientVersion"` // Client application version.
+	IP            string    `json:"ip"`            // Client IP address.
+	Current       bool      `json:"current"`       // The request was made from this session.
+	CreatedAt     time.Time `json:"createdAt"`     // Login time.
+	LastSeenAt    time.Time `json:"lastSeenAt"`    // Time of the last login or token refresh.
+	ExpiresAt     time.Time `json:"expiresAt"`     // Expiration time if tokens are not refreshed.
+}
+
+// SessionListResponse describes a page of the list of sessions returned to the client.
+type SessionListResponse struct {
+	Items   []SessionResponse `json:"items"`            // Active sessions.
+	Cursor  string            `json:"cursor,omitempty"` // Opaque cursor of the next page, omitted on the last page.
+	HasMore bool              `json:"hasMore"`          // More sessions are available with the cursor.
+}
+
+// RevokeSessionsResponse describes the result of revoking other sessions.
+type RevokeSessionsResponse struct {
+	Revoked int `json:"revoked"` // Number of revoked sessions.
+}
+
+func newSessionResponse(session *model.Session, currentSessionID string) SessionResponse {
+	return SessionResponse{
+		ID:            session.ID,
+		ClientName:    session.Client.Name,
+		ClientVersion: session.Client.Version,
+		IP:            session.Client.IP,
+		Current:       session.ID == currentSessionID,
+		CreatedAt:     session.CreatedAt,
+		LastSeenAt:    session.LastSeenAt,
+		ExpiresAt:     session.ExpiresAt,
+	}
+}
+
+func (s *Server) listSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	query, err := parsePageQuery(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	page, err := s.sessions.ListPage(r.Context(), userID, *query)
+	if err != nil {
+		s.writeInternalError(w, err)
+
+		return
+	}
+
+	currentSessionID := middleware.SessionIDFromContext(r.Context())
+
+	resp := SessionListResponse{
+		Items:   make([]SessionResponse, 0, len(page.Items)),
+		Cursor:  encodePageCursor(page.Next),
+		HasMore: page.Next != nil,
+	}
+
+	for _, session := range page.Items {
+		resp.Items = append(resp.Items, newSessionResponse(session, currentSessionID))
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) revokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.sessions.Revoke(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			s.writeError(w, http.StatusNotFound, errCodeNotFound, "session not found")
+
+			return
+		}
+
+		s.writeInternalError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.sessions.Revoke(r.Context(), userID, middleware.SessionIDFromContext(r.Context()))
+	if err != nil && !errors.Is(err, service.ErrSessionNotFound) {
+		s.writeInternalError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) revokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	currentSessionID := middleware.SessionIDFromContext(r.Context())
+
+	revoked, err := s.sessions.RevokeOthers(r.Context(), userID, currentSessionID)
+	if err != nil {
+		s.writeInternalError(w, err)
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, RevokeSessionsResponse{Revoked: revoked})
+}
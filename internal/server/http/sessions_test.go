@@ -0,0 +1,159 @@
+package http_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	server "github.com/mr-filatik/go-password-keeper/internal/server/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loginDevice logs the already registered user "alice" in from the named client.
+func loginDevice(t *testing.T, handler http.Handler, client string) server.LoginResponse {
+	t.Helper()
+
+	body := mustJSON(t, server.CredentialsRequest{Login: "alice", Password: "correct-horse"})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(server.HeaderClientName, client)
+	req.Header.Set(server.HeaderClientVersion, "1.2.3")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp server.LoginResponse
+
+	decodeBody(t, rec, &resp)
+
+	return resp
+}
+
+// listSessions returns the sessions of the user.
+func listSessions(t *testing.T, handler http.Handler, accessToken string) []server.SessionResponse {
+	t.Helper()
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/sessions", nil, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp server.SessionListResponse
+
+	decodeBody(t, rec, &resp)
+
+	return resp.Items
+}
+
+func TestServer_ListSessions(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	_ = registerAndLogin(t, handler, "alice")
+	laptop := loginDevice(t, handler, "laptop")
+
+	sessions := listSessions(t, handler, laptop.AccessToken)
+	require.Len(t, sessions, 2)
+
+	current := sessions[1]
+	assert.Equal(t, laptop.SessionID, current.ID)
+	assert.True(t, current.Current)
+	assert.False(t, sessions[0].Current)
+	assert.Equal(t, "laptop", current.ClientName)
+	assert.Equal(t, "1.2.3", current.ClientVersion)
+	assert.NotEmpty(t, current.IP)
+	assert.False(t, current.LastSeenAt.IsZero())
+}
+
+func TestServer_RevokeSession(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	phone := loginTokens(t, handler, "alice")
+	laptop := loginDevice(t, handler, "laptop")
+
+	rec := doJSON(t, handler, http.MethodDelete, "/api/v1/sessions/"+laptop.SessionID, nil,
+		phone.AccessToken)
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets", nil, laptop.AccessToken)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "access token must stop working at once")
+
+	rec = refreshTokens(t, handler, laptop.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "refresh token must stop working")
+
+	sessions := listSessions(t, handler, phone.AccessToken)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, phone.SessionID, sessions[0].ID)
+
+	rec = doJSON(t, handler, http.MethodDelete, "/api/v1/sessions/"+laptop.SessionID, nil,
+		phone.AccessToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_Logout(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	phone := loginTokens(t, handler, "alice")
+	laptop := loginDevice(t, handler, "laptop")
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/logout", nil, laptop.AccessToken)
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets", nil, laptop.AccessToken)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "access token must stop working at once")
+
+	rec = refreshTokens(t, handler, laptop.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "refresh token must stop working")
+
+	sessions := listSessions(t, handler, phone.AccessToken)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, phone.SessionID, sessions[0].ID, "other sessions must stay active")
+}
+
+func TestServer_RevokeOtherSessions(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	phone := loginTokens(t, handler, "alice")
+	laptop := loginDevice(t, handler, "laptop")
+	desktop := loginDevice(t, handler, "desktop")
+	stranger := loginTokens(t, handler, "bob")
+
+	rec := doJSON(t, handler, http.MethodDelete, "/api/v1/sessions/others", nil, phone.AccessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp server.RevokeSessionsResponse
+
+	decodeBody(t, rec, &resp)
+	assert.Equal(t, 2, resp.Revoked)
+
+	for _, accessToken := range []string{laptop.AccessToken, desktop.AccessToken} {
+		rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets", nil, accessToken)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	}
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets", nil, phone.AccessToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets", nil, stranger.AccessToken)
+	assert.Equal(t, http.StatusOK, rec.Code, "sessions of other users must not be affected")
+}
+
+func TestServer_RevokeForeignSession(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	owner := loginTokens(t, handler, "alice")
+	stranger := loginTokens(t, handler, "bob")
+
+	rec := doJSON(t, handler, http.MethodDelete, "/api/v1/sessions/"+owner.SessionID, nil,
+		stranger.AccessToken)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets", nil, owner.AccessToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
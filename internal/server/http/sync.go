@@ -0,0 +1,126 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+)
+
+// SyncService describes the functionality of the incremental synchronization of the user's vault.
+type SyncService interface {
+	// Changes returns the secrets created, updated or deleted after the cursor.
+	Changes(
+		ctx context.Context,
+		userID string,
+		cursor int64,
+		limit int,
+	) (*service.SyncResult, error)
+}
+
+// SyncChangeResponse describes the change of a secret returned to the client.
+type SyncChangeResponse struct {
+	SecretID  string          `json:"secretId"`         // Secret identifier.
+	Revision  int64           `json:"revision"`         // Revision written by the change.
+	Deleted   bool            `json:"deleted"`          // The secret has been deleted (tombstone).
+	ChangedAt time.Time       `json:"changedAt"`        // Time of the change.
+	Secret    *SecretResponse `json:"secret,omitempty"` // Current state of the secret, omitted for tombstones.
+}
+
+// SyncResponse describes a page of the changes of the user's vault.
+type SyncResponse struct {
+	Changes []SyncChangeResponse `json:"changes"` // Changes in the order they must be applied.
+	Cursor  string               `json:"cursor"`  // Opaque cursor of the next request.
+	HasMore bool                 `json:"hasMore"` // More changes are available right away.
+}
+
+func newSyncChangeResponse(change service.SyncChange) SyncChangeResponse {
+	resp := SyncChangeResponse{
+		SecretID:  change.SecretID,
+		Revision:  change.Revision,
+		Deleted:   change.Deleted,
+		ChangedAt: change.ChangedAt,
+		Secret:    nil,
+	}
+
+	if change.Secret != nil {
+		secret := newSecretResponse(change.Secret, true)
+		resp.Secret = &secret
+	}
+
+	return resp
+}
+
+// encodeSyncCursor converts the position of the change log to the opaque cursor.
+func encodeSyncCursor(seq int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(seq, 10)))
+}
+
+// decodeSyncCursor converts the opaque cursor to the position of the change log,
+// an empty cursor is the beginning of the log.
+func decodeSyncCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, service.ErrInvalidSyncCursor
+	}
+
+	seq, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil || seq < 0 {
+		return 0, service.ErrInvalidSyncCursor
+	}
+
+	return seq, nil
+}
+
+func (s *Server) syncSecrets(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	query, err := parseListQuery(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	cursor, err := decodeSyncCursor(query.cursor)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid cursor")
+
+		return
+	}
+
+	result, err := s.sync.Changes(r.Context(), userID, cursor, query.limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSyncCursor) {
+			s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid cursor")
+		} else {
+			s.writeInternalError(w, err)
+		}
+
+		return
+	}
+
+	resp := SyncResponse{
+		Changes: make([]SyncChangeResponse, 0, len(result.Changes)),
+		Cursor:  encodeSyncCursor(result.Cursor),
+		HasMore: result.HasMore,
+	}
+
+	for _, change := range result.Changes {
+		resp.Changes = append(resp.Changes, newSyncChangeResponse(change))
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,137 @@
+package http_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	server "github.com/mr-filatik/go-password-keeper/internal/server/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncSecrets requests the changes of the vault after the cursor.
+func syncSecrets(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+	cursor string,
+	limit string,
+) server.SyncResponse {
+	t.Helper()
+
+	query := url.Values{}
+	query.Set("cursor", cursor)
+	query.Set("limit", limit)
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/sync?"+query.Encode(), nil, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp server.SyncResponse
+
+	decodeBody(t, rec, &resp)
+
+	return resp
+}
+
+// createTextSecret creates a text note of the user.
+func createTextSecret(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+	value string,
+) server.SecretResponse {
+	t.Helper()
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/secrets", server.SecretRequest{
+		FolderID: "",
+		Type:     secret.TypeText,
+		Metadata: nil,
+		Tags:     nil,
+		Data:     encryptPayload(t, &secret.Text{Value: value}),
+		UploadID: "",
+		Indexes:  nil,
+	}, accessToken)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created server.SecretResponse
+
+	decodeBody(t, rec, &created)
+
+	return created
+}
+
+func TestServer_Sync(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+	stranger := registerAndLogin(t, handler, "bob")
+
+	first := createTextSecret(t, handler, accessToken, "first")
+	second := createTextSecret(t, handler, accessToken, "second")
+	third := createTextSecret(t, handler, accessToken, "third")
+
+	page := syncSecrets(t, handler, accessToken, "", "2")
+	require.Len(t, page.Changes, 2)
+	assert.True(t, page.HasMore)
+	assert.Equal(t, first.ID, page.Changes[0].SecretID)
+	assert.Equal(t, second.ID, page.Changes[1].SecretID)
+	require.NotNil(t, page.Changes[0].Secret)
+	assert.Equal(t, first, *page.Changes[0].Secret)
+
+	page = syncSecrets(t, handler, accessToken, page.Cursor, "2")
+	require.Len(t, page.Changes, 1)
+	assert.False(t, page.HasMore)
+	assert.Equal(t, third.ID, page.Changes[0].SecretID)
+
+	// Another machine of the user changes the vault.
+	updateSecretText(
+		t,
+		handler,
+		accessToken,
+		first.ID,
+		encryptPayload(t, &secret.Text{Value: "edited"}),
+	)
+
+	rec := doJSONWithHeaders(t, handler, http.MethodDelete, "/api/v1/secrets/"+second.ID, nil,
+		accessToken, ifMatch("*"))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	page = syncSecrets(t, handler, accessToken, page.Cursor, "")
+	require.Len(t, page.Changes, 2)
+	assert.False(t, page.HasMore)
+	assert.Equal(t, first.ID, page.Changes[0].SecretID)
+	assert.Equal(t, int64(2), page.Changes[0].Revision)
+	assert.False(t, page.Changes[0].Deleted)
+	assert.Equal(t, second.ID, page.Changes[1].SecretID)
+	assert.True(t, page.Changes[1].Deleted, "deletion must be returned as a tombstone")
+	assert.Nil(t, page.Changes[1].Secret)
+
+	page = syncSecrets(t, handler, accessToken, page.Cursor, "")
+	assert.Empty(t, page.Changes, "nothing changed since the last sync")
+
+	assert.Empty(t, syncSecrets(t, handler, stranger, "", "").Changes,
+		"changes of other users must not be returned")
+}
+
+func TestServer_SyncValidation(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	for _, target := range []string{
+		"/api/v1/sync?cursor=not-a-cursor!",
+		"/api/v1/sync?cursor=LTE",
+		"/api/v1/sync?limit=0",
+		"/api/v1/sync?limit=many",
+	} {
+		rec := doJSON(t, handler, http.MethodGet, target, nil, accessToken)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+	}
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/sync", nil, "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
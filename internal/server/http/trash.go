@@ -0,0 +1,120 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+)
+
+// TrashService describes the functionality of the trash of deleted secrets.
+type TrashService interface {
+	// ListPage returns a page of the user's deleted secrets, the most recently deleted first.
+	ListPage(
+		ctx context.Context,
+		userID string,
+		query model.PageQuery,
+	) (*service.Page[*model.TrashedSecret], error)
+
+	// Restore moves the user's deleted secret with its history back to the vault.
+	Restore(ctx context.Context, userID string, secretID string) (*model.Secret, error)
+
+	// PurgeAt returns the time the secret deleted at the time is purged from the trash.
+	PurgeAt(deletedAt time.Time) time.Time
+}
+
+// TrashedSecretResponse describes a deleted secret returned to the client.
+type TrashedSecretResponse struct {
+	ID        string            `json:"id"`                 // Secret identifier.
+	FolderID  string            `json:"folderId,omitempty"` // Folder the secret was in.
+	Type      secret.Type       `json:"type"`               // Kind of secret.
+	Metadata  map[string]string `json:"metadata"`           // Free-form metadata.
+	BlobSize  int64             `json:"blobSize,omitempty"` // Size of the binary payload.
+	Revision  int64             `json:"revision"`           // Number of the last revision.
+	CreatedAt time.Time         `json:"createdAt"`          // Creation time.
+	UpdatedAt time.Time         `json:"updatedAt"`          // Last modification time.
+	DeletedAt time.Time         `json:"deletedAt"`          // Time the secret was moved to the trash.
+	PurgeAt   time.Time         `json:"purgeAt"`            // Time the secret is deleted permanently.
+}
+
+// TrashListResponse describes a page of the content of the trash.
+type TrashListResponse struct {
+	Items   []TrashedSecretResponse `json:"items"`            // Deleted secrets without payload, the latest first.
+	Cursor  string                  `json:"cursor,omitempty"` // Opaque cursor of the next page, omitted on the last page.
+	HasMore bool                    `json:"hasMore"`          // More secrets are available with the cursor.
+}
+
+func (s *Server) newTrashedSecretResponse(item *model.TrashedSecret) TrashedSecretResponse {
+	resp := TrashedSecretResponse{
+		ID:        item.ID,
+		FolderID:  item.FolderID,
+		Type:      item.Type,
+		Metadata:  item.Metadata,
+		BlobSize:  item.BlobSize,
+		Revision:  item.Revision,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+		DeletedAt: item.DeletedAt,
+		PurgeAt:   s.trash.PurgeAt(item.DeletedAt),
+	}
+
+	if resp.Metadata == nil {
+		resp.Metadata = map[string]string{}
+	}
+
+	return resp
+}
+
+func (s *Server) listTrash(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	query, err := parsePageQuery(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	page, err := s.trash.ListPage(r.Context(), userID, *query)
+	if err != nil {
+		s.writeSecretError(w, err)
+
+		return
+	}
+
+	resp := TrashListResponse{
+		Items:   make([]TrashedSecretResponse, 0, len(page.Items)),
+		Cursor:  encodePageCursor(page.Next),
+		HasMore: page.Next != nil,
+	}
+
+	for _, item := range page.Items {
+		resp.Items = append(resp.Items, s.newTrashedSecretResponse(item))
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) restoreTrashedSecret(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := s.trash.Restore(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeSecretError(w, err)
+
+		return
+	}
+
+	s.writeSecret(w, http.StatusOK, item)
+}
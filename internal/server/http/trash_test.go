@@ -0,0 +1,172 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	server "github.com/mr-filatik/go-password-keeper/internal/server/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listTrash returns the first page of the deleted secrets of the user.
+func listTrash(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+) []server.TrashedSecretResponse {
+	t.Helper()
+
+	return listTrashPage(t, handler, accessToken, url.Values{}).Items
+}
+
+// listTrashPage returns the page of the deleted secrets of the user.
+func listTrashPage(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+	query url.Values,
+) server.TrashListResponse {
+	t.Helper()
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/trash?"+query.Encode(), nil, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp server.TrashListResponse
+
+	decodeBody(t, rec, &resp)
+
+	return resp
+}
+
+// moveToTrash deletes the secret of the user.
+func moveToTrash(t *testing.T, handler http.Handler, accessToken string, secretID string) {
+	t.Helper()
+
+	rec := doJSONWithHeaders(t, handler, http.MethodDelete, "/api/v1/secrets/"+secretID, nil,
+		accessToken, ifMatch("*"))
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+}
+
+// restoreFromTrash requests the restoration of the deleted secret of the user.
+func restoreFromTrash(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+	secretID string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	return doJSON(
+		t,
+		handler,
+		http.MethodPost,
+		"/api/v1/trash/"+secretID+"/restore",
+		nil,
+		accessToken,
+	)
+}
+
+func TestServer_TrashRestore(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	created := createTextSecret(t, handler, accessToken, "first")
+	updateSecretText(
+		t,
+		handler,
+		accessToken,
+		created.ID,
+		encryptPayload(t, &secret.Text{Value: "second"}),
+	)
+	moveToTrash(t, handler, accessToken, created.ID)
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/secrets/"+created.ID, nil, accessToken)
+	require.Equal(t, http.StatusNotFound, rec.Code, "deleted secret must be hidden")
+
+	trashed := listTrash(t, handler, accessToken)
+	require.Len(t, trashed, 1)
+	assert.Equal(t, created.ID, trashed[0].ID)
+	assert.Equal(t, int64(2), trashed[0].Revision)
+	assert.Equal(t, testTrashRetention, trashed[0].PurgeAt.Sub(trashed[0].DeletedAt))
+
+	cursor := syncSecrets(t, handler, accessToken, "", "10").Cursor
+
+	rec = restoreFromTrash(t, handler, accessToken, created.ID)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var restored server.SecretResponse
+
+	decodeBody(t, rec, &restored)
+	assert.Equal(t, int64(2), restored.Revision)
+	assert.NotEmpty(t, rec.Header().Get("ETag"))
+
+	assert.Empty(t, listTrash(t, handler, accessToken))
+
+	versions := listSecretVersions(t, handler, accessToken, created.ID)
+	assert.Len(t, versions, 1, "history must be restored")
+
+	changes := syncSecrets(t, handler, accessToken, cursor, "10")
+	require.Len(t, changes.Changes, 1, "restoration must reach the other devices")
+	assert.False(t, changes.Changes[0].Deleted)
+
+	rec = restoreFromTrash(t, handler, accessToken, created.ID)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_TrashIsPerUser(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	aliceToken := registerAndLogin(t, handler, "alice")
+	bobToken := registerAndLogin(t, handler, "bob")
+
+	created := createTextSecret(t, handler, aliceToken, "note")
+	moveToTrash(t, handler, aliceToken, created.ID)
+
+	assert.Empty(t, listTrash(t, handler, bobToken))
+
+	rec := restoreFromTrash(t, handler, bobToken, created.ID)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_TrashPagination(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	for range 5 {
+		created := createTextSecret(t, handler, accessToken, "note")
+		moveToTrash(t, handler, accessToken, created.ID)
+	}
+
+	all := listTrashPage(t, handler, accessToken, url.Values{})
+	require.Len(t, all.Items, 5)
+	assert.False(t, all.HasMore)
+	assert.Empty(t, all.Cursor)
+
+	var paged []server.TrashedSecretResponse
+
+	query := url.Values{"limit": {"2"}}
+	for pages := 1; ; pages++ {
+		page := listTrashPage(t, handler, accessToken, query)
+		require.LessOrEqual(t, len(page.Items), 2)
+		paged = append(paged, page.Items...)
+
+		if !page.HasMore {
+			assert.Equal(t, 3, pages)
+
+			break
+		}
+
+		query.Set("cursor", page.Cursor)
+	}
+
+	assert.Equal(t, all.Items, paged, "pages must neither skip nor repeat secrets")
+}
@@ -0,0 +1,196 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/http/middleware"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+)
+
+// TwoFactorService describes the functionality of two-factor authentication with one-time codes.
+type TwoFactorService interface {
+	// Enroll generates a new secret for the user.
+	Enroll(
+		ctx context.Context,
+		userID string,
+		password string,
+	) (*service.TwoFactorEnrollment, error)
+
+	// Confirm enables two-factor authentication after checking the code from the authenticator.
+	Confirm(ctx context.Context, userID string, code string, client model.ClientInfo) error
+
+	// Disable turns two-factor authentication off.
+	Disable(ctx context.Context, userID string, client model.ClientInfo) error
+
+	// IsEnabled checks whether the user has confirmed two-factor authentication.
+	IsEnabled(ctx context.Context, userID string) (bool, error)
+
+	// CheckCode returns true if the user has not enabled two-factor authentication or the code is valid.
+	CheckCode(
+		ctx context.Context,
+		userID string,
+		code string,
+		client model.ClientInfo,
+	) (bool, error)
+}
+
+// twoFactorCheck checks the one-time codes of sensitive operations for the middleware.
+//
+// Implements the middleware.TwoFactorVerifier interface.
+type twoFactorCheck struct {
+	twoFactor TwoFactorService
+}
+
+// CheckCode checks the one-time code presented by the client of the request.
+//
+// Implements the middleware.TwoFactorVerifier interface.
+func (c twoFactorCheck) CheckCode(r *http.Request, userID string, code string) (bool, error) {
+	valid, err := c.twoFactor.CheckCode(r.Context(), userID, code, clientInfo(r))
+	if err != nil {
+		if errors.Is(err, service.ErrTwoFactorLocked) {
+			return false, middleware.ErrTwoFactorLocked
+		}
+
+		return false, fmt.Errorf("check two-factor code: %w", err)
+	}
+
+	return valid, nil
+}
+
+// TwoFactorStatusResponse describes the state of two-factor authentication of the user.
+type TwoFactorStatusResponse struct {
+	Enabled bool `json:"enabled"` // Whether the one-time code is required.
+}
+
+// EnrollTwoFactorRequest describes the body of the request for a new secret.
+type EnrollTwoFactorRequest struct {
+	Password string `json:"password"` // Current authentication password.
+}
+
+// EnrollTwoFactorResponse describes the secret for adding to an authenticator application.
+type EnrollTwoFactorResponse struct {
+	Secret string `json:"secret"` // Shared secret in the base32 form for manual entry.
+	URI    string `json:"uri"`    // otpauth:// URI for the QR code.
+}
+
+// ConfirmTwoFactorRequest describes the body of the enrollment confirmation request.
+type ConfirmTwoFactorRequest struct {
+	Code string `json:"code"` // One-time code from the authenticator.
+}
+
+func (s *Server) getTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	enabled, err := s.twoFactor.IsEnabled(r.Context(), userID)
+	if err != nil {
+		s.writeInternalError(w, err)
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, TwoFactorStatusResponse{Enabled: enabled})
+}
+
+func (s *Server) enrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	var req EnrollTwoFactorRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	enrollment, err := s.twoFactor.Enroll(r.Context(), userID, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			s.writeError(w, http.StatusForbidden, errCodeInvalidCredentials,
+				"current password is incorrect")
+		default:
+			s.writeTwoFactorError(w, err)
+		}
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, EnrollTwoFactorResponse{
+		Secret: enrollment.Secret,
+		URI:    enrollment.URI,
+	})
+}
+
+func (s *Server) confirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	var req ConfirmTwoFactorRequest
+
+	err := decodeJSON(r, &req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid request body")
+
+		return
+	}
+
+	err = s.twoFactor.Confirm(r.Context(), userID, req.Code, clientInfo(r))
+	if err != nil {
+		s.writeTwoFactorError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) disableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.twoFactor.Disable(r.Context(), userID, clientInfo(r))
+	if err != nil {
+		s.writeTwoFactorError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) writeTwoFactorError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled):
+		s.writeError(w, http.StatusConflict, errCodeTwoFactorEnabled,
+			"two-factor authentication is already enabled")
+	case errors.Is(err, service.ErrTwoFactorNotEnrolled),
+		errors.Is(err, service.ErrTwoFactorNotEnabled):
+		s.writeError(w, http.StatusConflict, errCodeTwoFactorNotEnrolled,
+			"two-factor authentication is not enrolled")
+	case errors.Is(err, service.ErrInvalidTwoFactorCode):
+		s.writeError(
+			w,
+			http.StatusBadRequest,
+			errCodeInvalidTwoFactorCode,
+			"one-time code is invalid",
+		)
+	default:
+		s.writeInternalError(w, err)
+	}
+}
@@ -0,0 +1,305 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/totp"
+	server "github.com/mr-filatik/go-password-keeper/internal/server/http"
+	"github.com/mr-filatik/go-password-keeper/internal/server/http/middleware"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// totpCode returns the one-time code of the secret shifted by the number of time steps.
+//
+// Every code is accepted only once, so consecutive checks in a test use growing offsets.
+func totpCode(t *testing.T, secret string, offset int) string {
+	t.Helper()
+
+	raw, err := totp.DecodeSecret(secret)
+	require.NoError(t, err)
+
+	key := &totp.Key{
+		Issuer:    "",
+		Account:   "",
+		Secret:    raw,
+		Algorithm: totp.DefaultAlgorithm,
+		Digits:    totp.DefaultDigits,
+		Period:    totp.DefaultPeriod,
+	}
+
+	code, err := key.Code(time.Now().Add(time.Duration(offset) * totp.DefaultPeriod))
+	require.NoError(t, err)
+
+	return code
+}
+
+// enableTwoFactor enrolls the user "alice" and confirms the enrollment with the code
+// of the previous time step, so the codes of the current and the next steps remain usable.
+func enableTwoFactor(t *testing.T, handler http.Handler, accessToken string) string {
+	t.Helper()
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/account/2fa",
+		server.EnrollTwoFactorRequest{Password: "correct-horse"}, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var enrollment server.EnrollTwoFactorResponse
+
+	decodeBody(t, rec, &enrollment)
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/account/2fa/confirm",
+		server.ConfirmTwoFactorRequest{Code: totpCode(t, enrollment.Secret, -1)}, accessToken)
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	return enrollment.Secret
+}
+
+// deleteWithCode executes the DELETE request of the authenticated user with the one-time code.
+func deleteWithCode(
+	t *testing.T,
+	handler http.Handler,
+	target string,
+	accessToken string,
+	code string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodDelete, target, http.NoBody)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set(middleware.HeaderTwoFactorCode, code)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+// startLogin logs the user "alice" in and returns the login challenge.
+func startLogin(t *testing.T, handler http.Handler) string {
+	t.Helper()
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/login",
+		server.CredentialsRequest{Login: "alice", Password: "correct-horse"}, "")
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+
+	var challenge server.TwoFactorChallengeResponse
+
+	decodeBody(t, rec, &challenge)
+	require.NotEmpty(t, challenge.ChallengeToken)
+	assert.True(t, challenge.ExpiresAt.After(time.Now()))
+
+	return challenge.ChallengeToken
+}
+
+func TestServer_TwoFactorEnrollment(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/account/2fa",
+		server.EnrollTwoFactorRequest{Password: "wrong-password"}, accessToken)
+	requireErrorCode(t, rec, http.StatusForbidden, "invalid_credentials")
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/account/2fa/confirm",
+		server.ConfirmTwoFactorRequest{Code: "123456"}, accessToken)
+	requireErrorCode(t, rec, http.StatusConflict, "two_factor_not_enrolled")
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/account/2fa",
+		server.EnrollTwoFactorRequest{Password: "correct-horse"}, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var enrollment server.EnrollTwoFactorResponse
+
+	decodeBody(t, rec, &enrollment)
+	assert.NotEmpty(t, enrollment.Secret)
+	assert.Contains(t, enrollment.URI, "otpauth://totp/")
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/account/2fa/confirm",
+		server.ConfirmTwoFactorRequest{Code: "000000"}, accessToken)
+	requireErrorCode(t, rec, http.StatusBadRequest, "invalid_two_factor_code")
+
+	var status server.TwoFactorStatusResponse
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/account/2fa", nil, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	decodeBody(t, rec, &status)
+	assert.False(t, status.Enabled, "2FA must not be required before the confirmation")
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/account/2fa/confirm",
+		server.ConfirmTwoFactorRequest{Code: totpCode(t, enrollment.Secret, 0)}, accessToken)
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/account/2fa", nil, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	decodeBody(t, rec, &status)
+	assert.True(t, status.Enabled)
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/account/2fa",
+		server.EnrollTwoFactorRequest{Password: "correct-horse"}, accessToken)
+	requireErrorCode(t, rec, http.StatusConflict, "two_factor_already_enabled")
+}
+
+func TestServer_TwoFactorLogin(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	secret := enableTwoFactor(t, handler, registerAndLogin(t, handler, "alice"))
+
+	challenge := startLogin(t, handler)
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/login/2fa",
+		server.LoginTwoFactorRequest{ChallengeToken: challenge, Code: "000000"}, "")
+	requireErrorCode(t, rec, http.StatusUnauthorized, "invalid_two_factor_code")
+
+	code := totpCode(t, secret, 0)
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/login/2fa",
+		server.LoginTwoFactorRequest{ChallengeToken: challenge, Code: code}, "")
+	requireErrorCode(t, rec, http.StatusUnauthorized, "invalid_login_challenge")
+
+	rec = doJSON(
+		t,
+		handler,
+		http.MethodPost,
+		"/api/v1/auth/login/2fa",
+		server.LoginTwoFactorRequest{
+			ChallengeToken: startLogin(t, handler),
+			Code:           code,
+		},
+		"",
+	)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var login server.LoginResponse
+
+	decodeBody(t, rec, &login)
+	assert.NotEmpty(t, login.AccessToken)
+	assert.NotEmpty(t, login.RefreshToken)
+	assert.Equal(t, "alice", login.User.Login)
+
+	rec = doJSON(
+		t,
+		handler,
+		http.MethodPost,
+		"/api/v1/auth/login/2fa",
+		server.LoginTwoFactorRequest{
+			ChallengeToken: startLogin(t, handler),
+			Code:           code,
+		},
+		"",
+	)
+	requireErrorCode(t, rec, http.StatusUnauthorized, "invalid_two_factor_code")
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/login",
+		server.CredentialsRequest{Login: "alice", Password: "wrong-password"}, "")
+	requireErrorCode(t, rec, http.StatusUnauthorized, "invalid_credentials")
+}
+
+func TestServer_TwoFactorSensitiveOperations(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+	secret := enableTwoFactor(t, handler, accessToken)
+
+	rec := doJSON(t, handler, http.MethodDelete, "/api/v1/sessions/others", nil, accessToken)
+	requireErrorCode(t, rec, http.StatusForbidden, "two_factor_required")
+
+	rec = deleteWithCode(t, handler, "/api/v1/sessions/others", accessToken, "000000")
+	requireErrorCode(t, rec, http.StatusForbidden, "two_factor_required")
+
+	code := totpCode(t, secret, 0)
+
+	rec = deleteWithCode(t, handler, "/api/v1/sessions/others", accessToken, code)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = deleteWithCode(t, handler, "/api/v1/account/2fa", accessToken, code)
+	requireErrorCode(t, rec, http.StatusForbidden, "two_factor_required")
+
+	rec = deleteWithCode(t, handler, "/api/v1/account/2fa", accessToken,
+		totpCode(t, secret, 1))
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	rec = doJSON(t, handler, http.MethodDelete, "/api/v1/sessions/others", nil, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// The missing code only asks for it, the wrong and the replayed codes are recorded.
+	events := listAuditEvents(t, handler, accessToken)
+	require.Len(t, events, 4)
+	assert.Equal(t, "two_factor.enabled", events[0].Type)
+	assert.Equal(t, "two_factor.failed", events[1].Type)
+	assert.Equal(t, "two_factor.failed", events[2].Type)
+	assert.Equal(t, "two_factor.disabled", events[3].Type)
+}
+
+func TestServer_TwoFactorLockout(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+	secret := enableTwoFactor(t, handler, accessToken)
+
+	for range service.TwoFactorMaxFailures {
+		rec := deleteWithCode(t, handler, "/api/v1/sessions/others", accessToken, "000000")
+		requireErrorCode(t, rec, http.StatusForbidden, "two_factor_required")
+	}
+
+	// Even the valid code is not checked until the lockout expires.
+	rec := deleteWithCode(t, handler, "/api/v1/sessions/others", accessToken,
+		totpCode(t, secret, 0))
+	requireErrorCode(t, rec, http.StatusTooManyRequests, "two_factor_locked")
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/login/2fa",
+		server.LoginTwoFactorRequest{
+			ChallengeToken: startLogin(t, handler),
+			Code:           totpCode(t, secret, 0),
+		}, "")
+	requireErrorCode(t, rec, http.StatusTooManyRequests, "two_factor_locked")
+
+	events := listAuditEvents(t, handler, accessToken)
+	require.Len(t, events, 1+service.TwoFactorMaxFailures)
+
+	last := events[len(events)-1]
+	assert.Equal(t, "two_factor.failed", last.Type)
+	assert.Equal(t, strconv.Itoa(service.TwoFactorMaxFailures), last.Details["failures"])
+	assert.NotEmpty(t, last.Details["locked_until"])
+}
+
+func TestServer_RecoveryResetsTwoFactor(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	code := newRecoveryCode(t)
+	registerWithRecoveryCodes(t, handler, code)
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/login",
+		server.CredentialsRequest{Login: "alice", Password: "correct-horse"}, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var login server.LoginResponse
+
+	decodeBody(t, rec, &login)
+	enableTwoFactor(t, handler, login.AccessToken)
+	startLogin(t, handler)
+
+	rec = doJSON(
+		t,
+		handler,
+		http.MethodPost,
+		"/api/v1/auth/recovery",
+		newRecoverRequest(t, code),
+		"",
+	)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/login",
+		server.CredentialsRequest{Login: "alice", Password: "battery-staple"}, "")
+	assert.Equal(t, http.StatusOK, rec.Code, "recovery code replaces the lost second factor")
+}
@@ -0,0 +1,285 @@
+// Package http contains a description of the HTTP server.
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+)
+
+// Headers and values of the tus resumable upload protocol (https://tus.io/protocols/resumable-upload).
+const (
+	headerTusResumable   = "Tus-Resumable"
+	headerTusVersion     = "Tus-Version"
+	headerTusExtension   = "Tus-Extension"
+	headerTusMaxSize     = "Tus-Max-Size"
+	headerUploadLength   = "Upload-Length"
+	headerUploadOffset   = "Upload-Offset"
+	headerUploadMetadata = "Upload-Metadata"
+	headerUploadExpires  = "Upload-Expires"
+
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,expiration,termination"
+
+	contentTypeOffsetOctetStream = "application/offset+octet-stream"
+
+	uploadsPath = "/api/v1/uploads/"
+)
+
+// Error codes of the upload endpoints.
+const (
+	errCodeUploadOffsetMismatch = "upload_offset_mismatch"
+	errCodeUploadTooLarge       = "upload_too_large"
+	errCodeUnsupportedMedia     = "unsupported_media_type"
+)
+
+var errInvalidUploadMetadata = errors.New("invalid upload metadata")
+
+// UploadService describes the functionality of resumable uploads of large secret payloads.
+type UploadService interface {
+	// MaxSize returns the maximum size of a payload in bytes.
+	MaxSize() int64
+
+	// Create starts a new upload of the payload of the given length.
+	Create(
+		ctx context.Context,
+		userID string,
+		length int64,
+		metadata map[string]string,
+	) (*model.Upload, error)
+
+	// Get returns the user's upload with the number of bytes received.
+	Get(ctx context.Context, userID string, uploadID string) (*model.Upload, error)
+
+	// Append writes the chunk to the upload at the offset.
+	Append(
+		ctx context.Context,
+		userID string,
+		uploadID string,
+		offset int64,
+		chunk io.Reader,
+	) (*model.Upload, error)
+
+	// Terminate deletes the upload together with the bytes received.
+	Terminate(ctx context.Context, userID string, uploadID string) error
+}
+
+// parseUploadMetadata parses the Upload-Metadata header: comma-separated pairs
+// of a key and an optional base64-encoded value.
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for pair := range strings.SplitSeq(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errInvalidUploadMetadata
+		}
+
+		if _, exists := metadata[key]; exists {
+			return nil, errInvalidUploadMetadata
+		}
+
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errInvalidUploadMetadata
+		}
+
+		metadata[key] = string(value)
+	}
+
+	return metadata, nil
+}
+
+// formatUploadMetadata formats the metadata for the Upload-Metadata header.
+func formatUploadMetadata(metadata map[string]string) string {
+	pairs := make([]string, 0, len(metadata))
+
+	for key, value := range metadata {
+		if value == "" {
+			pairs = append(pairs, key)
+		} else {
+			pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(value)))
+		}
+	}
+
+	slices.Sort(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// parseUploadSize parses the non-negative size from the header of the request.
+func parseUploadSize(r *http.Request, name string) (int64, bool) {
+	size, err := strconv.ParseInt(r.Header.Get(name), 10, 64)
+	if err != nil || size < 0 {
+		return 0, false
+	}
+
+	return size, true
+}
+
+// tusResumable checks the version of the protocol requested by the client.
+//
+// All responses of the upload endpoints carry the version of the protocol.
+func (s *Server) tusResumable(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerTusResumable, tusVersion)
+
+		if r.Header.Get(headerTusResumable) != tusVersion {
+			w.Header().Set(headerTusVersion, tusVersion)
+			s.writeError(w, http.StatusPreconditionFailed, errCodePreconditionFailed,
+				"unsupported version of the tus protocol")
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) writeUploadError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrUploadNotFound):
+		s.writeError(w, http.StatusNotFound, errCodeNotFound, "upload not found")
+	case errors.Is(err, service.ErrInvalidUpload):
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, err.Error())
+	case errors.Is(err, service.ErrUploadTooLarge):
+		s.writeError(w, http.StatusRequestEntityTooLarge, errCodeUploadTooLarge, err.Error())
+	case errors.Is(err, service.ErrUploadOffsetMismatch):
+		s.writeError(w, http.StatusConflict, errCodeUploadOffsetMismatch,
+			"offset does not match the received content")
+	default:
+		s.writeInternalError(w, err)
+	}
+}
+
+// writeUploadState sets the headers describing the progress of the upload.
+func writeUploadState(w http.ResponseWriter, upload *model.Upload) {
+	w.Header().Set(headerUploadOffset, strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set(headerUploadExpires, upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", "no-store")
+}
+
+func (s *Server) describeUploads(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set(headerTusResumable, tusVersion)
+	w.Header().Set(headerTusVersion, tusVersion)
+	w.Header().Set(headerTusExtension, tusExtensions)
+	w.Header().Set(headerTusMaxSize, strconv.FormatInt(s.uploads.MaxSize(), 10))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) createUpload(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	length, valid := parseUploadSize(r, headerUploadLength)
+	if !valid {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid Upload-Length")
+
+		return
+	}
+
+	metadata, err := parseUploadMetadata(r.Header.Get(headerUploadMetadata))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid Upload-Metadata")
+
+		return
+	}
+
+	upload, err := s.uploads.Create(r.Context(), userID, length, metadata)
+	if err != nil {
+		s.writeUploadError(w, err)
+
+		return
+	}
+
+	writeUploadState(w, upload)
+	w.Header().Set("Location", uploadsPath+upload.ID)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) headUpload(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	upload, err := s.uploads.Get(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeUploadError(w, err)
+
+		return
+	}
+
+	writeUploadState(w, upload)
+	w.Header().Set(headerUploadLength, strconv.FormatInt(upload.Length, 10))
+
+	if len(upload.Metadata) > 0 {
+		w.Header().Set(headerUploadMetadata, formatUploadMetadata(upload.Metadata))
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) patchUpload(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != contentTypeOffsetOctetStream {
+		s.writeError(w, http.StatusUnsupportedMediaType, errCodeUnsupportedMedia,
+			"chunks must be sent as "+contentTypeOffsetOctetStream)
+
+		return
+	}
+
+	offset, valid := parseUploadSize(r, headerUploadOffset)
+	if !valid {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidRequest, "invalid Upload-Offset")
+
+		return
+	}
+
+	upload, err := s.uploads.Append(r.Context(), userID, chi.URLParam(r, "id"), offset, r.Body)
+	if err != nil {
+		s.writeUploadError(w, err)
+
+		return
+	}
+
+	writeUploadState(w, upload)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) terminateUpload(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.uploads.Terminate(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeUploadError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
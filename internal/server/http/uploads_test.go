@@ -0,0 +1,362 @@
+package http_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	server "github.com/mr-filatik/go-password-keeper/internal/server/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// doTus executes the request of the tus protocol with the raw body.
+func doTus(
+	t *testing.T,
+	handler http.Handler,
+	method string,
+	target string,
+	accessToken string,
+	headers map[string]string,
+	body []byte,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+// createUpload starts the upload of the payload and returns its location.
+func createUpload(t *testing.T, handler http.Handler, accessToken string, length int) string {
+	t.Helper()
+
+	rec := doTus(t, handler, http.MethodPost, "/api/v1/uploads", accessToken, map[string]string{
+		"Upload-Length":   strconv.Itoa(length),
+		"Upload-Metadata": "filename c2Nhbi5wZGY=,draft",
+	}, nil)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "1.0.0", rec.Header().Get("Tus-Resumable"))
+	assert.Equal(t, "0", rec.Header().Get("Upload-Offset"))
+	assert.NotEmpty(t, rec.Header().Get("Upload-Expires"))
+
+	return rec.Header().Get("Location")
+}
+
+// patchUpload sends the chunk of the upload at the offset.
+func patchUpload(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+	location string,
+	offset int,
+	chunk []byte,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	return doTus(t, handler, http.MethodPatch, location, accessToken, map[string]string{
+		"Content-Type":  "application/offset+octet-stream",
+		"Upload-Offset": strconv.Itoa(offset),
+	}, chunk)
+}
+
+// uploadOffset returns the number of bytes of the upload received by the server.
+func uploadOffset(t *testing.T, handler http.Handler, accessToken string, location string) string {
+	t.Helper()
+
+	rec := doTus(t, handler, http.MethodHead, location, accessToken, nil, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+	return rec.Header().Get("Upload-Offset")
+}
+
+// createBinarySecret creates a binary secret with the payload of the upload.
+func createBinarySecret(
+	t *testing.T,
+	handler http.Handler,
+	accessToken string,
+	uploadID string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	return doJSON(t, handler, http.MethodPost, "/api/v1/secrets", server.SecretRequest{
+		FolderID: "",
+		Type:     secret.TypeBinary,
+		Metadata: map[string]string{"file": "scan.pdf"},
+		Tags:     nil,
+		Data:     nil,
+		UploadID: uploadID,
+		Indexes:  nil,
+	}, accessToken)
+}
+
+// downloadBlob returns the binary payload of the secret.
+func downloadBlob(t *testing.T, handler http.Handler, accessToken string, secretID string) []byte {
+	t.Helper()
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/secrets/"+secretID+"/blob", nil, accessToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/octet-stream", rec.Header().Get("Content-Type"))
+	assert.Equal(t, strconv.Itoa(rec.Body.Len()), rec.Header().Get("Content-Length"))
+
+	return rec.Body.Bytes()
+}
+
+func TestServer_UploadDiscovery(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/uploads", http.NoBody)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "1.0.0", rec.Header().Get("Tus-Version"))
+	assert.Equal(t, "creation,expiration,termination", rec.Header().Get("Tus-Extension"))
+	assert.Equal(t, strconv.Itoa(1<<20), rec.Header().Get("Tus-Max-Size"))
+}
+
+func TestServer_ResumableUpload(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+	payload := encryptPayload(
+		t,
+		&secret.Binary{FileName: "scan.pdf", Data: bytes.Repeat([]byte("%PDF"), 4096)},
+	)
+	half := len(payload) / 2
+
+	location := createUpload(t, handler, accessToken, len(payload))
+
+	rec := patchUpload(t, handler, accessToken, location, 0, payload[:half])
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	assert.Equal(t, strconv.Itoa(half), rec.Header().Get("Upload-Offset"))
+
+	// The connection dropped before the response: the client resends the chunk it has not seen confirmed.
+	rec = patchUpload(t, handler, accessToken, location, 0, payload[:half])
+	assert.Equal(t, http.StatusConflict, rec.Code, "chunk must start at the received offset")
+
+	assert.Equal(t, strconv.Itoa(half), uploadOffset(t, handler, accessToken, location))
+
+	head := doTus(t, handler, http.MethodHead, location, accessToken, nil, nil)
+	assert.Equal(t, strconv.Itoa(len(payload)), head.Header().Get("Upload-Length"))
+	assert.Equal(t, "draft,filename c2Nhbi5wZGY=", head.Header().Get("Upload-Metadata"))
+
+	stranger := registerAndLogin(t, handler, "bob")
+	rec = doTus(t, handler, http.MethodHead, location, stranger, nil, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "uploads of other users must be hidden")
+
+	uploadID := location[len("/api/v1/uploads/"):]
+	rec = createBinarySecret(t, handler, accessToken, uploadID)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "incomplete upload must not become a secret")
+
+	rec = patchUpload(t, handler, accessToken, location, half, payload[half:])
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	assert.Equal(t, strconv.Itoa(len(payload)), rec.Header().Get("Upload-Offset"))
+
+	rec = createBinarySecret(t, handler, accessToken, uploadID)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created server.SecretResponse
+
+	decodeBody(t, rec, &created)
+	assert.Equal(t, int64(len(payload)), created.BlobSize)
+	assert.Equal(t, payload, downloadBlob(t, handler, accessToken, created.ID))
+
+	rec = doTus(t, handler, http.MethodHead, location, accessToken, nil, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "used upload must be deleted")
+}
+
+func TestServer_UploadValidation(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+	location := createUpload(t, handler, accessToken, 4)
+
+	steps := []struct {
+		name    string
+		method  string
+		target  string
+		headers map[string]string
+		body    []byte
+		want    int
+	}{
+		{"no version", http.MethodPost, "/api/v1/uploads", map[string]string{
+			"Tus-Resumable": "", "Upload-Length": "4",
+		}, nil, http.StatusPreconditionFailed},
+		{"no length", http.MethodPost, "/api/v1/uploads", nil, nil, http.StatusBadRequest},
+		{"too large", http.MethodPost, "/api/v1/uploads", map[string]string{
+			"Upload-Length": strconv.Itoa(1<<20 + 1),
+		}, nil, http.StatusRequestEntityTooLarge},
+		{"bad metadata", http.MethodPost, "/api/v1/uploads", map[string]string{
+			"Upload-Length": "4", "Upload-Metadata": "filename !!!",
+		}, nil, http.StatusBadRequest},
+		{"content type", http.MethodPatch, location, map[string]string{
+			"Content-Type": "application/json", "Upload-Offset": "0",
+		}, []byte("data"), http.StatusUnsupportedMediaType},
+		{"beyond length", http.MethodPatch, location, map[string]string{
+			"Content-Type": "application/offset+octet-stream", "Upload-Offset": "0",
+		}, []byte("too much data"), http.StatusRequestEntityTooLarge},
+		{"unknown", http.MethodHead, "/api/v1/uploads/unknown", nil, nil, http.StatusNotFound},
+		{"terminate", http.MethodDelete, location, nil, nil, http.StatusNoContent},
+		{"terminated", http.MethodHead, location, nil, nil, http.StatusNotFound},
+	}
+	for _, step := range steps {
+		rec := doTus(t, handler, step.method, step.target, accessToken, step.headers, step.body)
+		assert.Equal(t, step.want, rec.Code, step.name)
+	}
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/secrets", server.SecretRequest{
+		FolderID: "",
+		Type:     secret.TypeText,
+		Metadata: nil,
+		Tags:     nil,
+		Data:     nil,
+		UploadID: location[len("/api/v1/uploads/"):],
+		Indexes:  nil,
+	}, accessToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "upload is only a payload of binary secrets")
+}
+
+func TestServer_SecretBlob(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t)
+	accessToken := registerAndLogin(t, handler, "alice")
+	payload := encryptPayload(t, &secret.Binary{FileName: "key.bin", Data: []byte{0, 1, 2, 3}})
+
+	rec := doJSON(
+		t,
+		handler,
+		http.MethodPost,
+		"/api/v1/secrets",
+		binarySecretRequest(payload),
+		accessToken,
+	)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created server.SecretResponse
+
+	decodeBody(t, rec, &created)
+	assert.Empty(t, created.Data, "binary payload is served only by the blob endpoint")
+	assert.Equal(t, int64(len(payload)), created.BlobSize)
+	assert.Empty(t, getSecret(t, handler, accessToken, created.ID).Data)
+	assert.Equal(t, payload, downloadBlob(t, handler, accessToken, created.ID))
+
+	rec = doJSON(
+		t,
+		handler,
+		http.MethodGet,
+		"/api/v1/secrets/"+created.ID+"/blob",
+		nil,
+		accessToken,
+	)
+	assert.Equal(t, `"1"`, rec.Header().Get("ETag"))
+
+	stranger := registerAndLogin(t, handler, "bob")
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets/"+created.ID+"/blob", nil, stranger)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "payloads of other users must be hidden")
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/secrets",
+		textSecretRequest(encryptPayload(t, &secret.Text{Value: "note"})), accessToken)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var text server.SecretResponse
+
+	decodeBody(t, rec, &text)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/secrets/"+text.ID+"/blob", nil, accessToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "text secrets have no binary payload")
+}
+
+// binarySecretRequest returns the request of a binary secret with the inline payload.
+func binarySecretRequest(data []byte) server.SecretRequest {
+	return server.SecretRequest{
+		FolderID: "",
+		Type:     secret.TypeBinary,
+		Metadata: nil,
+		Tags:     nil,
+		Data:     data,
+		UploadID: "",
+		Indexes:  nil,
+	}
+}
+
+func TestServer_BlobDeduplication(t *testing.T) {
+	t.Parallel()
+
+	blobDir := t.TempDir()
+	handler := newTestServerWithBlobDir(t, blobDir)
+	accessToken := registerAndLogin(t, handler, "alice")
+	original := encryptPayload(t, &secret.Binary{FileName: "a.bin", Data: []byte("original")})
+	changed := encryptPayload(t, &secret.Binary{FileName: "a.bin", Data: []byte("changed")})
+
+	countBlobs := func() int {
+		entries, err := os.ReadDir(blobDir)
+		require.NoError(t, err)
+
+		return len(entries)
+	}
+
+	var first, copied server.SecretResponse
+
+	rec := doJSON(
+		t,
+		handler,
+		http.MethodPost,
+		"/api/v1/secrets",
+		binarySecretRequest(original),
+		accessToken,
+	)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	decodeBody(t, rec, &first)
+
+	rec = doJSON(
+		t,
+		handler,
+		http.MethodPost,
+		"/api/v1/secrets",
+		binarySecretRequest(original),
+		accessToken,
+	)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	decodeBody(t, rec, &copied)
+	assert.Equal(t, 1, countBlobs(), "copy of the payload must share the blob")
+
+	rec = doJSONWithHeaders(t, handler, http.MethodPut, "/api/v1/secrets/"+first.ID,
+		binarySecretRequest(changed), accessToken, ifMatch(`"1"`))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 2, countBlobs())
+
+	rec = doJSONWithHeaders(
+		t,
+		handler,
+		http.MethodPost,
+		"/api/v1/secrets/"+first.ID+"/versions/1/restore",
+		nil,
+		accessToken,
+		ifMatch("*"),
+	)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 2, countBlobs(), "restored revision must share the blob")
+
+	assert.Equal(t, original, downloadBlob(t, handler, accessToken, first.ID))
+	assert.Equal(t, original, downloadBlob(t, handler, accessToken, copied.ID))
+}
@@ -0,0 +1,32 @@
+// Package model contains the domain entities of the server application.
+package model
+
+import "time"
+
+// Types of audit events.
+const (
+	// AuditEventAccountRecovered - the user regained access with a recovery code.
+	AuditEventAccountRecovered = "account.recovered"
+
+	// AuditEventRecoveryCodesRegenerated - the user replaced the set of recovery codes.
+	AuditEventRecoveryCodesRegenerated = "recovery_codes.regenerated"
+
+	// AuditEventTwoFactorEnabled - the user confirmed the enrollment in two-factor authentication.
+	AuditEventTwoFactorEnabled = "two_factor.enabled"
+
+	// AuditEventTwoFactorDisabled - the user turned two-factor authentication off.
+	AuditEventTwoFactorDisabled = "two_factor.disabled"
+
+	// AuditEventTwoFactorFailed - a one-time code of the user has been rejected.
+	AuditEventTwoFactorFailed = "two_factor.failed"
+)
+
+// AuditEvent describes a security-relevant action on the user's account.
+type AuditEvent struct {
+	ID        string            // Event identifier.
+	UserID    string            // Identifier of the user the event belongs to.
+	Type      string            // Event type.
+	Client    ClientInfo        // Client from which the action was made.
+	Details   map[string]string // Additional event attributes.
+	CreatedAt time.Time         // Event time.
+}
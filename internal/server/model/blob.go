@@ -0,0 +1,57 @@
+// Package model contains the domain entities of the server application.
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// additionalDataPrefixBlobKey binds the wrapped data key of a blob to the blob,
+// so the key of one blob cannot be moved to another one.
+const additionalDataPrefixBlobKey = "go-password-keeper/v1/at-rest/blob-key:"
+
+// Blob describes a large payload saved in the blob storage.
+//
+// Blobs are content-addressed: the identifier is the SHA-256 hash of the content in hex,
+// so equal payloads are saved once and shared by all revisions referring to them.
+type Blob struct {
+	ID      string    // Hash of the content.
+	Size    int64     // Size of the content in bytes.
+	SavedAt time.Time // Time the content was saved in full.
+}
+
+// BlobReference describes a revision of a secret whose payload is kept in the blob.
+type BlobReference struct {
+	BlobID   string // Hash of the content of the blob.
+	UserID   string // Identifier of the owner of the secret.
+	SecretID string // Secret identifier.
+	Revision int64  // Number of the revision, current or from the history.
+}
+
+// BlobKeyAdditionalData returns the additional data that binds the data key of the blob
+// encrypted at rest to the blob.
+//
+// Parameters:
+//   - blobID string: blob identifier.
+func BlobKeyAdditionalData(blobID string) []byte {
+	return []byte(additionalDataPrefixBlobKey + blobID)
+}
+
+// IsValidBlobID checks that the identifier is a SHA-256 hash in lowercase hex.
+//
+// Parameters:
+//   - id string: blob identifier.
+func IsValidBlobID(id string) bool {
+	if len(id) != hex.EncodedLen(sha256.Size) {
+		return false
+	}
+
+	for _, char := range id {
+		if (char < '0' || char > '9') && (char < 'a' || char > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
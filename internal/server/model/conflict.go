@@ -0,0 +1,43 @@
+// Package model contains the domain entities of the server application.
+package model
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+)
+
+// SecretConflict describes an edit of a secret made offline against an outdated revision.
+//
+// The edit is kept next to the current revision of the secret until the user
+// resolves the conflict, so neither version is lost.
+type SecretConflict struct {
+	ID             string            // Unique conflict identifier.
+	UserID         string            // Identifier of the owner.
+	SecretID       string            // Identifier of the conflicting secret.
+	BaseRevision   int64             // Revision the client edit is based on.
+	ServerRevision int64             // Revision of the secret when the conflict was detected, 0 if deleted.
+	ServerDeleted  bool              // The secret has been deleted or moved to the trash since the base revision.
+	Type           secret.Type       // Kind of secret of the client edit.
+	Metadata       map[string]string // Metadata of the client edit.
+	Tags           []string          // Tags of the client edit.
+	Data           []byte            // Payload of the client edit encrypted on the client.
+	CreatedAt      time.Time         // Time the conflict was detected.
+
+	// Indexes - blind indexes of the client edit.
+	Indexes []crypto.BlindIndex
+}
+
+// Clone returns a deep copy of the conflict.
+func (c *SecretConflict) Clone() *SecretConflict {
+	clone := *c
+	clone.Metadata = maps.Clone(c.Metadata)
+	clone.Tags = slices.Clone(c.Tags)
+	clone.Indexes = slices.Clone(c.Indexes)
+	clone.Data = append([]byte(nil), c.Data...)
+
+	return &clone
+}
@@ -0,0 +1,23 @@
+// Package model contains the domain entities of the server application.
+package model
+
+import "time"
+
+// Types of the change events of the user's vault.
+const (
+	VaultEventSecretCreated  = "secret.created"
+	VaultEventSecretUpdated  = "secret.updated"
+	VaultEventSecretDeleted  = "secret.deleted"
+	VaultEventSecretRestored = "secret.restored"
+)
+
+// VaultEvent describes a change of the user's vault delivered to the connected clients.
+//
+// The event carries no secret data: clients fetch the changes with the sync protocol.
+type VaultEvent struct {
+	Type       string    `json:"type"`       // Event type (see VaultEvent*).
+	UserID     string    `json:"userId"`     // Identifier of the owner of the vault.
+	SecretID   string    `json:"secretId"`   // Identifier of the changed secret.
+	Revision   int64     `json:"revision"`   // Revision written by the change, the last one for deletions.
+	OccurredAt time.Time `json:"occurredAt"` // Time of the change.
+}
@@ -0,0 +1,41 @@
+// Package model contains the domain entities of the server application.
+package model
+
+import (
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+)
+
+// Folder describes a folder of the user's vault the secrets are organized into.
+//
+// Folders form a tree: a folder without a parent is at the top level of the vault.
+type Folder struct {
+	ID        string    // Unique folder identifier.
+	UserID    string    // Identifier of the owner.
+	ParentID  string    // Identifier of the parent folder, empty at the top level.
+	Name      []byte    // Name encrypted on the client.
+	CreatedAt time.Time // Creation time.
+	UpdatedAt time.Time // Time of the last rename or move.
+
+	// NameKey - key the name is encrypted with at rest, wrapped with the master key.
+	//
+	// Empty if the name is stored without encryption at rest.
+	NameKey atrest.WrappedKey
+}
+
+// FolderNameKey describes the wrapped key the name of a folder is encrypted with at rest.
+type FolderNameKey struct {
+	UserID   string            // Identifier of the owner.
+	FolderID string            // Folder identifier.
+	NameKey  atrest.WrappedKey // Wrapped name key.
+}
+
+// Clone returns a deep copy of the folder.
+func (f *Folder) Clone() *Folder {
+	clone := *f
+	clone.Name = append([]byte(nil), f.Name...)
+	clone.NameKey.Key = append([]byte(nil), f.NameKey.Key...)
+
+	return &clone
+}
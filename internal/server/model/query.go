@@ -0,0 +1,130 @@
+// Package model contains the domain entities of the server application.
+package model
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+)
+
+// SecretSort - field the listed secrets are sorted by.
+type SecretSort string
+
+// Fields the listed secrets can be sorted by.
+const (
+	SecretSortCreatedAt SecretSort = "createdAt" // Creation time.
+	SecretSortUpdatedAt SecretSort = "updatedAt" // Last modification time.
+)
+
+// IsValid checks whether the field is supported.
+func (s SecretSort) IsValid() bool {
+	return s == SecretSortCreatedAt || s == SecretSortUpdatedAt
+}
+
+// SecretFilter describes the conditions the listed secrets must meet, zero fields match any secret.
+type SecretFilter struct {
+	Type         secret.Type // Kind of secret.
+	Tag          string      // Tag the secret must have.
+	FolderID     string      // Folder the secret must be in.
+	UpdatedSince time.Time   // Secrets modified at this time or later.
+
+	// Indexes - blind indexes the secret must have, all of them.
+	Indexes []crypto.BlindIndex
+}
+
+// Matches checks whether the secret meets all conditions of the filter.
+func (f *SecretFilter) Matches(item *Secret) bool {
+	switch {
+	case f.Type != "" && item.Type != f.Type:
+		return false
+	case f.Tag != "" && !slices.Contains(item.Tags, f.Tag):
+		return false
+	case f.FolderID != "" && item.FolderID != f.FolderID:
+		return false
+	case !f.UpdatedSince.IsZero() && item.UpdatedAt.Before(f.UpdatedSince):
+		return false
+	default:
+		return item.HasIndexes(f.Indexes)
+	}
+}
+
+// SecretCursor describes a position in the sorted listing of secrets:
+// the sort key of the last secret of the previous page.
+type SecretCursor struct {
+	Time time.Time // Value of the sort field.
+	ID   string    // Secret identifier, orders the secrets with equal values.
+}
+
+// SecretQuery describes a page of the listing of the user's secrets.
+//
+// Secrets are ordered by the sort field with ties broken by identifier, so the order
+// is stable and the pages neither skip nor repeat secrets that have not been changed.
+type SecretQuery struct {
+	Filter     SecretFilter  // Conditions the secrets must meet.
+	Sort       SecretSort    // Field the secrets are sorted by.
+	Descending bool          // Sort in descending order.
+	After      *SecretCursor // Position to continue after, nil for the first page.
+	Limit      int           // Maximum number of secrets in the page.
+}
+
+// SortKey returns the position of the secret in the listing sorted by the field.
+func (s *Secret) SortKey(sort SecretSort) SecretCursor {
+	if sort == SecretSortUpdatedAt {
+		return SecretCursor{Time: s.UpdatedAt, ID: s.ID}
+	}
+
+	return SecretCursor{Time: s.CreatedAt, ID: s.ID}
+}
+
+// Compare compares the positions in the ascending order.
+func (c SecretCursor) Compare(other SecretCursor) int {
+	return cmp.Or(c.Time.Compare(other.Time), strings.Compare(c.ID, other.ID))
+}
+
+// Compare compares the positions of the secrets in the order of the query.
+func (q *SecretQuery) Compare(a *Secret, b *Secret) int {
+	order := a.SortKey(q.Sort).Compare(b.SortKey(q.Sort))
+	if q.Descending {
+		return -order
+	}
+
+	return order
+}
+
+// Follows checks whether the secret is placed after the cursor in the order of the query.
+func (q *SecretQuery) Follows(s *Secret) bool {
+	if q.After == nil {
+		return true
+	}
+
+	order := s.SortKey(q.Sort).Compare(*q.After)
+	if q.Descending {
+		return order < 0
+	}
+
+	return order > 0
+}
+
+// PageCursor describes a position in a listing ordered by time:
+// the time and identifier of the last item of the previous page.
+type PageCursor struct {
+	Time time.Time // Time the item is ordered by.
+	ID   string    // Item identifier, orders the items with equal times.
+}
+
+// Compare compares the positions in the ascending order.
+func (c PageCursor) Compare(other PageCursor) int {
+	return cmp.Or(c.Time.Compare(other.Time), strings.Compare(c.ID, other.ID))
+}
+
+// PageQuery describes a page of a listing ordered by time, such as the trash or the audit log.
+//
+// The order of the listing is fixed, items are ordered by time with ties broken by identifier.
+type PageQuery struct {
+	After *PageCursor // Position to continue after, nil for the first page.
+	Limit int         // Maximum number of items in the page.
+}
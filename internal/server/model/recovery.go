@@ -0,0 +1,28 @@
+// Package model contains the domain entities of the server application.
+package model
+
+import (
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+)
+
+// RecoveryCode describes a one-time recovery code of the user.
+//
+// The code itself is known only to the user; the server stores the hash of the
+// authentication password derived from it and a copy of the vault key wrapped with it.
+type RecoveryCode struct {
+	ID        string    // Identifier derived from the code on the client.
+	UserID    string    // Identifier of the owner.
+	AuthHash  string    // Hash of the authentication password derived from the code.
+	CreatedAt time.Time // Generation time of the set of codes.
+	UsedAt    time.Time // Time of use, zero if the code has not been used.
+
+	// VaultKey - vault key wrapped on the client with the key derived from the code.
+	VaultKey crypto.WrappedVaultKey
+}
+
+// IsUsed reports whether the code has already been used.
+func (c *RecoveryCode) IsUsed() bool {
+	return !c.UsedAt.IsZero()
+}
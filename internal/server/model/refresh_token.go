@@ -0,0 +1,15 @@
+// Package model contains the domain entities of the server application.
+package model
+
+import "time"
+
+// RefreshToken describes the state of an issued refresh token.
+//
+// The token itself is never stored, only its hash.
+type RefreshToken struct {
+	ID        string    `json:"id"`        // Hash of the opaque token value.
+	SessionID string    `json:"sessionId"` // Identifier of the session the token belongs to.
+	UserID    string    `json:"userId"`    // Identifier of the owner.
+	IssuedAt  time.Time `json:"issuedAt"`  // Issue time.
+	ExpiresAt time.Time `json:"expiresAt"` // Expiration time.
+}
@@ -0,0 +1,128 @@
+// Package model contains the domain entities of the server application.
+package model
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+)
+
+// Secret describes a secret stored in the user's vault.
+type Secret struct {
+	ID        string            // Unique secret identifier.
+	UserID    string            // Identifier of the owner.
+	FolderID  string            // Identifier of the folder, empty at the top level of the vault.
+	Type      secret.Type       // Kind of secret.
+	Metadata  map[string]string // Free-form metadata (site, bank, notes, etc.).
+	Tags      []string          // Labels the secrets are grouped by, sorted and unique.
+	Data      []byte            // Secret payload.
+	Revision  int64             // Number of the revision, starts with 1 and grows with every update.
+	CreatedAt time.Time         // Creation time.
+	UpdatedAt time.Time         // Last modification time.
+
+	// DataKey - key the data is encrypted with at rest, wrapped with the master key.
+	//
+	// Empty if the data is stored without encryption at rest.
+	DataKey atrest.WrappedKey
+
+	// BlobID - hash of the blob holding the payload of a binary secret, Data is empty in this case.
+	//
+	// Revisions with equal payloads share the blob. Empty if the payload is stored in Data.
+	BlobID string
+
+	// BlobSize - size of the payload in the blob in bytes.
+	BlobSize int64
+
+	// BlobKey - key the blob is encrypted with at rest, wrapped with the master key.
+	//
+	// The revisions sharing the blob keep copies of the same key. Empty if the blob
+	// is stored without encryption at rest.
+	BlobKey atrest.WrappedKey
+
+	// Indexes - blind indexes of the fields of the secret computed on the client, sorted and unique.
+	//
+	// They are stored as is: the server matches the tokens but cannot reverse them.
+	Indexes []crypto.BlindIndex
+}
+
+// SecretVersion describes a previous revision of a secret kept in its history.
+type SecretVersion struct {
+	Secret
+
+	ArchivedAt time.Time // Time the revision was replaced by the next one.
+}
+
+// TrashedSecret describes a deleted secret kept in the trash until it is purged.
+type TrashedSecret struct {
+	Secret
+
+	DeletedAt time.Time // Time the secret was moved to the trash.
+}
+
+// SecretChange describes the latest change of a secret in the change log of the user's vault.
+type SecretChange struct {
+	Seq       int64     // Position in the change log, grows monotonically with every change.
+	UserID    string    // Identifier of the owner.
+	SecretID  string    // Secret identifier.
+	Revision  int64     // Revision written by the change, the last one for deletions.
+	Deleted   bool      // The secret has been deleted, the change is a tombstone.
+	ChangedAt time.Time // Time of the change.
+}
+
+// SecretDataKey describes the wrapped data key of a secret revision or of its blob.
+type SecretDataKey struct {
+	UserID   string            // Identifier of the owner.
+	SecretID string            // Secret identifier.
+	Revision int64             // Revision the data key belongs to, current or from the history.
+	BlobID   string            // Blob the data key encrypts, empty for the key of the data.
+	DataKey  atrest.WrappedKey // Wrapped data key.
+}
+
+// HasBlob checks whether the payload of the secret is kept in the blob storage.
+func (s *Secret) HasBlob() bool {
+	return s.BlobID != ""
+}
+
+// HasIndexes checks whether the secret has all the blind indexes.
+func (s *Secret) HasIndexes(indexes []crypto.BlindIndex) bool {
+	for _, index := range indexes {
+		if !slices.Contains(s.Indexes, index) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Clone returns a deep copy of the secret.
+func (s *Secret) Clone() *Secret {
+	clone := *s
+	clone.Metadata = maps.Clone(s.Metadata)
+	clone.Tags = slices.Clone(s.Tags)
+	clone.Indexes = slices.Clone(s.Indexes)
+	clone.Data = append([]byte(nil), s.Data...)
+	clone.DataKey.Key = append([]byte(nil), s.DataKey.Key...)
+	clone.BlobKey.Key = append([]byte(nil), s.BlobKey.Key...)
+
+	return &clone
+}
+
+// Clone returns a deep copy of the version.
+func (v *SecretVersion) Clone() *SecretVersion {
+	return &SecretVersion{
+		Secret:     *v.Secret.Clone(),
+		ArchivedAt: v.ArchivedAt,
+	}
+}
+
+// Clone returns a deep copy of the trashed secret.
+func (t *TrashedSecret) Clone() *TrashedSecret {
+	return &TrashedSecret{
+		Secret:    *t.Secret.Clone(),
+		DeletedAt: t.DeletedAt,
+	}
+}
@@ -0,0 +1,24 @@
+// Package model contains the domain entities of the server application.
+package model
+
+import "time"
+
+// ClientInfo describes the client application from which the request was made.
+type ClientInfo struct {
+	Name    string `json:"name"`    // Client application name.
+	Version string `json:"version"` // Client application version.
+	IP      string `json:"ip"`      // Client IP address.
+}
+
+// Session describes a logged-in device of the user.
+//
+// The session lives as long as its refresh tokens are rotated;
+// all tokens of the session are revoked together with it.
+type Session struct {
+	ID         string     `json:"id"`         // Session identifier.
+	UserID     string     `json:"userId"`     // Identifier of the owner.
+	Client     ClientInfo `json:"client"`     // Client of the last login or refresh.
+	CreatedAt  time.Time  `json:"createdAt"`  // Login time.
+	LastSeenAt time.Time  `json:"lastSeenAt"` // Time of the last login or refresh.
+	ExpiresAt  time.Time  `json:"expiresAt"`  // Expiration time of the last refresh token.
+}
@@ -0,0 +1,29 @@
+// Package model contains the domain entities of the server application.
+package model
+
+import "time"
+
+// TwoFactor describes the enrollment of the user in two-factor authentication (TOTP).
+//
+// The enrollment becomes enabled only after the user confirms it with a code,
+// which proves that the secret has been added to the authenticator.
+type TwoFactor struct {
+	UserID       string    // Identifier of the user.
+	Secret       []byte    // Shared TOTP secret.
+	Enabled      bool      // The enrollment is confirmed and the second factor is required.
+	CreatedAt    time.Time // Enrollment time.
+	EnabledAt    time.Time // Confirmation time, zero if not confirmed.
+	LastUsedStep int64     // Time step of the last accepted code, protects against replays.
+
+	FailedAttempts int       // Number of rejected codes since the last accepted one.
+	LastFailedAt   time.Time // Time of the last rejected code, zero if none.
+}
+
+// LoginChallenge describes a login that is waiting for the second factor.
+//
+// The challenge is single-use: it is consumed by the first attempt to complete it.
+type LoginChallenge struct {
+	ID        string    `json:"id"`        // Hash of the challenge token.
+	UserID    string    `json:"userId"`    // Identifier of the user who passed the first factor.
+	ExpiresAt time.Time `json:"expiresAt"` // Expiration time.
+}
@@ -0,0 +1,20 @@
+// Package model contains the domain entities of the server application.
+package model
+
+import "time"
+
+// Upload describes a resumable upload of a payload sent in chunks.
+type Upload struct {
+	ID        string            // Unique upload identifier.
+	UserID    string            // Identifier of the owner.
+	Length    int64             // Total size of the payload in bytes.
+	Offset    int64             // Number of bytes received so far.
+	Metadata  map[string]string // Metadata passed by the client on creation.
+	CreatedAt time.Time         // Creation time.
+	ExpiresAt time.Time         // Time after which an incomplete upload is abandoned.
+}
+
+// IsComplete checks whether the whole payload has been received.
+func (u *Upload) IsComplete() bool {
+	return u.Offset == u.Length
+}
@@ -0,0 +1,19 @@
+// Package model contains the domain entities of the server application.
+package model
+
+import (
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+)
+
+// User describes a registered user of the password keeper.
+type User struct {
+	ID           string    // Unique user identifier.
+	Login        string    // Unique user login.
+	PasswordHash string    // Hash of the authentication password in the PHC string format.
+	CreatedAt    time.Time // Registration time.
+
+	// VaultKey - vault key wrapped on the client with the key derived from the master password.
+	VaultKey crypto.WrappedVaultKey
+}
@@ -0,0 +1,223 @@
+// Package encrypted provides repository decorators that encrypt data at rest.
+package encrypted
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// additionalDataPrefixFolder binds the encrypted name to the folder it belongs to,
+// so the name of one folder cannot be moved to another one.
+const additionalDataPrefixFolder = "go-password-keeper/v1/at-rest/folder:"
+
+// FolderStorage describes the underlying storage of folders with access to their name keys.
+type FolderStorage interface {
+	repository.FolderRepository
+	repository.FolderNameKeyStore
+}
+
+// FolderRepository - decorator that encrypts the names of folders before saving
+// and decrypts them after reading.
+//
+// Folders saved before the encryption at rest was enabled are read as is.
+//
+// Implements the repository.FolderRepository interface.
+type FolderRepository struct {
+	storage   FolderStorage
+	encrypter *atrest.Encrypter
+}
+
+// NewFolderRepository creates a new *FolderRepository instance.
+//
+// Parameters:
+//   - storage FolderStorage: underlying storage;
+//   - encrypter *atrest.Encrypter: encrypter of data at rest.
+func NewFolderRepository(storage FolderStorage, encrypter *atrest.Encrypter) *FolderRepository {
+	return &FolderRepository{
+		storage:   storage,
+		encrypter: encrypter,
+	}
+}
+
+// CreateFolder encrypts the name and saves a new folder.
+//
+// Implements the repository.FolderRepository interface.
+func (r *FolderRepository) CreateFolder(ctx context.Context, folder *model.Folder) error {
+	sealed, err := r.seal(ctx, folder)
+	if err != nil {
+		return err
+	}
+
+	err = r.storage.CreateFolder(ctx, sealed)
+	if err != nil {
+		return fmt.Errorf("create folder: %w", err)
+	}
+
+	return nil
+}
+
+// GetFolder returns the user's folder by identifier with decrypted name.
+//
+// Implements the repository.FolderRepository interface.
+func (r *FolderRepository) GetFolder(
+	ctx context.Context,
+	userID string,
+	folderID string,
+) (*model.Folder, error) {
+	folder, err := r.storage.GetFolder(ctx, userID, folderID)
+	if err != nil {
+		return nil, fmt.Errorf("get folder: %w", err)
+	}
+
+	return r.open(ctx, folder)
+}
+
+// ListFolders returns all folders of the user with decrypted names sorted by creation time.
+//
+// Implements the repository.FolderRepository interface.
+func (r *FolderRepository) ListFolders(
+	ctx context.Context,
+	userID string,
+) ([]*model.Folder, error) {
+	folders, err := r.storage.ListFolders(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list folders: %w", err)
+	}
+
+	for i, folder := range folders {
+		folders[i], err = r.open(ctx, folder)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return folders, nil
+}
+
+// RenameFolder encrypts the name with a new name key and replaces it.
+//
+// Implements the repository.FolderRepository interface.
+func (r *FolderRepository) RenameFolder(ctx context.Context, folder *model.Folder) error {
+	sealed, err := r.seal(ctx, folder)
+	if err != nil {
+		return err
+	}
+
+	err = r.storage.RenameFolder(ctx, sealed)
+	if err != nil {
+		return fmt.Errorf("rename folder: %w", err)
+	}
+
+	return nil
+}
+
+// MoveFolder replaces the parent of the user's folder, the name is kept as is.
+//
+// Implements the repository.FolderRepository interface.
+func (r *FolderRepository) MoveFolder(ctx context.Context, folder *model.Folder) error {
+	err := r.storage.MoveFolder(ctx, folder)
+	if err != nil {
+		return fmt.Errorf("move folder: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteFolder deletes the user's folder with all its descendants.
+//
+// Implements the repository.FolderRepository interface.
+func (r *FolderRepository) DeleteFolder(
+	ctx context.Context,
+	userID string,
+	folderID string,
+) ([]string, error) {
+	deleted, err := r.storage.DeleteFolder(ctx, userID, folderID)
+	if err != nil {
+		return nil, fmt.Errorf("delete folder: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// RewrapDataKeys re-wraps up to limit name keys wrapped with old master keys
+// with the current master key.
+//
+// The names are not re-encrypted, and folders renamed concurrently are skipped: they are already
+// encrypted with the current master key.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - limit int: maximum number of name keys in the batch.
+func (r *FolderRepository) RewrapDataKeys(
+	ctx context.Context,
+	limit int,
+) (atrest.RewrapResult, error) {
+	result := atrest.RewrapResult{Found: 0, Rewrapped: 0, Skipped: 0}
+
+	currentKeyID, err := r.encrypter.CurrentKeyID(ctx)
+	if err != nil {
+		return result, fmt.Errorf("get current key: %w", err)
+	}
+
+	keys, err := r.storage.ListStaleNameKeys(ctx, currentKeyID, limit)
+	if err != nil {
+		return result, fmt.Errorf("list stale name keys: %w", err)
+	}
+
+	return rewrapKeys(ctx, r.encrypter, keys,
+		func(key *model.FolderNameKey) (*atrest.WrappedKey, []byte, string) {
+			return &key.NameKey, folderAdditionalData(
+				key.UserID,
+				key.FolderID,
+			), "folder " + key.FolderID
+		},
+		r.storage.ReplaceNameKey,
+	)
+}
+
+// seal returns a copy of the folder with the name encrypted at rest.
+func (r *FolderRepository) seal(ctx context.Context, folder *model.Folder) (*model.Folder, error) {
+	sealed, err := r.encrypter.Encrypt(
+		ctx,
+		folder.Name,
+		folderAdditionalData(folder.UserID, folder.ID),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt folder name: %w", err)
+	}
+
+	clone := folder.Clone()
+	clone.Name = sealed.Data
+	clone.NameKey = sealed.DataKey
+
+	return clone, nil
+}
+
+// open decrypts the name of the folder read from the storage.
+func (r *FolderRepository) open(ctx context.Context, folder *model.Folder) (*model.Folder, error) {
+	if len(folder.NameKey.Key) == 0 {
+		return folder, nil
+	}
+
+	name, err := r.encrypter.Decrypt(ctx, &atrest.Sealed{
+		Data:    folder.Name,
+		DataKey: folder.NameKey,
+	}, folderAdditionalData(folder.UserID, folder.ID))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt name of folder %s: %w", folder.ID, err)
+	}
+
+	folder.Name = name
+	folder.NameKey = atrest.WrappedKey{MasterKeyID: "", Key: nil}
+
+	return folder, nil
+}
+
+// folderAdditionalData returns the additional data that binds the encrypted name to the folder.
+func folderAdditionalData(userID string, folderID string) []byte {
+	return []byte(additionalDataPrefixFolder + userID + "/" + folderID)
+}
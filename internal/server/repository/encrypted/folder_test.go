@@ -0,0 +1,129 @@
+package encrypted_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/encrypted"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFolderRepository creates the folder decorator over the storage with the keyring,
+// the first key is the current one.
+func newFolderRepository(
+	t *testing.T,
+	storage encrypted.FolderStorage,
+	keys ...*atrest.MasterKey,
+) *encrypted.FolderRepository {
+	t.Helper()
+
+	provider, err := atrest.NewStaticKeyProvider(keys)
+	require.NoError(t, err)
+
+	return encrypted.NewFolderRepository(storage, atrest.NewEncrypter(provider))
+}
+
+// newFolder creates a top-level folder of alice.
+func newFolder(folderID string, name string) *model.Folder {
+	now := time.Now().UTC()
+
+	return &model.Folder{
+		ID:        folderID,
+		UserID:    "alice",
+		ParentID:  "",
+		Name:      []byte(name),
+		CreatedAt: now,
+		UpdatedAt: now,
+		NameKey:   atrest.WrappedKey{MasterKeyID: "", Key: nil},
+	}
+}
+
+func TestFolderRepository_EncryptsAtRest(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := memory.NewFolderRepository()
+	repo := newFolderRepository(t, storage, newMasterKey(t, "2026-10"))
+
+	folder := newFolder("f1", "client ciphertext")
+	require.NoError(t, repo.CreateFolder(ctx, folder))
+
+	stored, err := storage.GetFolder(ctx, "alice", "f1")
+	require.NoError(t, err)
+	assert.NotContains(t, string(stored.Name), "client ciphertext")
+	assert.Equal(t, "2026-10", stored.NameKey.MasterKeyID)
+
+	fetched, err := repo.GetFolder(ctx, "alice", "f1")
+	require.NoError(t, err)
+	assert.Equal(t, folder, fetched)
+
+	folder.Name = []byte("renamed ciphertext")
+	require.NoError(t, repo.RenameFolder(ctx, folder))
+
+	child := newFolder("f2", "child")
+	require.NoError(t, repo.CreateFolder(ctx, child))
+
+	child.ParentID = "f1"
+	require.NoError(t, repo.MoveFolder(ctx, child))
+
+	listed, err := repo.ListFolders(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Equal(t, []byte("renamed ciphertext"), listed[0].Name)
+	assert.Equal(t, []byte("child"), listed[1].Name, "moving must keep the name readable")
+
+	legacy := newFolder("f3", "stored before encryption at rest")
+	require.NoError(t, storage.CreateFolder(ctx, legacy))
+
+	fetched, err = repo.GetFolder(ctx, "alice", "f3")
+	require.NoError(t, err)
+	assert.Equal(t, legacy, fetched)
+}
+
+func TestFolderRepository_RewrapDataKeys(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := memory.NewFolderRepository()
+	previous := newMasterKey(t, "2026-09")
+	current := newMasterKey(t, "2026-10")
+
+	oldRepo := newFolderRepository(t, storage, previous)
+	for _, id := range []string{"f1", "f2", "f3"} {
+		require.NoError(t, oldRepo.CreateFolder(ctx, newFolder(id, "name "+id)))
+	}
+
+	repo := newFolderRepository(t, storage, current, previous)
+
+	// A folder renamed during the rotation is wrapped with the current key at once.
+	require.NoError(t, repo.RenameFolder(ctx, newFolder("f3", "renamed f3")))
+
+	result, err := repo.RewrapDataKeys(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, atrest.RewrapResult{Found: 1, Rewrapped: 1, Skipped: 0}, result)
+
+	result, err = repo.RewrapDataKeys(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, atrest.RewrapResult{Found: 1, Rewrapped: 1, Skipped: 0}, result)
+
+	// The previous key is no longer needed.
+	rotated := newFolderRepository(t, storage, current)
+
+	listed, err := rotated.ListFolders(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, listed, 3)
+
+	for _, folder := range listed {
+		want := "name " + folder.ID
+		if folder.ID == "f3" {
+			want = "renamed f3"
+		}
+
+		assert.Equal(t, want, string(folder.Name))
+	}
+}
@@ -0,0 +1,57 @@
+// Package encrypted provides repository decorators that encrypt data at rest.
+package encrypted
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// rewrapKeys re-wraps the keys read from the storage with the current master key
+// and replaces them in the storage.
+//
+// Keys changed or deleted concurrently are skipped: the keys written instead of them
+// are already wrapped with the current master key.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - encrypter *atrest.Encrypter: encrypter of data at rest;
+//   - keys []K: keys wrapped with old master keys;
+//   - describe func(K): returns the wrapped key, its additional data and the name of its owner;
+//   - replace func: replaces the key in the storage if it has not been changed.
+func rewrapKeys[K any](
+	ctx context.Context,
+	encrypter *atrest.Encrypter,
+	keys []K,
+	describe func(key K) (*atrest.WrappedKey, []byte, string),
+	replace func(ctx context.Context, key K, newKey atrest.WrappedKey) error,
+) (atrest.RewrapResult, error) {
+	result := atrest.RewrapResult{Found: len(keys), Rewrapped: 0, Skipped: 0}
+
+	for _, key := range keys {
+		wrapped, additionalData, owner := describe(key)
+
+		rewrapped, err := encrypter.Rewrap(ctx, wrapped, additionalData)
+		if err != nil {
+			return result, fmt.Errorf("rewrap key of %s: %w", owner, err)
+		}
+
+		err = replace(ctx, key, *rewrapped)
+		if err != nil {
+			if errors.Is(err, repository.ErrConflict) || errors.Is(err, repository.ErrNotFound) {
+				result.Skipped++
+
+				continue
+			}
+
+			return result, fmt.Errorf("replace key of %s: %w", owner, err)
+		}
+
+		result.Rewrapped++
+	}
+
+	return result, nil
+}
@@ -0,0 +1,426 @@
+// Package encrypted provides repository decorators that encrypt data at rest.
+package encrypted
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// additionalDataPrefixSecret binds the encrypted data to the secret it belongs to,
+// so the data of one secret cannot be moved to another one.
+const additionalDataPrefixSecret = "go-password-keeper/v1/at-rest/secret:"
+
+// SecretStorage describes the underlying storage of secrets with access to their trash,
+// data keys and references to blobs.
+type SecretStorage interface {
+	repository.SecretRepository
+	repository.SecretTrash
+	repository.BlobReferenceStore
+	repository.SecretDataKeyStore
+}
+
+// SecretRepository - decorator that encrypts the data of secrets before saving
+// and decrypts it after reading.
+//
+// Secrets saved before the encryption at rest was enabled are read as is.
+//
+// Implements the repository.SecretRepository, repository.SecretTrash
+// and repository.BlobReferenceStore interfaces.
+type SecretRepository struct {
+	storage   SecretStorage
+	encrypter *atrest.Encrypter
+}
+
+// NewSecretRepository creates a new *SecretRepository instance.
+//
+// Parameters:
+//   - storage SecretStorage: underlying storage;
+//   - encrypter *atrest.Encrypter: encrypter of data at rest.
+func NewSecretRepository(storage SecretStorage, encrypter *atrest.Encrypter) *SecretRepository {
+	return &SecretRepository{
+		storage:   storage,
+		encrypter: encrypter,
+	}
+}
+
+// Create encrypts the data and saves a new secret.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) Create(ctx context.Context, secret *model.Secret) error {
+	sealed, err := r.seal(ctx, secret)
+	if err != nil {
+		return err
+	}
+
+	err = r.storage.Create(ctx, sealed)
+	if err != nil {
+		return fmt.Errorf("create secret: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the user's secret by identifier with decrypted data.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) Get(
+	ctx context.Context,
+	userID string,
+	secretID string,
+) (*model.Secret, error) {
+	secret, err := r.storage.Get(ctx, userID, secretID)
+	if err != nil {
+		return nil, fmt.Errorf("get secret: %w", err)
+	}
+
+	return r.open(ctx, secret)
+}
+
+// Update encrypts the data with a new data key and replaces the secret
+// if it has not been changed since the revision was read.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) Update(ctx context.Context, secret *model.Secret) error {
+	sealed, err := r.seal(ctx, secret)
+	if err != nil {
+		return err
+	}
+
+	err = r.storage.Update(ctx, sealed)
+	if err != nil {
+		return fmt.Errorf("update secret: %w", err)
+	}
+
+	secret.Revision = sealed.Revision
+
+	return nil
+}
+
+// Delete moves the user's secret to the trash if it has not been changed since the revision was read.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) Delete(
+	ctx context.Context,
+	userID string,
+	secretID string,
+	revision int64,
+) error {
+	err := r.storage.Delete(ctx, userID, secretID, revision)
+	if err != nil {
+		return fmt.Errorf("delete secret: %w", err)
+	}
+
+	return nil
+}
+
+// List returns all the user's secrets with decrypted data sorted by creation time.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) List(ctx context.Context, userID string) ([]*model.Secret, error) {
+	secrets, err := r.storage.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list secrets: %w", err)
+	}
+
+	for i, secret := range secrets {
+		secrets[i], err = r.open(ctx, secret)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return secrets, nil
+}
+
+// ListPage returns a page of the user's secrets with decrypted data,
+// only the secrets of the page are decrypted.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) ListPage(
+	ctx context.Context,
+	userID string,
+	query *model.SecretQuery,
+) ([]*model.Secret, error) {
+	secrets, err := r.storage.ListPage(ctx, userID, query)
+	if err != nil {
+		return nil, fmt.Errorf("list secret page: %w", err)
+	}
+
+	for i, secret := range secrets {
+		secrets[i], err = r.open(ctx, secret)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return secrets, nil
+}
+
+// ListVersions returns the history of the user's secret with decrypted data, the newest revision first.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) ListVersions(
+	ctx context.Context,
+	userID string,
+	secretID string,
+) ([]*model.SecretVersion, error) {
+	versions, err := r.storage.ListVersions(ctx, userID, secretID)
+	if err != nil {
+		return nil, fmt.Errorf("list secret versions: %w", err)
+	}
+
+	for _, version := range versions {
+		_, err = r.open(ctx, &version.Secret)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return versions, nil
+}
+
+// GetVersion returns the revision of the user's secret from its history with decrypted data.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) GetVersion(
+	ctx context.Context,
+	userID string,
+	secretID string,
+	revision int64,
+) (*model.SecretVersion, error) {
+	version, err := r.storage.GetVersion(ctx, userID, secretID, revision)
+	if err != nil {
+		return nil, fmt.Errorf("get secret version: %w", err)
+	}
+
+	_, err = r.open(ctx, &version.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+// PruneVersions deletes the old revisions from the history of the user's secret.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) PruneVersions(
+	ctx context.Context,
+	userID string,
+	secretID string,
+	keep int,
+	archivedBefore time.Time,
+) (int, error) {
+	deleted, err := r.storage.PruneVersions(ctx, userID, secretID, keep, archivedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("prune secret versions: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// ListChanges returns up to limit changes of the user's secrets made after the position
+// of the change log, the change log contains no data to decrypt.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) ListChanges(
+	ctx context.Context,
+	userID string,
+	after int64,
+	limit int,
+) ([]*model.SecretChange, error) {
+	changes, err := r.storage.ListChanges(ctx, userID, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list secret changes: %w", err)
+	}
+
+	return changes, nil
+}
+
+// ListTrash returns the user's deleted secrets with decrypted data, the most recently deleted first.
+//
+// Implements the repository.SecretTrash interface.
+func (r *SecretRepository) ListTrash(
+	ctx context.Context,
+	userID string,
+) ([]*model.TrashedSecret, error) {
+	secrets, err := r.storage.ListTrash(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list trash: %w", err)
+	}
+
+	for _, secret := range secrets {
+		_, err = r.open(ctx, &secret.Secret)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return secrets, nil
+}
+
+// RestoreTrash moves the user's deleted secret back from the trash and returns it with decrypted data.
+//
+// Implements the repository.SecretTrash interface.
+func (r *SecretRepository) RestoreTrash(
+	ctx context.Context,
+	userID string,
+	secretID string,
+) (*model.Secret, error) {
+	secret, err := r.storage.RestoreTrash(ctx, userID, secretID)
+	if err != nil {
+		return nil, fmt.Errorf("restore secret from trash: %w", err)
+	}
+
+	return r.open(ctx, secret)
+}
+
+// PurgeTrash permanently deletes the secrets kept in the trash for too long.
+//
+// Implements the repository.SecretTrash interface.
+func (r *SecretRepository) PurgeTrash(
+	ctx context.Context,
+	deletedBefore time.Time,
+	limit int,
+) (int, error) {
+	purged, err := r.storage.PurgeTrash(ctx, deletedBefore, limit)
+	if err != nil {
+		return 0, fmt.Errorf("purge trash: %w", err)
+	}
+
+	return purged, nil
+}
+
+// HoldBlob protects the blob from the collection, blob references are not encrypted.
+//
+// Implements the repository.BlobReferenceStore interface.
+func (r *SecretRepository) HoldBlob(ctx context.Context, blobID string, until time.Time) error {
+	err := r.storage.HoldBlob(ctx, blobID, until)
+	if err != nil {
+		return fmt.Errorf("hold blob: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimBlob marks the unused blob as being collected.
+//
+// Implements the repository.BlobReferenceStore interface.
+func (r *SecretRepository) ClaimBlob(
+	ctx context.Context,
+	blobID string,
+	now time.Time,
+) (bool, error) {
+	claimed, err := r.storage.ClaimBlob(ctx, blobID, now)
+	if err != nil {
+		return false, fmt.Errorf("claim blob: %w", err)
+	}
+
+	return claimed, nil
+}
+
+// ReleaseBlob removes the mark of the collection.
+//
+// Implements the repository.BlobReferenceStore interface.
+func (r *SecretRepository) ReleaseBlob(ctx context.Context, blobID string) error {
+	err := r.storage.ReleaseBlob(ctx, blobID)
+	if err != nil {
+		return fmt.Errorf("release blob: %w", err)
+	}
+
+	return nil
+}
+
+// ListBlobReferences returns the references of all revisions to blobs.
+//
+// Implements the repository.BlobReferenceStore interface.
+func (r *SecretRepository) ListBlobReferences(ctx context.Context) ([]*model.BlobReference, error) {
+	references, err := r.storage.ListBlobReferences(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list blob references: %w", err)
+	}
+
+	return references, nil
+}
+
+// RewrapDataKeys re-wraps up to limit data keys wrapped with old master keys
+// with the current master key.
+//
+// The keys of the blobs and the revisions in the history of secrets and the secrets in the trash
+// are re-wrapped as well. The data is not re-encrypted, and secrets changed concurrently
+// are skipped: they are already encrypted with the current master key.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - limit int: maximum number of data keys in the batch.
+func (r *SecretRepository) RewrapDataKeys(
+	ctx context.Context,
+	limit int,
+) (atrest.RewrapResult, error) {
+	result := atrest.RewrapResult{Found: 0, Rewrapped: 0, Skipped: 0}
+
+	currentKeyID, err := r.encrypter.CurrentKeyID(ctx)
+	if err != nil {
+		return result, fmt.Errorf("get current key: %w", err)
+	}
+
+	keys, err := r.storage.ListStaleDataKeys(ctx, currentKeyID, limit)
+	if err != nil {
+		return result, fmt.Errorf("list stale data keys: %w", err)
+	}
+
+	return rewrapKeys(ctx, r.encrypter, keys,
+		func(key *model.SecretDataKey) (*atrest.WrappedKey, []byte, string) {
+			if key.BlobID != "" {
+				return &key.DataKey, model.BlobKeyAdditionalData(key.BlobID), "blob " + key.BlobID
+			}
+
+			return &key.DataKey, additionalData(key.UserID, key.SecretID), "secret " + key.SecretID
+		},
+		r.storage.ReplaceDataKey,
+	)
+}
+
+// seal returns a copy of the secret with the data encrypted at rest.
+func (r *SecretRepository) seal(ctx context.Context, secret *model.Secret) (*model.Secret, error) {
+	sealed, err := r.encrypter.Encrypt(ctx, secret.Data, additionalData(secret.UserID, secret.ID))
+	if err != nil {
+		return nil, fmt.Errorf("encrypt secret data: %w", err)
+	}
+
+	clone := secret.Clone()
+	clone.Data = sealed.Data
+	clone.DataKey = sealed.DataKey
+
+	return clone, nil
+}
+
+// open decrypts the data of the secret read from the storage.
+func (r *SecretRepository) open(ctx context.Context, secret *model.Secret) (*model.Secret, error) {
+	if len(secret.DataKey.Key) == 0 {
+		return secret, nil
+	}
+
+	data, err := r.encrypter.Decrypt(ctx, &atrest.Sealed{
+		Data:    secret.Data,
+		DataKey: secret.DataKey,
+	}, additionalData(secret.UserID, secret.ID))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt data of secret %s: %w", secret.ID, err)
+	}
+
+	secret.Data = data
+	secret.DataKey = atrest.WrappedKey{MasterKeyID: "", Key: nil}
+
+	return secret, nil
+}
+
+// additionalData returns the additional data that binds the encrypted data to the secret.
+func additionalData(userID string, secretID string) []byte {
+	return []byte(additionalDataPrefixSecret + userID + "/" + secretID)
+}
@@ -0,0 +1,349 @@
+package encrypted_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/encrypted"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMasterKey creates a random master key with the identifier.
+func newMasterKey(t *testing.T, keyID string) *atrest.MasterKey {
+	t.Helper()
+
+	key, err := crypto.GenerateVaultKey()
+	require.NoError(t, err)
+
+	return &atrest.MasterKey{ID: keyID, Key: key}
+}
+
+// newRepository creates the decorator over the storage with the keyring,
+// the first key is the current one.
+func newRepository(
+	t *testing.T,
+	storage encrypted.SecretStorage,
+	keys ...*atrest.MasterKey,
+) *encrypted.SecretRepository {
+	t.Helper()
+
+	provider, err := atrest.NewStaticKeyProvider(keys)
+	require.NoError(t, err)
+
+	return encrypted.NewSecretRepository(storage, atrest.NewEncrypter(provider))
+}
+
+// newSecret creates a secret of the user.
+func newSecret(userID string, secretID string, data string) *model.Secret {
+	now := time.Now().UTC()
+
+	return &model.Secret{
+		ID:        secretID,
+		UserID:    userID,
+		FolderID:  "",
+		Type:      secret.TypeText,
+		Metadata:  map[string]string{"site": "example.com"},
+		Tags:      nil,
+		Data:      []byte(data),
+		BlobID:    "",
+		BlobSize:  0,
+		BlobKey:   atrest.WrappedKey{MasterKeyID: "", Key: nil},
+		Revision:  1,
+		CreatedAt: now,
+		UpdatedAt: now,
+		DataKey:   atrest.WrappedKey{MasterKeyID: "", Key: nil},
+		Indexes:   nil,
+	}
+}
+
+func TestSecretRepository_EncryptsAtRest(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := memory.NewSecretRepository()
+	repo := newRepository(t, storage, newMasterKey(t, "2026-10"))
+
+	item := newSecret("alice", "s1", "client ciphertext")
+	require.NoError(t, repo.Create(ctx, item))
+
+	stored, err := storage.Get(ctx, "alice", "s1")
+	require.NoError(t, err)
+	assert.NotContains(t, string(stored.Data), "client ciphertext")
+	assert.Equal(t, "2026-10", stored.DataKey.MasterKeyID)
+
+	fetched, err := repo.Get(ctx, "alice", "s1")
+	require.NoError(t, err)
+	assert.Equal(t, item, fetched)
+
+	item.Data = []byte("updated ciphertext")
+	require.NoError(t, repo.Update(ctx, item))
+
+	listed, err := repo.List(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, []byte("updated ciphertext"), listed[0].Data)
+}
+
+func TestSecretRepository_ReadsUnencryptedSecrets(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := memory.NewSecretRepository()
+
+	legacy := newSecret("alice", "s1", "stored before encryption at rest")
+	require.NoError(t, storage.Create(ctx, legacy))
+
+	repo := newRepository(t, storage, newMasterKey(t, "2026-10"))
+
+	fetched, err := repo.Get(ctx, "alice", "s1")
+	require.NoError(t, err)
+	assert.Equal(t, legacy, fetched)
+}
+
+func TestSecretRepository_RejectsMovedData(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := memory.NewSecretRepository()
+	repo := newRepository(t, storage, newMasterKey(t, "2026-10"))
+
+	require.NoError(t, repo.Create(ctx, newSecret("alice", "s1", "alice data")))
+	require.NoError(t, repo.Create(ctx, newSecret("bob", "s2", "bob data")))
+
+	// An attacker with access to the database copies the data of one secret to another.
+	stolen, err := storage.Get(ctx, "alice", "s1")
+	require.NoError(t, err)
+
+	stolen.UserID, stolen.ID = "bob", "s2"
+	require.NoError(t, storage.Update(ctx, stolen))
+
+	_, err = repo.Get(ctx, "bob", "s2")
+	require.ErrorIs(t, err, crypto.ErrDecrypt)
+}
+
+func TestSecretRepository_RewrapDataKeys(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := memory.NewSecretRepository()
+	previous := newMasterKey(t, "2026-09")
+	current := newMasterKey(t, "2026-10")
+
+	oldRepo := newRepository(t, storage, previous)
+	for _, id := range []string{"s1", "s2", "s3"} {
+		require.NoError(t, oldRepo.Create(ctx, newSecret("alice", id, "data "+id)))
+	}
+
+	repo := newRepository(t, storage, current, previous)
+
+	// A secret written during the rotation is wrapped with the current key at once,
+	// its previous revision in the history still has to be re-wrapped.
+	require.NoError(t, repo.Update(ctx, newSecret("alice", "s3", "updated s3")))
+
+	result, err := repo.RewrapDataKeys(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, atrest.RewrapResult{Found: 1, Rewrapped: 1, Skipped: 0}, result)
+
+	result, err = repo.RewrapDataKeys(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, atrest.RewrapResult{Found: 2, Rewrapped: 2, Skipped: 0}, result)
+
+	result, err = repo.RewrapDataKeys(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Found)
+
+	// The previous key is no longer needed.
+	rotated := newRepository(t, storage, current)
+
+	listed, err := rotated.List(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, listed, 3)
+
+	for _, item := range listed {
+		want := "data " + item.ID
+		if item.ID == "s3" {
+			want = "updated s3"
+		}
+
+		assert.Equal(t, want, string(item.Data))
+	}
+
+	versions, err := rotated.ListVersions(ctx, "alice", "s3")
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, "data s3", string(versions[0].Data))
+}
+
+func TestSecretRepository_RewrapBlobKeys(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := memory.NewSecretRepository()
+	previous := newMasterKey(t, "2026-09")
+	current := newMasterKey(t, "2026-10")
+
+	oldProvider, err := atrest.NewStaticKeyProvider([]*atrest.MasterKey{previous})
+	require.NoError(t, err)
+
+	blobKey, err := atrest.NewStreamKey()
+	require.NoError(t, err)
+
+	blobID := strings.Repeat("ab", 32)
+
+	wrapped, err := atrest.NewEncrypter(oldProvider).WrapStreamKey(ctx, blobKey,
+		model.BlobKeyAdditionalData(blobID))
+	require.NoError(t, err)
+
+	item := newSecret("alice", "s1", "")
+	item.Type, item.BlobID, item.BlobSize, item.BlobKey = secret.TypeBinary, blobID, 7, *wrapped
+	require.NoError(t, newRepository(t, storage, previous).Create(ctx, item))
+
+	result, err := newRepository(t, storage, current, previous).RewrapDataKeys(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, atrest.RewrapResult{Found: 2, Rewrapped: 2, Skipped: 0}, result,
+		"the keys of the data and of the blob must be re-wrapped")
+
+	stored, err := storage.Get(ctx, "alice", "s1")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10", stored.DataKey.MasterKeyID)
+	assert.Equal(t, "2026-10", stored.BlobKey.MasterKeyID)
+
+	currentProvider, err := atrest.NewStaticKeyProvider([]*atrest.MasterKey{current})
+	require.NoError(t, err)
+
+	sealed := blobKey.Encrypt(strings.NewReader("payload"), []byte("blob"))
+	plaintext, err := atrest.NewEncrypter(currentProvider).DecryptStream(ctx, &stored.BlobKey,
+		sealed, model.BlobKeyAdditionalData(blobID), []byte("blob"))
+	require.NoError(t, err, "the previous key must not be needed to open the blob")
+
+	content, err := io.ReadAll(plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, "payload", string(content))
+}
+
+func TestSecretRepository_RewrapSkipsConcurrentChanges(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := memory.NewSecretRepository()
+	previous := newMasterKey(t, "2026-09")
+	current := newMasterKey(t, "2026-10")
+
+	require.NoError(
+		t,
+		newRepository(t, storage, previous).Create(ctx, newSecret("alice", "s1", "data")),
+	)
+
+	stale, err := storage.ListStaleDataKeys(ctx, current.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, stale, 1)
+
+	repo := newRepository(t, storage, current, previous)
+	require.NoError(t, repo.Update(ctx, newSecret("alice", "s1", "updated")))
+
+	// Another instance re-wraps the revision moved to the history by the update.
+	result, err := repo.RewrapDataKeys(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, atrest.RewrapResult{Found: 1, Rewrapped: 1, Skipped: 0}, result)
+
+	// The data key read before must overwrite neither the current revision nor the re-wrapped one.
+	err = storage.ReplaceDataKey(ctx, stale[0], stale[0].DataKey)
+	require.ErrorIs(t, err, repository.ErrConflict)
+
+	fetched, err := repo.Get(ctx, "alice", "s1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("updated"), fetched.Data)
+
+	version, err := newRepository(
+		t,
+		storage,
+		current,
+	).GetVersion(ctx, "alice", "s1", stale[0].Revision)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), version.Data)
+}
+
+func TestSecretRepository_CompareAndSwap(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newRepository(t, memory.NewSecretRepository(), newMasterKey(t, "2026-10"))
+
+	require.NoError(t, repo.Create(ctx, newSecret("alice", "s1", "revision 1")))
+
+	// Two clients read the same revision and try to replace it.
+	first := newSecret("alice", "s1", "first writer")
+	second := newSecret("alice", "s1", "second writer")
+
+	require.NoError(t, repo.Update(ctx, first))
+	assert.Equal(t, int64(2), first.Revision)
+
+	err := repo.Update(ctx, second)
+	require.ErrorIs(t, err, repository.ErrConflict)
+
+	err = repo.Delete(ctx, "alice", "s1", 1)
+	require.ErrorIs(t, err, repository.ErrConflict)
+
+	fetched, err := repo.Get(ctx, "alice", "s1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("first writer"), fetched.Data)
+
+	require.NoError(t, repo.Delete(ctx, "alice", "s1", fetched.Revision))
+
+	_, err = repo.Get(ctx, "alice", "s1")
+	require.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestSecretRepository_Trash(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := memory.NewSecretRepository()
+	previous := newMasterKey(t, "2026-09")
+	current := newMasterKey(t, "2026-10")
+
+	oldRepo := newRepository(t, storage, previous)
+	require.NoError(t, oldRepo.Create(ctx, newSecret("alice", "s1", "deleted data")))
+	require.NoError(t, oldRepo.Delete(ctx, "alice", "s1", 1))
+
+	// The secrets in the trash are re-wrapped too, so they survive the retirement of the key.
+	repo := newRepository(t, storage, current, previous)
+
+	result, err := repo.RewrapDataKeys(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Rewrapped)
+
+	rotated := newRepository(t, storage, current)
+
+	trashed, err := rotated.ListTrash(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, trashed, 1)
+	assert.Equal(t, "deleted data", string(trashed[0].Data))
+
+	restored, err := rotated.RestoreTrash(ctx, "alice", "s1")
+	require.NoError(t, err)
+	assert.Equal(t, "deleted data", string(restored.Data))
+
+	_, err = rotated.RestoreTrash(ctx, "alice", "s1")
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	require.NoError(t, rotated.Delete(ctx, "alice", "s1", 1))
+
+	purged, err := rotated.PurgeTrash(ctx, time.Now().Add(time.Minute), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	trashed, err = rotated.ListTrash(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, trashed)
+}
@@ -0,0 +1,213 @@
+// Package filesystem provides implementations of the repositories on top of the local file system.
+package filesystem
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// blobTempPattern - name pattern of the content being written, such files are not visible as blobs.
+const blobTempPattern = ".blob-*.part"
+
+// Errors returned by the blob store.
+var (
+	// errInvalidBlobID - the identifier of a new blob is not a SHA-256 hash.
+	errInvalidBlobID = errors.New("invalid blob identifier")
+
+	// errBlobSizeMismatch - the content is shorter or longer than the declared size.
+	errBlobSizeMismatch = errors.New("blob content does not match its size")
+
+	// errBlobHashMismatch - the hash of the content differs from the identifier of the blob.
+	errBlobHashMismatch = errors.New("blob content does not match its hash")
+)
+
+// BlobStore - storage of blobs in a directory of the local file system, one file per blob
+// named by the hash of its content.
+//
+// Implements the repository.BlobStore interface.
+type BlobStore struct {
+	dir string
+}
+
+// NewBlobStore creates a new *BlobStore instance.
+//
+// The directory is created if it does not exist.
+//
+// Parameters:
+//   - dir string: directory of the blobs.
+func NewBlobStore(dir string) (*BlobStore, error) {
+	err := os.MkdirAll(dir, dirPermissions)
+	if err != nil {
+		return nil, fmt.Errorf("create blob directory: %w", err)
+	}
+
+	return &BlobStore{dir: dir}, nil
+}
+
+// PutBlob saves the content of the given size as the blob with the hash.
+//
+// The content is written to a temporary file which is renamed when it is complete
+// and matches the hash, so readers never see a partial or corrupted blob.
+//
+// Implements the repository.BlobStore interface.
+func (s *BlobStore) PutBlob(_ context.Context, blobID string, content io.Reader, size int64) error {
+	if !model.IsValidBlobID(blobID) {
+		return fmt.Errorf("%w: %q", errInvalidBlobID, blobID)
+	}
+
+	file, err := os.CreateTemp(s.dir, blobTempPattern)
+	if err != nil {
+		return fmt.Errorf("create blob file: %w", err)
+	}
+
+	err = writeBlobFile(file, content, size, blobID)
+	if err == nil {
+		err = os.Rename(file.Name(), s.blobPath(blobID))
+	}
+
+	if err != nil {
+		return errors.Join(fmt.Errorf("save blob: %w", err), removeIfExists(file.Name()))
+	}
+
+	return nil
+}
+
+// HasBlob checks whether the blob is saved.
+//
+// Implements the repository.BlobStore interface.
+func (s *BlobStore) HasBlob(_ context.Context, blobID string) (bool, error) {
+	if !model.IsValidBlobID(blobID) {
+		return false, nil
+	}
+
+	_, err := os.Stat(s.blobPath(blobID))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("stat blob: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetBlob returns the reader of the content of the blob.
+//
+// Implements the repository.BlobStore interface.
+func (s *BlobStore) GetBlob(_ context.Context, blobID string) (io.ReadCloser, error) {
+	if !model.IsValidBlobID(blobID) {
+		return nil, repository.ErrNotFound
+	}
+
+	file, err := os.Open(s.blobPath(blobID))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, repository.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("open blob: %w", err)
+	}
+
+	return file, nil
+}
+
+// DeleteBlob deletes the blob, deleting a missing blob is not an error.
+//
+// Implements the repository.BlobStore interface.
+func (s *BlobStore) DeleteBlob(_ context.Context, blobID string) error {
+	if !model.IsValidBlobID(blobID) {
+		return nil
+	}
+
+	err := removeIfExists(s.blobPath(blobID))
+	if err != nil {
+		return fmt.Errorf("delete blob: %w", err)
+	}
+
+	return nil
+}
+
+// ListBlobs returns the blobs saved before the time.
+//
+// Implements the repository.BlobStore interface.
+func (s *BlobStore) ListBlobs(_ context.Context, savedBefore time.Time) ([]*model.Blob, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("list blobs: %w", err)
+	}
+
+	blobs := make([]*model.Blob, 0, len(entries))
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !model.IsValidBlobID(entry.Name()) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue // Deleted concurrently.
+			}
+
+			return nil, fmt.Errorf("stat blob: %w", err)
+		}
+
+		if info.ModTime().Before(savedBefore) {
+			blobs = append(blobs, &model.Blob{
+				ID:      entry.Name(),
+				Size:    info.Size(),
+				SavedAt: info.ModTime(),
+			})
+		}
+	}
+
+	return blobs, nil
+}
+
+func (s *BlobStore) blobPath(blobID string) string {
+	return filepath.Join(s.dir, blobID)
+}
+
+// writeBlobFile copies exactly size bytes of the content with the hash to the file and closes it.
+func writeBlobFile(file *os.File, content io.Reader, size int64, hash string) error {
+	hasher := sha256.New()
+
+	written, copyErr := io.Copy(io.MultiWriter(file, hasher), io.LimitReader(content, size+1))
+
+	if copyErr == nil && written != size {
+		copyErr = fmt.Errorf("%w: %d bytes expected", errBlobSizeMismatch, size)
+	}
+
+	if copyErr == nil && hex.EncodeToString(hasher.Sum(nil)) != hash {
+		copyErr = errBlobHashMismatch
+	}
+
+	err := errors.Join(copyErr, file.Sync(), file.Close())
+	if err != nil {
+		return fmt.Errorf("write blob file: %w", err)
+	}
+
+	return nil
+}
+
+// removeIfExists deletes the file, a missing file is not an error.
+func removeIfExists(path string) error {
+	err := os.Remove(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package filesystem_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/filesystem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blobID returns the identifier of the blob with the content.
+func blobID(content string) string {
+	hash := sha256.Sum256([]byte(content))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func TestBlobStore_PutGetDelete(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	blobs, err := filesystem.NewBlobStore(dir)
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	key := blobID("ciphertext")
+	require.NoError(t, blobs.PutBlob(ctx, key, strings.NewReader("ciphertext"), 10))
+	require.NoError(
+		t,
+		blobs.PutBlob(ctx, key, strings.NewReader("ciphertext"), 10),
+		"saving again is allowed",
+	)
+
+	exists, err := blobs.HasBlob(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	content, err := blobs.GetBlob(ctx, key)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	require.NoError(t, content.Close())
+	assert.Equal(t, []byte("ciphertext"), data)
+
+	require.NoError(t, blobs.DeleteBlob(ctx, key))
+	require.NoError(t, blobs.DeleteBlob(ctx, key), "deleting a missing blob is not an error")
+
+	_, err = blobs.GetBlob(ctx, key)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	exists, err = blobs.HasBlob(ctx, key)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = blobs.GetBlob(ctx, "../"+key)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestBlobStore_PutMismatch(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	blobs, err := filesystem.NewBlobStore(dir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		id      string
+		content string
+	}{
+		{name: "short", id: blobID("short"), content: "short"},
+		{name: "long", id: blobID("too long content"), content: "too long content"},
+		{name: "hash", id: blobID("original"), content: "forged!!"},
+	}
+
+	for _, tt := range tests {
+		err = blobs.PutBlob(t.Context(), tt.id, bytes.NewReader([]byte(tt.content)), 8)
+		require.Error(t, err, tt.name)
+
+		_, err = blobs.GetBlob(t.Context(), tt.id)
+		require.ErrorIs(t, err, repository.ErrNotFound, "partial blob must not be visible")
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "temporary files must be removed")
+}
+
+func TestBlobStore_ListBlobs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	blobs, err := filesystem.NewBlobStore(dir)
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	oldID, newID := blobID("old"), blobID("new")
+	require.NoError(t, blobs.PutBlob(ctx, oldID, strings.NewReader("old"), 3))
+	require.NoError(t, blobs.PutBlob(ctx, newID, strings.NewReader("new"), 3))
+
+	savedAt := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, oldID), savedAt, savedAt))
+
+	listed, err := blobs.ListBlobs(ctx, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, oldID, listed[0].ID)
+	assert.Equal(t, int64(3), listed[0].Size)
+}
@@ -0,0 +1,427 @@
+// Package filesystem provides implementations of the repositories on top of the local file system.
+package filesystem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// Layout of the upload directory: each upload is a pair of files named by its identifier.
+const (
+	uploadContentExt = ".bin"  // Content received so far.
+	uploadInfoExt    = ".json" // Description of the upload.
+
+	dirPermissions  = 0o700
+	filePermissions = 0o600
+)
+
+// errInvalidUploadID - the identifier of a new upload is not a UUID.
+var errInvalidUploadID = errors.New("invalid upload identifier")
+
+// uploadInfo describes the upload saved next to its content.
+//
+// The number of bytes received is not saved: it is the size of the content file,
+// so it stays correct even if the server stops in the middle of a chunk.
+type uploadInfo struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"userId"`
+	Length    int64             `json:"length"`
+	Metadata  map[string]string `json:"metadata"`
+	CreatedAt time.Time         `json:"createdAt"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+// UploadRepository - storage of resumable uploads in a directory of the local file system.
+//
+// Implements the repository.UploadRepository interface.
+type UploadRepository struct {
+	dir string
+
+	mu      sync.Mutex
+	writing map[string]struct{} // Uploads whose chunks are being written.
+}
+
+// NewUploadRepository creates a new *UploadRepository instance.
+//
+// The directory is created if it does not exist.
+//
+// Parameters:
+//   - dir string: directory of the uploads.
+func NewUploadRepository(dir string) (*UploadRepository, error) {
+	err := os.MkdirAll(dir, dirPermissions)
+	if err != nil {
+		return nil, fmt.Errorf("create upload directory: %w", err)
+	}
+
+	return &UploadRepository{
+		dir:     dir,
+		mu:      sync.Mutex{},
+		writing: make(map[string]struct{}),
+	}, nil
+}
+
+// CreateUpload saves a new empty upload.
+//
+// Implements the repository.UploadRepository interface.
+func (r *UploadRepository) CreateUpload(_ context.Context, upload *model.Upload) error {
+	if !isUUID(upload.ID) {
+		return fmt.Errorf("%w: %q", errInvalidUploadID, upload.ID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	content, err := os.OpenFile(
+		r.contentPath(upload.ID),
+		os.O_CREATE|os.O_EXCL|os.O_WRONLY,
+		filePermissions,
+	)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return repository.ErrAlreadyExists
+		}
+
+		return fmt.Errorf("create upload content: %w", err)
+	}
+
+	err = content.Close()
+	if err != nil {
+		return fmt.Errorf("close upload content: %w", err)
+	}
+
+	err = r.writeInfo(&uploadInfo{
+		ID:        upload.ID,
+		UserID:    upload.UserID,
+		Length:    upload.Length,
+		Metadata:  maps.Clone(upload.Metadata),
+		CreatedAt: upload.CreatedAt,
+		ExpiresAt: upload.ExpiresAt,
+	})
+	if err != nil {
+		// Without the description the content would never expire.
+		return errors.Join(err, r.remove(upload.ID))
+	}
+
+	return nil
+}
+
+// GetUpload returns the user's upload by identifier with the number of bytes received.
+//
+// Implements the repository.UploadRepository interface.
+func (r *UploadRepository) GetUpload(
+	_ context.Context,
+	userID string,
+	uploadID string,
+) (*model.Upload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, err := r.readUserInfo(userID, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	offset, err := r.contentSize(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Upload{
+		ID:        info.ID,
+		UserID:    info.UserID,
+		Length:    info.Length,
+		Offset:    offset,
+		Metadata:  info.Metadata,
+		CreatedAt: info.CreatedAt,
+		ExpiresAt: info.ExpiresAt,
+	}, nil
+}
+
+// WriteUpload appends the chunk to the content of the user's upload at the offset
+// and prolongs the upload until expiresAt.
+//
+// The chunk is copied straight to the content file, only one chunk of an upload
+// is written at a time.
+//
+// Implements the repository.UploadRepository interface.
+func (r *UploadRepository) WriteUpload(
+	_ context.Context,
+	userID string,
+	uploadID string,
+	offset int64,
+	chunk io.Reader,
+	expiresAt time.Time,
+) (int64, error) {
+	err := r.lockWriting(userID, uploadID, offset, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+
+	defer r.unlockWriting(uploadID)
+
+	content, err := os.OpenFile(r.contentPath(uploadID), os.O_WRONLY|os.O_APPEND, filePermissions)
+	if err != nil {
+		return 0, fmt.Errorf("open upload content: %w", err)
+	}
+
+	written, copyErr := io.Copy(content, chunk)
+
+	// The bytes received before a failure are kept, so they must reach the disk as well.
+	err = errors.Join(content.Sync(), content.Close())
+	if err != nil {
+		return written, fmt.Errorf("save upload content: %w", err)
+	}
+
+	if copyErr != nil {
+		return written, fmt.Errorf("write upload content: %w", copyErr)
+	}
+
+	return written, nil
+}
+
+// OpenUpload returns the reader of the content of the user's upload.
+//
+// Implements the repository.UploadRepository interface.
+func (r *UploadRepository) OpenUpload(
+	_ context.Context,
+	userID string,
+	uploadID string,
+) (io.ReadCloser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.readUserInfo(userID, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.Open(r.contentPath(uploadID))
+	if err != nil {
+		return nil, fmt.Errorf("open upload content: %w", err)
+	}
+
+	return content, nil
+}
+
+// DeleteUpload deletes the user's upload together with its content.
+//
+// Implements the repository.UploadRepository interface.
+func (r *UploadRepository) DeleteUpload(_ context.Context, userID string, uploadID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.readUserInfo(userID, uploadID)
+	if err != nil {
+		return err
+	}
+
+	if _, busy := r.writing[uploadID]; busy {
+		return repository.ErrConflict
+	}
+
+	return r.remove(uploadID)
+}
+
+// DeleteExpiredUploads deletes the uploads expired before the time and returns their number.
+//
+// Implements the repository.UploadRepository interface.
+func (r *UploadRepository) DeleteExpiredUploads(_ context.Context, before time.Time) (int, error) {
+	infoPaths, err := filepath.Glob(filepath.Join(r.dir, "*"+uploadInfoExt))
+	if err != nil {
+		return 0, fmt.Errorf("list uploads: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+
+	for _, infoPath := range infoPaths {
+		uploadID := strings.TrimSuffix(filepath.Base(infoPath), uploadInfoExt)
+
+		if _, busy := r.writing[uploadID]; busy || !isUUID(uploadID) {
+			continue
+		}
+
+		info, err := r.readInfo(uploadID)
+		if err != nil {
+			return deleted, err
+		}
+
+		if !info.ExpiresAt.Before(before) {
+			continue
+		}
+
+		err = r.remove(uploadID)
+		if err != nil {
+			return deleted, err
+		}
+
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// lockWriting checks the offset and marks the upload as being written.
+func (r *UploadRepository) lockWriting(
+	userID string,
+	uploadID string,
+	offset int64,
+	expiresAt time.Time,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, err := r.readUserInfo(userID, uploadID)
+	if err != nil {
+		return err
+	}
+
+	if _, busy := r.writing[uploadID]; busy {
+		return repository.ErrConflict
+	}
+
+	size, err := r.contentSize(uploadID)
+	if err != nil {
+		return err
+	}
+
+	if size != offset {
+		return repository.ErrConflict
+	}
+
+	info.ExpiresAt = expiresAt
+
+	err = r.writeInfo(info)
+	if err != nil {
+		return err
+	}
+
+	r.writing[uploadID] = struct{}{}
+
+	return nil
+}
+
+// unlockWriting marks the upload as not being written.
+func (r *UploadRepository) unlockWriting(uploadID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.writing, uploadID)
+}
+
+// readUserInfo reads the description of the upload if it belongs to the user.
+func (r *UploadRepository) readUserInfo(userID string, uploadID string) (*uploadInfo, error) {
+	if !isUUID(uploadID) {
+		return nil, repository.ErrNotFound
+	}
+
+	info, err := r.readInfo(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.UserID != userID {
+		return nil, repository.ErrNotFound
+	}
+
+	return info, nil
+}
+
+// readInfo reads the description of the upload.
+func (r *UploadRepository) readInfo(uploadID string) (*uploadInfo, error) {
+	data, err := os.ReadFile(r.infoPath(uploadID))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, repository.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("read upload info: %w", err)
+	}
+
+	var info uploadInfo
+
+	err = json.Unmarshal(data, &info)
+	if err != nil {
+		return nil, fmt.Errorf("decode upload info: %w", err)
+	}
+
+	return &info, nil
+}
+
+// writeInfo replaces the description of the upload atomically.
+func (r *UploadRepository) writeInfo(info *uploadInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("encode upload info: %w", err)
+	}
+
+	tmpPath := r.infoPath(info.ID) + ".tmp"
+
+	err = os.WriteFile(tmpPath, data, filePermissions)
+	if err != nil {
+		return fmt.Errorf("write upload info: %w", err)
+	}
+
+	err = os.Rename(tmpPath, r.infoPath(info.ID))
+	if err != nil {
+		return fmt.Errorf("replace upload info: %w", err)
+	}
+
+	return nil
+}
+
+// contentSize returns the number of bytes of the upload received so far.
+func (r *UploadRepository) contentSize(uploadID string) (int64, error) {
+	stat, err := os.Stat(r.contentPath(uploadID))
+	if err != nil {
+		return 0, fmt.Errorf("stat upload content: %w", err)
+	}
+
+	return stat.Size(), nil
+}
+
+// remove deletes the files of the upload, the description goes last so a failed deletion can be repeated.
+func (r *UploadRepository) remove(uploadID string) error {
+	err := os.Remove(r.contentPath(uploadID))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove upload content: %w", err)
+	}
+
+	err = os.Remove(r.infoPath(uploadID))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove upload info: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UploadRepository) contentPath(uploadID string) string {
+	return filepath.Join(r.dir, uploadID+uploadContentExt)
+}
+
+func (r *UploadRepository) infoPath(uploadID string) string {
+	return filepath.Join(r.dir, uploadID+uploadInfoExt)
+}
+
+// isUUID checks that the identifier is a UUID, so it cannot point outside the directory.
+func isUUID(id string) bool {
+	parsed, err := uuid.Parse(id)
+
+	return err == nil && parsed.String() == id
+}
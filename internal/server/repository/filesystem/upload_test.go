@@ -0,0 +1,127 @@
+package filesystem_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/filesystem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errConnectionLost = errors.New("connection lost")
+
+// newTestUpload creates an empty upload of the user in the repository.
+func newTestUpload(
+	t *testing.T,
+	uploads *filesystem.UploadRepository,
+	userID string,
+	expiresAt time.Time,
+) *model.Upload {
+	t.Helper()
+
+	upload := &model.Upload{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Length:    8,
+		Offset:    0,
+		Metadata:  map[string]string{"filename": "key.pem"},
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: expiresAt,
+	}
+	require.NoError(t, uploads.CreateUpload(t.Context(), upload))
+
+	return upload
+}
+
+func TestUploadRepository_Resume(t *testing.T) {
+	t.Parallel()
+
+	uploads, err := filesystem.NewUploadRepository(t.TempDir())
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	expiresAt := time.Now().Add(time.Hour)
+	upload := newTestUpload(t, uploads, "alice", expiresAt)
+	write := func(offset int64, chunk io.Reader) (int64, error) {
+		return uploads.WriteUpload(ctx, "alice", upload.ID, offset, chunk, expiresAt)
+	}
+
+	// The connection drops after a part of the chunk: the received bytes are kept.
+	written, err := write(
+		0,
+		io.MultiReader(bytes.NewReader([]byte("abc")), iotest.ErrReader(errConnectionLost)),
+	)
+	require.ErrorIs(t, err, errConnectionLost)
+	assert.Equal(t, int64(3), written)
+
+	stored, err := uploads.GetUpload(ctx, "alice", upload.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stored.Offset)
+	assert.Equal(t, upload.Metadata, stored.Metadata)
+
+	_, err = write(0, bytes.NewReader([]byte("abc")))
+	require.ErrorIs(t, err, repository.ErrConflict, "chunk must start at the received offset")
+
+	written, err = write(3, bytes.NewReader([]byte("defgh")))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), written)
+
+	content, err := uploads.OpenUpload(ctx, "alice", upload.ID)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	require.NoError(t, content.Close())
+	assert.Equal(t, []byte("abcdefgh"), data)
+
+	_, err = uploads.GetUpload(ctx, "bob", upload.ID)
+	require.ErrorIs(t, err, repository.ErrNotFound, "uploads of other users must be hidden")
+
+	_, err = uploads.GetUpload(ctx, "alice", "../"+upload.ID)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	require.NoError(t, uploads.DeleteUpload(ctx, "alice", upload.ID))
+
+	_, err = uploads.OpenUpload(ctx, "alice", upload.ID)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestUploadRepository_DeleteExpired(t *testing.T) {
+	t.Parallel()
+
+	uploads, err := filesystem.NewUploadRepository(t.TempDir())
+	require.NoError(t, err)
+
+	now := time.Now()
+	abandoned := newTestUpload(t, uploads, "alice", now.Add(-time.Minute))
+	active := newTestUpload(t, uploads, "alice", now.Add(-time.Minute))
+
+	// Writing a chunk prolongs the upload.
+	_, err = uploads.WriteUpload(
+		t.Context(),
+		"alice",
+		active.ID,
+		0,
+		bytes.NewReader([]byte("a")),
+		now.Add(time.Hour),
+	)
+	require.NoError(t, err)
+
+	deleted, err := uploads.DeleteExpiredUploads(t.Context(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	_, err = uploads.GetUpload(t.Context(), "alice", abandoned.ID)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	_, err = uploads.GetUpload(t.Context(), "alice", active.ID)
+	require.NoError(t, err)
+}
@@ -0,0 +1,61 @@
+// Package memory provides in-memory implementations of the repositories.
+//
+// The data is lost when the application is restarted.
+// Used for single-node runs and tests.
+package memory
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+)
+
+// AuditLog - in-memory append-only storage of audit events.
+//
+// Implements the repository.AuditLog interface.
+type AuditLog struct {
+	mu     sync.RWMutex
+	byUser map[string][]model.AuditEvent
+}
+
+// NewAuditLog creates a new *AuditLog instance.
+func NewAuditLog() *AuditLog {
+	return &AuditLog{
+		mu:     sync.RWMutex{},
+		byUser: make(map[string][]model.AuditEvent),
+	}
+}
+
+// Append saves a new event.
+//
+// Implements the repository.AuditLog interface.
+func (l *AuditLog) Append(_ context.Context, event *model.AuditEvent) error {
+	stored := *event
+	stored.Details = maps.Clone(event.Details)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.byUser[event.UserID] = append(l.byUser[event.UserID], stored)
+
+	return nil
+}
+
+// List returns all events of the user sorted by time.
+//
+// Implements the repository.AuditLog interface.
+func (l *AuditLog) List(_ context.Context, userID string) ([]*model.AuditEvent, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	events := make([]*model.AuditEvent, 0, len(l.byUser[userID]))
+
+	for _, event := range l.byUser[userID] {
+		event.Details = maps.Clone(event.Details)
+		events = append(events, &event)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,97 @@
+// Package memory provides in-memory implementations of the repositories.
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+)
+
+// subscriberBufferSize - number of events a subscriber may lag behind before it is disconnected.
+const subscriberBufferSize = 16
+
+// EventBroker - in-process delivery of the change events of the users' vaults.
+//
+// Events reach only the subscribers connected to the same instance.
+//
+// Implements the repository.EventBroker interface.
+type EventBroker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan model.VaultEvent]struct{}
+}
+
+// NewEventBroker creates a new *EventBroker instance.
+func NewEventBroker() *EventBroker {
+	return &EventBroker{
+		mu:          sync.Mutex{},
+		subscribers: make(map[string]map[chan model.VaultEvent]struct{}),
+	}
+}
+
+// Publish delivers the event to all current subscribers of its user.
+//
+// A subscriber whose buffer is full is disconnected instead of blocking the publisher.
+//
+// Implements the repository.EventBroker interface.
+func (b *EventBroker) Publish(_ context.Context, event model.VaultEvent) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for stream := range b.subscribers[event.UserID] {
+		select {
+		case stream <- event:
+		default:
+			b.unsubscribe(event.UserID, stream)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe returns the stream of the events of the user.
+//
+// Implements the repository.EventBroker interface.
+func (b *EventBroker) Subscribe(
+	ctx context.Context,
+	userID string,
+) (<-chan model.VaultEvent, error) {
+	stream := make(chan model.VaultEvent, subscriberBufferSize)
+
+	b.mu.Lock()
+
+	streams, ok := b.subscribers[userID]
+	if !ok {
+		streams = make(map[chan model.VaultEvent]struct{})
+		b.subscribers[userID] = streams
+	}
+
+	streams[stream] = struct{}{}
+
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		b.unsubscribe(userID, stream)
+	}()
+
+	return stream, nil
+}
+
+// unsubscribe removes the subscriber and closes its stream. Must be called under the lock.
+func (b *EventBroker) unsubscribe(userID string, stream chan model.VaultEvent) {
+	if _, ok := b.subscribers[userID][stream]; !ok {
+		return
+	}
+
+	delete(b.subscribers[userID], stream)
+	close(stream)
+
+	if len(b.subscribers[userID]) == 0 {
+		delete(b.subscribers, userID)
+	}
+}
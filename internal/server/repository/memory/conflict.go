@@ -0,0 +1,114 @@
+// Package memory provides in-memory implementations of the repositories.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// SecretConflictRepository - in-memory storage of unresolved conflicts of offline edits.
+//
+// Implements the repository.SecretConflictRepository interface.
+type SecretConflictRepository struct {
+	mu     sync.RWMutex
+	byUser map[string]map[string]*model.SecretConflict
+}
+
+// NewSecretConflictRepository creates a new *SecretConflictRepository instance.
+func NewSecretConflictRepository() *SecretConflictRepository {
+	return &SecretConflictRepository{
+		mu:     sync.RWMutex{},
+		byUser: make(map[string]map[string]*model.SecretConflict),
+	}
+}
+
+// CreateConflict saves a new conflict.
+//
+// Implements the repository.SecretConflictRepository interface.
+func (r *SecretConflictRepository) CreateConflict(
+	_ context.Context,
+	conflict *model.SecretConflict,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	conflicts, ok := r.byUser[conflict.UserID]
+	if !ok {
+		conflicts = make(map[string]*model.SecretConflict)
+		r.byUser[conflict.UserID] = conflicts
+	}
+
+	if _, exists := conflicts[conflict.ID]; exists {
+		return repository.ErrAlreadyExists
+	}
+
+	conflicts[conflict.ID] = conflict.Clone()
+
+	return nil
+}
+
+// GetConflict returns the user's conflict by identifier.
+//
+// Implements the repository.SecretConflictRepository interface.
+func (r *SecretConflictRepository) GetConflict(
+	_ context.Context,
+	userID string,
+	conflictID string,
+) (*model.SecretConflict, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	conflict, ok := r.byUser[userID][conflictID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	return conflict.Clone(), nil
+}
+
+// ListConflicts returns all conflicts of the user sorted by detection time.
+//
+// Implements the repository.SecretConflictRepository interface.
+func (r *SecretConflictRepository) ListConflicts(
+	_ context.Context,
+	userID string,
+) ([]*model.SecretConflict, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	conflicts := make([]*model.SecretConflict, 0, len(r.byUser[userID]))
+	for _, conflict := range r.byUser[userID] {
+		conflicts = append(conflicts, conflict.Clone())
+	}
+
+	slices.SortFunc(conflicts, func(a, b *model.SecretConflict) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return conflicts, nil
+}
+
+// DeleteConflict deletes the user's conflict.
+//
+// Implements the repository.SecretConflictRepository interface.
+func (r *SecretConflictRepository) DeleteConflict(
+	_ context.Context,
+	userID string,
+	conflictID string,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byUser[userID][conflictID]; !ok {
+		return repository.ErrNotFound
+	}
+
+	delete(r.byUser[userID], conflictID)
+
+	return nil
+}
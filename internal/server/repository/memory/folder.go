@@ -0,0 +1,256 @@
+// Package memory provides in-memory implementations of the repositories.
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// FolderRepository - in-memory storage of the folders of the users' vaults.
+//
+// Implements the repository.FolderRepository and repository.FolderNameKeyStore interfaces.
+type FolderRepository struct {
+	mu     sync.RWMutex
+	byUser map[string]map[string]*model.Folder
+}
+
+// NewFolderRepository creates a new *FolderRepository instance.
+func NewFolderRepository() *FolderRepository {
+	return &FolderRepository{
+		mu:     sync.RWMutex{},
+		byUser: make(map[string]map[string]*model.Folder),
+	}
+}
+
+// CreateFolder saves a new folder.
+//
+// Implements the repository.FolderRepository interface.
+func (r *FolderRepository) CreateFolder(_ context.Context, folder *model.Folder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	folders, ok := r.byUser[folder.UserID]
+	if !ok {
+		folders = make(map[string]*model.Folder)
+		r.byUser[folder.UserID] = folders
+	}
+
+	if _, exists := folders[folder.ID]; exists {
+		return repository.ErrAlreadyExists
+	}
+
+	if _, found := folders[folder.ParentID]; folder.ParentID != "" && !found {
+		return repository.ErrNotFound
+	}
+
+	folders[folder.ID] = folder.Clone()
+
+	return nil
+}
+
+// GetFolder returns the user's folder by identifier.
+//
+// Implements the repository.FolderRepository interface.
+func (r *FolderRepository) GetFolder(
+	_ context.Context,
+	userID string,
+	folderID string,
+) (*model.Folder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	folder, ok := r.byUser[userID][folderID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	return folder.Clone(), nil
+}
+
+// ListFolders returns all folders of the user sorted by creation time.
+//
+// Implements the repository.FolderRepository interface.
+func (r *FolderRepository) ListFolders(_ context.Context, userID string) ([]*model.Folder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	folders := make([]*model.Folder, 0, len(r.byUser[userID]))
+	for _, folder := range r.byUser[userID] {
+		folders = append(folders, folder.Clone())
+	}
+
+	slices.SortFunc(folders, func(a, b *model.Folder) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return folders, nil
+}
+
+// RenameFolder replaces the name, its key and the modification time of the user's folder.
+//
+// Implements the repository.FolderRepository interface.
+func (r *FolderRepository) RenameFolder(_ context.Context, folder *model.Folder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.byUser[folder.UserID][folder.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	renamed := stored.Clone()
+
+	renamed.Name = append([]byte(nil), folder.Name...)
+	renamed.NameKey = atrest.WrappedKey{
+		MasterKeyID: folder.NameKey.MasterKeyID,
+		Key:         append([]byte(nil), folder.NameKey.Key...),
+	}
+	renamed.UpdatedAt = folder.UpdatedAt
+	r.byUser[folder.UserID][folder.ID] = renamed
+
+	return nil
+}
+
+// MoveFolder replaces the parent and the modification time of the user's folder.
+//
+// Implements the repository.FolderRepository interface.
+func (r *FolderRepository) MoveFolder(_ context.Context, folder *model.Folder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	folders := r.byUser[folder.UserID]
+
+	stored, ok := folders[folder.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	// Walking up from the new parent must reach the top level without passing the folder.
+	for ancestorID := folder.ParentID; ancestorID != ""; ancestorID = folders[ancestorID].ParentID {
+		if ancestorID == folder.ID {
+			return repository.ErrConflict
+		}
+
+		if _, found := folders[ancestorID]; !found {
+			return repository.ErrNotFound
+		}
+	}
+
+	moved := stored.Clone()
+	moved.ParentID = folder.ParentID
+	moved.UpdatedAt = folder.UpdatedAt
+	folders[folder.ID] = moved
+
+	return nil
+}
+
+// DeleteFolder deletes the user's folder with all its descendants.
+//
+// Implements the repository.FolderRepository interface.
+func (r *FolderRepository) DeleteFolder(
+	_ context.Context,
+	userID string,
+	folderID string,
+) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	folders := r.byUser[userID]
+	if _, ok := folders[folderID]; !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	deleted := []string{folderID}
+
+	// Every pass over the folders adds the children of the folders found so far.
+	for found := true; found; {
+		found = false
+
+		for _, folder := range folders {
+			if slices.Contains(deleted, folder.ParentID) && !slices.Contains(deleted, folder.ID) {
+				deleted = append(deleted, folder.ID)
+				found = true
+			}
+		}
+	}
+
+	for _, deletedID := range deleted {
+		delete(folders, deletedID)
+	}
+
+	return deleted, nil
+}
+
+// ListStaleNameKeys returns up to limit name keys wrapped with master keys other than masterKeyID.
+//
+// Implements the repository.FolderNameKeyStore interface.
+func (r *FolderRepository) ListStaleNameKeys(
+	_ context.Context,
+	masterKeyID string,
+	limit int,
+) ([]*model.FolderNameKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*model.FolderNameKey, 0)
+
+	for _, folders := range r.byUser {
+		for _, folder := range folders {
+			if len(folder.NameKey.Key) == 0 || folder.NameKey.MasterKeyID == masterKeyID {
+				continue
+			}
+
+			keys = append(keys, &model.FolderNameKey{
+				UserID:   folder.UserID,
+				FolderID: folder.ID,
+				NameKey: atrest.WrappedKey{
+					MasterKeyID: folder.NameKey.MasterKeyID,
+					Key:         append([]byte(nil), folder.NameKey.Key...),
+				},
+			})
+		}
+	}
+
+	slices.SortFunc(keys, func(a, b *model.FolderNameKey) int {
+		return cmp.Or(cmp.Compare(a.UserID, b.UserID), cmp.Compare(a.FolderID, b.FolderID))
+	})
+
+	return keys[:min(limit, len(keys))], nil
+}
+
+// ReplaceNameKey replaces the wrapped name key of the folder if it is still equal to oldKey.
+//
+// Implements the repository.FolderNameKeyStore interface.
+func (r *FolderRepository) ReplaceNameKey(
+	_ context.Context,
+	oldKey *model.FolderNameKey,
+	newKey atrest.WrappedKey,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	folder, ok := r.byUser[oldKey.UserID][oldKey.FolderID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	if folder.NameKey.MasterKeyID != oldKey.NameKey.MasterKeyID ||
+		!bytes.Equal(folder.NameKey.Key, oldKey.NameKey.Key) {
+		return repository.ErrConflict
+	}
+
+	// Readers get copies, so the stored folder can be changed in place.
+	folder.NameKey = atrest.WrappedKey{
+		MasterKeyID: newKey.MasterKeyID,
+		Key:         append([]byte(nil), newKey.Key...),
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+// Package memory provides in-memory implementations of the repositories.
+//
+// The data is lost when the application is restarted.
+// Used for single-node runs and tests.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// RecoveryCodeRepository - in-memory storage of the users' recovery codes.
+//
+// Implements the repository.RecoveryCodeRepository interface.
+type RecoveryCodeRepository struct {
+	mu     sync.RWMutex
+	byUser map[string]map[string]model.RecoveryCode
+}
+
+// NewRecoveryCodeRepository creates a new *RecoveryCodeRepository instance.
+func NewRecoveryCodeRepository() *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{
+		mu:     sync.RWMutex{},
+		byUser: make(map[string]map[string]model.RecoveryCode),
+	}
+}
+
+// ReplaceRecoveryCodes replaces all recovery codes of the user with the new set.
+//
+// Implements the repository.RecoveryCodeRepository interface.
+func (r *RecoveryCodeRepository) ReplaceRecoveryCodes(
+	_ context.Context,
+	userID string,
+	codes []*model.RecoveryCode,
+) error {
+	set := make(map[string]model.RecoveryCode, len(codes))
+
+	for _, code := range codes {
+		if _, ok := set[code.ID]; ok {
+			return repository.ErrAlreadyExists
+		}
+
+		set[code.ID] = *code
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byUser[userID] = set
+
+	return nil
+}
+
+// GetRecoveryCode returns the user's recovery code by identifier.
+//
+// Implements the repository.RecoveryCodeRepository interface.
+func (r *RecoveryCodeRepository) GetRecoveryCode(
+	_ context.Context,
+	userID string,
+	codeID string,
+) (*model.RecoveryCode, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	code, ok := r.byUser[userID][codeID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	return &code, nil
+}
+
+// UseRecoveryCode atomically marks the unused recovery code as used.
+//
+// Implements the repository.RecoveryCodeRepository interface.
+func (r *RecoveryCodeRepository) UseRecoveryCode(
+	_ context.Context,
+	userID string,
+	codeID string,
+	usedAt time.Time,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, ok := r.byUser[userID][codeID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	if code.IsUsed() {
+		return repository.ErrConflict
+	}
+
+	code.UsedAt = usedAt
+	r.byUser[userID][codeID] = code
+
+	return nil
+}
+
+// ReleaseRecoveryCode atomically marks the recovery code used at usedAt as unused again.
+//
+// Implements the repository.RecoveryCodeRepository interface.
+func (r *RecoveryCodeRepository) ReleaseRecoveryCode(
+	_ context.Context,
+	userID string,
+	codeID string,
+	usedAt time.Time,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, ok := r.byUser[userID][codeID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	if !code.UsedAt.Equal(usedAt) {
+		return repository.ErrConflict
+	}
+
+	code.UsedAt = time.Time{}
+	r.byUser[userID][codeID] = code
+
+	return nil
+}
+
+// ListRecoveryCodes returns all recovery codes of the user.
+//
+// Implements the repository.RecoveryCodeRepository interface.
+func (r *RecoveryCodeRepository) ListRecoveryCodes(
+	_ context.Context,
+	userID string,
+) ([]*model.RecoveryCode, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	codes := make([]*model.RecoveryCode, 0, len(r.byUser[userID]))
+
+	for _, code := range r.byUser[userID] {
+		codes = append(codes, &code)
+	}
+
+	slices.SortFunc(codes, func(a, b *model.RecoveryCode) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return codes, nil
+}
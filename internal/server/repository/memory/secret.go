@@ -0,0 +1,760 @@
+// Package memory provides in-memory implementations of the repositories.
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// SecretRepository - in-memory storage of secrets.
+//
+// Implements the repository.SecretRepository, repository.SecretTrash, repository.BlobReferenceStore
+// and repository.SecretDataKeyStore interfaces.
+type SecretRepository struct {
+	mu     sync.RWMutex
+	byUser map[string]map[string]*model.Secret
+
+	// versions - history of secrets by the owner and the secret identifier, the oldest revision first.
+	//
+	// The history of the secrets in the trash is kept here as well.
+	versions map[string]map[string][]*model.SecretVersion
+
+	// trash - deleted secrets by the owner and the secret identifier.
+	trash map[string]map[string]*model.TrashedSecret
+
+	// changes - change log of the users' vaults, the latest change of each secret in the order of the log.
+	changes map[string][]*model.SecretChange
+	seq     int64
+
+	// blobs - use of the blobs referred to by the revisions, held or being collected.
+	blobs map[string]*blobUse
+}
+
+// blobUse describes the use of a blob by the secrets.
+type blobUse struct {
+	refs      int       // Number of revisions referring to the blob, current and from the history.
+	heldUntil time.Time // Time until which the blob is protected from the collection.
+	claimed   bool      // The blob is being deleted by the collector.
+}
+
+// NewSecretRepository creates a new *SecretRepository instance.
+func NewSecretRepository() *SecretRepository {
+	return &SecretRepository{
+		mu:       sync.RWMutex{},
+		byUser:   make(map[string]map[string]*model.Secret),
+		versions: make(map[string]map[string][]*model.SecretVersion),
+		trash:    make(map[string]map[string]*model.TrashedSecret),
+		changes:  make(map[string][]*model.SecretChange),
+		seq:      0,
+		blobs:    make(map[string]*blobUse),
+	}
+}
+
+// Create saves a new secret.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) Create(_ context.Context, secret *model.Secret) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	secrets, ok := r.byUser[secret.UserID]
+	if !ok {
+		secrets = make(map[string]*model.Secret)
+		r.byUser[secret.UserID] = secrets
+	}
+
+	if _, exists := secrets[secret.ID]; exists {
+		return repository.ErrAlreadyExists
+	}
+
+	if _, trashed := r.trash[secret.UserID][secret.ID]; trashed {
+		return repository.ErrAlreadyExists
+	}
+
+	secrets[secret.ID] = secret.Clone()
+	r.addBlobRef(secret.BlobID)
+	r.recordChange(secret.UserID, secret.ID, secret.Revision, false)
+
+	return nil
+}
+
+// Get returns the user's secret by identifier.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) Get(
+	_ context.Context,
+	userID string,
+	secretID string,
+) (*model.Secret, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	secret, ok := r.byUser[userID][secretID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	return secret.Clone(), nil
+}
+
+// Update replaces the type, metadata, data, blob reference and modification time of the user's secret.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) Update(_ context.Context, secret *model.Secret) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, found := r.byUser[secret.UserID][secret.ID]
+	if !found {
+		return repository.ErrNotFound
+	}
+
+	if stored.Revision != secret.Revision {
+		return repository.ErrConflict
+	}
+
+	userVersions, exists := r.versions[secret.UserID]
+	if !exists {
+		userVersions = make(map[string][]*model.SecretVersion)
+		r.versions[secret.UserID] = userVersions
+	}
+
+	userVersions[secret.ID] = append(userVersions[secret.ID], &model.SecretVersion{
+		Secret:     *stored,
+		ArchivedAt: time.Now().UTC(),
+	})
+
+	secret.Revision = stored.Revision + 1
+
+	updated := secret.Clone()
+	updated.CreatedAt = stored.CreatedAt
+
+	// The replaced revision keeps its reference in the history.
+	r.byUser[secret.UserID][secret.ID] = updated
+	r.addBlobRef(updated.BlobID)
+	r.recordChange(secret.UserID, secret.ID, secret.Revision, false)
+
+	return nil
+}
+
+// Delete moves the user's secret with its history to the trash.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) Delete(
+	_ context.Context,
+	userID string,
+	secretID string,
+	revision int64,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.byUser[userID][secretID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	if stored.Revision != revision {
+		return repository.ErrConflict
+	}
+
+	trash, exists := r.trash[userID]
+	if !exists {
+		trash = make(map[string]*model.TrashedSecret)
+		r.trash[userID] = trash
+	}
+
+	// The history and the blob references stay with the secret until it is purged.
+	trash[secretID] = &model.TrashedSecret{Secret: *stored, DeletedAt: time.Now().UTC()}
+
+	delete(r.byUser[userID], secretID)
+	r.recordChange(userID, secretID, revision, true)
+
+	return nil
+}
+
+// List returns all the user's secrets sorted by creation time.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) List(_ context.Context, userID string) ([]*model.Secret, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	secrets := make([]*model.Secret, 0, len(r.byUser[userID]))
+	for _, secret := range r.byUser[userID] {
+		secrets = append(secrets, secret.Clone())
+	}
+
+	slices.SortFunc(secrets, func(a, b *model.Secret) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return secrets, nil
+}
+
+// ListPage returns up to query.Limit of the user's secrets that match the filter
+// and follow the cursor in the order of the query.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) ListPage(
+	_ context.Context,
+	userID string,
+	query *model.SecretQuery,
+) ([]*model.Secret, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	secrets := make([]*model.Secret, 0, query.Limit)
+	for _, secret := range r.byUser[userID] {
+		if query.Filter.Matches(secret) && query.Follows(secret) {
+			secrets = append(secrets, secret)
+		}
+	}
+
+	slices.SortFunc(secrets, query.Compare)
+
+	secrets = secrets[:min(query.Limit, len(secrets))]
+	for i, secret := range secrets {
+		secrets[i] = secret.Clone()
+	}
+
+	return secrets, nil
+}
+
+// ListVersions returns the history of the user's secret, the newest revision first.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) ListVersions(
+	_ context.Context,
+	userID string,
+	secretID string,
+) ([]*model.SecretVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.byUser[userID][secretID]; !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	stored := r.versions[userID][secretID]
+	versions := make([]*model.SecretVersion, 0, len(stored))
+
+	for _, version := range slices.Backward(stored) {
+		versions = append(versions, version.Clone())
+	}
+
+	return versions, nil
+}
+
+// GetVersion returns the revision of the user's secret from its history.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) GetVersion(
+	_ context.Context,
+	userID string,
+	secretID string,
+	revision int64,
+) (*model.SecretVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.byUser[userID][secretID]; !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	for _, version := range r.versions[userID][secretID] {
+		if version.Revision == revision {
+			return version.Clone(), nil
+		}
+	}
+
+	return nil, repository.ErrNotFound
+}
+
+// PruneVersions deletes from the history of the user's secret all revisions except
+// the newest keep ones and the revisions archived before archivedBefore.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) PruneVersions(
+	_ context.Context,
+	userID string,
+	secretID string,
+	keep int,
+	archivedBefore time.Time,
+) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := r.versions[userID][secretID]
+	kept := stored[len(stored)-min(max(keep, 0), len(stored)):]
+
+	if !archivedBefore.IsZero() {
+		kept = slices.DeleteFunc(slices.Clone(kept), func(version *model.SecretVersion) bool {
+			return version.ArchivedAt.Before(archivedBefore)
+		})
+	}
+
+	if len(kept) == len(stored) {
+		return 0, nil
+	}
+
+	for _, version := range stored {
+		if !slices.Contains(kept, version) {
+			r.dropBlobRef(version.BlobID)
+		}
+	}
+
+	if len(kept) == 0 {
+		delete(r.versions[userID], secretID)
+	} else {
+		r.versions[userID][secretID] = slices.Clone(kept)
+	}
+
+	return len(stored) - len(kept), nil
+}
+
+// ListChanges returns up to limit changes of the user's secrets made after the position
+// of the change log, in the order of the log.
+//
+// Implements the repository.SecretRepository interface.
+func (r *SecretRepository) ListChanges(
+	_ context.Context,
+	userID string,
+	after int64,
+	limit int,
+) ([]*model.SecretChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored := r.changes[userID]
+	start, _ := slices.BinarySearchFunc(
+		stored,
+		after,
+		func(change *model.SecretChange, seq int64) int {
+			return cmp.Compare(change.Seq, seq+1)
+		},
+	)
+
+	changes := make([]*model.SecretChange, 0, min(max(limit, 0), len(stored)-start))
+	for _, change := range stored[start:min(start+max(limit, 0), len(stored))] {
+		clone := *change
+		changes = append(changes, &clone)
+	}
+
+	return changes, nil
+}
+
+// ListTrash returns the user's deleted secrets, the most recently deleted first.
+//
+// Implements the repository.SecretTrash interface.
+func (r *SecretRepository) ListTrash(
+	_ context.Context,
+	userID string,
+) ([]*model.TrashedSecret, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	secrets := make([]*model.TrashedSecret, 0, len(r.trash[userID]))
+	for _, secret := range r.trash[userID] {
+		secrets = append(secrets, secret.Clone())
+	}
+
+	slices.SortFunc(secrets, func(a, b *model.TrashedSecret) int {
+		return cmp.Or(b.DeletedAt.Compare(a.DeletedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return secrets, nil
+}
+
+// RestoreTrash moves the user's deleted secret with its history back from the trash.
+//
+// Implements the repository.SecretTrash interface.
+func (r *SecretRepository) RestoreTrash(
+	_ context.Context,
+	userID string,
+	secretID string,
+) (*model.Secret, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trashed, ok := r.trash[userID][secretID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	secrets, exists := r.byUser[userID]
+	if !exists {
+		secrets = make(map[string]*model.Secret)
+		r.byUser[userID] = secrets
+	}
+
+	secrets[secretID] = &trashed.Secret
+	delete(r.trash[userID], secretID)
+	r.recordChange(userID, secretID, trashed.Revision, false)
+
+	return trashed.Secret.Clone(), nil
+}
+
+// PurgeTrash permanently deletes up to limit secrets moved to the trash before deletedBefore
+// with their history, the earliest deleted first.
+//
+// Implements the repository.SecretTrash interface.
+func (r *SecretRepository) PurgeTrash(
+	_ context.Context,
+	deletedBefore time.Time,
+	limit int,
+) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expired := make([]*model.TrashedSecret, 0)
+
+	for _, secrets := range r.trash {
+		for _, secret := range secrets {
+			if secret.DeletedAt.Before(deletedBefore) {
+				expired = append(expired, secret)
+			}
+		}
+	}
+
+	slices.SortFunc(expired, func(a, b *model.TrashedSecret) int {
+		return cmp.Or(a.DeletedAt.Compare(b.DeletedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	expired = expired[:min(max(limit, 0), len(expired))]
+
+	for _, secret := range expired {
+		r.dropBlobRef(secret.BlobID)
+
+		for _, version := range r.versions[secret.UserID][secret.ID] {
+			r.dropBlobRef(version.BlobID)
+		}
+
+		delete(r.trash[secret.UserID], secret.ID)
+		delete(r.versions[secret.UserID], secret.ID)
+	}
+
+	return len(expired), nil
+}
+
+// HoldBlob protects the blob from the collection until the time.
+//
+// Implements the repository.BlobReferenceStore interface.
+func (r *SecretRepository) HoldBlob(_ context.Context, blobID string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	use := r.blobUse(blobID)
+	if use.claimed {
+		return repository.ErrConflict
+	}
+
+	if until.After(use.heldUntil) {
+		use.heldUntil = until
+	}
+
+	return nil
+}
+
+// ClaimBlob marks the blob as being collected if no revision refers to it and it is not held.
+//
+// Implements the repository.BlobReferenceStore interface.
+func (r *SecretRepository) ClaimBlob(
+	_ context.Context,
+	blobID string,
+	now time.Time,
+) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	use := r.blobUse(blobID)
+	if use.claimed || use.refs > 0 || use.heldUntil.After(now) {
+		return false, nil
+	}
+
+	use.claimed = true
+
+	return true, nil
+}
+
+// ReleaseBlob removes the mark of the collection.
+//
+// Implements the repository.BlobReferenceStore interface.
+func (r *SecretRepository) ReleaseBlob(_ context.Context, blobID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// A claimed blob can be neither held nor referenced, so nothing is left to track.
+	delete(r.blobs, blobID)
+
+	return nil
+}
+
+// ListBlobReferences returns the references of the current revisions and the revisions
+// in the history of all secrets to blobs, sorted by the blob identifier.
+//
+// The references are collected from the revisions themselves, not from the counters,
+// so the result can be used to check the storage.
+//
+// Implements the repository.BlobReferenceStore interface.
+func (r *SecretRepository) ListBlobReferences(_ context.Context) ([]*model.BlobReference, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	references := make([]*model.BlobReference, 0)
+
+	appendReference := func(secret *model.Secret) {
+		if secret.HasBlob() {
+			references = append(references, &model.BlobReference{
+				BlobID:   secret.BlobID,
+				UserID:   secret.UserID,
+				SecretID: secret.ID,
+				Revision: secret.Revision,
+			})
+		}
+	}
+
+	for _, secrets := range r.byUser {
+		for _, secret := range secrets {
+			appendReference(secret)
+		}
+	}
+
+	for _, secrets := range r.trash {
+		for _, secret := range secrets {
+			appendReference(&secret.Secret)
+		}
+	}
+
+	for _, secrets := range r.versions {
+		for _, versions := range secrets {
+			for _, version := range versions {
+				appendReference(&version.Secret)
+			}
+		}
+	}
+
+	slices.SortFunc(references, func(a, b *model.BlobReference) int {
+		return cmp.Or(
+			cmp.Compare(a.BlobID, b.BlobID),
+			cmp.Compare(a.UserID, b.UserID),
+			cmp.Compare(a.SecretID, b.SecretID),
+			cmp.Compare(a.Revision, b.Revision),
+		)
+	})
+
+	return references, nil
+}
+
+// ListStaleDataKeys returns up to limit data keys wrapped with master keys other than masterKeyID.
+//
+// Implements the repository.SecretDataKeyStore interface.
+func (r *SecretRepository) ListStaleDataKeys(
+	_ context.Context,
+	masterKeyID string,
+	limit int,
+) ([]*model.SecretDataKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*model.SecretDataKey, 0)
+
+	appendStale := func(secret *model.Secret) {
+		keys = appendStaleKey(keys, secret, "", &secret.DataKey, masterKeyID)
+		keys = appendStaleKey(keys, secret, secret.BlobID, &secret.BlobKey, masterKeyID)
+	}
+
+	for _, secrets := range r.byUser {
+		for _, secret := range secrets {
+			appendStale(secret)
+		}
+	}
+
+	for _, secrets := range r.trash {
+		for _, secret := range secrets {
+			appendStale(&secret.Secret)
+		}
+	}
+
+	for _, secrets := range r.versions {
+		for _, versions := range secrets {
+			for _, version := range versions {
+				appendStale(&version.Secret)
+			}
+		}
+	}
+
+	slices.SortFunc(keys, func(a, b *model.SecretDataKey) int {
+		return cmp.Or(
+			cmp.Compare(a.UserID, b.UserID),
+			cmp.Compare(a.SecretID, b.SecretID),
+			cmp.Compare(a.Revision, b.Revision),
+			cmp.Compare(a.BlobID, b.BlobID),
+		)
+	})
+
+	return keys[:min(limit, len(keys))], nil
+}
+
+// ReplaceDataKey replaces the wrapped data key of the secret or of its blob
+// if it is still equal to oldKey.
+//
+// Implements the repository.SecretDataKeyStore interface.
+func (r *SecretRepository) ReplaceDataKey(
+	_ context.Context,
+	oldKey *model.SecretDataKey,
+	newKey atrest.WrappedKey,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	secret := r.findRevision(oldKey.UserID, oldKey.SecretID, oldKey.Revision)
+	if secret == nil {
+		return repository.ErrNotFound
+	}
+
+	key := &secret.DataKey
+	if oldKey.BlobID != "" {
+		key = &secret.BlobKey
+	}
+
+	if key.MasterKeyID != oldKey.DataKey.MasterKeyID || !bytes.Equal(key.Key, oldKey.DataKey.Key) {
+		return repository.ErrConflict
+	}
+
+	// Readers get copies, so the stored revision can be changed in place.
+	*key = atrest.WrappedKey{
+		MasterKeyID: newKey.MasterKeyID,
+		Key:         append([]byte(nil), newKey.Key...),
+	}
+
+	return nil
+}
+
+// recordChange writes the change of the secret to the end of the change log
+// replacing the previous change of the same secret. Must be called under the lock.
+func (r *SecretRepository) recordChange(
+	userID string,
+	secretID string,
+	revision int64,
+	deleted bool,
+) {
+	r.seq++
+
+	changes := slices.DeleteFunc(r.changes[userID], func(change *model.SecretChange) bool {
+		return change.SecretID == secretID
+	})
+
+	r.changes[userID] = append(changes, &model.SecretChange{
+		Seq:       r.seq,
+		UserID:    userID,
+		SecretID:  secretID,
+		Revision:  revision,
+		Deleted:   deleted,
+		ChangedAt: time.Now().UTC(),
+	})
+}
+
+// blobUse returns the use of the blob, creating it if the blob is not tracked yet.
+// Must be called under the lock.
+func (r *SecretRepository) blobUse(blobID string) *blobUse {
+	use, ok := r.blobs[blobID]
+	if !ok {
+		use = &blobUse{refs: 0, heldUntil: time.Time{}, claimed: false}
+		r.blobs[blobID] = use
+	}
+
+	return use
+}
+
+// addBlobRef counts a new revision referring to the blob. Must be called under the lock.
+func (r *SecretRepository) addBlobRef(blobID string) {
+	if blobID != "" {
+		r.blobUse(blobID).refs++
+	}
+}
+
+// dropBlobRef uncounts a deleted revision referring to the blob. Must be called under the lock.
+//
+// The blob is not tracked anymore once it is neither referenced nor held.
+func (r *SecretRepository) dropBlobRef(blobID string) {
+	use, ok := r.blobs[blobID]
+	if !ok {
+		return
+	}
+
+	use.refs--
+
+	if use.refs <= 0 && !use.claimed && !use.heldUntil.After(time.Now()) {
+		delete(r.blobs, blobID)
+	}
+}
+
+// findRevision returns the stored revision of the secret, current or from the history,
+// the secrets in the trash included.
+//
+// Returns nil if there is no such secret or revision. Must be called under the lock.
+func (r *SecretRepository) findRevision(
+	userID string,
+	secretID string,
+	revision int64,
+) *model.Secret {
+	secret, ok := r.byUser[userID][secretID]
+	if !ok {
+		trashed, found := r.trash[userID][secretID]
+		if !found {
+			return nil
+		}
+
+		secret = &trashed.Secret
+	}
+
+	if secret.Revision == revision {
+		return secret
+	}
+
+	for _, version := range r.versions[userID][secretID] {
+		if version.Revision == revision {
+			return &version.Secret
+		}
+	}
+
+	return nil
+}
+
+// appendStaleKey appends the wrapped key of the revision or of its blob to the keys
+// if it is wrapped with a master key other than masterKeyID.
+func appendStaleKey(
+	keys []*model.SecretDataKey,
+	secret *model.Secret,
+	blobID string,
+	key *atrest.WrappedKey,
+	masterKeyID string,
+) []*model.SecretDataKey {
+	if len(key.Key) == 0 || key.MasterKeyID == masterKeyID {
+		return keys
+	}
+
+	return append(keys, &model.SecretDataKey{
+		UserID:   secret.UserID,
+		SecretID: secret.ID,
+		Revision: secret.Revision,
+		BlobID:   blobID,
+		DataKey: atrest.WrappedKey{
+			MasterKeyID: key.MasterKeyID,
+			Key:         append([]byte(nil), key.Key...),
+		},
+	})
+}
@@ -0,0 +1,251 @@
+// Package memory provides in-memory implementations of the repositories.
+//
+// The data is lost when the application is restarted.
+// Used for single-node runs and tests.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// SessionStore - in-memory storage of user sessions and their refresh tokens.
+//
+// Expired entries are deleted lazily when they are accessed.
+//
+// Implements the repository.SessionStore interface.
+type SessionStore struct {
+	mu         sync.Mutex
+	sessions   map[string]model.Session
+	tokens     map[string]model.RefreshToken
+	used       map[string]struct{}
+	challenges map[string]model.LoginChallenge
+}
+
+// NewSessionStore creates a new *SessionStore instance.
+func NewSessionStore() *SessionStore {
+	return &SessionStore{
+		mu:         sync.Mutex{},
+		sessions:   make(map[string]model.Session),
+		tokens:     make(map[string]model.RefreshToken),
+		used:       make(map[string]struct{}),
+		challenges: make(map[string]model.LoginChallenge),
+	}
+}
+
+// CreateSession saves a new session until its expiration time.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) CreateSession(_ context.Context, session *model.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.sessions[session.ID]; found {
+		return repository.ErrAlreadyExists
+	}
+
+	s.sessions[session.ID] = *session
+
+	return nil
+}
+
+// UpdateSession replaces the existing session and prolongs it until its expiration time.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) UpdateSession(_ context.Context, session *model.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, found := s.sessions[session.ID]
+	if !found || isExpired(current.ExpiresAt) {
+		return repository.ErrNotFound
+	}
+
+	s.sessions[session.ID] = *session
+
+	return nil
+}
+
+// GetSession returns the session by identifier.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) GetSession(_ context.Context, sessionID string) (*model.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, found := s.sessions[sessionID]
+	if !found {
+		return nil, repository.ErrNotFound
+	}
+
+	if isExpired(session.ExpiresAt) {
+		delete(s.sessions, sessionID)
+
+		return nil, repository.ErrNotFound
+	}
+
+	return &session, nil
+}
+
+// ListSessions returns all active sessions of the user sorted by creation time.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) ListSessions(_ context.Context, userID string) ([]*model.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := make([]*model.Session, 0)
+
+	for sessionID, session := range s.sessions {
+		if isExpired(session.ExpiresAt) {
+			delete(s.sessions, sessionID)
+
+			continue
+		}
+
+		if session.UserID == userID {
+			sessions = append(sessions, &session)
+		}
+	}
+
+	slices.SortFunc(sessions, func(a, b *model.Session) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return sessions, nil
+}
+
+// DeleteSession deletes the user's session.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) DeleteSession(_ context.Context, userID string, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, found := s.sessions[sessionID]
+	if !found || session.UserID != userID {
+		return repository.ErrNotFound
+	}
+
+	delete(s.sessions, sessionID)
+
+	return nil
+}
+
+// SaveRefreshToken saves a new refresh token until its expiration time.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) SaveRefreshToken(_ context.Context, token *model.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.tokens[token.ID]; found {
+		return repository.ErrAlreadyExists
+	}
+
+	s.tokens[token.ID] = *token
+
+	return nil
+}
+
+// GetRefreshToken returns the refresh token by identifier.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) GetRefreshToken(
+	_ context.Context,
+	tokenID string,
+) (*model.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, found := s.tokens[tokenID]
+	if !found {
+		return nil, repository.ErrNotFound
+	}
+
+	if isExpired(token.ExpiresAt) {
+		delete(s.tokens, tokenID)
+		delete(s.used, tokenID)
+
+		return nil, repository.ErrNotFound
+	}
+
+	return &token, nil
+}
+
+// MarkRefreshTokenUsed atomically marks the refresh token as used.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) MarkRefreshTokenUsed(
+	_ context.Context,
+	token *model.RefreshToken,
+) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.used[token.ID]; found {
+		return false, nil
+	}
+
+	s.used[token.ID] = struct{}{}
+
+	return true, nil
+}
+
+// SaveLoginChallenge saves a new login challenge until its expiration time.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) SaveLoginChallenge(
+	_ context.Context,
+	challenge *model.LoginChallenge,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.challenges[challenge.ID]; found {
+		return repository.ErrAlreadyExists
+	}
+
+	s.challenges[challenge.ID] = *challenge
+
+	return nil
+}
+
+// TakeLoginChallenge returns the login challenge and atomically consumes it.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) TakeLoginChallenge(
+	_ context.Context,
+	challengeID string,
+) (*model.LoginChallenge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	challenge, found := s.challenges[challengeID]
+	if !found {
+		return nil, repository.ErrNotFound
+	}
+
+	delete(s.challenges, challengeID)
+
+	if isExpired(challenge.ExpiresAt) {
+		return nil, repository.ErrNotFound
+	}
+
+	return &challenge, nil
+}
+
+// isExpired checks whether the expiration time has come.
+func isExpired(expiresAt time.Time) bool {
+	return !expiresAt.After(time.Now())
+}
@@ -0,0 +1,128 @@
+// Package memory provides in-memory implementations of the repositories.
+//
+// The data is lost when the application is restarted.
+// Used for single-node runs and tests.
+package memory
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// TwoFactorRepository - in-memory storage of the users' two-factor enrollments.
+//
+// Implements the repository.TwoFactorRepository interface.
+type TwoFactorRepository struct {
+	mu     sync.RWMutex
+	byUser map[string]model.TwoFactor
+}
+
+// NewTwoFactorRepository creates a new *TwoFactorRepository instance.
+func NewTwoFactorRepository() *TwoFactorRepository {
+	return &TwoFactorRepository{
+		mu:     sync.RWMutex{},
+		byUser: make(map[string]model.TwoFactor),
+	}
+}
+
+// SaveTwoFactor creates or replaces the enrollment of the user.
+//
+// Implements the repository.TwoFactorRepository interface.
+func (r *TwoFactorRepository) SaveTwoFactor(_ context.Context, twoFactor *model.TwoFactor) error {
+	stored := *twoFactor
+	stored.Secret = bytes.Clone(twoFactor.Secret)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byUser[twoFactor.UserID] = stored
+
+	return nil
+}
+
+// GetTwoFactor returns the enrollment of the user.
+//
+// Implements the repository.TwoFactorRepository interface.
+func (r *TwoFactorRepository) GetTwoFactor(
+	_ context.Context,
+	userID string,
+) (*model.TwoFactor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	twoFactor, ok := r.byUser[userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	twoFactor.Secret = bytes.Clone(twoFactor.Secret)
+
+	return &twoFactor, nil
+}
+
+// DeleteTwoFactor deletes the enrollment of the user.
+//
+// Implements the repository.TwoFactorRepository interface.
+func (r *TwoFactorRepository) DeleteTwoFactor(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byUser[userID]; !ok {
+		return repository.ErrNotFound
+	}
+
+	delete(r.byUser, userID)
+
+	return nil
+}
+
+// UseTwoFactorStep atomically records the time step of an accepted code.
+//
+// Implements the repository.TwoFactorRepository interface.
+func (r *TwoFactorRepository) UseTwoFactorStep(_ context.Context, userID string, step int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	twoFactor, ok := r.byUser[userID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	if step <= twoFactor.LastUsedStep {
+		return repository.ErrConflict
+	}
+
+	twoFactor.LastUsedStep = step
+	twoFactor.FailedAttempts = 0
+	r.byUser[userID] = twoFactor
+
+	return nil
+}
+
+// FailTwoFactor atomically records a rejected code.
+//
+// Implements the repository.TwoFactorRepository interface.
+func (r *TwoFactorRepository) FailTwoFactor(
+	_ context.Context,
+	userID string,
+	failedAt time.Time,
+) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	twoFactor, ok := r.byUser[userID]
+	if !ok {
+		return 0, repository.ErrNotFound
+	}
+
+	twoFactor.FailedAttempts++
+	twoFactor.LastFailedAt = failedAt
+	r.byUser[userID] = twoFactor
+
+	return twoFactor.FailedAttempts, nil
+}
@@ -0,0 +1,129 @@
+// Package memory provides in-memory implementations of the repositories.
+//
+// The data is lost when the application is restarted.
+// Used for single-node runs and tests.
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// UserRepository - in-memory storage of users.
+//
+// Implements the repository.UserRepository interface.
+type UserRepository struct {
+	mu      sync.RWMutex
+	byID    map[string]model.User
+	idByLgn map[string]string
+}
+
+// NewUserRepository creates a new *UserRepository instance.
+func NewUserRepository() *UserRepository {
+	return &UserRepository{
+		mu:      sync.RWMutex{},
+		byID:    make(map[string]model.User),
+		idByLgn: make(map[string]string),
+	}
+}
+
+// Create saves a new user.
+//
+// Implements the repository.UserRepository interface.
+func (r *UserRepository) Create(_ context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.idByLgn[user.Login]; ok {
+		return repository.ErrAlreadyExists
+	}
+
+	if _, ok := r.byID[user.ID]; ok {
+		return repository.ErrAlreadyExists
+	}
+
+	r.byID[user.ID] = *user
+	r.idByLgn[user.Login] = user.ID
+
+	return nil
+}
+
+// GetByID returns the user by identifier.
+//
+// Implements the repository.UserRepository interface.
+func (r *UserRepository) GetByID(_ context.Context, userID string) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.byID[userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	return &user, nil
+}
+
+// GetByLogin returns the user by login.
+//
+// Implements the repository.UserRepository interface.
+func (r *UserRepository) GetByLogin(_ context.Context, login string) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	userID, ok := r.idByLgn[login]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	user := r.byID[userID]
+
+	return &user, nil
+}
+
+// UpdateCredentials atomically replaces the password hash and the wrapped vault key of the user.
+//
+// Implements the repository.UserRepository interface.
+func (r *UserRepository) UpdateCredentials(
+	_ context.Context,
+	user *model.User,
+	previousHash string,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.byID[user.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	if stored.PasswordHash != previousHash {
+		return repository.ErrConflict
+	}
+
+	stored.PasswordHash = user.PasswordHash
+	stored.VaultKey = user.VaultKey
+	r.byID[user.ID] = stored
+
+	return nil
+}
+
+// Delete deletes the user.
+//
+// Implements the repository.UserRepository interface.
+func (r *UserRepository) Delete(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.byID[userID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	delete(r.byID, userID)
+	delete(r.idByLgn, user.Login)
+
+	return nil
+}
@@ -0,0 +1,104 @@
+// Package redis provides implementations of the repositories on top of redis.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	rediscache "github.com/mr-filatik/go-password-keeper/internal/platform/caching/redis"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/memory"
+)
+
+// channelVaultEvents - pub/sub channel of the change events of all vaults.
+const channelVaultEvents = "vault_events"
+
+// EventBroker - delivery of the change events of the users' vaults between instances via redis pub/sub.
+//
+// Each instance keeps a single subscription to the shared channel and fans the received
+// events out to its own subscribers with the in-process broker.
+//
+// Implements the repository.EventBroker interface.
+type EventBroker struct {
+	cacher *rediscache.Cacher
+	local  *memory.EventBroker
+	logger logging.Logger
+}
+
+// NewEventBroker creates a new *EventBroker instance.
+//
+// Parameters:
+//   - cacher *rediscache.Cacher: started redis cacher;
+//   - logger logging.Logger: logger.
+func NewEventBroker(cacher *rediscache.Cacher, logger logging.Logger) *EventBroker {
+	return &EventBroker{
+		cacher: cacher,
+		local:  memory.NewEventBroker(),
+		logger: logger,
+	}
+}
+
+// Start subscribes the instance to the shared channel and relays the events until ctx is done.
+func (b *EventBroker) Start(ctx context.Context) error {
+	messages, err := b.cacher.Subscribe(ctx, channelVaultEvents)
+	if err != nil {
+		return fmt.Errorf("subscribe to vault events: %w", err)
+	}
+
+	go b.relay(ctx, messages)
+
+	return nil
+}
+
+// Publish delivers the event to the subscribers of its user on all instances.
+//
+// Implements the repository.EventBroker interface.
+func (b *EventBroker) Publish(ctx context.Context, event model.VaultEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal vault event: %w", err)
+	}
+
+	err = b.cacher.Publish(ctx, channelVaultEvents, string(data))
+	if err != nil {
+		return fmt.Errorf("publish vault event: %w", err)
+	}
+
+	return nil
+}
+
+// Subscribe returns the stream of the events of the user received by this instance.
+//
+// Implements the repository.EventBroker interface.
+func (b *EventBroker) Subscribe(
+	ctx context.Context,
+	userID string,
+) (<-chan model.VaultEvent, error) {
+	stream, err := b.local.Subscribe(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("subscribe to user events: %w", err)
+	}
+
+	return stream, nil
+}
+
+// relay passes the events received from redis to the local subscribers.
+func (b *EventBroker) relay(ctx context.Context, messages <-chan string) {
+	for message := range messages {
+		var event model.VaultEvent
+
+		err := json.Unmarshal([]byte(message), &event)
+		if err != nil {
+			b.logger.Warn("Invalid vault event received", err)
+
+			continue
+		}
+
+		err = b.local.Publish(ctx, event)
+		if err != nil {
+			b.logger.Warn("Relaying vault event error", err)
+		}
+	}
+}
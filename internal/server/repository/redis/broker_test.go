@@ -0,0 +1,76 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	redisrepo "github.com/mr-filatik/go-password-keeper/internal/server/repository/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBroker creates a started broker of one instance connected to the redis server.
+func newTestBroker(t *testing.T, server *miniredis.Miniredis) *redisrepo.EventBroker {
+	t.Helper()
+
+	broker := redisrepo.NewEventBroker(newTestCacher(t, server), newTestLogger(t))
+	require.NoError(t, broker.Start(t.Context()))
+
+	return broker
+}
+
+func newVaultEvent(userID string, secretID string) model.VaultEvent {
+	return model.VaultEvent{
+		Type:       model.VaultEventSecretCreated,
+		UserID:     userID,
+		SecretID:   secretID,
+		Revision:   1,
+		OccurredAt: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func TestEventBroker_FanOut(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.RunT(t)
+	publisher := newTestBroker(t, server)
+	subscriber := newTestBroker(t, server)
+
+	events, err := subscriber.Subscribe(t.Context(), "alice")
+	require.NoError(t, err)
+
+	require.NoError(t, publisher.Publish(t.Context(), newVaultEvent("bob", "secret-1")))
+
+	want := newVaultEvent("alice", "secret-2")
+	require.NoError(t, publisher.Publish(t.Context(), want))
+
+	select {
+	case got := <-events:
+		assert.Equal(t, want, got, "events must reach other instances and only the owner")
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "event was not delivered")
+	}
+}
+
+func TestEventBroker_Unsubscribe(t *testing.T) {
+	t.Parallel()
+
+	broker := newTestBroker(t, miniredis.RunT(t))
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	events, err := broker.Subscribe(ctx, "alice")
+	require.NoError(t, err)
+
+	cancel()
+
+	select {
+	case _, open := <-events:
+		assert.False(t, open, "stream must be closed when the subscriber leaves")
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "stream was not closed")
+	}
+}
@@ -0,0 +1,351 @@
+// Package redis provides implementations of the repositories on top of redis.
+//
+// Used when the state must be shared between several instances of the server.
+package redis
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	rediscache "github.com/mr-filatik/go-password-keeper/internal/platform/caching/redis"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// Key prefixes of the session state.
+const (
+	keyPrefixSession      = "session:"
+	keyPrefixUserSessions = "user_sessions:"
+	keyPrefixRefreshToken = "refresh_token:"
+	keyPrefixRefreshUsed  = "refresh_token_used:"
+	keyPrefixChallenge    = "login_challenge:"
+	keyPrefixChallengeUse = "login_challenge_used:"
+	valueMarker           = "1"
+	minKeyLifetime        = time.Millisecond
+)
+
+// SessionStore - storage of user sessions and their refresh tokens in redis.
+//
+// All keys expire together with the sessions and tokens, so no cleanup is required.
+// The identifiers of the user's sessions are additionally kept in a set for listing.
+//
+// Implements the repository.SessionStore interface.
+type SessionStore struct {
+	cacher *rediscache.Cacher
+}
+
+// NewSessionStore creates a new *SessionStore instance.
+//
+// Parameters:
+//   - cacher *rediscache.Cacher: started redis cacher.
+func NewSessionStore(cacher *rediscache.Cacher) *SessionStore {
+	return &SessionStore{
+		cacher: cacher,
+	}
+}
+
+// CreateSession saves a new session until its expiration time.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) CreateSession(ctx context.Context, session *model.Session) error {
+	return s.saveSession(ctx, session, s.cacher.SetValueIfNotExists, repository.ErrAlreadyExists)
+}
+
+// UpdateSession replaces the existing session and prolongs it until its expiration time.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) UpdateSession(ctx context.Context, session *model.Session) error {
+	return s.saveSession(ctx, session, s.cacher.SetValueIfExists, repository.ErrNotFound)
+}
+
+// GetSession returns the session by identifier.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) GetSession(ctx context.Context, sessionID string) (*model.Session, error) {
+	session := new(model.Session)
+
+	err := s.getJSON(ctx, keyPrefixSession+sessionID, session)
+	if err != nil {
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+
+	return session, nil
+}
+
+// ListSessions returns all active sessions of the user sorted by creation time.
+//
+// Identifiers of expired sessions are removed from the user's set.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) ListSessions(ctx context.Context, userID string) ([]*model.Session, error) {
+	sessionIDs, err := s.cacher.GetSetMembers(ctx, keyPrefixUserSessions+userID)
+	if err != nil {
+		return nil, fmt.Errorf("list user sessions: %w", err)
+	}
+
+	sessions := make([]*model.Session, 0, len(sessionIDs))
+	expired := make([]string, 0)
+
+	for _, sessionID := range sessionIDs {
+		session, err := s.GetSession(ctx, sessionID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				expired = append(expired, sessionID)
+
+				continue
+			}
+
+			return nil, err
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	if len(expired) > 0 {
+		err = s.cacher.RemoveSetMembers(ctx, keyPrefixUserSessions+userID, expired...)
+		if err != nil {
+			return nil, fmt.Errorf("remove expired sessions: %w", err)
+		}
+	}
+
+	slices.SortFunc(sessions, func(a, b *model.Session) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return sessions, nil
+}
+
+// DeleteSession deletes the user's session.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) DeleteSession(ctx context.Context, userID string, sessionID string) error {
+	session, err := s.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if session.UserID != userID {
+		return repository.ErrNotFound
+	}
+
+	err = s.cacher.DeleteValues(ctx, keyPrefixSession+sessionID)
+	if err != nil {
+		return fmt.Errorf("delete session: %w", err)
+	}
+
+	err = s.cacher.RemoveSetMembers(ctx, keyPrefixUserSessions+userID, sessionID)
+	if err != nil {
+		return fmt.Errorf("delete user session: %w", err)
+	}
+
+	return nil
+}
+
+// SaveRefreshToken saves a new refresh token until its expiration time.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) SaveRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("marshal refresh token: %w", err)
+	}
+
+	isSet, err := s.cacher.SetValueIfNotExists(
+		ctx,
+		keyPrefixRefreshToken+token.ID,
+		string(data),
+		lifetime(token.ExpiresAt),
+	)
+	if err != nil {
+		return fmt.Errorf("save refresh token: %w", err)
+	}
+
+	if !isSet {
+		return repository.ErrAlreadyExists
+	}
+
+	return nil
+}
+
+// GetRefreshToken returns the refresh token by identifier.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) GetRefreshToken(
+	ctx context.Context,
+	tokenID string,
+) (*model.RefreshToken, error) {
+	token := new(model.RefreshToken)
+
+	err := s.getJSON(ctx, keyPrefixRefreshToken+tokenID, token)
+	if err != nil {
+		return nil, fmt.Errorf("get refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// MarkRefreshTokenUsed atomically marks the refresh token as used.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) MarkRefreshTokenUsed(
+	ctx context.Context,
+	token *model.RefreshToken,
+) (bool, error) {
+	isSet, err := s.cacher.SetValueIfNotExists(
+		ctx,
+		keyPrefixRefreshUsed+token.ID,
+		valueMarker,
+		lifetime(token.ExpiresAt),
+	)
+	if err != nil {
+		return false, fmt.Errorf("mark refresh token used: %w", err)
+	}
+
+	return isSet, nil
+}
+
+// SaveLoginChallenge saves a new login challenge until its expiration time.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) SaveLoginChallenge(
+	ctx context.Context,
+	challenge *model.LoginChallenge,
+) error {
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		return fmt.Errorf("marshal login challenge: %w", err)
+	}
+
+	isSet, err := s.cacher.SetValueIfNotExists(
+		ctx,
+		keyPrefixChallenge+challenge.ID,
+		string(data),
+		lifetime(challenge.ExpiresAt),
+	)
+	if err != nil {
+		return fmt.Errorf("save login challenge: %w", err)
+	}
+
+	if !isSet {
+		return repository.ErrAlreadyExists
+	}
+
+	return nil
+}
+
+// TakeLoginChallenge returns the login challenge and atomically consumes it.
+//
+// The challenge is consumed by a marker key, like refresh tokens, so two instances
+// cannot complete the same challenge.
+//
+// Implements the repository.SessionStore interface.
+func (s *SessionStore) TakeLoginChallenge(
+	ctx context.Context,
+	challengeID string,
+) (*model.LoginChallenge, error) {
+	challenge := new(model.LoginChallenge)
+
+	err := s.getJSON(ctx, keyPrefixChallenge+challengeID, challenge)
+	if err != nil {
+		return nil, fmt.Errorf("get login challenge: %w", err)
+	}
+
+	isSet, err := s.cacher.SetValueIfNotExists(
+		ctx,
+		keyPrefixChallengeUse+challengeID,
+		valueMarker,
+		lifetime(challenge.ExpiresAt),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("consume login challenge: %w", err)
+	}
+
+	if !isSet {
+		return nil, repository.ErrNotFound
+	}
+
+	err = s.cacher.DeleteValues(ctx, keyPrefixChallenge+challengeID)
+	if err != nil {
+		return nil, fmt.Errorf("delete login challenge: %w", err)
+	}
+
+	return challenge, nil
+}
+
+// conditionalSetter describes a function that stores the value by key under a condition.
+type conditionalSetter func(
+	ctx context.Context,
+	key string,
+	value string,
+	expiration time.Duration,
+) (bool, error)
+
+// saveSession stores the session with the setter and adds it to the user's set.
+//
+// Returns errNotSet if the setter condition is not met.
+func (s *SessionStore) saveSession(
+	ctx context.Context,
+	session *model.Session,
+	setter conditionalSetter,
+	errNotSet error,
+) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("marshal session: %w", err)
+	}
+
+	ttl := lifetime(session.ExpiresAt)
+
+	isSet, err := setter(ctx, keyPrefixSession+session.ID, string(data), ttl)
+	if err != nil {
+		return fmt.Errorf("save session: %w", err)
+	}
+
+	if !isSet {
+		return errNotSet
+	}
+
+	err = s.cacher.AddSetMembers(ctx, keyPrefixUserSessions+session.UserID, ttl, session.ID)
+	if err != nil {
+		return fmt.Errorf("save user session: %w", err)
+	}
+
+	return nil
+}
+
+// getJSON gets the value by key and decodes it from JSON.
+//
+// Returns repository.ErrNotFound if there is no value for the key.
+func (s *SessionStore) getJSON(ctx context.Context, key string, dst any) error {
+	value, err := s.cacher.GetValue(ctx, key)
+	if err != nil {
+		if errors.Is(err, rediscache.ErrKeyNotFound) {
+			return repository.ErrNotFound
+		}
+
+		return fmt.Errorf("get value: %w", err)
+	}
+
+	err = json.Unmarshal([]byte(value), dst)
+	if err != nil {
+		return fmt.Errorf("unmarshal value: %w", err)
+	}
+
+	return nil
+}
+
+// lifetime returns the remaining lifetime of the key.
+//
+// Redis treats zero expiration as "no expiration", so the result is always positive.
+func lifetime(expiresAt time.Time) time.Duration {
+	return max(time.Until(expiresAt), minKeyLifetime)
+}
@@ -0,0 +1,208 @@
+package redis_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	rediscache "github.com/mr-filatik/go-password-keeper/internal/platform/caching/redis"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+	redisrepo "github.com/mr-filatik/go-password-keeper/internal/server/repository/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStore creates a store on top of an in-process redis server.
+func newTestStore(t *testing.T) (*redisrepo.SessionStore, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+
+	return redisrepo.NewSessionStore(newTestCacher(t, server)), server
+}
+
+// newTestCacher creates a started cacher connected to the redis server.
+func newTestCacher(t *testing.T, server *miniredis.Miniredis) *rediscache.Cacher {
+	t.Helper()
+
+	cacher := rediscache.NewCacher(rediscache.CacherConfig{
+		ClientName: "test",
+		Address:    server.Addr(),
+		DBNumber:   0,
+		Username:   "",
+		Password:   "",
+	}, newTestLogger(t))
+	require.NoError(t, cacher.Start(t.Context()))
+
+	t.Cleanup(func() {
+		_ = cacher.Close()
+	})
+
+	return cacher
+}
+
+// newTestLogger creates a logger that discards the output.
+func newTestLogger(t *testing.T) *logging.ZapSugarLogger {
+	t.Helper()
+
+	logger, err := logging.NewZapSugarLogger(logging.LevelError, io.Discard, logging.FormatJSON)
+	require.NoError(t, err)
+
+	return logger
+}
+
+func newSession(sessionID string, userID string, createdAt time.Time) *model.Session {
+	return &model.Session{
+		ID:     sessionID,
+		UserID: userID,
+		Client: model.ClientInfo{
+			Name:    "cli",
+			Version: "1.0.0",
+			IP:      "127.0.0.1",
+		},
+		CreatedAt:  createdAt,
+		LastSeenAt: createdAt,
+		ExpiresAt:  createdAt.Add(time.Hour),
+	}
+}
+
+func TestSessionStore_Sessions(t *testing.T) {
+	t.Parallel()
+
+	store, _ := newTestStore(t)
+	ctx := t.Context()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	first := newSession("session-1", "user-1", now)
+	second := newSession("session-2", "user-1", now.Add(time.Second))
+
+	require.NoError(t, store.CreateSession(ctx, second))
+	require.NoError(t, store.CreateSession(ctx, first))
+	require.NoError(t, store.CreateSession(ctx, newSession("session-3", "user-2", now)))
+	require.ErrorIs(t, store.CreateSession(ctx, first), repository.ErrAlreadyExists)
+
+	sessions, err := store.ListSessions(ctx, "user-1")
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "session-1", sessions[0].ID)
+	assert.Equal(t, "session-2", sessions[1].ID)
+	assert.Equal(t, first.Client, sessions[0].Client)
+
+	first.Client.Version = "1.1.0"
+	require.NoError(t, store.UpdateSession(ctx, first))
+
+	got, err := store.GetSession(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", got.Client.Version)
+
+	err = store.DeleteSession(ctx, "user-2", first.ID)
+	require.ErrorIs(t, err, repository.ErrNotFound, "foreign session must not be deleted")
+
+	require.NoError(t, store.DeleteSession(ctx, "user-1", first.ID))
+
+	_, err = store.GetSession(ctx, first.ID)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	err = store.UpdateSession(ctx, first)
+	require.ErrorIs(t, err, repository.ErrNotFound, "deleted session must not be recreated")
+
+	sessions, err = store.ListSessions(ctx, "user-1")
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "session-2", sessions[0].ID)
+}
+
+func TestSessionStore_SessionsExpire(t *testing.T) {
+	t.Parallel()
+
+	store, server := newTestStore(t)
+	ctx := t.Context()
+
+	require.NoError(t, store.CreateSession(ctx, newSession("session-1", "user-1", time.Now())))
+
+	server.FastForward(2 * time.Hour)
+
+	_, err := store.GetSession(ctx, "session-1")
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	sessions, err := store.ListSessions(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestSessionStore_RefreshTokens(t *testing.T) {
+	t.Parallel()
+
+	store, server := newTestStore(t)
+	ctx := t.Context()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	token := &model.RefreshToken{
+		ID:        "hash-1",
+		SessionID: "session-1",
+		UserID:    "user-1",
+		IssuedAt:  now,
+		ExpiresAt: now.Add(time.Hour),
+	}
+
+	require.NoError(t, store.SaveRefreshToken(ctx, token))
+	require.ErrorIs(t, store.SaveRefreshToken(ctx, token), repository.ErrAlreadyExists)
+
+	got, err := store.GetRefreshToken(ctx, token.ID)
+	require.NoError(t, err)
+	assert.Equal(t, token.SessionID, got.SessionID)
+	assert.Equal(t, token.UserID, got.UserID)
+	assert.True(t, token.ExpiresAt.Equal(got.ExpiresAt))
+
+	marked, err := store.MarkRefreshTokenUsed(ctx, token)
+	require.NoError(t, err)
+	assert.True(t, marked)
+
+	marked, err = store.MarkRefreshTokenUsed(ctx, token)
+	require.NoError(t, err)
+	assert.False(t, marked, "token must be marked only once")
+
+	server.FastForward(2 * time.Hour)
+
+	_, err = store.GetRefreshToken(ctx, token.ID)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestSessionStore_LoginChallenges(t *testing.T) {
+	t.Parallel()
+
+	store, server := newTestStore(t)
+	ctx := t.Context()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	challenge := &model.LoginChallenge{
+		ID:        "challenge-1",
+		UserID:    "user-1",
+		ExpiresAt: now.Add(time.Minute),
+	}
+
+	require.NoError(t, store.SaveLoginChallenge(ctx, challenge))
+	require.ErrorIs(t, store.SaveLoginChallenge(ctx, challenge), repository.ErrAlreadyExists)
+
+	got, err := store.TakeLoginChallenge(ctx, challenge.ID)
+	require.NoError(t, err)
+	assert.Equal(t, challenge.UserID, got.UserID)
+
+	_, err = store.TakeLoginChallenge(ctx, challenge.ID)
+	require.ErrorIs(t, err, repository.ErrNotFound, "challenge must be consumed only once")
+
+	expired := &model.LoginChallenge{
+		ID:        "challenge-2",
+		UserID:    "user-1",
+		ExpiresAt: now.Add(time.Minute),
+	}
+	require.NoError(t, store.SaveLoginChallenge(ctx, expired))
+
+	server.FastForward(2 * time.Minute)
+
+	_, err = store.TakeLoginChallenge(ctx, expired.ID)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+}
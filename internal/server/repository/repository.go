@@ -0,0 +1,491 @@
+// Package repository describes the interfaces of data stores used by the server application.
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+)
+
+// Errors returned by all repository implementations.
+var (
+	// ErrNotFound - the requested entity does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrAlreadyExists - an entity with the same unique key already exists.
+	ErrAlreadyExists = errors.New("already exists")
+
+	// ErrConflict - the entity was changed concurrently.
+	ErrConflict = errors.New("conflict")
+)
+
+// UserRepository describes the storage of users.
+type UserRepository interface {
+	// Create saves a new user.
+	//
+	// Returns ErrAlreadyExists if the login is already taken.
+	Create(ctx context.Context, user *model.User) error
+
+	// GetByID returns the user by identifier.
+	//
+	// Returns ErrNotFound if there is no such user.
+	GetByID(ctx context.Context, userID string) (*model.User, error)
+
+	// GetByLogin returns the user by login.
+	//
+	// Returns ErrNotFound if there is no such user.
+	GetByLogin(ctx context.Context, login string) (*model.User, error)
+
+	// UpdateCredentials atomically replaces the password hash and the wrapped vault key
+	// of the user if the password hash is still equal to previousHash.
+	//
+	// Returns ErrNotFound if there is no such user
+	// and ErrConflict if the password has been changed concurrently.
+	UpdateCredentials(ctx context.Context, user *model.User, previousHash string) error
+
+	// Delete deletes the user.
+	//
+	// Returns ErrNotFound if there is no such user.
+	Delete(ctx context.Context, userID string) error
+}
+
+// SecretRepository describes the storage of secrets.
+//
+// All operations are scoped to the owner of the secret.
+type SecretRepository interface {
+	// Create saves a new secret.
+	//
+	// Returns ErrAlreadyExists if a secret with the same identifier exists.
+	Create(ctx context.Context, secret *model.Secret) error
+
+	// Get returns the user's secret by identifier.
+	//
+	// Returns ErrNotFound if the user has no such secret.
+	Get(ctx context.Context, userID string, secretID string) (*model.Secret, error)
+
+	// Update replaces the folder, type, metadata, tags, data, blob reference and modification time of the user's secret
+	// if its stored revision is still equal to the Revision field of the secret (compare-and-swap).
+	//
+	// The replaced revision is moved to the history of the secret, and the number
+	// of the new revision is set to the Revision field of the secret.
+	//
+	// Returns ErrNotFound if the user has no such secret
+	// and ErrConflict if the secret has been changed since the revision was read.
+	Update(ctx context.Context, secret *model.Secret) error
+
+	// Delete moves the user's secret with its history to the trash if its stored revision is still equal to revision.
+	//
+	// The secret keeps its references to blobs until it is purged from the trash.
+	//
+	// Returns ErrNotFound if the user has no such secret
+	// and ErrConflict if the secret has been changed since the revision was read.
+	Delete(ctx context.Context, userID string, secretID string, revision int64) error
+
+	// List returns all the user's secrets sorted by creation time.
+	List(ctx context.Context, userID string) ([]*model.Secret, error)
+
+	// ListPage returns up to query.Limit of the user's secrets that match the filter
+	// and follow the cursor in the order of the query.
+	ListPage(ctx context.Context, userID string, query *model.SecretQuery) ([]*model.Secret, error)
+
+	// ListVersions returns the history of the user's secret, the newest revision first.
+	//
+	// Returns ErrNotFound if the user has no such secret.
+	ListVersions(
+		ctx context.Context,
+		userID string,
+		secretID string,
+	) ([]*model.SecretVersion, error)
+
+	// GetVersion returns the revision of the user's secret from its history.
+	//
+	// Returns ErrNotFound if the user has no such secret or the revision is not in the history.
+	GetVersion(
+		ctx context.Context,
+		userID string,
+		secretID string,
+		revision int64,
+	) (*model.SecretVersion, error)
+
+	// PruneVersions deletes from the history of the user's secret all revisions except
+	// the newest keep ones and the revisions archived before archivedBefore.
+	//
+	// A zero archivedBefore disables the removal by age. Returns the number of deleted revisions.
+	PruneVersions(
+		ctx context.Context,
+		userID string,
+		secretID string,
+		keep int,
+		archivedBefore time.Time,
+	) (int, error)
+
+	// ListChanges returns up to limit changes of the user's secrets made after the position
+	// of the change log, in the order of the log.
+	//
+	// Creations, updates and deletions are written to the log atomically with the change itself.
+	// Only the latest change of each secret is kept, secrets moved to the trash are represented by tombstones.
+	ListChanges(
+		ctx context.Context,
+		userID string,
+		after int64,
+		limit int,
+	) ([]*model.SecretChange, error)
+}
+
+// BlobReferenceStore describes the use of blobs by the revisions of secrets.
+//
+// The revisions referring to each blob are counted atomically with the changes of the secrets,
+// so a blob is known to be orphaned without scanning the secrets. It is implemented
+// by the storage of secrets.
+type BlobReferenceStore interface {
+	// HoldBlob protects the blob from the collection until the time, so a payload being saved
+	// or shared is not deleted before a revision of a secret refers to it.
+	//
+	// Returns ErrConflict if the blob is being collected.
+	HoldBlob(ctx context.Context, blobID string, until time.Time) error
+
+	// ClaimBlob marks the blob as being collected if no revision of any secret refers to it
+	// and it is not held at the time. Returns false if the blob is still in use.
+	ClaimBlob(ctx context.Context, blobID string, now time.Time) (bool, error)
+
+	// ReleaseBlob removes the mark of the collection after the blob has been deleted,
+	// so it can be held and referenced again.
+	ReleaseBlob(ctx context.Context, blobID string) error
+
+	// ListBlobReferences returns the references of the current revisions and the revisions
+	// in the history of all secrets to blobs, sorted by the blob identifier.
+	//
+	// The references of the secrets in the trash are included.
+	ListBlobReferences(ctx context.Context) ([]*model.BlobReference, error)
+}
+
+// SecretTrash describes the trash of deleted secrets.
+//
+// A deleted secret is kept in the trash with its history until it is restored or purged,
+// it is not visible to the other operations of SecretRepository meanwhile.
+type SecretTrash interface {
+	// ListTrash returns the user's deleted secrets, the most recently deleted first.
+	ListTrash(ctx context.Context, userID string) ([]*model.TrashedSecret, error)
+
+	// RestoreTrash moves the user's deleted secret with its history back from the trash.
+	//
+	// The restoration is written to the change log atomically with itself.
+	// Returns ErrNotFound if the trash of the user has no such secret.
+	RestoreTrash(ctx context.Context, userID string, secretID string) (*model.Secret, error)
+
+	// PurgeTrash permanently deletes up to limit secrets moved to the trash before deletedBefore
+	// with their history and returns the number of purged secrets.
+	//
+	// Each secret is purged atomically only if it is still in the trash, so purging is safe
+	// to run concurrently on several instances and together with the restoration.
+	PurgeTrash(ctx context.Context, deletedBefore time.Time, limit int) (int, error)
+}
+
+// SecretDataKeyStore describes the access to the wrapped data keys of secrets
+// used to rotate the master key.
+type SecretDataKeyStore interface {
+	// ListStaleDataKeys returns up to limit data keys wrapped with master keys other than masterKeyID.
+	//
+	// The keys of the blobs of the revisions, of the revisions in the history and of the secrets
+	// in the trash are included. Secrets stored without encryption at rest are skipped.
+	ListStaleDataKeys(
+		ctx context.Context,
+		masterKeyID string,
+		limit int,
+	) ([]*model.SecretDataKey, error)
+
+	// ReplaceDataKey replaces the wrapped data key of the secret revision or of its blob
+	// if it is still equal to oldKey.
+	//
+	// Returns ErrNotFound if the user has no such secret or revision
+	// and ErrConflict if the data key has been changed since it was read.
+	ReplaceDataKey(ctx context.Context, oldKey *model.SecretDataKey, newKey atrest.WrappedKey) error
+}
+
+// SecretConflictRepository describes the storage of unresolved conflicts of offline edits.
+type SecretConflictRepository interface {
+	// CreateConflict saves a new conflict.
+	//
+	// Returns ErrAlreadyExists if a conflict with the same identifier exists.
+	CreateConflict(ctx context.Context, conflict *model.SecretConflict) error
+
+	// GetConflict returns the user's conflict by identifier.
+	//
+	// Returns ErrNotFound if the user has no such conflict.
+	GetConflict(
+		ctx context.Context,
+		userID string,
+		conflictID string,
+	) (*model.SecretConflict, error)
+
+	// ListConflicts returns all conflicts of the user sorted by detection time.
+	ListConflicts(ctx context.Context, userID string) ([]*model.SecretConflict, error)
+
+	// DeleteConflict deletes the user's conflict.
+	//
+	// Returns ErrNotFound if the user has no such conflict.
+	DeleteConflict(ctx context.Context, userID string, conflictID string) error
+}
+
+// FolderRepository describes the storage of the folders of the users' vaults.
+//
+// The operations changing the tree check it atomically, so concurrent changes
+// can neither create a cycle nor attach a folder to a deleted parent.
+type FolderRepository interface {
+	// CreateFolder saves a new folder.
+	//
+	// Returns ErrAlreadyExists if a folder with the same identifier exists
+	// and ErrNotFound if the user has no parent folder.
+	CreateFolder(ctx context.Context, folder *model.Folder) error
+
+	// GetFolder returns the user's folder by identifier.
+	//
+	// Returns ErrNotFound if the user has no such folder.
+	GetFolder(ctx context.Context, userID string, folderID string) (*model.Folder, error)
+
+	// ListFolders returns all folders of the user sorted by creation time.
+	ListFolders(ctx context.Context, userID string) ([]*model.Folder, error)
+
+	// RenameFolder replaces the name, its key and the modification time of the user's folder.
+	//
+	// Returns ErrNotFound if the user has no such folder.
+	RenameFolder(ctx context.Context, folder *model.Folder) error
+
+	// MoveFolder replaces the parent and the modification time of the user's folder.
+	//
+	// Returns ErrNotFound if the user has no such folder or parent folder
+	// and ErrConflict if the parent is the folder itself or one of its descendants.
+	MoveFolder(ctx context.Context, folder *model.Folder) error
+
+	// DeleteFolder deletes the user's folder with all its descendants
+	// and returns the identifiers of the deleted folders.
+	//
+	// Returns ErrNotFound if the user has no such folder.
+	DeleteFolder(ctx context.Context, userID string, folderID string) ([]string, error)
+}
+
+// FolderNameKeyStore describes the access to the wrapped name keys of folders
+// used to rotate the master key.
+type FolderNameKeyStore interface {
+	// ListStaleNameKeys returns up to limit name keys wrapped with master keys other than masterKeyID.
+	//
+	// Folders stored without encryption at rest are skipped.
+	ListStaleNameKeys(
+		ctx context.Context,
+		masterKeyID string,
+		limit int,
+	) ([]*model.FolderNameKey, error)
+
+	// ReplaceNameKey replaces the wrapped name key of the folder if it is still equal to oldKey.
+	//
+	// Returns ErrNotFound if the user has no such folder
+	// and ErrConflict if the name key has been changed since it was read.
+	ReplaceNameKey(ctx context.Context, oldKey *model.FolderNameKey, newKey atrest.WrappedKey) error
+}
+
+// UploadRepository describes the storage of resumable uploads and their content.
+//
+// The content is written as it arrives, without buffering it in memory.
+type UploadRepository interface {
+	// CreateUpload saves a new empty upload.
+	//
+	// Returns ErrAlreadyExists if an upload with the same identifier exists.
+	CreateUpload(ctx context.Context, upload *model.Upload) error
+
+	// GetUpload returns the user's upload by identifier with the number of bytes received.
+	//
+	// Returns ErrNotFound if the user has no such upload.
+	GetUpload(ctx context.Context, userID string, uploadID string) (*model.Upload, error)
+
+	// WriteUpload appends the chunk to the content of the user's upload at the offset
+	// and prolongs the upload until expiresAt.
+	//
+	// Returns the number of bytes saved. They are kept even if reading the chunk fails,
+	// so the client can resume from the new offset. Returns ErrNotFound if the user has
+	// no such upload and ErrConflict if the offset differs from the number of bytes received
+	// or the upload is being written concurrently.
+	WriteUpload(
+		ctx context.Context,
+		userID string,
+		uploadID string,
+		offset int64,
+		chunk io.Reader,
+		expiresAt time.Time,
+	) (int64, error)
+
+	// OpenUpload returns the reader of the content of the user's upload.
+	//
+	// Returns ErrNotFound if the user has no such upload.
+	OpenUpload(ctx context.Context, userID string, uploadID string) (io.ReadCloser, error)
+
+	// DeleteUpload deletes the user's upload together with its content.
+	//
+	// Returns ErrNotFound if the user has no such upload and ErrConflict if it is being written.
+	DeleteUpload(ctx context.Context, userID string, uploadID string) error
+
+	// DeleteExpiredUploads deletes the uploads expired before the time and returns their number.
+	//
+	// Uploads being written are skipped.
+	DeleteExpiredUploads(ctx context.Context, before time.Time) (int, error)
+}
+
+// BlobStore describes the storage of large payloads of secrets.
+//
+// The content is streamed in both directions, without buffering it in memory.
+// Blobs are content-addressed and immutable: the identifier is the SHA-256 hash
+// of the content in hex, a changed payload is saved as a new blob.
+type BlobStore interface {
+	// PutBlob saves the content of the given size as the blob with the hash.
+	//
+	// The blob becomes visible only when the content is saved in full. Saving the blob again
+	// keeps a single copy. Returns an error if the content is shorter or longer than the size
+	// or does not match the hash.
+	PutBlob(ctx context.Context, blobID string, content io.Reader, size int64) error
+
+	// HasBlob checks whether the blob is saved.
+	HasBlob(ctx context.Context, blobID string) (bool, error)
+
+	// GetBlob returns the reader of the content of the blob.
+	//
+	// Returns ErrNotFound if there is no such blob.
+	GetBlob(ctx context.Context, blobID string) (io.ReadCloser, error)
+
+	// DeleteBlob deletes the blob, deleting a missing blob is not an error.
+	DeleteBlob(ctx context.Context, blobID string) error
+
+	// ListBlobs returns the blobs saved before the time.
+	ListBlobs(ctx context.Context, savedBefore time.Time) ([]*model.Blob, error)
+}
+
+// RecoveryCodeRepository describes the storage of the users' recovery codes.
+type RecoveryCodeRepository interface {
+	// ReplaceRecoveryCodes replaces all recovery codes of the user with the new set.
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codes []*model.RecoveryCode) error
+
+	// GetRecoveryCode returns the user's recovery code by identifier.
+	//
+	// Returns ErrNotFound if the user has no such code.
+	GetRecoveryCode(ctx context.Context, userID string, codeID string) (*model.RecoveryCode, error)
+
+	// UseRecoveryCode atomically marks the unused recovery code as used.
+	//
+	// Returns ErrNotFound if the user has no such code
+	// and ErrConflict if the code has already been used.
+	UseRecoveryCode(ctx context.Context, userID string, codeID string, usedAt time.Time) error
+
+	// ReleaseRecoveryCode atomically marks the recovery code used at usedAt as unused again.
+	//
+	// Returns ErrNotFound if the user has no such code
+	// and ErrConflict if the code is unused or has been used at another time.
+	ReleaseRecoveryCode(ctx context.Context, userID string, codeID string, usedAt time.Time) error
+
+	// ListRecoveryCodes returns all recovery codes of the user.
+	ListRecoveryCodes(ctx context.Context, userID string) ([]*model.RecoveryCode, error)
+}
+
+// TwoFactorRepository describes the storage of the users' two-factor enrollments.
+type TwoFactorRepository interface {
+	// SaveTwoFactor creates or replaces the enrollment of the user.
+	SaveTwoFactor(ctx context.Context, twoFactor *model.TwoFactor) error
+
+	// GetTwoFactor returns the enrollment of the user.
+	//
+	// Returns ErrNotFound if the user has no enrollment.
+	GetTwoFactor(ctx context.Context, userID string) (*model.TwoFactor, error)
+
+	// DeleteTwoFactor deletes the enrollment of the user.
+	//
+	// Returns ErrNotFound if the user has no enrollment.
+	DeleteTwoFactor(ctx context.Context, userID string) error
+
+	// UseTwoFactorStep atomically records the time step of an accepted code
+	// and resets the number of rejected codes.
+	//
+	// Returns ErrNotFound if the user has no enrollment
+	// and ErrConflict if a code of this or a later step has already been used.
+	UseTwoFactorStep(ctx context.Context, userID string, step int64) error
+
+	// FailTwoFactor atomically records a rejected code at failedAt
+	// and returns the number of rejected codes since the last accepted one.
+	//
+	// Returns ErrNotFound if the user has no enrollment.
+	FailTwoFactor(ctx context.Context, userID string, failedAt time.Time) (int, error)
+}
+
+// AuditLog describes the append-only storage of audit events.
+type AuditLog interface {
+	// Append saves a new event.
+	Append(ctx context.Context, event *model.AuditEvent) error
+
+	// List returns all events of the user sorted by time.
+	List(ctx context.Context, userID string) ([]*model.AuditEvent, error)
+}
+
+// EventBroker describes the delivery of the change events of the users' vaults to their connected clients.
+//
+// Delivery is best effort: clients that miss events catch up with the sync protocol.
+type EventBroker interface {
+	// Publish delivers the event to all current subscribers of its user.
+	Publish(ctx context.Context, event model.VaultEvent) error
+
+	// Subscribe returns the stream of the events of the user.
+	//
+	// The stream is closed when ctx is done or the subscriber cannot keep up with the events.
+	Subscribe(ctx context.Context, userID string) (<-chan model.VaultEvent, error)
+}
+
+// SessionStore describes the storage of user sessions and their refresh tokens.
+//
+// The refresh tokens of a session are obtained from each other by rotation.
+// Deleting the session revokes all its tokens.
+type SessionStore interface {
+	// CreateSession saves a new session until its expiration time.
+	CreateSession(ctx context.Context, session *model.Session) error
+
+	// UpdateSession replaces the existing session and prolongs it until its expiration time.
+	//
+	// Returns ErrNotFound if there is no such session, for example, it has been deleted.
+	UpdateSession(ctx context.Context, session *model.Session) error
+
+	// GetSession returns the session by identifier.
+	//
+	// Returns ErrNotFound if there is no such session or it has expired.
+	GetSession(ctx context.Context, sessionID string) (*model.Session, error)
+
+	// ListSessions returns all active sessions of the user sorted by creation time.
+	ListSessions(ctx context.Context, userID string) ([]*model.Session, error)
+
+	// DeleteSession deletes the user's session.
+	//
+	// Returns ErrNotFound if the user has no such session.
+	DeleteSession(ctx context.Context, userID string, sessionID string) error
+
+	// SaveRefreshToken saves a new refresh token until its expiration time.
+	//
+	// Returns ErrAlreadyExists if a token with the same identifier exists.
+	SaveRefreshToken(ctx context.Context, token *model.RefreshToken) error
+
+	// GetRefreshToken returns the refresh token by identifier.
+	//
+	// Returns ErrNotFound if there is no such token or it has expired.
+	GetRefreshToken(ctx context.Context, tokenID string) (*model.RefreshToken, error)
+
+	// MarkRefreshTokenUsed atomically marks the refresh token as used.
+	//
+	// Returns false if the token has already been marked before.
+	MarkRefreshTokenUsed(ctx context.Context, token *model.RefreshToken) (bool, error)
+
+	// SaveLoginChallenge saves a new login challenge until its expiration time.
+	//
+	// Returns ErrAlreadyExists if a challenge with the same identifier exists.
+	SaveLoginChallenge(ctx context.Context, challenge *model.LoginChallenge) error
+
+	// TakeLoginChallenge returns the login challenge and atomically consumes it.
+	//
+	// Returns ErrNotFound if there is no such challenge, it has expired or has been consumed.
+	TakeLoginChallenge(ctx context.Context, challengeID string) (*model.LoginChallenge, error)
+}
@@ -0,0 +1,324 @@
+// Package s3 provides implementations of the repositories on top of an S3-compatible object storage.
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// maxErrorBodySize - maximum size of an error response read to describe the failure.
+const maxErrorBodySize = 4 << 10
+
+// Errors returned by the blob store.
+var (
+	// ErrInvalidConfig - the connection settings are incomplete.
+	ErrInvalidConfig = errors.New("invalid S3 config")
+
+	// errInvalidBlobID - the identifier of a new blob is not a SHA-256 hash.
+	errInvalidBlobID = errors.New("invalid blob identifier")
+
+	// errUnexpectedResponse - the storage responded with an unexpected status.
+	errUnexpectedResponse = errors.New("unexpected S3 response")
+)
+
+// Config describes the connection to an S3-compatible storage.
+type Config struct {
+	Endpoint  string // Base URL of the storage, the bucket is addressed in the path.
+	Region    string // Region the requests are signed for.
+	Bucket    string // Bucket of the blobs.
+	Prefix    string // Prefix of the keys of the blobs in the bucket, empty for the root of the bucket.
+	AccessKey string // Access key identifier.
+	SecretKey string // Secret access key.
+}
+
+// BlobStore - storage of blobs in a bucket of an S3-compatible storage, one object per blob
+// keyed by the hash of its content.
+//
+// Objects are addressed in the path style, so any S3-compatible storage works without DNS setup.
+// The identifier of a blob is the SHA-256 hash of its content, so the content is signed
+// without reading it in advance, streamed as it is read and verified by the storage.
+// With a key prefix the store sees only the objects under the prefix, so the stores
+// with different prefixes share the bucket without seeing the blobs of each other.
+//
+// Implements the repository.BlobStore interface.
+type BlobStore struct {
+	client *http.Client
+	bucket *url.URL
+	prefix string
+	signer *signer
+}
+
+// NewBlobStore creates a new *BlobStore instance.
+//
+// Parameters:
+//   - config Config: connection settings;
+//   - client *http.Client: HTTP client of the requests to the storage.
+func NewBlobStore(config Config, client *http.Client) (*BlobStore, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("%w: endpoint must be an absolute URL", ErrInvalidConfig)
+	}
+
+	if config.Bucket == "" || config.Region == "" {
+		return nil, fmt.Errorf("%w: bucket and region are required", ErrInvalidConfig)
+	}
+
+	prefix := strings.Trim(config.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	return &BlobStore{
+		client: client,
+		bucket: endpoint.JoinPath(config.Bucket),
+		prefix: prefix,
+		signer: newSigner(config.AccessKey, config.SecretKey, config.Region),
+	}, nil
+}
+
+// PutBlob saves the content of the given size as the blob with the hash.
+//
+// Implements the repository.BlobStore interface.
+func (s *BlobStore) PutBlob(
+	ctx context.Context,
+	blobID string,
+	content io.Reader,
+	size int64,
+) error {
+	if !model.IsValidBlobID(blobID) {
+		return fmt.Errorf("%w: %q", errInvalidBlobID, blobID)
+	}
+
+	body := content
+	if size == 0 {
+		body = http.NoBody
+	}
+
+	target := s.objectURL(blobID).String()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	req.ContentLength = size
+
+	resp, err := s.send(req, blobID)
+	if err != nil {
+		return fmt.Errorf("put blob: %w", err)
+	}
+
+	defer closeBody(resp)
+
+	return checkStatus(resp, http.StatusOK)
+}
+
+// HasBlob checks whether the blob is saved.
+//
+// Implements the repository.BlobStore interface.
+func (s *BlobStore) HasBlob(ctx context.Context, blobID string) (bool, error) {
+	resp, err := s.do(ctx, http.MethodHead, s.objectURL(blobID))
+	if err != nil {
+		return false, fmt.Errorf("head blob: %w", err)
+	}
+
+	defer closeBody(resp)
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	err = checkStatus(resp, http.StatusOK)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetBlob returns the reader of the content of the blob.
+//
+// Implements the repository.BlobStore interface.
+func (s *BlobStore) GetBlob(ctx context.Context, blobID string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.objectURL(blobID))
+	if err != nil {
+		return nil, fmt.Errorf("get blob: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		closeBody(resp)
+
+		return nil, repository.ErrNotFound
+	}
+
+	err = checkStatus(resp, http.StatusOK)
+	if err != nil {
+		closeBody(resp)
+
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// DeleteBlob deletes the blob, deleting a missing blob is not an error.
+//
+// Implements the repository.BlobStore interface.
+func (s *BlobStore) DeleteBlob(ctx context.Context, blobID string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.objectURL(blobID))
+	if err != nil {
+		return fmt.Errorf("delete blob: %w", err)
+	}
+
+	defer closeBody(resp)
+
+	return checkStatus(resp, http.StatusNoContent, http.StatusOK, http.StatusNotFound)
+}
+
+// ListBlobs returns the blobs saved before the time.
+//
+// Only the objects under the key prefix are listed, those whose keys are not blob identifiers
+// are skipped.
+//
+// Implements the repository.BlobStore interface.
+func (s *BlobStore) ListBlobs(ctx context.Context, savedBefore time.Time) ([]*model.Blob, error) {
+	blobs := make([]*model.Blob, 0)
+	token := ""
+
+	for {
+		page, err := s.listPage(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range page.Contents {
+			blobID, found := strings.CutPrefix(object.Key, s.prefix)
+			if found && model.IsValidBlobID(blobID) && object.LastModified.Before(savedBefore) {
+				blobs = append(blobs, &model.Blob{
+					ID:      blobID,
+					Size:    object.Size,
+					SavedAt: object.LastModified,
+				})
+			}
+		}
+
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return blobs, nil
+		}
+
+		token = page.NextContinuationToken
+	}
+}
+
+// listBucketResult describes a page of the ListObjectsV2 response.
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		Size         int64     `xml:"Size"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// listPage requests the page of the objects under the key prefix that starts at the continuation token.
+func (s *BlobStore) listPage(ctx context.Context, token string) (*listBucketResult, error) {
+	query := url.Values{"list-type": {"2"}}
+	if s.prefix != "" {
+		query.Set("prefix", s.prefix)
+	}
+
+	if token != "" {
+		query.Set("continuation-token", token)
+	}
+
+	target := *s.bucket
+	target.RawQuery = encodeQuery(query)
+
+	resp, err := s.do(ctx, http.MethodGet, &target)
+	if err != nil {
+		return nil, fmt.Errorf("list blobs: %w", err)
+	}
+
+	defer closeBody(resp)
+
+	err = checkStatus(resp, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	var page listBucketResult
+
+	err = xml.NewDecoder(resp.Body).Decode(&page)
+	if err != nil {
+		return nil, fmt.Errorf("decode blob list: %w", err)
+	}
+
+	return &page, nil
+}
+
+// objectURL returns the URL of the object of the blob.
+func (s *BlobStore) objectURL(blobID string) *url.URL {
+	return s.bucket.JoinPath(s.prefix + blobID)
+}
+
+// do signs and sends the request without a body.
+func (s *BlobStore) do(
+	ctx context.Context,
+	method string,
+	target *url.URL,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	return s.send(req, emptyPayloadHash)
+}
+
+// send signs the request with the SHA-256 hash of its body in hex and sends it.
+func (s *BlobStore) send(req *http.Request, payloadHash string) (*http.Response, error) {
+	s.signer.sign(req, payloadHash, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+
+	return resp, nil
+}
+
+// checkStatus returns an error with the description of the storage if the status is not expected.
+func checkStatus(resp *http.Response, expected ...int) error {
+	if slices.Contains(expected, resp.StatusCode) {
+		return nil
+	}
+
+	var failure struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+
+	// The description is optional, the status alone is enough to report the failure.
+	_ = xml.NewDecoder(io.LimitReader(resp.Body, maxErrorBodySize)).Decode(&failure)
+
+	return fmt.Errorf("%w: %d %s: %s", errUnexpectedResponse,
+		resp.StatusCode, failure.Code, failure.Message)
+}
+
+// closeBody drains and closes the body, so the connection can be reused.
+func closeBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodySize))
+	_ = resp.Body.Close()
+}
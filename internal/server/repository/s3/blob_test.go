@@ -0,0 +1,163 @@
+package s3_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blobID returns the identifier of the blob with the content.
+func blobID(content string) string {
+	hash := sha256.Sum256([]byte(content))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func TestBlobStore_PutGetDelete(t *testing.T) {
+	t.Parallel()
+
+	_, blobs := newFakeS3(t, fakeSecretKey)
+
+	ctx := t.Context()
+	key := blobID("ciphertext")
+	require.NoError(t, blobs.PutBlob(ctx, key, strings.NewReader("ciphertext"), 10))
+	require.NoError(
+		t,
+		blobs.PutBlob(ctx, key, strings.NewReader("ciphertext"), 10),
+		"saving again is allowed",
+	)
+
+	exists, err := blobs.HasBlob(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	content, err := blobs.GetBlob(ctx, key)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	require.NoError(t, content.Close())
+	assert.Equal(t, []byte("ciphertext"), data)
+
+	require.NoError(t, blobs.DeleteBlob(ctx, key))
+	require.NoError(t, blobs.DeleteBlob(ctx, key), "deleting a missing blob is not an error")
+
+	_, err = blobs.GetBlob(ctx, key)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	exists, err = blobs.HasBlob(ctx, key)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	err = blobs.PutBlob(ctx, blobID("short"), strings.NewReader("short"), 10)
+	require.Error(t, err, "content shorter than the size must be rejected")
+
+	err = blobs.PutBlob(ctx, blobID("other"), strings.NewReader("forged"), 6)
+	require.ErrorContains(t, err, "XAmzContentSHA256Mismatch", "storage must verify the hash")
+
+	err = blobs.PutBlob(ctx, "not-a-hash", strings.NewReader("data"), 4)
+	require.Error(t, err)
+}
+
+func TestBlobStore_ListBlobs(t *testing.T) {
+	t.Parallel()
+
+	fake, blobs := newFakeS3(t, fakeSecretKey)
+
+	ctx := t.Context()
+	old := time.Now().Add(-time.Hour).UTC()
+	oldIDs := make([]string, 0, 3)
+
+	for i := range 3 {
+		content := "old" + strconv.Itoa(i)
+		key := blobID(content)
+		require.NoError(t, blobs.PutBlob(ctx, key, strings.NewReader(content), 4))
+		fake.setModified(key, old)
+
+		oldIDs = append(oldIDs, key)
+	}
+
+	require.NoError(t, blobs.PutBlob(ctx, blobID("new"), strings.NewReader("new"), 3))
+
+	listed, err := blobs.ListBlobs(ctx, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	listedIDs := make([]string, 0, len(listed))
+	for _, blob := range listed {
+		assert.Equal(t, int64(4), blob.Size)
+		assert.True(t, blob.SavedAt.Equal(old))
+
+		listedIDs = append(listedIDs, blob.ID)
+	}
+
+	assert.ElementsMatch(t, oldIDs, listedIDs, "all pages must be read")
+}
+
+func TestBlobStore_Prefix(t *testing.T) {
+	t.Parallel()
+
+	fake, stores := newFakeS3WithPrefixes(t, fakeSecretKey, "instance-a", "/instance-b/")
+	first, second := stores[0], stores[1]
+
+	ctx := t.Context()
+	shared, own := blobID("shared"), blobID("own")
+	require.NoError(t, first.PutBlob(ctx, shared, strings.NewReader("shared"), 6))
+	require.NoError(t, first.PutBlob(ctx, own, strings.NewReader("own"), 3))
+	require.NoError(t, second.PutBlob(ctx, shared, strings.NewReader("shared"), 6))
+
+	fake.mu.Lock()
+	assert.Contains(t, fake.objects, "instance-a/"+own)
+	assert.Contains(t, fake.objects, "instance-b/"+shared)
+	fake.mu.Unlock()
+
+	exists, err := second.HasBlob(ctx, own)
+	require.NoError(t, err)
+	assert.False(t, exists, "the blobs of another prefix must not be seen")
+
+	listed, err := second.ListBlobs(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, shared, listed[0].ID)
+
+	// Collecting the blob under one prefix keeps the equal blob under another.
+	require.NoError(t, second.DeleteBlob(ctx, shared))
+
+	exists, err = first.HasBlob(ctx, shared)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	listed, err = first.ListBlobs(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, listed, 2)
+}
+
+func TestBlobStore_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, blobs := newFakeS3(t, "wrong-secret-key")
+
+	err := blobs.PutBlob(t.Context(), blobID("data"), strings.NewReader("data"), 4)
+	require.ErrorContains(t, err, "SignatureDoesNotMatch")
+
+	_, err = blobs.ListBlobs(t.Context(), time.Now())
+	require.Error(t, err)
+
+	_, err = s3.NewBlobStore(s3.Config{
+		Endpoint:  "localhost:9000",
+		Region:    fakeRegion,
+		Bucket:    fakeBucket,
+		Prefix:    "",
+		AccessKey: fakeAccessKey,
+		SecretKey: fakeSecretKey,
+	}, nil)
+	require.ErrorIs(t, err, s3.ErrInvalidConfig)
+}
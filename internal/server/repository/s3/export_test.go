@@ -0,0 +1,18 @@
+package s3
+
+import (
+	"net/http"
+	"time"
+)
+
+// SignRequest exposes the signing of requests to the tests.
+func SignRequest(
+	req *http.Request,
+	accessKey string,
+	secretKey string,
+	region string,
+	payloadHash string,
+	now time.Time,
+) {
+	newSigner(accessKey, secretKey, region).sign(req, payloadHash, now)
+}
@@ -0,0 +1,262 @@
+package s3_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/s3"
+)
+
+// Settings of the fake storage.
+const (
+	fakeBucket    = "blobs"
+	fakeRegion    = "eu-central-1"
+	fakeAccessKey = "test-access-key"
+	fakeSecretKey = "test-secret-key"
+	fakePageSize  = 2
+)
+
+// fakeObject describes an object kept by the fake storage.
+type fakeObject struct {
+	data     []byte
+	modified time.Time
+}
+
+// fakeS3 - in-process S3-compatible storage of a single bucket.
+//
+// It checks the signature of every request and the hash of every uploaded body and serves
+// the subset of the API used by the blob store: PutObject, HeadObject, GetObject, DeleteObject
+// and ListObjectsV2 with pages of fakePageSize objects.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]*fakeObject
+}
+
+// newFakeS3 starts the fake storage and returns the blob store connected to it.
+func newFakeS3(t *testing.T, secretKey string) (*fakeS3, *s3.BlobStore) {
+	t.Helper()
+
+	fake, stores := newFakeS3WithPrefixes(t, secretKey, "")
+
+	return fake, stores[0]
+}
+
+// newFakeS3WithPrefixes starts the fake storage and returns the blob stores connected to it,
+// one for every key prefix.
+func newFakeS3WithPrefixes(
+	t *testing.T,
+	secretKey string,
+	prefixes ...string,
+) (*fakeS3, []*s3.BlobStore) {
+	t.Helper()
+
+	fake := &fakeS3{
+		mu:      sync.Mutex{},
+		objects: make(map[string]*fakeObject),
+	}
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	stores := make([]*s3.BlobStore, 0, len(prefixes))
+
+	for _, prefix := range prefixes {
+		blobs, err := s3.NewBlobStore(s3.Config{
+			Endpoint:  server.URL,
+			Region:    fakeRegion,
+			Bucket:    fakeBucket,
+			Prefix:    prefix,
+			AccessKey: fakeAccessKey,
+			SecretKey: secretKey,
+		}, server.Client())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stores = append(stores, blobs)
+	}
+
+	return fake, stores
+}
+
+// ServeHTTP handles the request to the storage.
+//
+// Implements the http.Handler interface.
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !validSignature(r) {
+		writeFakeError(w, http.StatusForbidden, "SignatureDoesNotMatch")
+
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != fakeBucket {
+		writeFakeError(w, http.StatusNotFound, "NoSuchBucket")
+
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if key == "" && r.Method == http.MethodGet {
+		f.list(w, r)
+
+		return
+	}
+
+	f.serveObject(w, r, key)
+}
+
+// setModified changes the modification time of the object.
+func (f *fakeS3) setModified(key string, modified time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.objects[key].modified = modified
+}
+
+// serveObject handles the request to the object with the key.
+func (f *fakeS3) serveObject(w http.ResponseWriter, r *http.Request, key string) {
+	switch r.Method {
+	case http.MethodPut:
+		f.put(w, r, key)
+	case http.MethodGet, http.MethodHead:
+		object, ok := f.objects[key]
+		if !ok {
+			writeFakeError(w, http.StatusNotFound, "NoSuchKey")
+
+			return
+		}
+
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		_, _ = w.Write(object.data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeFakeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// put saves the object if the body is complete and matches the signed hash.
+func (f *fakeS3) put(w http.ResponseWriter, r *http.Request, key string) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil || int64(len(data)) != r.ContentLength {
+		writeFakeError(w, http.StatusBadRequest, "IncompleteBody")
+
+		return
+	}
+
+	hash := sha256.Sum256(data)
+	if hex.EncodeToString(hash[:]) != r.Header.Get("X-Amz-Content-Sha256") {
+		writeFakeError(w, http.StatusBadRequest, "XAmzContentSHA256Mismatch")
+
+		return
+	}
+
+	f.objects[key] = &fakeObject{data: data, modified: time.Now().UTC()}
+}
+
+// list writes the page of the objects under the prefix sorted by key
+// that starts after the continuation token.
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+
+	keys := make([]string, 0, len(f.objects))
+
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	start, _ := strconv.Atoi(r.URL.Query().Get("continuation-token"))
+	end := min(start+fakePageSize, len(keys))
+
+	var page strings.Builder
+
+	page.WriteString("<ListBucketResult>")
+
+	for _, key := range keys[start:end] {
+		object := f.objects[key]
+		page.WriteString("<Contents><Key>" + key + "</Key>" +
+			"<LastModified>" + object.modified.Format(time.RFC3339Nano) + "</LastModified>" +
+			"<Size>" + strconv.Itoa(len(object.data)) + "</Size></Contents>")
+	}
+
+	if end < len(keys) {
+		page.WriteString("<IsTruncated>true</IsTruncated>" +
+			"<NextContinuationToken>" + strconv.Itoa(end) + "</NextContinuationToken>")
+	}
+
+	page.WriteString("</ListBucketResult>")
+
+	_, _ = io.WriteString(w, page.String())
+}
+
+// validSignature signs a copy of the request as received with the known credentials
+// and compares the signatures, so the canonical form must survive the transport.
+func validSignature(r *http.Request) bool {
+	authorization := r.Header.Get("Authorization")
+
+	_, signedHeaders, found := strings.Cut(authorization, "SignedHeaders=")
+	if !found {
+		return false
+	}
+
+	signedHeaders, _, _ = strings.Cut(signedHeaders, ",")
+
+	signedAt, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return false
+	}
+
+	clone, err := http.NewRequestWithContext(
+		r.Context(),
+		r.Method,
+		"http://"+r.Host+r.RequestURI,
+		http.NoBody,
+	)
+	if err != nil {
+		return false
+	}
+
+	for name := range strings.SplitSeq(signedHeaders, ";") {
+		if name != "host" {
+			clone.Header[http.CanonicalHeaderKey(name)] = r.Header.Values(name)
+		}
+	}
+
+	s3.SignRequest(clone, fakeAccessKey, fakeSecretKey, fakeRegion,
+		r.Header.Get("X-Amz-Content-Sha256"), signedAt)
+
+	return clone.Header.Get("Authorization") == authorization
+}
+
+// writeFakeError writes the error response in the format of S3.
+func writeFakeError(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string   `xml:"Code"`
+		Message string   `xml:"Message"`
+	}{
+		XMLName: xml.Name{Space: "", Local: "Error"},
+		Code:    code,
+		Message: "fake storage error",
+	})
+}
@@ -0,0 +1,152 @@
+// Package s3 provides implementations of the repositories on top of an S3-compatible object storage.
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Parameters of AWS Signature Version 4.
+const (
+	signAlgorithm   = "AWS4-HMAC-SHA256"
+	signService     = "s3"
+	signTerminator  = "aws4_request"
+	signDateLayout  = "20060102"
+	signTimeLayout  = "20060102T150405Z"
+	headerAmzDate   = "X-Amz-Date"
+	headerAmzSHA256 = "X-Amz-Content-Sha256"
+
+	// emptyPayloadHash - SHA-256 of an empty body.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// signer signs requests with AWS Signature Version 4
+// (https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html).
+type signer struct {
+	accessKey string
+	secretKey string
+	region    string
+}
+
+// newSigner creates a new *signer instance.
+func newSigner(accessKey string, secretKey string, region string) *signer {
+	return &signer{
+		accessKey: accessKey,
+		secretKey: secretKey,
+		region:    region,
+	}
+}
+
+// sign adds the date, the payload hash and the authorization headers to the request.
+//
+// All headers set before the call are signed, headers added by the transport later are not.
+func (s *signer) sign(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	req.Header.Set(headerAmzDate, now.Format(signTimeLayout))
+	req.Header.Set(headerAmzSHA256, payloadHash)
+
+	canonicalHeaders, signedHeaders := canonicalizeHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join(
+		[]string{now.Format(signDateLayout), s.region, signService, signTerminator},
+		"/",
+	)
+	stringToSign := strings.Join([]string{
+		signAlgorithm,
+		now.Format(signTimeLayout),
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), now.Format(signDateLayout))
+	for _, part := range []string{s.region, signService, signTerminator} {
+		key = hmacSHA256(key, part)
+	}
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", signAlgorithm+" Credential="+s.accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// encodeQuery encodes the query parameters in the canonical form: sorted by key,
+// with spaces as %20, so the same string is sent and signed.
+func encodeQuery(values url.Values) string {
+	return strings.ReplaceAll(values.Encode(), "+", "%20")
+}
+
+// canonicalPath returns the escaped path of the request, the root if it is empty.
+func canonicalPath(target *url.URL) string {
+	path := target.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+
+	return path
+}
+
+// canonicalizeHeaders returns the canonical headers of the request and the list of their names.
+func canonicalizeHeaders(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	values := map[string]string{"host": host}
+
+	for name, headerValues := range req.Header {
+		name = strings.ToLower(name)
+		if name == "authorization" || name == "user-agent" {
+			continue
+		}
+
+		trimmed := make([]string, 0, len(headerValues))
+		for _, value := range headerValues {
+			trimmed = append(trimmed, strings.Join(strings.Fields(value), " "))
+		}
+
+		values[name] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	var canonical strings.Builder
+
+	for _, name := range names {
+		canonical.WriteString(name + ":" + values[name] + "\n")
+	}
+
+	return canonical.String(), strings.Join(names, ";")
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,51 @@
+// Package server provides general functionality for running a server application.
+package server
+
+import (
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+)
+
+const (
+	keyRotationBatchSize = 100
+	keyRotationPause     = 100 * time.Millisecond
+)
+
+// newKeyRotationService creates the rotation of the master key over the storages the instance
+// serves requests from, so the data stays readable while the rotation is in progress.
+//
+// The rotation is started by the administrator on the running instance. The keyring file
+// is read again before every rotation, the keyring of the environment variable cannot change
+// without a restart. Returns nil if no master keys are configured.
+//
+// Parameters:
+//   - keys atrest.KeyProvider: source of master keys, nil if encryption at rest is disabled;
+//   - logger logging.Logger: logger;
+//   - storages ...any: storages of the instance, those encrypted at rest are rotated.
+func newKeyRotationService(
+	keys atrest.KeyProvider,
+	logger logging.Logger,
+	storages ...any,
+) *service.KeyRotationService {
+	if keys == nil {
+		return nil
+	}
+
+	rewrappers := make([]service.DataKeyRewrapper, 0, len(storages))
+
+	for _, storage := range storages {
+		if rewrapper, ok := storage.(service.DataKeyRewrapper); ok {
+			rewrappers = append(rewrappers, rewrapper)
+		}
+	}
+
+	reloader, _ := keys.(service.KeyringReloader)
+
+	return service.NewKeyRotationService(service.KeyRotationConfig{
+		BatchSize: keyRotationBatchSize,
+		Pause:     keyRotationPause,
+	}, reloader, logger, rewrappers...)
+}
@@ -4,16 +4,22 @@ package server
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/mr-filatik/go-password-keeper/internal/platform"
 	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
 	"github.com/mr-filatik/go-password-keeper/internal/platform/metrics"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/password"
 	"github.com/mr-filatik/go-password-keeper/internal/server/config"
 	"github.com/mr-filatik/go-password-keeper/internal/server/http"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/filesystem"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/memory"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
 )
 
 //nolint:gochecknoglobals // substitution of linker flags via -ldflags
@@ -27,6 +33,16 @@ const (
 	shutdownTimeout = 5 * time.Second
 )
 
+// Commands of the server application.
+const (
+	commandServe      = "serve"
+	commandRotateKeys = "rotate-keys"
+	commandCheckBlobs = "check-blobs"
+)
+
+// errUnknownCommand - the first argument is not a known command.
+var errUnknownCommand = errors.New("unknown command")
+
 // IServer - interface for all application servers.
 type IServer interface {
 	// Starting the server.
@@ -40,10 +56,19 @@ type IServer interface {
 	platform.IShutdowner
 }
 
-// Run starts the server application.
+// Run starts the server application or executes the command given by the first argument
+// and exits with a non-zero code if the command has failed.
 //
-//nolint:funlen // Run() is the main function in which all components are initialized.
+// Commands:
+//   - serve (default): starts the HTTP server;
+//   - rotate-keys: re-wraps the data keys under the current master key on the running instance;
+//   - check-blobs: reports dangling references to blobs and unreferenced blobs of the running instance.
 func Run() {
+	os.Exit(run())
+}
+
+// run executes the command of the server application and returns the exit code.
+func run() int {
 	logger, loggerErr := logging.NewZapSugarLogger(logging.LevelInfo, os.Stdout, logging.FormatJSON)
 	if loggerErr != nil {
 		panic(loggerErr)
@@ -70,13 +95,175 @@ func Run() {
 		syscall.SIGQUIT)
 	defer exitFn()
 
-	appConfig := config.Initialize()
+	command, args := parseCommand(os.Args[1:])
+	appConfig := config.Initialize(args)
+
+	var err error
 
+	switch command {
+	case commandServe:
+		runServer(exitCtx, appConfig, logger)
+	case commandRotateKeys:
+		err = runKeyRotation(exitCtx, appConfig, logger)
+	case commandCheckBlobs:
+		err = runBlobCheck(exitCtx, appConfig, logger)
+	default:
+		err = fmt.Errorf("%w: %q", errUnknownCommand, command)
+	}
+
+	if err != nil {
+		logger.Error("Command error", err, "command", command)
+
+		return 1
+	}
+
+	return 0
+}
+
+// parseCommand splits the arguments into the command and its flags.
+//
+// If there are no arguments or the first one is a flag, the default serve command is returned.
+func parseCommand(args []string) (string, []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return commandServe, args
+	}
+
+	return args[0], args[1:]
+}
+
+// runServer starts the HTTP server and waits for the stop signal.
+//
+//nolint:funlen // runServer() is the main function in which all components are initialized.
+func runServer(exitCtx context.Context, appConfig *config.Config, logger logging.Logger) {
 	metricsProvider := metrics.CreateProvider("filatik_go_password_keeper", "server")
 
+	tokenManager, tokenErr := newTokenManager(appConfig, logger)
+	if tokenErr != nil {
+		logger.Fatal("Creating token manager error", tokenErr)
+	}
+
+	cacher, cacherErr := startCacher(exitCtx, appConfig, logger)
+	if cacherErr != nil {
+		logger.Fatal("Starting cacher error", cacherErr)
+	}
+
+	sessionStore := newSessionStore(cacher, logger)
+
+	eventBroker, eventBrokerErr := newEventBroker(exitCtx, cacher, logger)
+	if eventBrokerErr != nil {
+		logger.Fatal("Starting event broker error", eventBrokerErr)
+	}
+
+	userRepository := memory.NewUserRepository()
+	recoveryCodeRepository := memory.NewRecoveryCodeRepository()
+	passwordHasher := password.NewArgon2idHasher(password.DefaultArgon2idParams())
+	auditService := service.NewAuditService(memory.NewAuditLog(), logger)
+	twoFactorService := service.NewTwoFactorService(
+		memory.NewTwoFactorRepository(),
+		userRepository,
+		passwordHasher,
+		auditService,
+		logger,
+	)
+	authService := service.NewAuthService(
+		userRepository,
+		recoveryCodeRepository,
+		passwordHasher,
+		tokenManager,
+		service.RefreshTokenConfig{
+			Store: sessionStore,
+			TTL:   appConfig.RefreshTokenTTL,
+		},
+		twoFactorService,
+		logger,
+	)
+
+	uploadRepository, uploadRepositoryErr := filesystem.NewUploadRepository(appConfig.UploadDir)
+	if uploadRepositoryErr != nil {
+		logger.Fatal("Creating upload repository error", uploadRepositoryErr)
+	}
+
+	uploadService := service.NewUploadService(
+		uploadRepository,
+		service.UploadConfig{
+			MaxSize: int64(appConfig.UploadMaxSize),
+			TTL:     appConfig.UploadTTL,
+		},
+		logger,
+	)
+	uploadService.StartPurging(exitCtx)
+
+	stores, secretStoresErr := startSecretStores(exitCtx, appConfig, logger)
+	if secretStoresErr != nil {
+		logger.Fatal("Creating secret storages error", secretStoresErr)
+	}
+
+	secretService := service.NewSecretService(
+		stores.secrets,
+		stores.folders,
+		service.SecretRetention{
+			Limit:  appConfig.SecretVersionsLimit,
+			MaxAge: appConfig.SecretVersionsMaxAge,
+		},
+		stores.blobs,
+		uploadService,
+		eventBroker,
+		logger,
+	)
+
+	folderService := service.NewFolderService(stores.folders, secretService, logger)
+
+	trashService := service.NewTrashService(
+		stores.secrets,
+		appConfig.TrashRetention,
+		eventBroker,
+		logger,
+	)
+	trashService.StartPurging(exitCtx)
+
+	syncService := service.NewSyncService(stores.secrets, logger)
+	conflictService := service.NewConflictService(
+		memory.NewSecretConflictRepository(),
+		secretService,
+		logger,
+	)
+
+	sessionService := service.NewSessionService(sessionStore, logger)
+	accountService := service.NewAccountService(
+		userRepository,
+		passwordHasher,
+		sessionService,
+		logger,
+	)
+	recoveryService := service.NewRecoveryService(
+		userRepository,
+		recoveryCodeRepository,
+		passwordHasher,
+		sessionService,
+		twoFactorService,
+		auditService,
+		logger,
+	)
+
 	httpServerConfig := http.ServerConfig{
-		Address:         appConfig.Address,
-		MetricsProvider: metricsProvider,
+		Address:          appConfig.Address,
+		MetricsProvider:  metricsProvider,
+		AuthService:      authService,
+		SecretService:    secretService,
+		TrashService:     trashService,
+		FolderService:    folderService,
+		SyncService:      syncService,
+		ConflictService:  conflictService,
+		EventSubscriber:  eventBroker,
+		UploadService:    uploadService,
+		SessionService:   sessionService,
+		AccountService:   accountService,
+		RecoveryService:  recoveryService,
+		AuditService:     auditService,
+		TwoFactorService: twoFactorService,
+		TokenVerifier:    tokenManager,
+		SessionChecker:   sessionService,
+		Admin:            newAdminConfig(appConfig, stores),
 	}
 
 	var mainServer IServer = http.NewServer(httpServerConfig, logger)
@@ -88,28 +275,13 @@ func Run() {
 
 	logger.Info("Application starting is successful")
 
-	// redisCacherConfig := redis.CacherConfig{
-	// 	ClientName: "server",
-	// 	Address:    "redis:6379",
-	// 	DBNumber:   0,
-	// 	Username:   "",
-	// 	Password:   "",
-	// }
-
-	// cacher := redis.NewCacher(redisCacherConfig, logger)
-
-	// cacherErr := cacher.Start(exitCtx)
-	// if cacherErr != nil {
-	// 	logger.Error("Starting cacher error", cacherErr)
-	// }
-
 	// ===== Waiting for the stop signal =====
 	<-exitCtx.Done()
 
 	// ===== Start of server shutdown =====
 	logger.Info("Application shutdown starting...")
 
-	shutdownCtx, cansel := context.WithTimeout(context.Background(), shutdownTimeout)
+	shutdownCtx, cansel := context.WithTimeout(context.WithoutCancel(exitCtx), shutdownTimeout)
 	defer cansel()
 
 	shutdownErr := mainServer.Shutdown(shutdownCtx)
@@ -126,5 +298,7 @@ func Run() {
 		}
 	}
 
+	shutdownCacher(shutdownCtx, cacher, logger)
+
 	logger.Info("Application shutdown is successful")
 }
@@ -0,0 +1,147 @@
+// Package service contains the business logic of the server application.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// SessionRevoker describes the functionality of revoking the user's sessions.
+type SessionRevoker interface {
+	// RevokeOthers revokes all the user's sessions except the current one
+	// and returns the number of revoked sessions.
+	RevokeOthers(ctx context.Context, userID string, currentSessionID string) (int, error)
+}
+
+// ChangePasswordInput describes the data for changing the master password.
+//
+// The client re-wraps the same vault key with the key derived from the new master password,
+// so the secrets encrypted with the vault key stay readable without re-encryption.
+type ChangePasswordInput struct {
+	CurrentPassword string                 // Authentication password derived from the current master password.
+	NewPassword     string                 // Authentication password derived from the new master password.
+	VaultKey        crypto.WrappedVaultKey // Vault key wrapped with the new master password.
+}
+
+// AccountService implements the management of the user's account.
+type AccountService struct {
+	users    repository.UserRepository
+	hasher   PasswordHasher
+	sessions SessionRevoker
+	logger   logging.Logger
+}
+
+// NewAccountService creates a new *AccountService instance.
+//
+// Parameters:
+//   - users repository.UserRepository: user storage;
+//   - hasher PasswordHasher: password hasher;
+//   - sessions SessionRevoker: revoker of the user's sessions;
+//   - logger logging.Logger: logger.
+func NewAccountService(
+	users repository.UserRepository,
+	hasher PasswordHasher,
+	sessions SessionRevoker,
+	logger logging.Logger,
+) *AccountService {
+	return &AccountService{
+		users:    users,
+		hasher:   hasher,
+		sessions: sessions,
+		logger:   logger,
+	}
+}
+
+// ChangePassword replaces the authentication password and the wrapped vault key of the user
+// in one step and revokes all the user's sessions except the current one.
+//
+// Returns the number of revoked sessions.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the user;
+//   - currentSessionID string: identifier of the session that stays active;
+//   - input ChangePasswordInput: current and new credentials.
+func (s *AccountService) ChangePassword(
+	ctx context.Context,
+	userID string,
+	currentSessionID string,
+	input ChangePasswordInput,
+) (int, error) {
+	err := validatePassword(input.NewPassword)
+	if err != nil {
+		return 0, err
+	}
+
+	err = validateVaultKey(&input.VaultKey)
+	if err != nil {
+		return 0, err
+	}
+
+	user, err := verifyUserPassword(ctx, s.users, s.hasher, userID, input.CurrentPassword)
+	if err != nil {
+		return 0, err
+	}
+
+	hash, err := s.hasher.Hash(input.NewPassword)
+	if err != nil {
+		return 0, fmt.Errorf("hash password: %w", err)
+	}
+
+	previousHash := user.PasswordHash
+	user.PasswordHash = hash
+	user.VaultKey = input.VaultKey
+
+	err = s.users.UpdateCredentials(ctx, user, previousHash)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) || errors.Is(err, repository.ErrNotFound) {
+			return 0, ErrInvalidCredentials
+		}
+
+		return 0, fmt.Errorf("update credentials: %w", err)
+	}
+
+	s.logger.Info("Master password changed", "user_id", userID)
+
+	revoked, err := s.sessions.RevokeOthers(ctx, userID, currentSessionID)
+	if err != nil {
+		return revoked, fmt.Errorf("revoke other sessions: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// verifyUserPassword returns the user if the password matches the stored hash.
+func verifyUserPassword(
+	ctx context.Context,
+	users repository.UserRepository,
+	hasher PasswordHasher,
+	userID string,
+	password string,
+) (*model.User, error) {
+	user, err := users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	ok, err := hasher.Verify(password, user.PasswordHash)
+	if err != nil {
+		return nil, fmt.Errorf("verify password: %w", err)
+	}
+
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
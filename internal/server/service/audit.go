@@ -0,0 +1,116 @@
+// Package service contains the business logic of the server application.
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// AuditRecorder describes the functionality of recording security-relevant actions.
+type AuditRecorder interface {
+	// Record saves the event of the user's account.
+	Record(
+		ctx context.Context,
+		userID string,
+		eventType string,
+		client model.ClientInfo,
+		details map[string]string,
+	) error
+}
+
+// AuditService implements the audit log of the users' accounts.
+type AuditService struct {
+	log    repository.AuditLog
+	logger logging.Logger
+}
+
+// NewAuditService creates a new *AuditService instance.
+//
+// Parameters:
+//   - log repository.AuditLog: storage of audit events;
+//   - logger logging.Logger: logger.
+func NewAuditService(log repository.AuditLog, logger logging.Logger) *AuditService {
+	return &AuditService{
+		log:    log,
+		logger: logger,
+	}
+}
+
+// Record saves the event of the user's account.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the user;
+//   - eventType string: event type (see model.AuditEvent*);
+//   - client model.ClientInfo: client from which the action was made;
+//   - details map[string]string: additional event attributes.
+func (s *AuditService) Record(
+	ctx context.Context,
+	userID string,
+	eventType string,
+	client model.ClientInfo,
+	details map[string]string,
+) error {
+	event := &model.AuditEvent{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Type:      eventType,
+		Client:    client,
+		Details:   details,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	err := s.log.Append(ctx, event)
+	if err != nil {
+		return fmt.Errorf("append audit event: %w", err)
+	}
+
+	s.logger.Info("Audit event recorded",
+		"user_id", userID,
+		"event_id", event.ID,
+		"type", eventType,
+	)
+
+	return nil
+}
+
+// List returns all events of the user's account sorted by time.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the user.
+func (s *AuditService) List(ctx context.Context, userID string) ([]*model.AuditEvent, error) {
+	events, err := s.log.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list audit events: %w", err)
+	}
+
+	return events, nil
+}
+
+// ListPage returns a page of the events of the user's account sorted by time.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the user;
+//   - query model.PageQuery: position and size of the page.
+func (s *AuditService) ListPage(
+	ctx context.Context,
+	userID string,
+	query model.PageQuery,
+) (*Page[*model.AuditEvent], error) {
+	events, err := s.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return paginate(events, query, func(event *model.AuditEvent) model.PageCursor {
+		return model.PageCursor{Time: event.CreatedAt, ID: event.ID}
+	}, false), nil
+}
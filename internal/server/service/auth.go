@@ -0,0 +1,678 @@
+// Package service contains the business logic of the server application.
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// Errors returned by the authentication service.
+var (
+	// ErrUserAlreadyExists - a user with the same login is already registered.
+	ErrUserAlreadyExists = errors.New("user already exists")
+
+	// ErrInvalidCredentials - the login or password is incorrect.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+
+	// ErrInvalidLogin - the login does not meet the requirements.
+	ErrInvalidLogin = errors.New("invalid login")
+
+	// ErrInvalidPassword - the password does not meet the requirements.
+	ErrInvalidPassword = errors.New("invalid password")
+
+	// ErrInvalidVaultKey - the wrapped vault key or its KDF parameters do not meet the requirements.
+	ErrInvalidVaultKey = errors.New("invalid vault key")
+
+	// ErrInvalidRefreshToken - the refresh token is unknown, expired or its session is revoked.
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+	// ErrRefreshTokenReused - an already used refresh token was presented again.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+
+	// ErrInvalidLoginChallenge - the login challenge is unknown, expired or has already been used.
+	ErrInvalidLoginChallenge = errors.New("invalid login challenge")
+)
+
+// Restrictions on credentials.
+const (
+	minLoginLength    = 3
+	maxLoginLength    = 64
+	minPasswordLength = 8
+	maxPasswordLength = 256
+)
+
+const refreshTokenLength = 32
+
+// loginChallengeTTL - time given to enter the one-time code after the password has been checked.
+const loginChallengeTTL = 5 * time.Minute
+
+// PasswordHasher describes the functionality for hashing and verifying passwords.
+type PasswordHasher interface {
+	// Hash calculates the hash of the password.
+	Hash(password string) (string, error)
+
+	// Verify checks whether the password matches the hash.
+	Verify(password string, encoded string) (bool, error)
+}
+
+// AccessTokenIssuer describes the functionality of issuing access tokens.
+type AccessTokenIssuer interface {
+	// Issue creates a signed access token for the user session
+	// and returns it with its expiration time.
+	Issue(userID string, sessionID string) (string, time.Time, error)
+}
+
+// TwoFactorVerifier describes the check of the second authentication factor.
+type TwoFactorVerifier interface {
+	// IsEnabled checks whether the user has enabled two-factor authentication.
+	IsEnabled(ctx context.Context, userID string) (bool, error)
+
+	// Verify checks the one-time code of the user.
+	Verify(ctx context.Context, userID string, code string, client model.ClientInfo) error
+}
+
+// TokenPair describes a pair of access and refresh tokens.
+type TokenPair struct {
+	SessionID             string    // Identifier of the session the tokens belong to.
+	AccessToken           string    // Signed access token.
+	AccessTokenExpiresAt  time.Time // Expiration time of the access token.
+	RefreshToken          string    // Opaque refresh token.
+	RefreshTokenExpiresAt time.Time // Expiration time of the refresh token.
+}
+
+// TwoFactorChallenge describes the login waiting for the one-time code.
+type TwoFactorChallenge struct {
+	Token     string    // Opaque token of the login challenge.
+	ExpiresAt time.Time // Expiration time of the challenge.
+}
+
+// LoginResult describes the result of a successful login.
+//
+// If the user has enabled two-factor authentication, only Challenge is set
+// and the tokens are issued by LoginTwoFactor.
+type LoginResult struct {
+	User      *model.User         // Authenticated user.
+	Tokens    TokenPair           // Issued tokens.
+	Challenge *TwoFactorChallenge // Login challenge waiting for the one-time code.
+}
+
+// RefreshTokenConfig describes the settings of refresh tokens.
+type RefreshTokenConfig struct {
+	Store repository.SessionStore // Storage of sessions and refresh tokens.
+	TTL   time.Duration           // Lifetime of refresh tokens.
+}
+
+// AuthService implements user registration, login and token refresh.
+type AuthService struct {
+	users     repository.UserRepository
+	recovery  repository.RecoveryCodeRepository
+	hasher    PasswordHasher
+	tokens    AccessTokenIssuer
+	refresh   RefreshTokenConfig
+	twoFactor TwoFactorVerifier
+	logger    logging.Logger
+
+	// fakeSaltKey is used to generate stable salts for unknown logins.
+	fakeSaltKey []byte
+}
+
+// NewAuthService creates a new *AuthService instance.
+//
+// Parameters:
+//   - users repository.UserRepository: user storage;
+//   - recovery repository.RecoveryCodeRepository: storage of recovery codes;
+//   - hasher PasswordHasher: password hasher;
+//   - tokens AccessTokenIssuer: access token issuer;
+//   - refresh RefreshTokenConfig: refresh token settings;
+//   - twoFactor TwoFactorVerifier: checker of the second factor;
+//   - logger logging.Logger: logger.
+func NewAuthService(
+	users repository.UserRepository,
+	recovery repository.RecoveryCodeRepository,
+	hasher PasswordHasher,
+	tokens AccessTokenIssuer,
+	refresh RefreshTokenConfig,
+	twoFactor TwoFactorVerifier,
+	logger logging.Logger,
+) *AuthService {
+	fakeSaltKey := make([]byte, crypto.KeySize)
+	_, _ = rand.Read(fakeSaltKey)
+
+	return &AuthService{
+		users:       users,
+		recovery:    recovery,
+		hasher:      hasher,
+		tokens:      tokens,
+		refresh:     refresh,
+		twoFactor:   twoFactor,
+		logger:      logger,
+		fakeSaltKey: fakeSaltKey,
+	}
+}
+
+// RegisterInput describes the data for creating a user account.
+type RegisterInput struct {
+	Login        string                 // User login.
+	Password     string                 // Authentication password.
+	VaultKey     crypto.WrappedVaultKey // Vault key wrapped on the client.
+	RecoveryKeys []crypto.RecoveryKey   // Copies of the vault key wrapped with the recovery codes.
+}
+
+// Register creates a new user account with its set of recovery codes.
+//
+// The password is the authentication password derived on the client from the master password;
+// the master password, the recovery codes and the vault key are never sent to the server.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - input RegisterInput: credentials and wrapped vault keys.
+func (s *AuthService) Register(ctx context.Context, input RegisterInput) (*model.User, error) {
+	err := validateCredentials(input.Login, input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateVaultKey(&input.VaultKey)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := uuid.NewString()
+
+	codes, err := newRecoveryCodes(userID, input.RecoveryKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := s.hasher.Hash(input.Password)
+	if err != nil {
+		return nil, fmt.Errorf("hash password: %w", err)
+	}
+
+	user := &model.User{
+		ID:           userID,
+		Login:        input.Login,
+		PasswordHash: hash,
+		CreatedAt:    time.Now().UTC(),
+		VaultKey:     input.VaultKey,
+	}
+
+	err = s.users.Create(ctx, user)
+	if err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, ErrUserAlreadyExists
+		}
+
+		return nil, fmt.Errorf("create user: %w", err)
+	}
+
+	err = s.saveRecoveryCodes(ctx, user.ID, codes)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("User registered", "user_id", user.ID)
+
+	return user, nil
+}
+
+// KDFParams returns the key derivation parameters of the user,
+// which the client needs to derive the authentication password before login.
+//
+// For unknown logins stable fake parameters are returned, so the response
+// does not reveal whether the login is registered.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - login string: user login.
+func (s *AuthService) KDFParams(ctx context.Context, login string) (crypto.KDFParams, error) {
+	user, err := s.users.GetByLogin(ctx, login)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			mac := hmac.New(sha256.New, s.fakeSaltKey)
+			mac.Write([]byte(login))
+
+			return crypto.DefaultKDFParams(mac.Sum(nil)[:crypto.SaltSize]), nil
+		}
+
+		return crypto.KDFParams{}, fmt.Errorf("get user: %w", err)
+	}
+
+	return user.VaultKey.KDF, nil
+}
+
+// Login checks the user's credentials and issues a new pair of tokens.
+//
+// Each login starts a new session of the client. If the user has enabled two-factor
+// authentication, a login challenge is returned instead, see LoginTwoFactor.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - login string: user login;
+//   - password string: user password in plain form;
+//   - client model.ClientInfo: client that logs in.
+func (s *AuthService) Login(
+	ctx context.Context,
+	login string,
+	password string,
+	client model.ClientInfo,
+) (*LoginResult, error) {
+	user, err := s.authenticate(ctx, login, password)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled, err := s.twoFactor.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("check two-factor authentication: %w", err)
+	}
+
+	if enabled {
+		challenge, err := s.createLoginChallenge(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		s.logger.Info("Two-factor code required", "user_id", user.ID)
+
+		return &LoginResult{
+			User: user,
+			Tokens: TokenPair{
+				SessionID:             "",
+				AccessToken:           "",
+				AccessTokenExpiresAt:  time.Time{},
+				RefreshToken:          "",
+				RefreshTokenExpiresAt: time.Time{},
+			},
+			Challenge: challenge,
+		}, nil
+	}
+
+	return s.startSession(ctx, user, client)
+}
+
+// LoginTwoFactor completes the login with the one-time code and issues a new pair of tokens.
+//
+// The challenge is single-use: after a wrong code the login must be started again.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - challengeToken string: opaque token of the login challenge;
+//   - code string: one-time code;
+//   - client model.ClientInfo: client that logs in.
+func (s *AuthService) LoginTwoFactor(
+	ctx context.Context,
+	challengeToken string,
+	code string,
+	client model.ClientInfo,
+) (*LoginResult, error) {
+	if challengeToken == "" {
+		return nil, ErrInvalidLoginChallenge
+	}
+
+	challenge, err := s.refresh.Store.TakeLoginChallenge(ctx, hashRefreshToken(challengeToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidLoginChallenge
+		}
+
+		return nil, fmt.Errorf("take login challenge: %w", err)
+	}
+
+	user, err := s.users.GetByID(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidLoginChallenge
+		}
+
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	err = s.twoFactor.Verify(ctx, user.ID, code, client)
+	if err != nil {
+		return nil, fmt.Errorf("verify two-factor code: %w", err)
+	}
+
+	return s.startSession(ctx, user, client)
+}
+
+// Refresh exchanges the refresh token for a new pair of tokens.
+//
+// The presented token is marked as used. Presenting a used token again is treated as theft:
+// the whole session is revoked and ErrRefreshTokenReused is returned.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - refreshToken string: opaque refresh token;
+//   - client model.ClientInfo: client that refreshes the tokens.
+func (s *AuthService) Refresh(
+	ctx context.Context,
+	refreshToken string,
+	client model.ClientInfo,
+) (*TokenPair, error) {
+	current, session, err := s.getRefreshTokenSession(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	marked, err := s.refresh.Store.MarkRefreshTokenUsed(ctx, current)
+	if err != nil {
+		return nil, fmt.Errorf("mark refresh token used: %w", err)
+	}
+
+	if !marked {
+		err = s.refresh.Store.DeleteSession(ctx, session.UserID, session.ID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("revoke session: %w", err)
+		}
+
+		s.logger.Warn("Refresh token reuse detected, session revoked", nil,
+			"user_id", session.UserID, "session_id", session.ID)
+
+		return nil, ErrRefreshTokenReused
+	}
+
+	session.Client = client
+
+	return s.issueTokens(ctx, session, false)
+}
+
+// saveRecoveryCodes saves the recovery codes of the registered user.
+//
+// Without the codes the lost master password could not be recovered, and the user could not
+// generate them without logging in, so the user is deleted if the codes are not saved.
+func (s *AuthService) saveRecoveryCodes(
+	ctx context.Context,
+	userID string,
+	codes []*model.RecoveryCode,
+) error {
+	err := s.recovery.ReplaceRecoveryCodes(ctx, userID, codes)
+	if err == nil {
+		return nil
+	}
+
+	err = fmt.Errorf("save recovery codes: %w", err)
+
+	deleteErr := s.users.Delete(context.WithoutCancel(ctx), userID)
+	if deleteErr != nil {
+		return errors.Join(err, fmt.Errorf("delete user: %w", deleteErr))
+	}
+
+	return err
+}
+
+// authenticate returns the user if the login and the password are correct.
+func (s *AuthService) authenticate(
+	ctx context.Context,
+	login string,
+	password string,
+) (*model.User, error) {
+	user, err := s.users.GetByLogin(ctx, login)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			// Hashing equalizes the response time for existing and non-existing logins.
+			_, _ = s.hasher.Hash(password)
+
+			return nil, ErrInvalidCredentials
+		}
+
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	ok, err := s.hasher.Verify(password, user.PasswordHash)
+	if err != nil {
+		return nil, fmt.Errorf("verify password: %w", err)
+	}
+
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+// createLoginChallenge saves a login challenge of the user and returns its token.
+func (s *AuthService) createLoginChallenge(
+	ctx context.Context,
+	userID string,
+) (*TwoFactorChallenge, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("generate login challenge: %w", err)
+	}
+
+	challenge := &model.LoginChallenge{
+		ID:        hashRefreshToken(token),
+		UserID:    userID,
+		ExpiresAt: time.Now().UTC().Add(loginChallengeTTL),
+	}
+
+	err = s.refresh.Store.SaveLoginChallenge(ctx, challenge)
+	if err != nil {
+		return nil, fmt.Errorf("save login challenge: %w", err)
+	}
+
+	return &TwoFactorChallenge{
+		Token:     token,
+		ExpiresAt: challenge.ExpiresAt,
+	}, nil
+}
+
+// startSession starts a new session of the client and issues its tokens.
+func (s *AuthService) startSession(
+	ctx context.Context,
+	user *model.User,
+	client model.ClientInfo,
+) (*LoginResult, error) {
+	now := time.Now().UTC()
+
+	session := &model.Session{
+		ID:         uuid.NewString(),
+		UserID:     user.ID,
+		Client:     client,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now,
+	}
+
+	tokens, err := s.issueTokens(ctx, session, true)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("User logged in", "user_id", user.ID, "session_id", session.ID)
+
+	return &LoginResult{
+		User:      user,
+		Tokens:    *tokens,
+		Challenge: nil,
+	}, nil
+}
+
+// getRefreshTokenSession returns the state of the refresh token and its active session.
+func (s *AuthService) getRefreshTokenSession(
+	ctx context.Context,
+	refreshToken string,
+) (*model.RefreshToken, *model.Session, error) {
+	if refreshToken == "" {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	current, err := s.refresh.Store.GetRefreshToken(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+
+		return nil, nil, fmt.Errorf("get refresh token: %w", err)
+	}
+
+	if !current.ExpiresAt.After(time.Now()) {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	session, err := s.refresh.Store.GetSession(ctx, current.SessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+
+		return nil, nil, fmt.Errorf("get session: %w", err)
+	}
+
+	if session.UserID != current.UserID {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	return current, session, nil
+}
+
+// issueTokens issues an access token and a refresh token of the session
+// and prolongs the session until the expiration time of the refresh token.
+//
+// If isNew is false, the session must exist, otherwise it has been revoked concurrently.
+func (s *AuthService) issueTokens(
+	ctx context.Context,
+	session *model.Session,
+	isNew bool,
+) (*TokenPair, error) {
+	accessToken, accessExpiresAt, err := s.tokens.Issue(session.UserID, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("issue access token: %w", err)
+	}
+
+	refreshToken, err := newOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("generate refresh token: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	state := &model.RefreshToken{
+		ID:        hashRefreshToken(refreshToken),
+		SessionID: session.ID,
+		UserID:    session.UserID,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(s.refresh.TTL),
+	}
+
+	session.LastSeenAt = now
+	session.ExpiresAt = state.ExpiresAt
+
+	err = s.saveSession(ctx, session, isNew)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.refresh.Store.SaveRefreshToken(ctx, state)
+	if err != nil {
+		return nil, fmt.Errorf("save refresh token: %w", err)
+	}
+
+	return &TokenPair{
+		SessionID:             session.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: state.ExpiresAt,
+	}, nil
+}
+
+// saveSession creates a new session or updates an existing one.
+func (s *AuthService) saveSession(ctx context.Context, session *model.Session, isNew bool) error {
+	if isNew {
+		err := s.refresh.Store.CreateSession(ctx, session)
+		if err != nil {
+			return fmt.Errorf("create session: %w", err)
+		}
+
+		return nil
+	}
+
+	err := s.refresh.Store.UpdateSession(ctx, session)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidRefreshToken
+		}
+
+		return fmt.Errorf("update session: %w", err)
+	}
+
+	return nil
+}
+
+// newOpaqueToken generates a random token for the refresh tokens and the login challenges.
+func newOpaqueToken() (string, error) {
+	raw := make([]byte, refreshTokenLength)
+
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", fmt.Errorf("read random bytes: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// hashRefreshToken returns the identifier under which the refresh token is stored.
+func hashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func validateCredentials(login string, password string) error {
+	loginLen := utf8.RuneCountInString(login)
+	if loginLen < minLoginLength || loginLen > maxLoginLength {
+		return fmt.Errorf("%w: length must be from %d to %d characters",
+			ErrInvalidLogin, minLoginLength, maxLoginLength)
+	}
+
+	return validatePassword(password)
+}
+
+func validatePassword(password string) error {
+	passwordLen := utf8.RuneCountInString(password)
+	if passwordLen < minPasswordLength || passwordLen > maxPasswordLength {
+		return fmt.Errorf("%w: length must be from %d to %d characters",
+			ErrInvalidPassword, minPasswordLength, maxPasswordLength)
+	}
+
+	return nil
+}
+
+func validateVaultKey(vaultKey *crypto.WrappedVaultKey) error {
+	err := vaultKey.KDF.Validate()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidVaultKey, err)
+	}
+
+	// The key is wrapped on the client, so only the envelope structure can be checked.
+	envelope, err := crypto.ParseEnvelope(vaultKey.WrappedKey)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidVaultKey, err)
+	}
+
+	if len(envelope.Ciphertext) != crypto.KeySize {
+		return fmt.Errorf(
+			"%w: wrapped key must contain %d bytes",
+			ErrInvalidVaultKey,
+			crypto.KeySize,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,594 @@
+// Package service contains the business logic of the server application.
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+const (
+	// blobCollectInterval - interval between the collections of orphaned blobs.
+	blobCollectInterval = time.Hour
+
+	// blobCollectGrace - age of a blob before it is considered for the collection.
+	//
+	// Fresh blobs are held by the changes in progress anyway, so they are not even checked.
+	blobCollectGrace = time.Hour
+
+	// blobHoldDuration - time a blob is protected from the collection while a revision
+	// referring to it is saved.
+	blobHoldDuration = time.Hour
+
+	// blobHoldAttempts - number of attempts to hold a blob that is being collected.
+	blobHoldAttempts = 3
+
+	// blobHoldPause - pause between the attempts to hold a blob.
+	blobHoldPause = 100 * time.Millisecond
+
+	// blobAdditionalData binds the content encrypted at rest to the blob storage.
+	blobAdditionalData = "go-password-keeper/v1/at-rest/blob"
+)
+
+var (
+	// errBlobMissing - the blob a revision refers to is not in the blob storage.
+	errBlobMissing = errors.New("blob is missing")
+
+	// errBlobKeysNotSet - the blob is encrypted at rest, but the encryption keys are not set.
+	errBlobKeysNotSet = errors.New("blob is encrypted at rest, but encryption keys are not set")
+
+	// errBlobContentChanged - the payload read to save the blob differs from the one it is named by.
+	errBlobContentChanged = errors.New("blob content does not match its identifier")
+)
+
+// PayloadOpener opens the payload of a binary secret for reading from the start and returns its size.
+type PayloadOpener func(ctx context.Context) (io.ReadCloser, int64, error)
+
+// SavedBlob describes the blob the payload of a binary secret has been saved to.
+type SavedBlob struct {
+	ID   string            // Identifier of the blob.
+	Size int64             // Size of the payload in bytes.
+	Key  atrest.WrappedKey // Wrapped data key of the blob, empty without encryption at rest.
+}
+
+// BlobCheckReport describes the inconsistencies between the secrets and the blob storage.
+type BlobCheckReport struct {
+	Blobs        int                    // Number of blobs in the storage.
+	References   int                    // Number of revisions referring to blobs.
+	Dangling     []*model.BlobReference // References to missing blobs.
+	Unreferenced []*model.Blob          // Blobs no revision refers to.
+}
+
+// Consistent checks whether the check has found no inconsistencies.
+func (r *BlobCheckReport) Consistent() bool {
+	return len(r.Dangling) == 0 && len(r.Unreferenced) == 0
+}
+
+// BlobService implements the content-addressed storage of the payloads of binary secrets,
+// the garbage collection of blobs no secret refers to and the consistency check.
+//
+// A payload is saved by the hash of its ciphertext, so equal payloads are saved once.
+// With encryption at rest the data key of a payload is derived from its hash and the master key,
+// so equal payloads are encrypted to the same content and share the blob as well, until
+// the master key is rotated. The data key is wrapped with the master key and kept by the revision.
+// Blobs become orphaned when the last revision referring to them leaves the history
+// or its secret is deleted, and when a change fails after its blob has been saved.
+// A blob is collected when the secret storage has no references to it, so the collection
+// must run only against a blob storage no other secret storage refers to.
+//
+// Implements the BlobKeeper interface.
+type BlobService struct {
+	blobs      repository.BlobStore
+	references repository.BlobReferenceStore
+	encrypter  *atrest.Encrypter
+	logger     logging.Logger
+}
+
+// NewBlobService creates a new *BlobService instance.
+//
+// Parameters:
+//   - blobs repository.BlobStore: storage of the payloads of binary secrets;
+//   - references repository.BlobReferenceStore: secret storage counting the references to the blobs;
+//   - encrypter *atrest.Encrypter: encrypter of blobs at rest, nil if blobs are stored as is;
+//   - logger logging.Logger: logger.
+func NewBlobService(
+	blobs repository.BlobStore,
+	references repository.BlobReferenceStore,
+	encrypter *atrest.Encrypter,
+	logger logging.Logger,
+) *BlobService {
+	return &BlobService{
+		blobs:      blobs,
+		references: references,
+		encrypter:  encrypter,
+		logger:     logger,
+	}
+}
+
+// SaveBlob saves the payload as a blob shared with the equal payloads
+// and returns the identifier of the blob, the size of the payload and the wrapped data key.
+//
+// The payload is streamed, so large payloads are never held in memory: it is read to compute
+// its hash, with encryption at rest once more to compute the hash of the encrypted content,
+// and, if no blob with the hash is saved yet, once more to save it. Every read of the payload
+// encrypted with the data key derived from its hash gives the same content, the content saved
+// is checked against the hash. The blob is held for blobHoldDuration, if no revision refers
+// to it by then, it is collected.
+//
+// Implements the BlobKeeper interface.
+func (s *BlobService) SaveBlob(ctx context.Context, open PayloadOpener) (*SavedBlob, error) {
+	blobID, size, err := hashPayload(ctx, open, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.blobKey(ctx, blobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if key != nil {
+		blobID, _, err = hashPayload(ctx, open, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	wrapped, err := s.wrapBlobKey(ctx, key, blobID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.holdBlob(ctx, blobID)
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := s.blobs.HasBlob(ctx, blobID)
+	if err != nil {
+		return nil, fmt.Errorf("check blob: %w", err)
+	}
+
+	if !saved {
+		err = s.putBlob(ctx, blobID, sealPayload(open, key))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &SavedBlob{ID: blobID, Size: size, Key: wrapped}, nil
+}
+
+// ShareBlob makes sure the saved blob stays in the storage while a new revision
+// referring to it is saved.
+//
+// Implements the BlobKeeper interface.
+func (s *BlobService) ShareBlob(ctx context.Context, blobID string) error {
+	err := s.holdBlob(ctx, blobID)
+	if err != nil {
+		return err
+	}
+
+	saved, err := s.blobs.HasBlob(ctx, blobID)
+	if err != nil {
+		return fmt.Errorf("check blob: %w", err)
+	}
+
+	if !saved {
+		return fmt.Errorf("%w: %s", errBlobMissing, blobID)
+	}
+
+	return nil
+}
+
+// OpenBlob returns the reader of the payload kept in the blob.
+//
+// The content is decrypted as it is read if the blob is encrypted at rest, blobs saved
+// before the encryption at rest was enabled have no key and are read as is.
+//
+// Implements the BlobKeeper interface.
+func (s *BlobService) OpenBlob(
+	ctx context.Context,
+	blobID string,
+	key atrest.WrappedKey,
+) (io.ReadCloser, error) {
+	if len(key.Key) > 0 && s.encrypter == nil {
+		return nil, errBlobKeysNotSet
+	}
+
+	content, err := s.blobs.GetBlob(ctx, blobID)
+	if err != nil {
+		return nil, fmt.Errorf("get blob: %w", err)
+	}
+
+	if len(key.Key) == 0 {
+		return content, nil
+	}
+
+	payload, err := s.encrypter.DecryptStream(ctx, &key, content,
+		model.BlobKeyAdditionalData(blobID), []byte(blobAdditionalData))
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("decrypt blob: %w", err), content.Close())
+	}
+
+	return &blobReader{Reader: payload, Closer: content}, nil
+}
+
+// Collect deletes the orphaned blobs and returns their number.
+//
+// A blob is claimed in the secret storage before it is deleted, so a revision
+// cannot start referring to it while its content is being deleted.
+//
+// Parameters:
+//   - ctx context.Context: context.
+func (s *BlobService) Collect(ctx context.Context) (int, error) {
+	blobs, err := s.blobs.ListBlobs(ctx, time.Now().Add(-blobCollectGrace))
+	if err != nil {
+		return 0, fmt.Errorf("list blobs: %w", err)
+	}
+
+	deleted := 0
+
+	for _, blob := range blobs {
+		collected, err := s.collectBlob(ctx, blob)
+		if err != nil {
+			return deleted, err
+		}
+
+		if collected {
+			deleted++
+		}
+	}
+
+	if deleted > 0 {
+		s.logger.Info("Orphaned blobs deleted", "count", deleted)
+	}
+
+	return deleted, nil
+}
+
+// StartCollecting deletes the orphaned blobs and checks the rest against the references
+// of the secrets in the background until ctx is done.
+//
+// The check runs against the live secret storage after every collection, so the blobs
+// it reports as unreferenced are the ones held by the changes in progress or not collected yet.
+//
+// Parameters:
+//   - ctx context.Context: context of the application.
+func (s *BlobService) StartCollecting(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(blobCollectInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_, err := s.Collect(ctx)
+				if err != nil {
+					s.logger.Error("Collecting orphaned blobs error", err)
+				}
+
+				_, err = s.Check(ctx)
+				if err != nil {
+					s.logger.Error("Checking blobs error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Check compares the references of all revisions to blobs with the content of the blob storage
+// and reports the references to missing blobs and the blobs no revision refers to.
+//
+// Nothing is changed. Blobs of the changes in progress are reported as unreferenced too,
+// they are collected only if no revision refers to them after the grace period.
+//
+// Parameters:
+//   - ctx context.Context: context.
+func (s *BlobService) Check(ctx context.Context) (*BlobCheckReport, error) {
+	blobs, err := s.blobs.ListBlobs(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("list blobs: %w", err)
+	}
+
+	references, err := s.references.ListBlobReferences(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list blob references: %w", err)
+	}
+
+	report := compareBlobs(blobs, references)
+
+	for _, reference := range report.Dangling {
+		s.logger.Warn(
+			"Dangling blob reference",
+			nil,
+			"blob_id",
+			reference.BlobID,
+			"user_id",
+			reference.UserID,
+			"secret_id",
+			reference.SecretID,
+			"revision",
+			reference.Revision,
+		)
+	}
+
+	for _, blob := range report.Unreferenced {
+		s.logger.Warn("Unreferenced blob", nil,
+			"blob_id", blob.ID, "size", blob.Size, "saved_at", blob.SavedAt)
+	}
+
+	s.logger.Info("Blob check completed", "blobs", report.Blobs, "references", report.References,
+		"dangling", len(report.Dangling), "unreferenced", len(report.Unreferenced))
+
+	return report, nil
+}
+
+// collectBlob deletes the blob if no revision refers to it and it is not held.
+func (s *BlobService) collectBlob(ctx context.Context, blob *model.Blob) (bool, error) {
+	claimed, err := s.references.ClaimBlob(ctx, blob.ID, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("claim blob: %w", err)
+	}
+
+	if !claimed {
+		return false, nil
+	}
+
+	deleteErr := s.blobs.DeleteBlob(ctx, blob.ID)
+
+	// The claim is released even if the deletion fails, so the blob can be used again.
+	err = s.references.ReleaseBlob(ctx, blob.ID)
+	if err != nil {
+		s.logger.Warn("Releasing blob error", err, "blob_id", blob.ID)
+	}
+
+	if deleteErr != nil {
+		return false, fmt.Errorf("delete blob: %w", deleteErr)
+	}
+
+	s.logger.Debug("Orphaned blob deleted", "blob_id", blob.ID, "size", blob.Size)
+
+	return true, nil
+}
+
+// blobKey derives the data key of the payload with the hash from the current master key.
+//
+// Returns nil if blobs are stored without encryption at rest.
+func (s *BlobService) blobKey(ctx context.Context, payloadHash string) (*atrest.StreamKey, error) {
+	if s.encrypter == nil {
+		return nil, nil //nolint:nilnil // encryption at rest is optional
+	}
+
+	key, err := s.encrypter.DeriveStreamKey(ctx, []byte(payloadHash))
+	if err != nil {
+		return nil, fmt.Errorf("derive blob key: %w", err)
+	}
+
+	return key, nil
+}
+
+// wrapBlobKey wraps the data key of the blob with the current master key.
+func (s *BlobService) wrapBlobKey(
+	ctx context.Context,
+	key *atrest.StreamKey,
+	blobID string,
+) (atrest.WrappedKey, error) {
+	if key == nil {
+		return atrest.WrappedKey{MasterKeyID: "", Key: nil}, nil
+	}
+
+	wrapped, err := s.encrypter.WrapStreamKey(ctx, key, model.BlobKeyAdditionalData(blobID))
+	if err != nil {
+		return atrest.WrappedKey{MasterKeyID: "", Key: nil}, fmt.Errorf("wrap blob key: %w", err)
+	}
+
+	return *wrapped, nil
+}
+
+// holdBlob protects the blob from the collection while a revision referring to it is saved.
+//
+// A blob being collected is held as soon as the collector releases it,
+// its content is checked and saved again after that.
+func (s *BlobService) holdBlob(ctx context.Context, blobID string) error {
+	for attempt := 1; ; attempt++ {
+		err := s.references.HoldBlob(ctx, blobID, time.Now().Add(blobHoldDuration))
+		if err == nil {
+			return nil
+		}
+
+		if !errors.Is(err, repository.ErrConflict) || attempt == blobHoldAttempts {
+			return fmt.Errorf("hold blob: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("hold blob: %w", ctx.Err())
+		case <-time.After(blobHoldPause):
+		}
+	}
+}
+
+// putBlob streams the payload to the blob storage as the blob with the hash.
+//
+// The payload is read once more, so it is hashed again as it is streamed: a payload changed
+// since it has been hashed fails before its last bytes reach the storage.
+func (s *BlobService) putBlob(ctx context.Context, blobID string, open PayloadOpener) error {
+	content, size, err := open(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		closeErr := content.Close()
+		if closeErr != nil {
+			s.logger.Warn("Closing payload error", closeErr, "blob_id", blobID)
+		}
+	}()
+
+	verified := &verifiedReader{
+		reader:    content,
+		hasher:    sha256.New(),
+		remaining: size,
+		blobID:    blobID,
+	}
+
+	err = s.blobs.PutBlob(ctx, blobID, verified, size)
+	if err != nil {
+		return fmt.Errorf("put blob: %w", err)
+	}
+
+	return nil
+}
+
+// compareBlobs finds the references to missing blobs and the blobs no reference points to.
+func compareBlobs(blobs []*model.Blob, references []*model.BlobReference) *BlobCheckReport {
+	report := &BlobCheckReport{
+		Blobs:        len(blobs),
+		References:   len(references),
+		Dangling:     make([]*model.BlobReference, 0),
+		Unreferenced: make([]*model.Blob, 0),
+	}
+
+	saved := make(map[string]bool, len(blobs))
+	for _, blob := range blobs {
+		saved[blob.ID] = true
+	}
+
+	referenced := make(map[string]bool, len(references))
+
+	for _, reference := range references {
+		referenced[reference.BlobID] = true
+
+		if !saved[reference.BlobID] {
+			report.Dangling = append(report.Dangling, reference)
+		}
+	}
+
+	for _, blob := range blobs {
+		if !referenced[blob.ID] {
+			report.Unreferenced = append(report.Unreferenced, blob)
+		}
+	}
+
+	return report
+}
+
+// blobReader - reader of the decrypted content of the blob that closes the blob.
+type blobReader struct {
+	io.Reader
+	io.Closer
+}
+
+// verifiedReader - reader of the content of a blob that checks the content against
+// the identifier of the blob and fails instead of returning its last bytes if they differ.
+type verifiedReader struct {
+	reader    io.Reader
+	hasher    hash.Hash
+	remaining int64
+	blobID    string
+}
+
+// Read reads the content and checks its hash once the expected size has been read.
+//
+// Implements the io.Reader interface.
+func (r *verifiedReader) Read(data []byte) (int, error) {
+	size, err := r.reader.Read(data)
+
+	_, _ = r.hasher.Write(data[:size])
+	r.remaining -= int64(size)
+
+	if size > 0 && r.remaining <= 0 && hex.EncodeToString(r.hasher.Sum(nil)) != r.blobID {
+		return 0, fmt.Errorf("%w: %s", errBlobContentChanged, r.blobID)
+	}
+
+	return size, err //nolint:wrapcheck // the reader passes the errors of the content as is
+}
+
+// sealPayload returns the opener of the payload encrypted with the data key of the blob.
+//
+// Returns the opener of the payload itself if the key is nil.
+func sealPayload(open PayloadOpener, key *atrest.StreamKey) PayloadOpener {
+	if key == nil {
+		return open
+	}
+
+	return func(ctx context.Context) (io.ReadCloser, int64, error) {
+		content, size, err := open(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		sealed := key.Encrypt(content, []byte(blobAdditionalData))
+
+		return &blobReader{Reader: sealed, Closer: content}, atrest.StreamSize(size), nil
+	}
+}
+
+// hashPayload reads the payload of a binary secret and returns the identifier of its blob and its size.
+//
+// The identifier is the hash of the payload encrypted with the key if the key is set.
+func hashPayload(
+	ctx context.Context,
+	open PayloadOpener,
+	key *atrest.StreamKey,
+) (string, int64, error) {
+	content, size, err := open(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+
+	blobID, err := readPayloadHash(bufio.NewReader(content), size, key)
+
+	err = errors.Join(err, content.Close())
+	if err != nil {
+		return "", 0, err
+	}
+
+	return blobID, size, nil
+}
+
+// readPayloadHash returns the SHA-256 hash of the payload of the size in hex,
+// encrypted with the key if it is set.
+//
+// An upload has not been validated yet, so the structure of its envelope is checked
+// by the first bytes before the payload is read further.
+func readPayloadHash(content *bufio.Reader, size int64, key *atrest.StreamKey) (string, error) {
+	prefix, err := content.Peek(int(min(size, crypto.EnvelopePrefixSize)))
+	if err != nil {
+		return "", fmt.Errorf("read payload: %w", err)
+	}
+
+	err = crypto.ValidateEnvelopePrefix(prefix, size)
+	if err != nil {
+		return "", fmt.Errorf("%w: data: %w", ErrInvalidSecret, err)
+	}
+
+	stored := io.Reader(content)
+	if key != nil {
+		stored = key.Encrypt(content, []byte(blobAdditionalData))
+	}
+
+	hasher := sha256.New()
+
+	_, err = io.Copy(hasher, stored)
+	if err != nil {
+		return "", fmt.Errorf("read payload: %w", err)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,363 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/filesystem"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/memory"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestLogger creates a logger that discards the records.
+func newTestLogger(t *testing.T) *logging.ZapSugarLogger {
+	t.Helper()
+
+	logger, err := logging.NewZapSugarLogger(logging.LevelError, io.Discard, logging.FormatJSON)
+	require.NoError(t, err)
+
+	return logger
+}
+
+// newTestEncrypter creates an encrypter of data at rest with a single master key.
+func newTestEncrypter(t *testing.T) *atrest.Encrypter {
+	t.Helper()
+
+	key, err := crypto.GenerateVaultKey()
+	require.NoError(t, err)
+
+	provider, err := atrest.NewStaticKeyProvider([]*atrest.MasterKey{{ID: "2026-10", Key: key}})
+	require.NoError(t, err)
+
+	return atrest.NewEncrypter(provider)
+}
+
+// newPayload encrypts the content on the client side, like a payload of a binary secret.
+func newPayload(t *testing.T, content string) []byte {
+	t.Helper()
+
+	key, err := crypto.GenerateVaultKey()
+	require.NoError(t, err)
+
+	vault, err := crypto.NewVault(key)
+	require.NoError(t, err)
+
+	payload, err := vault.Seal([]byte(content), nil)
+	require.NoError(t, err)
+
+	return payload
+}
+
+// openPayload returns the opener of the payload kept in memory.
+func openPayload(payload []byte) service.PayloadOpener {
+	return func(context.Context) (io.ReadCloser, int64, error) {
+		return io.NopCloser(bytes.NewReader(payload)), int64(len(payload)), nil
+	}
+}
+
+// newTestBlobStore creates the blob storage over a temporary directory and returns the directory.
+func newTestBlobStore(t *testing.T) (*filesystem.BlobStore, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	blobs, err := filesystem.NewBlobStore(dir)
+	require.NoError(t, err)
+
+	return blobs, dir
+}
+
+// blobIDOf returns the identifier of the blob with the content.
+func blobIDOf(content string) string {
+	hash := sha256.Sum256([]byte(content))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// putAgedBlob saves the content as a blob older than the grace period of the collection,
+// bypassing the blob service, so the blob is not held.
+func putAgedBlob(t *testing.T, blobs *filesystem.BlobStore, dir string, content string) string {
+	t.Helper()
+
+	blobID := blobIDOf(content)
+
+	err := blobs.PutBlob(context.Background(), blobID, strings.NewReader(content),
+		int64(len(content)))
+	require.NoError(t, err)
+
+	aged := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, blobID), aged, aged))
+
+	return blobID
+}
+
+// newBinarySecret creates a binary secret of alice with the payload kept in the blob.
+func newBinarySecret(secretID string, blobID string) *model.Secret {
+	now := time.Now().UTC()
+
+	return &model.Secret{
+		ID:        secretID,
+		UserID:    "alice",
+		FolderID:  "",
+		Type:      secret.TypeBinary,
+		Metadata:  nil,
+		Tags:      nil,
+		Data:      nil,
+		Revision:  1,
+		CreatedAt: now,
+		UpdatedAt: now,
+		DataKey:   atrest.WrappedKey{MasterKeyID: "", Key: nil},
+		BlobID:    blobID,
+		BlobSize:  1,
+		BlobKey:   atrest.WrappedKey{MasterKeyID: "", Key: nil},
+		Indexes:   nil,
+	}
+}
+
+// assertBlobs checks which of the blobs are kept in the storage.
+func assertBlobs(t *testing.T, blobs *filesystem.BlobStore, want map[string]bool) {
+	t.Helper()
+
+	for blobID, kept := range want {
+		saved, err := blobs.HasBlob(context.Background(), blobID)
+		require.NoError(t, err)
+		assert.Equal(t, kept, saved, "blob %s", blobID)
+	}
+}
+
+// readAll reads the content and closes the reader.
+func readAll(t *testing.T, content io.ReadCloser) []byte {
+	t.Helper()
+
+	defer func() {
+		require.NoError(t, content.Close())
+	}()
+
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestBlobService_EncryptsAtRest(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := newTestLogger(t)
+	blobs, _ := newTestBlobStore(t)
+
+	references := memory.NewSecretRepository()
+	blobService := service.NewBlobService(blobs, references, newTestEncrypter(t), logger)
+	payload := newPayload(t, "top secret payload")
+
+	saved, err := blobService.SaveBlob(ctx, openPayload(payload))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(payload)), saved.Size, "size of the payload, not of the blob")
+	assert.Equal(t, "2026-10", saved.Key.MasterKeyID)
+
+	stored, err := blobs.GetBlob(ctx, saved.ID)
+	require.NoError(t, err)
+	assert.NotContains(t, string(readAll(t, stored)), string(payload),
+		"the blob must not keep the payload as is")
+
+	content, err := blobService.OpenBlob(ctx, saved.ID, saved.Key)
+	require.NoError(t, err)
+	assert.Equal(t, payload, readAll(t, content))
+
+	plain := service.NewBlobService(blobs, references, nil, logger)
+
+	_, err = plain.OpenBlob(ctx, saved.ID, saved.Key)
+	require.Error(t, err, "the blob must not be opened without the master key")
+}
+
+func TestBlobService_DeduplicatesEncryptedPayloads(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	blobs, _ := newTestBlobStore(t)
+	secrets := memory.NewSecretRepository()
+	blobService := service.NewBlobService(blobs, secrets, newTestEncrypter(t), newTestLogger(t))
+	payload := newPayload(t, "shared payload")
+
+	first, err := blobService.SaveBlob(ctx, openPayload(payload))
+	require.NoError(t, err)
+
+	second, err := blobService.SaveBlob(ctx, openPayload(payload))
+	require.NoError(t, err)
+	require.Equal(t, first.ID, second.ID, "equal payloads must share the blob")
+
+	other, err := blobService.SaveBlob(ctx, openPayload(newPayload(t, "shared payload")))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, other.ID, "the payloads of the clients differ")
+
+	for index, saved := range []*service.SavedBlob{first, second} {
+		item := newBinarySecret(fmt.Sprintf("s%d", index), saved.ID)
+		item.BlobKey = saved.Key
+		require.NoError(t, secrets.Create(ctx, item))
+
+		content, err := blobService.OpenBlob(ctx, item.BlobID, item.BlobKey)
+		require.NoError(t, err)
+		assert.Equal(t, payload, readAll(t, content))
+	}
+
+	references, err := secrets.ListBlobReferences(ctx)
+	require.NoError(t, err)
+
+	count := 0
+
+	for _, reference := range references {
+		if reference.BlobID == first.ID {
+			count++
+		}
+	}
+
+	assert.Equal(t, 2, count, "the blob must be referred to by both secrets")
+
+	stored, err := blobs.ListBlobs(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Len(t, stored, 2, "the shared blob and the blob of the other payload")
+}
+
+func TestBlobService_RejectsChangedPayload(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	blobs, _ := newTestBlobStore(t)
+	blobService := service.NewBlobService(blobs, memory.NewSecretRepository(),
+		newTestEncrypter(t), newTestLogger(t))
+
+	payload := newPayload(t, "original payload")
+	reads := 0
+
+	// The payload is changed after it has been hashed, but before it is saved.
+	_, err := blobService.SaveBlob(ctx, func(context.Context) (io.ReadCloser, int64, error) {
+		reads++
+		if reads > 2 {
+			payload = newPayload(t, "modified payload")
+		}
+
+		return io.NopCloser(bytes.NewReader(payload)), int64(len(payload)), nil
+	})
+	require.ErrorContains(t, err, "blob content does not match its identifier")
+
+	stored, err := blobs.ListBlobs(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+}
+
+func TestBlobService_CollectDeletesOrphans(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	blobs, dir := newTestBlobStore(t)
+	blobService := service.NewBlobService(blobs, memory.NewSecretRepository(), nil,
+		newTestLogger(t))
+
+	orphan := putAgedBlob(t, blobs, dir, "orphan")
+	shared := putAgedBlob(t, blobs, dir, "shared")
+
+	fresh := blobIDOf("fresh")
+	require.NoError(t, blobs.PutBlob(ctx, fresh, strings.NewReader("fresh"), 5))
+
+	saved, err := blobService.SaveBlob(ctx, openPayload(newPayload(t, "being saved")))
+	require.NoError(t, err)
+
+	aged := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, saved.ID), aged, aged))
+
+	// A revision is about to refer to the orphaned blob again.
+	require.NoError(t, blobService.ShareBlob(ctx, shared))
+
+	deleted, err := blobService.Collect(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	assertBlobs(t, blobs, map[string]bool{
+		orphan:   false,
+		shared:   true,
+		fresh:    true,
+		saved.ID: true,
+	})
+
+	deleted, err = blobService.Collect(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, deleted, "collection must be idempotent")
+}
+
+func TestBlobService_CollectKeepsReferencedBlobs(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	blobs, dir := newTestBlobStore(t)
+	secrets := memory.NewSecretRepository()
+	blobService := service.NewBlobService(blobs, secrets, nil, newTestLogger(t))
+
+	current := putAgedBlob(t, blobs, dir, "current")
+	previous := putAgedBlob(t, blobs, dir, "previous")
+	next := putAgedBlob(t, blobs, dir, "next")
+
+	require.NoError(t, secrets.Create(ctx, newBinarySecret("s1", current)))
+	require.NoError(t, secrets.Create(ctx, newBinarySecret("s2", previous)))
+
+	// The live revision moves on, the replaced one stays in the history.
+	require.NoError(t, secrets.Update(ctx, newBinarySecret("s2", next)))
+
+	deleted, err := blobService.Collect(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+	assertBlobs(t, blobs, map[string]bool{current: true, previous: true, next: true})
+
+	pruned, err := secrets.PruneVersions(ctx, "alice", "s2", 0, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, 1, pruned)
+
+	deleted, err = blobService.Collect(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted, "the blob must be collected once it leaves the history")
+	assertBlobs(t, blobs, map[string]bool{current: true, previous: false, next: true})
+}
+
+func TestBlobService_Check(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	blobs, dir := newTestBlobStore(t)
+	secrets := memory.NewSecretRepository()
+	blobService := service.NewBlobService(blobs, secrets, nil, newTestLogger(t))
+
+	referenced := putAgedBlob(t, blobs, dir, "referenced")
+	orphan := putAgedBlob(t, blobs, dir, "orphan")
+	missing := blobIDOf("missing")
+
+	require.NoError(t, secrets.Create(ctx, newBinarySecret("s1", referenced)))
+	require.NoError(t, secrets.Create(ctx, newBinarySecret("s2", missing)))
+
+	report, err := blobService.Check(ctx)
+	require.NoError(t, err)
+	assert.False(t, report.Consistent())
+	assert.Equal(t, 2, report.Blobs)
+	assert.Equal(t, 2, report.References)
+	require.Len(t, report.Dangling, 1)
+	assert.Equal(t, missing, report.Dangling[0].BlobID)
+	assert.Equal(t, "s2", report.Dangling[0].SecretID)
+	require.Len(t, report.Unreferenced, 1)
+	assert.Equal(t, orphan, report.Unreferenced[0].ID)
+
+	assertBlobs(t, blobs, map[string]bool{referenced: true, orphan: true})
+}
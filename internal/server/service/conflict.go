@@ -0,0 +1,337 @@
+// Package service contains the business logic of the server application.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// Errors returned by the conflict service.
+var (
+	// ErrSecretConflictNotFound - the user has no such conflict.
+	ErrSecretConflictNotFound = errors.New("secret conflict not found")
+
+	// ErrInvalidResolution - the resolution of the conflict is unknown or incomplete.
+	ErrInvalidResolution = errors.New("invalid conflict resolution")
+)
+
+// Resolution - version of the secret the user has chosen to keep.
+type Resolution string
+
+// Supported resolutions of conflicts.
+const (
+	ResolutionServer Resolution = "server" // Keep the current revision, discard the client edit.
+	ResolutionClient Resolution = "client" // Replace the current revision with the client edit.
+	ResolutionMerged Resolution = "merged" // Replace the current revision with the merged content.
+)
+
+// SecretEditor describes the functionality of changing secrets used to apply offline edits.
+type SecretEditor interface {
+	// Create saves a new secret of the user.
+	Create(ctx context.Context, userID string, input SecretInput) (*model.Secret, error)
+
+	// Get returns the user's secret.
+	Get(ctx context.Context, userID string, secretID string) (*model.Secret, error)
+
+	// Update replaces the content of the user's secret if the revision matches.
+	Update(
+		ctx context.Context,
+		userID string,
+		secretID string,
+		input SecretInput,
+		match RevisionMatch,
+	) (*model.Secret, error)
+}
+
+// PushResult describes the outcome of an offline edit, exactly one of the fields is set.
+type PushResult struct {
+	Secret   *model.Secret         // Updated secret, if the edit was based on the current revision.
+	Conflict *model.SecretConflict // Recorded conflict, if the secret has been changed or deleted in the meantime.
+}
+
+// ConflictService implements the detection and resolution of conflicts of offline edits.
+type ConflictService struct {
+	conflicts repository.SecretConflictRepository
+	secrets   SecretEditor
+	logger    logging.Logger
+}
+
+// NewConflictService creates a new *ConflictService instance.
+//
+// Parameters:
+//   - conflicts repository.SecretConflictRepository: storage of conflicts;
+//   - secrets SecretEditor: secret management;
+//   - logger logging.Logger: logger.
+func NewConflictService(
+	conflicts repository.SecretConflictRepository,
+	secrets SecretEditor,
+	logger logging.Logger,
+) *ConflictService {
+	return &ConflictService{
+		conflicts: conflicts,
+		secrets:   secrets,
+		logger:    logger,
+	}
+}
+
+// Push applies the edit made offline against the base revision.
+//
+// If the secret has been changed since the base revision, the edit is not applied
+// but kept as a conflict next to the current revision. If the secret has been deleted
+// or moved to the trash, the edit is kept as a conflict with the deleted server state,
+// so it is not lost and the user decides whether to recreate the secret.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - secretID string: secret identifier;
+//   - baseRevision int64: revision the edit is based on;
+//   - input SecretInput: edited secret data.
+func (s *ConflictService) Push(
+	ctx context.Context,
+	userID string,
+	secretID string,
+	baseRevision int64,
+	input SecretInput,
+) (*PushResult, error) {
+	item, err := s.secrets.Update(ctx, userID, secretID, input, RevisionMatch{baseRevision})
+	if err == nil {
+		return &PushResult{Secret: item, Conflict: nil}, nil
+	}
+
+	var current *model.Secret
+
+	switch {
+	case errors.Is(err, ErrSecretModified):
+		current, err = s.secrets.Get(ctx, userID, secretID)
+		if err != nil && !errors.Is(err, ErrSecretNotFound) {
+			return nil, fmt.Errorf("get secret: %w", err)
+		}
+	case errors.Is(err, ErrSecretNotFound):
+	default:
+		return nil, fmt.Errorf("update secret: %w", err)
+	}
+
+	conflict := newSecretConflict(userID, secretID, baseRevision, input, current)
+
+	err = s.conflicts.CreateConflict(ctx, conflict)
+	if err != nil {
+		return nil, fmt.Errorf("create conflict: %w", err)
+	}
+
+	s.logger.Info("Secret conflict recorded",
+		"user_id", userID,
+		"secret_id", secretID,
+		"conflict_id", conflict.ID,
+		"base_revision", baseRevision,
+		"server_revision", conflict.ServerRevision,
+		"server_deleted", conflict.ServerDeleted,
+	)
+
+	return &PushResult{Secret: nil, Conflict: conflict}, nil
+}
+
+// Get returns the user's conflict.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - conflictID string: conflict identifier.
+func (s *ConflictService) Get(
+	ctx context.Context,
+	userID string,
+	conflictID string,
+) (*model.SecretConflict, error) {
+	conflict, err := s.conflicts.GetConflict(ctx, userID, conflictID)
+	if err != nil {
+		return nil, mapConflictError("get conflict", err)
+	}
+
+	return conflict, nil
+}
+
+// List returns all unresolved conflicts of the user sorted by detection time.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner.
+func (s *ConflictService) List(
+	ctx context.Context,
+	userID string,
+) ([]*model.SecretConflict, error) {
+	conflicts, err := s.conflicts.ListConflicts(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list conflicts: %w", err)
+	}
+
+	return conflicts, nil
+}
+
+// ListPage returns a page of the unresolved conflicts of the user sorted by detection time.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - query model.PageQuery: position and size of the page.
+func (s *ConflictService) ListPage(
+	ctx context.Context,
+	userID string,
+	query model.PageQuery,
+) (*Page[*model.SecretConflict], error) {
+	conflicts, err := s.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return paginate(conflicts, query, func(conflict *model.SecretConflict) model.PageCursor {
+		return model.PageCursor{Time: conflict.CreatedAt, ID: conflict.ID}
+	}, false), nil
+}
+
+// Resolve applies the version chosen by the user and deletes the conflict.
+//
+// The client and merged resolutions replace the current revision of the secret
+// only if it matches, so a revision the user has not seen is never overwritten.
+// The replaced revision stays in the history of the secret. If the secret has been deleted,
+// they recreate it as a new secret, the deleted one stays in the trash until it is purged.
+//
+// Returns the recreated secret, nil if the secret has not been recreated.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - conflictID string: conflict identifier;
+//   - resolution Resolution: version to keep;
+//   - merged *SecretInput: merged content, required only for the merged resolution;
+//   - match RevisionMatch: current revisions of the secret the resolution is allowed on.
+func (s *ConflictService) Resolve(
+	ctx context.Context,
+	userID string,
+	conflictID string,
+	resolution Resolution,
+	merged *SecretInput,
+	match RevisionMatch,
+) (*model.Secret, error) {
+	conflict, err := s.conflicts.GetConflict(ctx, userID, conflictID)
+	if err != nil {
+		return nil, mapConflictError("get conflict", err)
+	}
+
+	recreated, err := s.apply(ctx, conflict, resolution, merged, match)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.conflicts.DeleteConflict(ctx, userID, conflictID)
+	if err != nil {
+		return nil, mapConflictError("delete conflict", err)
+	}
+
+	s.logger.Info("Secret conflict resolved",
+		"user_id", userID,
+		"secret_id", conflict.SecretID,
+		"conflict_id", conflictID,
+		"resolution", string(resolution),
+	)
+
+	return recreated, nil
+}
+
+// apply writes the version chosen by the user to the secret
+// and returns the secret recreated in place of the deleted one.
+func (s *ConflictService) apply(
+	ctx context.Context,
+	conflict *model.SecretConflict,
+	resolution Resolution,
+	merged *SecretInput,
+	match RevisionMatch,
+) (*model.Secret, error) {
+	var input SecretInput
+
+	switch resolution {
+	case ResolutionServer:
+		return nil, nil //nolint:nilnil // the server version is kept as is
+	case ResolutionClient:
+		input = SecretInput{
+			FolderID: "",
+			Type:     conflict.Type,
+			Metadata: conflict.Metadata,
+			Tags:     conflict.Tags,
+			Data:     conflict.Data,
+			UploadID: "",
+			Indexes:  conflict.Indexes,
+		}
+	case ResolutionMerged:
+		if merged == nil {
+			return nil, fmt.Errorf("%w: merged content is required", ErrInvalidResolution)
+		}
+
+		input = *merged
+	default:
+		return nil, fmt.Errorf("%w: unknown resolution %q", ErrInvalidResolution, resolution)
+	}
+
+	_, err := s.secrets.Update(ctx, conflict.UserID, conflict.SecretID, input, match)
+	if err == nil {
+		return nil, nil //nolint:nilnil // the secret has been updated in place
+	}
+
+	// The secret restored from the trash in the meantime is updated, never duplicated.
+	if !conflict.ServerDeleted || !errors.Is(err, ErrSecretNotFound) {
+		return nil, fmt.Errorf("update secret: %w", err)
+	}
+
+	recreated, err := s.secrets.Create(ctx, conflict.UserID, input)
+	if err != nil {
+		return nil, fmt.Errorf("recreate secret: %w", err)
+	}
+
+	return recreated, nil
+}
+
+// newSecretConflict creates the conflict of the edit with the current revision of the secret,
+// nil if the secret has been deleted.
+func newSecretConflict(
+	userID string,
+	secretID string,
+	baseRevision int64,
+	input SecretInput,
+	current *model.Secret,
+) *model.SecretConflict {
+	conflict := &model.SecretConflict{
+		ID:             uuid.NewString(),
+		UserID:         userID,
+		SecretID:       secretID,
+		BaseRevision:   baseRevision,
+		ServerRevision: 0,
+		ServerDeleted:  current == nil,
+		Type:           input.Type,
+		Metadata:       input.Metadata,
+		Tags:           normalizeTags(input.Tags),
+		Data:           input.Data,
+		CreatedAt:      time.Now().UTC(),
+		Indexes:        normalizeIndexes(input.Indexes),
+	}
+
+	if current != nil {
+		conflict.ServerRevision = current.Revision
+	}
+
+	return conflict
+}
+
+// mapConflictError converts repository errors to the errors of the service.
+func mapConflictError(operation string, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrSecretConflictNotFound
+	}
+
+	return fmt.Errorf("%s: %w", operation, err)
+}
@@ -0,0 +1,317 @@
+// Package service contains the business logic of the server application.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// Errors returned by the folder service.
+var (
+	// ErrFolderNotFound - the user has no such folder.
+	ErrFolderNotFound = errors.New("folder not found")
+
+	// ErrInvalidFolder - the folder does not meet the requirements or cannot be placed there.
+	ErrInvalidFolder = errors.New("invalid folder")
+)
+
+// maxFolderNameSize - maximum size of the encrypted name of a folder in bytes.
+const maxFolderNameSize = 1024
+
+// FolderContents describes the access to the secrets placed in folders.
+type FolderContents interface {
+	// List returns all the user's secrets.
+	List(ctx context.Context, userID string) ([]*model.Secret, error)
+
+	// Delete moves the user's secret to the trash if the revision matches.
+	Delete(ctx context.Context, userID string, secretID string, match RevisionMatch) error
+}
+
+// FolderTree describes a folder with its subfolders and secrets.
+type FolderTree struct {
+	Folder  *model.Folder   // Folder, nil for the top level of the vault.
+	Folders []*FolderTree   // Subfolders sorted by creation time.
+	Secrets []*model.Secret // Secrets of the folder sorted by creation time.
+}
+
+// FolderService implements the organization of the user's secrets into nested folders.
+//
+// Folder names are encrypted on the client like the payloads of secrets, the server
+// checks only the structure of the envelope. Secrets referring to a folder that does not
+// exist anymore, such as the ones restored from the trash after their folder was deleted,
+// are listed at the top level.
+type FolderService struct {
+	folders repository.FolderRepository
+	secrets FolderContents
+	logger  logging.Logger
+}
+
+// NewFolderService creates a new *FolderService instance.
+//
+// Parameters:
+//   - folders repository.FolderRepository: folder storage;
+//   - secrets FolderContents: secrets placed in the folders;
+//   - logger logging.Logger: logger.
+func NewFolderService(
+	folders repository.FolderRepository,
+	secrets FolderContents,
+	logger logging.Logger,
+) *FolderService {
+	return &FolderService{
+		folders: folders,
+		secrets: secrets,
+		logger:  logger,
+	}
+}
+
+// Create creates a new folder for the user.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - name []byte: name encrypted on the client;
+//   - parentID string: identifier of the parent folder, empty for the top level.
+func (s *FolderService) Create(
+	ctx context.Context,
+	userID string,
+	name []byte,
+	parentID string,
+) (*model.Folder, error) {
+	err := validateFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	folder := &model.Folder{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		ParentID:  parentID,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+		NameKey:   atrest.WrappedKey{MasterKeyID: "", Key: nil},
+	}
+
+	err = s.folders.CreateFolder(ctx, folder)
+	if err != nil {
+		return nil, mapFolderError("create folder", err)
+	}
+
+	s.logger.Debug("Folder created", "user_id", userID, "folder_id", folder.ID)
+
+	return folder, nil
+}
+
+// Rename replaces the name of the user's folder.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - folderID string: folder identifier;
+//   - name []byte: new name encrypted on the client.
+func (s *FolderService) Rename(
+	ctx context.Context,
+	userID string,
+	folderID string,
+	name []byte,
+) (*model.Folder, error) {
+	err := validateFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	folder, err := s.folders.GetFolder(ctx, userID, folderID)
+	if err != nil {
+		return nil, mapFolderError("get folder", err)
+	}
+
+	folder.Name = name
+	folder.UpdatedAt = time.Now().UTC()
+
+	err = s.folders.RenameFolder(ctx, folder)
+	if err != nil {
+		return nil, mapFolderError("rename folder", err)
+	}
+
+	s.logger.Debug("Folder renamed", "user_id", userID, "folder_id", folderID)
+
+	return folder, nil
+}
+
+// Move places the user's folder with its contents into another folder.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - folderID string: folder identifier;
+//   - parentID string: identifier of the new parent folder, empty for the top level.
+func (s *FolderService) Move(
+	ctx context.Context,
+	userID string,
+	folderID string,
+	parentID string,
+) (*model.Folder, error) {
+	folder, err := s.folders.GetFolder(ctx, userID, folderID)
+	if err != nil {
+		return nil, mapFolderError("get folder", err)
+	}
+
+	folder.ParentID = parentID
+	folder.UpdatedAt = time.Now().UTC()
+
+	err = s.folders.MoveFolder(ctx, folder)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("%w: folder cannot be moved into itself", ErrInvalidFolder)
+		}
+
+		return nil, mapFolderError("move folder", err)
+	}
+
+	s.logger.Debug("Folder moved", "user_id", userID, "folder_id", folderID, "parent_id", parentID)
+
+	return folder, nil
+}
+
+// Delete deletes the user's folder with its subfolders and moves their secrets to the trash.
+//
+// The folders are deleted first, so a secret placed into them concurrently is not lost:
+// it is listed at the top level.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - folderID string: folder identifier.
+func (s *FolderService) Delete(ctx context.Context, userID string, folderID string) error {
+	deleted, err := s.folders.DeleteFolder(ctx, userID, folderID)
+	if err != nil {
+		return mapFolderError("delete folder", err)
+	}
+
+	items, err := s.secrets.List(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("list secrets: %w", err)
+	}
+
+	trashed := 0
+
+	for _, item := range items {
+		if !slices.Contains(deleted, item.FolderID) {
+			continue
+		}
+
+		// A secret changed concurrently is kept, it is listed at the top level.
+		err = s.secrets.Delete(ctx, userID, item.ID, RevisionMatch{item.Revision})
+		if err != nil && !errors.Is(err, ErrSecretModified) && !errors.Is(err, ErrSecretNotFound) {
+			return fmt.Errorf("delete secret %s: %w", item.ID, err)
+		}
+
+		if err == nil {
+			trashed++
+		}
+	}
+
+	s.logger.Info("Folder deleted", "user_id", userID, "folder_id", folderID,
+		"folders", len(deleted), "secrets", trashed)
+
+	return nil
+}
+
+// Tree returns the folders of the user's vault with their secrets.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner.
+func (s *FolderService) Tree(ctx context.Context, userID string) (*FolderTree, error) {
+	folders, err := s.folders.ListFolders(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list folders: %w", err)
+	}
+
+	items, err := s.secrets.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list secrets: %w", err)
+	}
+
+	root := &FolderTree{Folder: nil, Folders: nil, Secrets: nil}
+
+	nodes := make(map[string]*FolderTree, len(folders))
+	for _, folder := range folders {
+		nodes[folder.ID] = &FolderTree{Folder: folder, Folders: nil, Secrets: nil}
+	}
+
+	// The folders are sorted by creation time, so the children keep the order.
+	for _, folder := range folders {
+		parent, ok := nodes[folder.ParentID]
+		if !ok {
+			parent = root
+		}
+
+		parent.Folders = append(parent.Folders, nodes[folder.ID])
+	}
+
+	for _, item := range items {
+		node, ok := nodes[item.FolderID]
+		if !ok {
+			node = root
+		}
+
+		node.Secrets = append(node.Secrets, item)
+	}
+
+	return root, nil
+}
+
+// checkFolder checks that the user has the folder, an empty identifier means the top level.
+func checkFolder(
+	ctx context.Context,
+	folders repository.FolderRepository,
+	userID string,
+	folderID string,
+) error {
+	if folderID == "" {
+		return nil
+	}
+
+	_, err := folders.GetFolder(ctx, userID, folderID)
+	if err != nil {
+		return mapFolderError("get folder", err)
+	}
+
+	return nil
+}
+
+func mapFolderError(operation string, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrFolderNotFound
+	}
+
+	return fmt.Errorf("%s: %w", operation, err)
+}
+
+// validateFolderName checks the encrypted name of a folder,
+// only the structure of the envelope can be checked.
+func validateFolderName(name []byte) error {
+	if len(name) > maxFolderNameSize {
+		return fmt.Errorf("%w: name must be at most %d bytes", ErrInvalidFolder, maxFolderNameSize)
+	}
+
+	_, err := crypto.ParseEnvelope(name)
+	if err != nil {
+		return fmt.Errorf("%w: name: %w", ErrInvalidFolder, err)
+	}
+
+	return nil
+}
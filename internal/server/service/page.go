@@ -0,0 +1,73 @@
+// Package service contains the business logic of the server application.
+package service
+
+import (
+	"slices"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+)
+
+// Restrictions on the size of a page of the listings ordered by time.
+const (
+	DefaultPageLimit = 100
+	MaxPageLimit     = 500
+)
+
+// Page describes a page of a listing ordered by time.
+type Page[T any] struct {
+	Items []T               // Items in the order of the listing.
+	Next  *model.PageCursor // Position the next page starts after, nil on the last page.
+}
+
+// paginate returns the page of the items of the listing.
+//
+// The items are ordered by their positions, so the pages neither skip nor repeat items
+// that have not been changed whatever order the storage returns them in.
+//
+// Parameters:
+//   - items []T: all items of the listing;
+//   - query model.PageQuery: position of the page, the limit is DefaultPageLimit
+//     if not positive and at most MaxPageLimit;
+//   - position func(T) model.PageCursor: position of the item in the listing;
+//   - descending bool: the listing starts with the latest item.
+func paginate[T any](
+	items []T,
+	query model.PageQuery,
+	position func(T) model.PageCursor,
+	descending bool,
+) *Page[T] {
+	compare := func(a, b model.PageCursor) int {
+		if descending {
+			return b.Compare(a)
+		}
+
+		return a.Compare(b)
+	}
+
+	slices.SortFunc(items, func(a, b T) int {
+		return compare(position(a), position(b))
+	})
+
+	if query.After != nil {
+		items = slices.DeleteFunc(items, func(item T) bool {
+			return compare(position(item), *query.After) <= 0
+		})
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+
+	limit = min(limit, MaxPageLimit)
+
+	page := &Page[T]{Items: items, Next: nil}
+
+	if len(items) > limit {
+		page.Items = items[:limit]
+		next := position(items[limit-1])
+		page.Next = &next
+	}
+
+	return page
+}
@@ -0,0 +1,451 @@
+// Package service contains the business logic of the server application.
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// Errors returned by the recovery service.
+var (
+	// ErrInvalidRecoveryCodes - the set of recovery codes does not meet the requirements.
+	ErrInvalidRecoveryCodes = errors.New("invalid recovery codes")
+
+	// ErrRecoveryCodeUsed - the recovery code has already been used.
+	ErrRecoveryCodeUsed = errors.New("recovery code already used")
+)
+
+// TwoFactorResetter describes the removal of the user's second factor.
+type TwoFactorResetter interface {
+	// Reset removes the enrollment of the user in two-factor authentication, if any.
+	Reset(ctx context.Context, userID string) error
+}
+
+// maxRecoveryCodes - maximum number of recovery codes in a set.
+const maxRecoveryCodes = 20
+
+// RecoverInput describes the data for regaining access with a recovery code.
+//
+// The client unwraps the vault key with the key derived from the recovery code
+// and wraps it again with the key derived from the new master password.
+type RecoverInput struct {
+	Login        string                 // User login.
+	CodeID       string                 // Identifier of the recovery code.
+	CodePassword string                 // Authentication password derived from the recovery code.
+	NewPassword  string                 // Authentication password derived from the new master password.
+	VaultKey     crypto.WrappedVaultKey // Vault key wrapped with the new master password.
+}
+
+// RecoverResult describes the result of a successful recovery.
+type RecoverResult struct {
+	RevokedSessions int // Number of revoked sessions.
+	RemainingCodes  int // Number of unused recovery codes left.
+}
+
+// RecoveryCodesStatus describes the state of the user's set of recovery codes.
+type RecoveryCodesStatus struct {
+	Total     int       // Number of codes in the set.
+	Remaining int       // Number of unused codes.
+	CreatedAt time.Time // Generation time of the set, zero if there are no codes.
+}
+
+// RecoveryService implements the account recovery with one-time recovery codes.
+//
+// Every recovery code protects its own copy of the vault key, so the access is regained
+// without re-encrypting secrets and without the server ever seeing the vault key.
+type RecoveryService struct {
+	users     repository.UserRepository
+	codes     repository.RecoveryCodeRepository
+	hasher    PasswordHasher
+	sessions  SessionRevoker
+	twoFactor TwoFactorResetter
+	audit     AuditRecorder
+	logger    logging.Logger
+}
+
+// NewRecoveryService creates a new *RecoveryService instance.
+//
+// Parameters:
+//   - users repository.UserRepository: user storage;
+//   - codes repository.RecoveryCodeRepository: storage of recovery codes;
+//   - hasher PasswordHasher: password hasher;
+//   - sessions SessionRevoker: revoker of the user's sessions;
+//   - twoFactor TwoFactorResetter: remover of the second factor;
+//   - audit AuditRecorder: audit log;
+//   - logger logging.Logger: logger.
+func NewRecoveryService(
+	users repository.UserRepository,
+	codes repository.RecoveryCodeRepository,
+	hasher PasswordHasher,
+	sessions SessionRevoker,
+	twoFactor TwoFactorResetter,
+	audit AuditRecorder,
+	logger logging.Logger,
+) *RecoveryService {
+	return &RecoveryService{
+		users:     users,
+		codes:     codes,
+		hasher:    hasher,
+		sessions:  sessions,
+		twoFactor: twoFactor,
+		audit:     audit,
+		logger:    logger,
+	}
+}
+
+// RecoveryKey returns the copy of the vault key wrapped with the recovery code.
+//
+// The identifier is derived from the code, so only the owner of the code can find the key,
+// and the key can be unwrapped only with the code itself.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - login string: user login;
+//   - codeID string: identifier of the recovery code.
+func (s *RecoveryService) RecoveryKey(
+	ctx context.Context,
+	login string,
+	codeID string,
+) (*crypto.WrappedVaultKey, error) {
+	_, code, err := s.getCode(ctx, login, codeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if code.IsUsed() {
+		return nil, ErrRecoveryCodeUsed
+	}
+
+	return &code.VaultKey, nil
+}
+
+// Recover sets a new master password with the recovery code.
+//
+// The code is invalidated, all the user's sessions are revoked, two-factor authentication
+// is turned off (the recovery codes replace the lost second factor),
+// and the recovery is written to the audit log.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - input RecoverInput: recovery code and new credentials;
+//   - client model.ClientInfo: client from which the recovery is made.
+func (s *RecoveryService) Recover(
+	ctx context.Context,
+	input RecoverInput,
+	client model.ClientInfo,
+) (*RecoverResult, error) {
+	err := validatePassword(input.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateVaultKey(&input.VaultKey)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.verifyCode(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	// The event is written before the changes, so no recovery is left out of the audit log.
+	err = s.audit.Record(ctx, user.ID, model.AuditEventAccountRecovered, client,
+		map[string]string{"recovery_code_id": input.CodeID})
+	if err != nil {
+		return nil, fmt.Errorf("record audit event: %w", err)
+	}
+
+	err = s.useCode(ctx, user, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Account recovered with recovery code",
+		"user_id", user.ID, "recovery_code_id", input.CodeID)
+
+	revoked, err := s.resetAccess(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := s.Status(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecoverResult{
+		RevokedSessions: revoked,
+		RemainingCodes:  status.Remaining,
+	}, nil
+}
+
+// Regenerate replaces the set of recovery codes of the user, the old codes stop working.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the user;
+//   - password string: current authentication password;
+//   - keys []crypto.RecoveryKey: copies of the vault key wrapped with the new codes;
+//   - client model.ClientInfo: client from which the codes are regenerated.
+func (s *RecoveryService) Regenerate(
+	ctx context.Context,
+	userID string,
+	password string,
+	keys []crypto.RecoveryKey,
+	client model.ClientInfo,
+) error {
+	codes, err := newRecoveryCodes(userID, keys)
+	if err != nil {
+		return err
+	}
+
+	_, err = verifyUserPassword(ctx, s.users, s.hasher, userID, password)
+	if err != nil {
+		return err
+	}
+
+	err = s.codes.ReplaceRecoveryCodes(ctx, userID, codes)
+	if err != nil {
+		return fmt.Errorf("replace recovery codes: %w", err)
+	}
+
+	err = s.audit.Record(ctx, userID, model.AuditEventRecoveryCodesRegenerated, client,
+		map[string]string{"count": strconv.Itoa(len(codes))})
+	if err != nil {
+		return fmt.Errorf("record audit event: %w", err)
+	}
+
+	return nil
+}
+
+// Status returns the state of the user's set of recovery codes.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the user.
+func (s *RecoveryService) Status(ctx context.Context, userID string) (*RecoveryCodesStatus, error) {
+	codes, err := s.codes.ListRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list recovery codes: %w", err)
+	}
+
+	status := &RecoveryCodesStatus{
+		Total:     len(codes),
+		Remaining: 0,
+		CreatedAt: time.Time{},
+	}
+
+	for _, code := range codes {
+		if !code.IsUsed() {
+			status.Remaining++
+		}
+
+		status.CreatedAt = code.CreatedAt
+	}
+
+	return status, nil
+}
+
+// getCode returns the user and the recovery code by the login and the code identifier.
+func (s *RecoveryService) getCode(
+	ctx context.Context,
+	login string,
+	codeID string,
+) (*model.User, *model.RecoveryCode, error) {
+	user, err := s.users.GetByLogin(ctx, login)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrInvalidCredentials
+		}
+
+		return nil, nil, fmt.Errorf("get user: %w", err)
+	}
+
+	code, err := s.codes.GetRecoveryCode(ctx, user.ID, codeID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrInvalidCredentials
+		}
+
+		return nil, nil, fmt.Errorf("get recovery code: %w", err)
+	}
+
+	return user, code, nil
+}
+
+// verifyCode returns the user if the recovery code is valid and has not been used.
+func (s *RecoveryService) verifyCode(
+	ctx context.Context,
+	input *RecoverInput,
+) (*model.User, error) {
+	user, code, err := s.getCode(ctx, input.Login, input.CodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := hashRecoveryCodePassword(input.CodePassword)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(code.AuthHash)) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+
+	if code.IsUsed() {
+		return nil, ErrRecoveryCodeUsed
+	}
+
+	return user, nil
+}
+
+// useCode invalidates the recovery code and sets the new credentials of the user.
+//
+// The code is invalidated first, so it cannot be used by two recoveries at once,
+// and is released if the credentials are not replaced, so a failed recovery does not burn it.
+func (s *RecoveryService) useCode(
+	ctx context.Context,
+	user *model.User,
+	input *RecoverInput,
+) error {
+	usedAt := time.Now().UTC()
+
+	err := s.codes.UseRecoveryCode(ctx, user.ID, input.CodeID, usedAt)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return ErrRecoveryCodeUsed
+		}
+
+		return fmt.Errorf("use recovery code: %w", err)
+	}
+
+	err = s.replaceCredentials(ctx, user, input.NewPassword, input.VaultKey)
+	if err != nil {
+		releaseErr := s.codes.ReleaseRecoveryCode(
+			context.WithoutCancel(ctx), user.ID, input.CodeID, usedAt)
+		if releaseErr != nil {
+			s.logger.Error("Releasing recovery code error", releaseErr,
+				"user_id", user.ID, "recovery_code_id", input.CodeID)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// replaceCredentials sets the new password and the vault key wrapped with it.
+func (s *RecoveryService) replaceCredentials(
+	ctx context.Context,
+	user *model.User,
+	password string,
+	vaultKey crypto.WrappedVaultKey,
+) error {
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+
+	previousHash := user.PasswordHash
+	user.PasswordHash = hash
+	user.VaultKey = vaultKey
+
+	err = s.users.UpdateCredentials(ctx, user, previousHash)
+	if err != nil {
+		return fmt.Errorf("update credentials: %w", err)
+	}
+
+	return nil
+}
+
+// resetAccess turns two-factor authentication off and revokes all the user's sessions.
+//
+// Returns the number of revoked sessions.
+func (s *RecoveryService) resetAccess(ctx context.Context, userID string) (int, error) {
+	err := s.twoFactor.Reset(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("reset two-factor authentication: %w", err)
+	}
+
+	revoked, err := s.sessions.RevokeOthers(ctx, userID, "")
+	if err != nil {
+		return 0, fmt.Errorf("revoke sessions: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// newRecoveryCodes validates the copies of the vault key and creates the recovery codes of the user.
+func newRecoveryCodes(userID string, keys []crypto.RecoveryKey) ([]*model.RecoveryCode, error) {
+	if len(keys) == 0 || len(keys) > maxRecoveryCodes {
+		return nil, fmt.Errorf("%w: from 1 to %d codes are required",
+			ErrInvalidRecoveryCodes, maxRecoveryCodes)
+	}
+
+	now := time.Now().UTC()
+	codes := make([]*model.RecoveryCode, 0, len(keys))
+	seen := make(map[string]struct{}, len(keys))
+
+	for _, key := range keys {
+		err := validateRecoveryKey(&key)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := seen[key.ID]; ok {
+			return nil, fmt.Errorf("%w: duplicate code %s", ErrInvalidRecoveryCodes, key.ID)
+		}
+
+		seen[key.ID] = struct{}{}
+
+		codes = append(codes, &model.RecoveryCode{
+			ID:        key.ID,
+			UserID:    userID,
+			AuthHash:  hashRecoveryCodePassword(key.AuthPassword),
+			CreatedAt: now,
+			UsedAt:    time.Time{},
+			VaultKey:  key.VaultKey,
+		})
+	}
+
+	return codes, nil
+}
+
+func validateRecoveryKey(key *crypto.RecoveryKey) error {
+	raw, err := hex.DecodeString(key.ID)
+	if err != nil || len(key.ID) != crypto.RecoveryCodeIDLength ||
+		hex.EncodeToString(raw) != key.ID {
+		return fmt.Errorf("%w: code identifier must be %d lowercase hex characters",
+			ErrInvalidRecoveryCodes, crypto.RecoveryCodeIDLength)
+	}
+
+	err = validatePassword(key.AuthPassword)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRecoveryCodes, err)
+	}
+
+	err = validateVaultKey(&key.VaultKey)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRecoveryCodes, err)
+	}
+
+	return nil
+}
+
+// hashRecoveryCodePassword returns the hash under which the password of the recovery code is stored.
+//
+// The password is derived from a random code with a slow KDF on the client,
+// so, like refresh tokens, it does not need a slow hash on the server.
+func hashRecoveryCodePassword(password string) string {
+	sum := sha256.Sum256([]byte(password))
+
+	return hex.EncodeToString(sum[:])
+}
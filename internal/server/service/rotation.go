@@ -0,0 +1,202 @@
+// Package service contains the business logic of the server application.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+)
+
+// ErrRotationInProgress - the rotation of the master key has already been started.
+var ErrRotationInProgress = errors.New("master key rotation is in progress")
+
+// KeyringReloader describes the source of master keys that loads its keyring again.
+type KeyringReloader interface {
+	// Reload loads the keyring again, the first key becomes the current one.
+	Reload() error
+}
+
+// DataKeyRewrapper describes the storage that re-wraps data keys with the current master key.
+type DataKeyRewrapper interface {
+	// RewrapDataKeys re-wraps up to limit data keys wrapped with old master keys.
+	RewrapDataKeys(ctx context.Context, limit int) (atrest.RewrapResult, error)
+}
+
+// KeyRotationConfig describes the settings of the master key rotation.
+type KeyRotationConfig struct {
+	BatchSize int           // Maximum number of data keys re-wrapped at once.
+	Pause     time.Duration // Pause between batches to limit the load on the storage.
+}
+
+// KeyRotationStatus describes the state of the last rotation of the master key.
+type KeyRotationStatus struct {
+	Running    bool                // The rotation is in progress.
+	StartedAt  time.Time           // Start time, zero if no rotation has been started.
+	FinishedAt time.Time           // Completion time, zero while the rotation is in progress.
+	Result     atrest.RewrapResult // Totals of the data keys processed so far.
+	Err        error               // Error the rotation has stopped with.
+}
+
+// KeyRotationService re-wraps the data keys under the current master key.
+//
+// The rotation runs online on the storages the instance serves requests from: while it is
+// in progress, the data wrapped with both the old and the new master keys stays readable,
+// and new data is written with the new master key.
+type KeyRotationService struct {
+	storages []DataKeyRewrapper
+	keys     KeyringReloader
+	conf     KeyRotationConfig
+	logger   logging.Logger
+
+	mu     sync.Mutex
+	status KeyRotationStatus
+}
+
+// NewKeyRotationService creates a new *KeyRotationService instance.
+//
+// Parameters:
+//   - conf KeyRotationConfig: rotation settings;
+//   - keys KeyringReloader: source of master keys reloaded before the rotation,
+//     nil if the keyring cannot change while the instance is running;
+//   - logger logging.Logger: logger;
+//   - storages ...DataKeyRewrapper: storages with data keys.
+func NewKeyRotationService(
+	conf KeyRotationConfig,
+	keys KeyringReloader,
+	logger logging.Logger,
+	storages ...DataKeyRewrapper,
+) *KeyRotationService {
+	return &KeyRotationService{
+		storages: storages,
+		keys:     keys,
+		conf:     conf,
+		logger:   logger,
+		mu:       sync.Mutex{},
+		status: KeyRotationStatus{
+			Running:    false,
+			StartedAt:  time.Time{},
+			FinishedAt: time.Time{},
+			Result:     atrest.RewrapResult{Found: 0, Rewrapped: 0, Skipped: 0},
+			Err:        nil,
+		},
+	}
+}
+
+// Start reloads the keyring and starts re-wrapping the data keys in the background.
+//
+// To rotate the master key, put the new key first in the keyring, start the rotation and,
+// once it has completed, remove the old key from the keyring.
+//
+// Parameters:
+//   - ctx context.Context: context of the rotation, cancellation stops it between batches.
+func (s *KeyRotationService) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.status.Running {
+		return ErrRotationInProgress
+	}
+
+	if s.keys != nil {
+		err := s.keys.Reload()
+		if err != nil {
+			return fmt.Errorf("reload keyring: %w", err)
+		}
+	}
+
+	s.status = KeyRotationStatus{
+		Running:    true,
+		StartedAt:  time.Now().UTC(),
+		FinishedAt: time.Time{},
+		Result:     atrest.RewrapResult{Found: 0, Rewrapped: 0, Skipped: 0},
+		Err:        nil,
+	}
+
+	go func() {
+		result, err := s.Run(ctx)
+		if err != nil {
+			s.logger.Error("Master key rotation error", err)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.status.Running = false
+		s.status.FinishedAt = time.Now().UTC()
+		s.status.Result = result
+		s.status.Err = err
+	}()
+
+	return nil
+}
+
+// Status returns the state of the last rotation started by Start.
+func (s *KeyRotationService) Status() KeyRotationStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.status
+}
+
+// Run re-wraps all data keys of the storages wrapped with old master keys and returns the totals.
+//
+// Parameters:
+//   - ctx context.Context: context, cancellation stops the rotation between batches.
+func (s *KeyRotationService) Run(ctx context.Context) (atrest.RewrapResult, error) {
+	total := atrest.RewrapResult{Found: 0, Rewrapped: 0, Skipped: 0}
+
+	for _, storage := range s.storages {
+		err := s.rotate(ctx, storage, &total)
+		if err != nil {
+			return total, err
+		}
+	}
+
+	s.logger.Info("Master key rotation completed",
+		"rewrapped", total.Rewrapped,
+		"skipped", total.Skipped,
+	)
+
+	return total, nil
+}
+
+// rotate re-wraps the data keys of the storage batch by batch.
+func (s *KeyRotationService) rotate(
+	ctx context.Context,
+	storage DataKeyRewrapper,
+	total *atrest.RewrapResult,
+) error {
+	for {
+		result, err := storage.RewrapDataKeys(ctx, s.conf.BatchSize)
+		if err != nil {
+			return fmt.Errorf("rewrap data keys: %w", err)
+		}
+
+		total.Found += result.Found
+		total.Rewrapped += result.Rewrapped
+		total.Skipped += result.Skipped
+
+		s.logger.Debug("Data keys batch rewrapped",
+			"found", result.Found,
+			"rewrapped", result.Rewrapped,
+			"skipped", result.Skipped,
+		)
+
+		// The written data keys are wrapped with the current master key,
+		// so a short batch means that no stale keys are left.
+		if result.Found < s.conf.BatchSize {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("rotation interrupted: %w", ctx.Err())
+		case <-time.After(s.conf.Pause):
+		}
+	}
+}
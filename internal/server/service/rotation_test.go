@@ -0,0 +1,131 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/encrypted"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/memory"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMasterKey creates a random master key with the identifier.
+func newMasterKey(t *testing.T, keyID string) *atrest.MasterKey {
+	t.Helper()
+
+	key, err := crypto.GenerateVaultKey()
+	require.NoError(t, err)
+
+	return &atrest.MasterKey{ID: keyID, Key: key}
+}
+
+// newFolder creates a top-level folder of alice.
+func newFolder(folderID string, name string) *model.Folder {
+	now := time.Now().UTC()
+
+	return &model.Folder{
+		ID:        folderID,
+		UserID:    "alice",
+		ParentID:  "",
+		Name:      []byte(name),
+		CreatedAt: now,
+		UpdatedAt: now,
+		NameKey:   atrest.WrappedKey{MasterKeyID: "", Key: nil},
+	}
+}
+
+func TestKeyRotationService_RotatesToNewKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	oldKey, newKey := newMasterKey(t, "2026-09"), newMasterKey(t, "2026-10")
+
+	// The keyring stands for the file the administrator edits between the reloads.
+	keyring := []*atrest.MasterKey{oldKey}
+	keys, err := atrest.NewReloadableKeyProvider(func() ([]*atrest.MasterKey, error) {
+		return keyring, nil
+	})
+	require.NoError(t, err)
+
+	secrets := encrypted.NewSecretRepository(
+		memory.NewSecretRepository(),
+		atrest.NewEncrypter(keys),
+	)
+	folders := encrypted.NewFolderRepository(
+		memory.NewFolderRepository(),
+		atrest.NewEncrypter(keys),
+	)
+
+	item := newBinarySecret("s1", "")
+	item.Data = []byte("client ciphertext")
+	require.NoError(t, secrets.Create(ctx, item))
+
+	require.NoError(t, folders.CreateFolder(ctx, newFolder("f1", "folder ciphertext")))
+
+	rotation := service.NewKeyRotationService(
+		service.KeyRotationConfig{BatchSize: 1, Pause: time.Millisecond},
+		keys,
+		newTestLogger(t),
+		secrets,
+		folders,
+	)
+
+	keyring = []*atrest.MasterKey{newKey, oldKey}
+
+	require.NoError(t, rotation.Start(ctx))
+	require.Eventually(t, func() bool {
+		return !rotation.Status().Running
+	}, 5*time.Second, 10*time.Millisecond)
+
+	status := rotation.Status()
+	require.NoError(t, status.Err)
+	assert.Equal(t, 2, status.Result.Rewrapped)
+
+	// Once the rotation has completed, the old key is removed from the keyring.
+	keyring = []*atrest.MasterKey{newKey}
+
+	require.NoError(t, keys.Reload())
+
+	fetched, err := secrets.Get(ctx, "alice", "s1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("client ciphertext"), fetched.Data)
+
+	fetchedFolder, err := folders.GetFolder(ctx, "alice", "f1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("folder ciphertext"), fetchedFolder.Name)
+}
+
+func TestKeyRotationService_RejectsConcurrentStart(t *testing.T) {
+	t.Parallel()
+
+	rotation := service.NewKeyRotationService(
+		service.KeyRotationConfig{BatchSize: 1, Pause: time.Millisecond},
+		nil,
+		newTestLogger(t),
+		blockingRewrapper{},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, rotation.Start(ctx))
+	require.ErrorIs(t, rotation.Start(ctx), service.ErrRotationInProgress)
+}
+
+// blockingRewrapper - storage whose rotation lasts until the context is canceled.
+type blockingRewrapper struct{}
+
+func (blockingRewrapper) RewrapDataKeys(ctx context.Context, _ int) (atrest.RewrapResult, error) {
+	<-ctx.Done()
+
+	err := fmt.Errorf("rewrap: %w", ctx.Err())
+
+	return atrest.RewrapResult{Found: 0, Rewrapped: 0, Skipped: 0}, err
+}
@@ -0,0 +1,845 @@
+// Package service contains the business logic of the server application.
+package service
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/crypto"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/secret"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// Errors returned by the secret service.
+var (
+	// ErrSecretNotFound - the user has no such secret.
+	ErrSecretNotFound = errors.New("secret not found")
+
+	// ErrInvalidSecret - the secret does not meet the requirements.
+	ErrInvalidSecret = errors.New("invalid secret")
+
+	// ErrSecretVersionNotFound - the revision is not in the history of the secret.
+	ErrSecretVersionNotFound = errors.New("secret version not found")
+
+	// ErrSecretModified - the secret has been changed since the revision the operation is based on.
+	ErrSecretModified = errors.New("secret modified")
+
+	// ErrSecretHasNoBlob - the payload of the secret is kept inline, not in the blob storage.
+	ErrSecretHasNoBlob = errors.New("secret has no blob")
+
+	// ErrInvalidSecretQuery - the filter or the sort of the listing is not supported.
+	ErrInvalidSecretQuery = errors.New("invalid secret query")
+)
+
+// Restrictions on secret metadata.
+const (
+	maxMetadataEntries     = 32
+	maxMetadataKeyLength   = 64
+	maxMetadataValueLength = 1024
+	maxTags                = 32
+	maxTagLength           = 64
+	maxIndexes             = 64
+)
+
+// Restrictions on the size of a page of secrets.
+const (
+	DefaultSecretPageLimit = 100
+	MaxSecretPageLimit     = 500
+)
+
+// SecretInput describes the data for creating or updating a secret.
+type SecretInput struct {
+	// FolderID - folder to create the secret in, empty for the top level.
+	//
+	// Updates keep the folder of the secret, secrets are moved between folders with Move.
+	FolderID string
+
+	Type     secret.Type       // Kind of secret.
+	Metadata map[string]string // Free-form metadata.
+	Tags     []string          // Labels the secrets are grouped by.
+	Data     []byte            // Payload encrypted on the client.
+
+	// Indexes - blind indexes of the fields of the secret computed on the client.
+	Indexes []crypto.BlindIndex
+
+	// UploadID - completed upload to take the payload from instead of Data, only for binary secrets.
+	UploadID string
+}
+
+// SecretPage describes a page of the listing of the user's secrets.
+type SecretPage struct {
+	Items []*model.Secret     // Secrets in the order of the query.
+	Next  *model.SecretCursor // Position the next page starts after, nil on the last page.
+}
+
+// RevisionMatch describes the revisions of a secret a change is allowed on.
+//
+// A nil value allows any revision. The change is applied only if the secret
+// has not been changed concurrently since the matched revision was read.
+type RevisionMatch []int64
+
+// Allows checks whether the change is allowed on the revision.
+//
+// Parameters:
+//   - revision int64: current revision of the secret.
+func (m RevisionMatch) Allows(revision int64) bool {
+	return m == nil || slices.Contains(m, revision)
+}
+
+// EventPublisher describes the delivery of the change events of the user's vault to the connected clients.
+type EventPublisher interface {
+	// Publish delivers the event to all current subscribers of its user.
+	Publish(ctx context.Context, event model.VaultEvent) error
+}
+
+// BlobKeeper describes the storage of the payloads of binary secrets.
+type BlobKeeper interface {
+	// SaveBlob saves the payload as a blob shared with the equal payloads
+	// and returns the identifier of the blob, the size of the payload and the wrapped data key.
+	SaveBlob(ctx context.Context, open PayloadOpener) (*SavedBlob, error)
+
+	// ShareBlob makes sure the saved blob stays in the storage while a new revision
+	// referring to it is saved.
+	ShareBlob(ctx context.Context, blobID string) error
+
+	// OpenBlob returns the reader of the payload kept in the blob encrypted with the key,
+	// the key is empty if the blob is stored without encryption at rest.
+	OpenBlob(ctx context.Context, blobID string, key atrest.WrappedKey) (io.ReadCloser, error)
+}
+
+// UploadSource describes the access to the completed uploads used as payloads of binary secrets.
+type UploadSource interface {
+	// Open returns the reader of the payload of the completed upload and its size.
+	Open(ctx context.Context, userID string, uploadID string) (io.ReadCloser, int64, error)
+
+	// Terminate deletes the upload.
+	Terminate(ctx context.Context, userID string, uploadID string) error
+}
+
+// SecretRetention describes how long the previous revisions of secrets are kept.
+type SecretRetention struct {
+	Limit  int           // Maximum number of previous revisions of a secret, zero disables the history.
+	MaxAge time.Duration // Time a replaced revision is kept, zero means no limit.
+}
+
+// SecretService implements the management of the user's secrets.
+//
+// Payloads of binary secrets are kept in the blob storage by the hash of their ciphertext,
+// the secrets refer to them. Equal payloads, such as a restored revision or a copied file,
+// are saved once and shared.
+type SecretService struct {
+	secrets   repository.SecretRepository
+	folders   repository.FolderRepository
+	retention SecretRetention
+	blobs     BlobKeeper
+	uploads   UploadSource
+	events    EventPublisher
+	logger    logging.Logger
+}
+
+// NewSecretService creates a new *SecretService instance.
+//
+// Parameters:
+//   - secrets repository.SecretRepository: secret storage;
+//   - folders repository.FolderRepository: storage of the folders the secrets are placed in;
+//   - retention SecretRetention: retention of the previous revisions;
+//   - blobs BlobKeeper: storage of the payloads of binary secrets;
+//   - uploads UploadSource: completed uploads of large payloads;
+//   - events EventPublisher: delivery of the change events;
+//   - logger logging.Logger: logger.
+func NewSecretService(
+	secrets repository.SecretRepository,
+	folders repository.FolderRepository,
+	retention SecretRetention,
+	blobs BlobKeeper,
+	uploads UploadSource,
+	events EventPublisher,
+	logger logging.Logger,
+) *SecretService {
+	return &SecretService{
+		secrets:   secrets,
+		folders:   folders,
+		retention: retention,
+		blobs:     blobs,
+		uploads:   uploads,
+		events:    events,
+		logger:    logger,
+	}
+}
+
+// Create creates a new secret for the user.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - input SecretInput: secret data.
+func (s *SecretService) Create(
+	ctx context.Context,
+	userID string,
+	input SecretInput,
+) (*model.Secret, error) {
+	err := validateSecretInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkFolder(ctx, s.folders, userID, input.FolderID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	item := &model.Secret{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		FolderID:  input.FolderID,
+		Type:      input.Type,
+		Metadata:  input.Metadata,
+		Tags:      normalizeTags(input.Tags),
+		Data:      input.Data,
+		Revision:  1,
+		CreatedAt: now,
+		UpdatedAt: now,
+		DataKey:   atrest.WrappedKey{MasterKeyID: "", Key: nil},
+		BlobID:    "",
+		BlobSize:  0,
+		BlobKey:   atrest.WrappedKey{MasterKeyID: "", Key: nil},
+		Indexes:   normalizeIndexes(input.Indexes),
+	}
+
+	err = s.storeBlob(ctx, item, input.UploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.secrets.Create(ctx, item)
+	if err != nil {
+		return nil, fmt.Errorf("create secret: %w", err)
+	}
+
+	s.logger.Debug("Secret created", "user_id", userID, "secret_id", item.ID)
+	s.publish(ctx, model.VaultEventSecretCreated, item)
+	s.releaseUpload(ctx, userID, input.UploadID)
+
+	return item, nil
+}
+
+// Get returns the user's secret.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - secretID string: secret identifier.
+func (s *SecretService) Get(
+	ctx context.Context,
+	userID string,
+	secretID string,
+) (*model.Secret, error) {
+	item, err := s.secrets.Get(ctx, userID, secretID)
+	if err != nil {
+		return nil, mapSecretError("get secret", err)
+	}
+
+	return item, nil
+}
+
+// Update replaces the content of the user's secret, the previous revision is kept in the history.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - secretID string: secret identifier;
+//   - input SecretInput: new secret data;
+//   - match RevisionMatch: revisions the change is allowed on.
+func (s *SecretService) Update(
+	ctx context.Context,
+	userID string,
+	secretID string,
+	input SecretInput,
+	match RevisionMatch,
+) (*model.Secret, error) {
+	err := validateSecretInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.getMatching(ctx, userID, secretID, match)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Type = input.Type
+	item.Metadata = input.Metadata
+	item.Tags = normalizeTags(input.Tags)
+	item.Data = input.Data
+	item.Indexes = normalizeIndexes(input.Indexes)
+
+	err = s.storeBlob(ctx, item, input.UploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.replace(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug("Secret updated", "user_id", userID, "secret_id", secretID)
+	s.releaseUpload(ctx, userID, input.UploadID)
+
+	return item, nil
+}
+
+// Move places the user's secret into the folder, the move is saved as a new revision
+// so the other devices of the user get it with sync.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - secretID string: secret identifier;
+//   - folderID string: identifier of the folder, empty for the top level;
+//   - match RevisionMatch: revisions the move is allowed on.
+func (s *SecretService) Move(
+	ctx context.Context,
+	userID string,
+	secretID string,
+	folderID string,
+	match RevisionMatch,
+) (*model.Secret, error) {
+	item, err := s.getMatching(ctx, userID, secretID, match)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkFolder(ctx, s.folders, userID, folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.FolderID == folderID {
+		return item, nil
+	}
+
+	// The payload is not changed, so the blob stays shared with the previous revision.
+	item.FolderID = folderID
+
+	err = s.replace(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug("Secret moved", "user_id", userID, "secret_id", secretID, "folder_id", folderID)
+
+	return item, nil
+}
+
+// Delete moves the user's secret to the trash, it can be restored until it is purged.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - secretID string: secret identifier;
+//   - match RevisionMatch: revisions the deletion is allowed on.
+func (s *SecretService) Delete(
+	ctx context.Context,
+	userID string,
+	secretID string,
+	match RevisionMatch,
+) error {
+	item, err := s.getMatching(ctx, userID, secretID, match)
+	if err != nil {
+		return err
+	}
+
+	err = s.secrets.Delete(ctx, userID, secretID, item.Revision)
+	if err != nil {
+		return mapSecretError("delete secret", err)
+	}
+
+	s.logger.Debug("Secret moved to trash", "user_id", userID, "secret_id", secretID)
+	s.publish(ctx, model.VaultEventSecretDeleted, item)
+
+	return nil
+}
+
+// List returns all the user's secrets.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner.
+func (s *SecretService) List(ctx context.Context, userID string) ([]*model.Secret, error) {
+	items, err := s.secrets.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list secrets: %w", err)
+	}
+
+	return items, nil
+}
+
+// ListPage returns a page of the user's secrets that match the filter,
+// sorted by the creation time if the query has no sort.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - query model.SecretQuery: filter, sort and position of the page,
+//     the limit is DefaultSecretPageLimit if not positive and at most MaxSecretPageLimit.
+func (s *SecretService) ListPage(
+	ctx context.Context,
+	userID string,
+	query model.SecretQuery,
+) (*SecretPage, error) {
+	if query.Sort == "" {
+		query.Sort = model.SecretSortCreatedAt
+	}
+
+	if !query.Sort.IsValid() {
+		return nil, fmt.Errorf("%w: unknown sort %q", ErrInvalidSecretQuery, query.Sort)
+	}
+
+	if query.Filter.Type != "" && !query.Filter.Type.IsValid() {
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidSecretQuery, query.Filter.Type)
+	}
+
+	err := validateIndexes(query.Filter.Indexes, ErrInvalidSecretQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = DefaultSecretPageLimit
+	}
+
+	limit := min(query.Limit, MaxSecretPageLimit)
+
+	// One more secret tells whether the page is the last one.
+	query.Limit = limit + 1
+
+	items, err := s.secrets.ListPage(ctx, userID, &query)
+	if err != nil {
+		return nil, fmt.Errorf("list secret page: %w", err)
+	}
+
+	page := &SecretPage{Items: items, Next: nil}
+
+	if len(items) > limit {
+		page.Items = items[:limit]
+		next := items[limit-1].SortKey(query.Sort)
+		page.Next = &next
+	}
+
+	return page, nil
+}
+
+// ListVersions returns the previous revisions of the user's secret, the newest first.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - secretID string: secret identifier.
+func (s *SecretService) ListVersions(
+	ctx context.Context,
+	userID string,
+	secretID string,
+) ([]*model.SecretVersion, error) {
+	// Revisions outdated since the last update are removed before reading.
+	err := s.pruneVersions(ctx, userID, secretID)
+	if err != nil {
+		return nil, mapSecretError("prune secret versions", err)
+	}
+
+	versions, err := s.secrets.ListVersions(ctx, userID, secretID)
+	if err != nil {
+		return nil, mapSecretError("list secret versions", err)
+	}
+
+	return versions, nil
+}
+
+// Restore makes the content of the previous revision current again.
+//
+// The restored content becomes a new revision, so the replaced one stays in the history.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - secretID string: secret identifier;
+//   - revision int64: number of the revision in the history;
+//   - match RevisionMatch: current revisions the restore is allowed on.
+func (s *SecretService) Restore(
+	ctx context.Context,
+	userID string,
+	secretID string,
+	revision int64,
+	match RevisionMatch,
+) (*model.Secret, error) {
+	item, err := s.getMatching(ctx, userID, secretID, match)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.secrets.GetVersion(ctx, userID, secretID, revision)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrSecretVersionNotFound
+		}
+
+		return nil, fmt.Errorf("get secret version: %w", err)
+	}
+
+	// The blob is shared with the revision in the history, blobs are never changed.
+	if version.HasBlob() {
+		err = s.blobs.ShareBlob(ctx, version.BlobID)
+		if err != nil {
+			return nil, fmt.Errorf("share blob: %w", err)
+		}
+	}
+
+	item.Type = version.Type
+	item.Metadata = version.Metadata
+	item.Tags = version.Tags
+	item.Data = version.Data
+	item.BlobID = version.BlobID
+	item.BlobSize = version.BlobSize
+	item.BlobKey = version.BlobKey
+	item.Indexes = version.Indexes
+
+	err = s.replace(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Secret restored", "user_id", userID, "secret_id", secretID,
+		"from_revision", revision, "revision", item.Revision)
+
+	return item, nil
+}
+
+// OpenBlob returns the user's secret with the reader of its payload kept in the blob storage.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - secretID string: secret identifier.
+func (s *SecretService) OpenBlob(
+	ctx context.Context,
+	userID string,
+	secretID string,
+) (*model.Secret, io.ReadCloser, error) {
+	item, err := s.Get(ctx, userID, secretID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !item.HasBlob() {
+		return nil, nil, ErrSecretHasNoBlob
+	}
+
+	content, err := s.blobs.OpenBlob(ctx, item.BlobID, item.BlobKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get blob %s of secret %s: %w", item.BlobID, secretID, err)
+	}
+
+	return item, content, nil
+}
+
+// getMatching returns the user's secret if the change is allowed on its current revision.
+func (s *SecretService) getMatching(
+	ctx context.Context,
+	userID string,
+	secretID string,
+	match RevisionMatch,
+) (*model.Secret, error) {
+	item, err := s.secrets.Get(ctx, userID, secretID)
+	if err != nil {
+		return nil, mapSecretError("get secret", err)
+	}
+
+	if !match.Allows(item.Revision) {
+		return nil, ErrSecretModified
+	}
+
+	return item, nil
+}
+
+// replace saves the new content of the secret and applies the retention to its history.
+func (s *SecretService) replace(ctx context.Context, item *model.Secret) error {
+	item.UpdatedAt = time.Now().UTC()
+
+	err := s.secrets.Update(ctx, item)
+	if err != nil {
+		return mapSecretError("update secret", err)
+	}
+
+	// The update is already saved, so the history is pruned again on the next access.
+	err = s.pruneVersions(ctx, item.UserID, item.ID)
+	if err != nil {
+		s.logger.Warn("Pruning secret versions error", err,
+			"user_id", item.UserID, "secret_id", item.ID)
+	}
+
+	s.publish(ctx, model.VaultEventSecretUpdated, item)
+
+	return nil
+}
+
+// storeBlob saves the payload of a binary secret to the blob storage and leaves only
+// the reference to it in the secret. Payloads of other kinds stay inline.
+//
+// The payload is taken from the completed upload if uploadID is set, otherwise from the data.
+func (s *SecretService) storeBlob(ctx context.Context, item *model.Secret, uploadID string) error {
+	item.BlobID, item.BlobSize = "", 0
+	item.BlobKey = atrest.WrappedKey{MasterKeyID: "", Key: nil}
+
+	if item.Type != secret.TypeBinary {
+		return nil
+	}
+
+	blob, err := s.blobs.SaveBlob(ctx, s.payloadOpener(item.UserID, item.Data, uploadID))
+	if err != nil {
+		return fmt.Errorf("save blob: %w", err)
+	}
+
+	item.Data, item.BlobID, item.BlobSize, item.BlobKey = nil, blob.ID, blob.Size, blob.Key
+
+	return nil
+}
+
+// payloadOpener returns the opener of the payload kept in the completed upload if uploadID is set,
+// otherwise of the data.
+func (s *SecretService) payloadOpener(userID string, data []byte, uploadID string) PayloadOpener {
+	if uploadID == "" {
+		return func(context.Context) (io.ReadCloser, int64, error) {
+			return io.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+		}
+	}
+
+	return func(ctx context.Context) (io.ReadCloser, int64, error) {
+		content, size, err := s.uploads.Open(ctx, userID, uploadID)
+		if err != nil {
+			if errors.Is(err, ErrUploadNotFound) || errors.Is(err, ErrUploadIncomplete) {
+				return nil, 0, fmt.Errorf("%w: %w", ErrInvalidSecret, err)
+			}
+
+			return nil, 0, fmt.Errorf("open upload: %w", err)
+		}
+
+		return content, size, nil
+	}
+}
+
+// releaseUpload deletes the upload whose payload has been saved to the secret.
+//
+// The secret is already saved, so a failure is only logged: the upload expires anyway.
+func (s *SecretService) releaseUpload(ctx context.Context, userID string, uploadID string) {
+	if uploadID == "" {
+		return
+	}
+
+	err := s.uploads.Terminate(ctx, userID, uploadID)
+	if err != nil {
+		s.logger.Warn("Deleting used upload error", err, "user_id", userID, "upload_id", uploadID)
+	}
+}
+
+// publish notifies the connected clients of the owner about the change of the secret.
+//
+// The change is already saved, so a failed delivery is only logged: clients catch up with sync.
+func (s *SecretService) publish(ctx context.Context, eventType string, item *model.Secret) {
+	err := s.events.Publish(ctx, model.VaultEvent{
+		Type:       eventType,
+		UserID:     item.UserID,
+		SecretID:   item.ID,
+		Revision:   item.Revision,
+		OccurredAt: time.Now().UTC(),
+	})
+	if err != nil {
+		s.logger.Warn("Publishing vault event error", err,
+			"user_id", item.UserID, "secret_id", item.ID, "type", eventType)
+	}
+}
+
+// pruneVersions deletes the revisions not covered by the retention from the history of the secret.
+func (s *SecretService) pruneVersions(ctx context.Context, userID string, secretID string) error {
+	archivedBefore := time.Time{}
+	if s.retention.MaxAge > 0 {
+		archivedBefore = time.Now().UTC().Add(-s.retention.MaxAge)
+	}
+
+	deleted, err := s.secrets.PruneVersions(
+		ctx,
+		userID,
+		secretID,
+		s.retention.Limit,
+		archivedBefore,
+	)
+	if err != nil {
+		return fmt.Errorf("prune secret versions: %w", err)
+	}
+
+	if deleted > 0 {
+		s.logger.Debug("Secret versions pruned",
+			"user_id", userID, "secret_id", secretID, "deleted", deleted)
+	}
+
+	return nil
+}
+
+func mapSecretError(operation string, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrSecretNotFound
+	}
+
+	if errors.Is(err, repository.ErrConflict) {
+		return ErrSecretModified
+	}
+
+	return fmt.Errorf("%s: %w", operation, err)
+}
+
+func validateSecretInput(input SecretInput) error {
+	if !input.Type.IsValid() {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidSecret, input.Type)
+	}
+
+	err := validateSecretMetadata(input.Type, input.Metadata)
+	if err != nil {
+		return err
+	}
+
+	err = validateTags(input.Tags)
+	if err != nil {
+		return err
+	}
+
+	err = validateIndexes(input.Indexes, ErrInvalidSecret)
+	if err != nil {
+		return err
+	}
+
+	// The payload of an upload is checked when it is streamed to the blob storage.
+	if input.UploadID != "" {
+		if input.Type != secret.TypeBinary || len(input.Data) != 0 {
+			return fmt.Errorf(
+				"%w: upload is allowed only as the payload of a binary secret without data",
+				ErrInvalidSecret,
+			)
+		}
+
+		return nil
+	}
+
+	// The payload is encrypted on the client, so only the envelope structure can be checked.
+	_, err = crypto.ParseEnvelope(input.Data)
+	if err != nil {
+		return fmt.Errorf("%w: data: %w", ErrInvalidSecret, err)
+	}
+
+	return nil
+}
+
+// validateSecretMetadata checks the metadata of the secret of the kind against the restrictions.
+func validateSecretMetadata(secretType secret.Type, metadata map[string]string) error {
+	err := validateMetadata(metadata, ErrInvalidSecret)
+	if err != nil {
+		return err
+	}
+
+	// The metadata is stored without encryption, so the card may be shown there only masked,
+	// and the listings show every card by its masked entries.
+	if secretType == secret.TypeCard {
+		err = secret.ValidateCardMetadata(metadata)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidSecret, err)
+		}
+	}
+
+	return nil
+}
+
+// validateTags checks the tags against the restrictions.
+func validateTags(tags []string) error {
+	if len(tags) > maxTags {
+		return fmt.Errorf("%w: at most %d tags are allowed", ErrInvalidSecret, maxTags)
+	}
+
+	for _, tag := range tags {
+		if tag == "" || len(tag) > maxTagLength {
+			return fmt.Errorf("%w: tag must be from 1 to %d bytes", ErrInvalidSecret, maxTagLength)
+		}
+	}
+
+	return nil
+}
+
+// normalizeTags returns the sorted tags without duplicates.
+func normalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	normalized := slices.Clone(tags)
+	slices.Sort(normalized)
+
+	return slices.Compact(normalized)
+}
+
+// validateIndexes checks the blind indexes against the restrictions,
+// the violation is reported with the invalid error.
+func validateIndexes(indexes []crypto.BlindIndex, invalid error) error {
+	if len(indexes) > maxIndexes {
+		return fmt.Errorf("%w: at most %d indexes are allowed", invalid, maxIndexes)
+	}
+
+	for _, index := range indexes {
+		err := index.Validate()
+		if err != nil {
+			return fmt.Errorf("%w: %w", invalid, err)
+		}
+	}
+
+	return nil
+}
+
+// normalizeIndexes returns the blind indexes sorted by field and token without duplicates.
+func normalizeIndexes(indexes []crypto.BlindIndex) []crypto.BlindIndex {
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	normalized := slices.Clone(indexes)
+	slices.SortFunc(normalized, func(a, b crypto.BlindIndex) int {
+		return cmp.Or(cmp.Compare(a.Field, b.Field), cmp.Compare(a.Token, b.Token))
+	})
+
+	return slices.Compact(normalized)
+}
+
+// validateMetadata checks the free-form metadata against the restrictions,
+// the violation is reported with the invalid error.
+func validateMetadata(metadata map[string]string, invalid error) error {
+	if len(metadata) > maxMetadataEntries {
+		return fmt.Errorf("%w: metadata must contain at most %d entries",
+			invalid, maxMetadataEntries)
+	}
+
+	for key, value := range metadata {
+		if key == "" || len(key) > maxMetadataKeyLength || len(value) > maxMetadataValueLength {
+			return fmt.Errorf("%w: metadata key must be from 1 to %d bytes, value at most %d bytes",
+				invalid, maxMetadataKeyLength, maxMetadataValueLength)
+		}
+	}
+
+	return nil
+}
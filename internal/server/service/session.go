@@ -0,0 +1,154 @@
+// Package service contains the business logic of the server application.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// ErrSessionNotFound - the user has no such active session.
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionService implements the management of the user's sessions (logged-in devices).
+type SessionService struct {
+	sessions repository.SessionStore
+	logger   logging.Logger
+}
+
+// NewSessionService creates a new *SessionService instance.
+//
+// Parameters:
+//   - sessions repository.SessionStore: storage of sessions, shared with the AuthService;
+//   - logger logging.Logger: logger.
+func NewSessionService(sessions repository.SessionStore, logger logging.Logger) *SessionService {
+	return &SessionService{
+		sessions: sessions,
+		logger:   logger,
+	}
+}
+
+// List returns all active sessions of the user.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner.
+func (s *SessionService) List(ctx context.Context, userID string) ([]*model.Session, error) {
+	sessions, err := s.sessions.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// ListPage returns a page of the active sessions of the user sorted by creation time.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - query model.PageQuery: position and size of the page.
+func (s *SessionService) ListPage(
+	ctx context.Context,
+	userID string,
+	query model.PageQuery,
+) (*Page[*model.Session], error) {
+	sessions, err := s.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return paginate(sessions, query, func(session *model.Session) model.PageCursor {
+		return model.PageCursor{Time: session.CreatedAt, ID: session.ID}
+	}, false), nil
+}
+
+// Revoke revokes the user's session.
+//
+// Access and refresh tokens of the session stop working immediately.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - sessionID string: session identifier.
+func (s *SessionService) Revoke(ctx context.Context, userID string, sessionID string) error {
+	err := s.sessions.DeleteSession(ctx, userID, sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrSessionNotFound
+		}
+
+		return fmt.Errorf("delete session: %w", err)
+	}
+
+	s.logger.Info("Session revoked", "user_id", userID, "session_id", sessionID)
+
+	return nil
+}
+
+// RevokeOthers revokes all the user's sessions except the current one.
+//
+// Returns the number of revoked sessions.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - currentSessionID string: identifier of the session to keep.
+func (s *SessionService) RevokeOthers(
+	ctx context.Context,
+	userID string,
+	currentSessionID string,
+) (int, error) {
+	sessions, err := s.List(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+
+	for _, session := range sessions {
+		if session.ID == currentSessionID {
+			continue
+		}
+
+		err = s.Revoke(ctx, userID, session.ID)
+		if err != nil {
+			if errors.Is(err, ErrSessionNotFound) {
+				continue
+			}
+
+			return revoked, err
+		}
+
+		revoked++
+	}
+
+	return revoked, nil
+}
+
+// IsActive checks whether the user's session has not been revoked or expired.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - sessionID string: session identifier.
+func (s *SessionService) IsActive(
+	ctx context.Context,
+	userID string,
+	sessionID string,
+) (bool, error) {
+	session, err := s.sessions.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("get session: %w", err)
+	}
+
+	return session.UserID == userID, nil
+}
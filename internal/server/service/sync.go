@@ -0,0 +1,133 @@
+// Package service contains the business logic of the server application.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// Restrictions on the size of a sync page.
+const (
+	DefaultSyncLimit = 100
+	MaxSyncLimit     = 500
+)
+
+// ErrInvalidSyncCursor - the sync cursor does not point to a position of the change log.
+var ErrInvalidSyncCursor = errors.New("invalid sync cursor")
+
+// SyncChange describes the change of a secret returned to the client.
+type SyncChange struct {
+	model.SecretChange
+
+	Secret *model.Secret // Current state of the secret, nil for tombstones.
+}
+
+// SyncResult describes a page of the changes of the user's vault.
+type SyncResult struct {
+	Changes []SyncChange // Changes in the order of the change log.
+	Cursor  int64        // Position of the change log to continue from.
+	HasMore bool         // The change log has more changes after the cursor.
+}
+
+// SyncService implements the incremental synchronization of the user's vault.
+type SyncService struct {
+	secrets repository.SecretRepository
+	logger  logging.Logger
+}
+
+// NewSyncService creates a new *SyncService instance.
+//
+// Parameters:
+//   - secrets repository.SecretRepository: secret storage with the change log;
+//   - logger logging.Logger: logger.
+func NewSyncService(secrets repository.SecretRepository, logger logging.Logger) *SyncService {
+	return &SyncService{
+		secrets: secrets,
+		logger:  logger,
+	}
+}
+
+// Changes returns the secrets created, updated or deleted after the cursor.
+//
+// A zero cursor returns the whole vault. The client applies the changes in order
+// and continues from the returned cursor until HasMore is false.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - cursor int64: position of the change log received from the previous call;
+//   - limit int: maximum number of changes, DefaultSyncLimit if not positive.
+func (s *SyncService) Changes(
+	ctx context.Context,
+	userID string,
+	cursor int64,
+	limit int,
+) (*SyncResult, error) {
+	if cursor < 0 {
+		return nil, ErrInvalidSyncCursor
+	}
+
+	if limit <= 0 {
+		limit = DefaultSyncLimit
+	}
+
+	limit = min(limit, MaxSyncLimit)
+
+	changes, err := s.secrets.ListChanges(ctx, userID, cursor, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("list secret changes: %w", err)
+	}
+
+	result := &SyncResult{
+		Changes: make([]SyncChange, 0, min(len(changes), limit)),
+		Cursor:  cursor,
+		HasMore: len(changes) > limit,
+	}
+
+	for _, change := range changes[:min(len(changes), limit)] {
+		item, err := s.resolve(ctx, change)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Changes = append(result.Changes, item)
+		result.Cursor = change.Seq
+	}
+
+	return result, nil
+}
+
+// resolve attaches the current state of the secret to the change.
+//
+// The secret may have been changed after the change log was read: a newer revision is returned
+// as is and will be repeated later in the log, a deleted secret is returned as a tombstone.
+func (s *SyncService) resolve(ctx context.Context, change *model.SecretChange) (SyncChange, error) {
+	result := SyncChange{SecretChange: *change, Secret: nil}
+
+	if change.Deleted {
+		return result, nil
+	}
+
+	item, err := s.secrets.Get(ctx, change.UserID, change.SecretID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.logger.Debug("Secret deleted during sync", "secret_id", change.SecretID)
+
+			result.Deleted = true
+
+			return result, nil
+		}
+
+		return SyncChange{}, fmt.Errorf("get secret: %w", err)
+	}
+
+	result.Revision = item.Revision
+	result.Secret = item
+
+	return result, nil
+}
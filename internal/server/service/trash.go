@@ -0,0 +1,192 @@
+// Package service contains the business logic of the server application.
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+const (
+	// trashPurgeInterval - interval between the purges of the expired secrets from the trash.
+	trashPurgeInterval = time.Hour
+
+	// trashPurgeBatch - maximum number of secrets purged in one call to the storage.
+	trashPurgeBatch = 100
+)
+
+// TrashService implements the trash of deleted secrets: listing, restoration
+// and the scheduled purge of the secrets kept longer than the retention period.
+//
+// Purging a secret deletes its history and drops its references to blobs, the blobs
+// no other secret refers to are deleted by the collection of the blob service.
+// Every secret is purged atomically by the storage together with its history.
+//
+// The purge needs no lock between instances sharing the storage: a secret is deleted only
+// if it is still in the trash and has been deleted before the cutoff at the moment of the deletion.
+// Instances purging at the same time share the work, every secret is purged at most once,
+// and a secret restored in the meantime is never purged. The cutoff is computed
+// from the clock of the purging instance, so the clock skew between the instances
+// shortens or extends the retention by the same amount.
+type TrashService struct {
+	trash     repository.SecretTrash
+	retention time.Duration
+	events    EventPublisher
+	logger    logging.Logger
+}
+
+// NewTrashService creates a new *TrashService instance.
+//
+// Parameters:
+//   - trash repository.SecretTrash: storage of deleted secrets;
+//   - retention time.Duration: time a deleted secret is kept in the trash;
+//   - events EventPublisher: delivery of the change events;
+//   - logger logging.Logger: logger.
+func NewTrashService(
+	trash repository.SecretTrash,
+	retention time.Duration,
+	events EventPublisher,
+	logger logging.Logger,
+) *TrashService {
+	return &TrashService{
+		trash:     trash,
+		retention: retention,
+		events:    events,
+		logger:    logger,
+	}
+}
+
+// List returns the user's deleted secrets, the most recently deleted first.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner.
+func (s *TrashService) List(ctx context.Context, userID string) ([]*model.TrashedSecret, error) {
+	items, err := s.trash.ListTrash(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list trash: %w", err)
+	}
+
+	return items, nil
+}
+
+// ListPage returns a page of the user's deleted secrets, the most recently deleted first.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - query model.PageQuery: position and size of the page.
+func (s *TrashService) ListPage(
+	ctx context.Context,
+	userID string,
+	query model.PageQuery,
+) (*Page[*model.TrashedSecret], error) {
+	items, err := s.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return paginate(items, query, func(item *model.TrashedSecret) model.PageCursor {
+		return model.PageCursor{Time: item.DeletedAt, ID: item.ID}
+	}, true), nil
+}
+
+// Restore moves the user's deleted secret with its history back to the vault.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - secretID string: secret identifier.
+func (s *TrashService) Restore(
+	ctx context.Context,
+	userID string,
+	secretID string,
+) (*model.Secret, error) {
+	item, err := s.trash.RestoreTrash(ctx, userID, secretID)
+	if err != nil {
+		return nil, mapSecretError("restore secret from trash", err)
+	}
+
+	s.logger.Info("Secret restored from trash", "user_id", userID, "secret_id", secretID)
+
+	err = s.events.Publish(ctx, model.VaultEvent{
+		Type:       model.VaultEventSecretRestored,
+		UserID:     userID,
+		SecretID:   secretID,
+		Revision:   item.Revision,
+		OccurredAt: time.Now().UTC(),
+	})
+	if err != nil {
+		s.logger.Warn("Publishing vault event error", err, "user_id", userID,
+			"secret_id", secretID, "type", model.VaultEventSecretRestored)
+	}
+
+	return item, nil
+}
+
+// PurgeAt returns the time the secret deleted at the time is purged from the trash.
+//
+// Parameters:
+//   - deletedAt time.Time: time the secret was moved to the trash.
+func (s *TrashService) PurgeAt(deletedAt time.Time) time.Time {
+	return deletedAt.Add(s.retention)
+}
+
+// Purge permanently deletes the secrets kept in the trash longer than the retention period
+// at the time and returns their number.
+//
+// Safe to run concurrently on several instances, see TrashService.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - now time.Time: current time the retention period is counted back from.
+func (s *TrashService) Purge(ctx context.Context, now time.Time) (int, error) {
+	deletedBefore := now.UTC().Add(-s.retention)
+	total := 0
+
+	for {
+		purged, err := s.trash.PurgeTrash(ctx, deletedBefore, trashPurgeBatch)
+		if err != nil {
+			return total, fmt.Errorf("purge trash: %w", err)
+		}
+
+		total += purged
+
+		if purged < trashPurgeBatch {
+			break
+		}
+	}
+
+	if total > 0 {
+		s.logger.Info("Trash purged", "purged", total)
+	}
+
+	return total, nil
+}
+
+// StartPurging purges the expired secrets from the trash in the background until ctx is done.
+//
+// Parameters:
+//   - ctx context.Context: context of the application.
+func (s *TrashService) StartPurging(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				_, err := s.Purge(ctx, now)
+				if err != nil {
+					s.logger.Error("Purging trash error", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,105 @@
+package service_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/memory"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrashService_Purge(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := newTestLogger(t)
+	blobs, dir := newTestBlobStore(t)
+	secrets := memory.NewSecretRepository()
+	blobService := service.NewBlobService(blobs, secrets, nil, logger)
+
+	previous := putAgedBlob(t, blobs, dir, "previous")
+	current := putAgedBlob(t, blobs, dir, "current")
+	recent := putAgedBlob(t, blobs, dir, "recent")
+
+	require.NoError(t, secrets.Create(ctx, newBinarySecret("expired", previous)))
+	require.NoError(t, secrets.Update(ctx, newBinarySecret("expired", current)))
+	require.NoError(t, secrets.Delete(ctx, "alice", "expired", 2))
+
+	// The retention period ends at the cutoff: only the secret deleted before it has expired.
+	cutoff := time.Now()
+
+	require.NoError(t, secrets.Create(ctx, newBinarySecret("recent", recent)))
+	require.NoError(t, secrets.Delete(ctx, "alice", "recent", 1))
+
+	const retention = time.Hour
+
+	trashService := service.NewTrashService(secrets, retention, memory.NewEventBroker(), logger)
+	now := cutoff.Add(retention)
+
+	purged, err := trashService.Purge(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged, "only the secret kept longer than the retention must be purged")
+
+	trashed, err := trashService.List(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, trashed, 1)
+	assert.Equal(t, "recent", trashed[0].ID)
+
+	deleted, err := blobService.Collect(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted, "blobs of the purged secret and of its history must be released")
+	assertBlobs(t, blobs, map[string]bool{previous: false, current: false, recent: true})
+
+	// Another instance purging with the same cutoff finds nothing left to purge.
+	purged, err = trashService.Purge(ctx, now)
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+}
+
+func TestTrashService_PurgeConcurrently(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := newTestLogger(t)
+	secrets := memory.NewSecretRepository()
+
+	const trashed = 250
+
+	for i := range trashed {
+		secretID := "s" + strconv.Itoa(i)
+		require.NoError(t, secrets.Create(ctx, newBinarySecret(secretID, "")))
+		require.NoError(t, secrets.Delete(ctx, "alice", secretID, 1))
+	}
+
+	now := time.Now().Add(time.Hour)
+	totals := make(chan int, 2)
+
+	// Two instances sharing the storage purge the same trash at the same time.
+	var purging sync.WaitGroup
+
+	for range cap(totals) {
+		instance := service.NewTrashService(secrets, time.Minute, memory.NewEventBroker(), logger)
+
+		purging.Go(func() {
+			purged, err := instance.Purge(ctx, now)
+			assert.NoError(t, err)
+
+			totals <- purged
+		})
+	}
+
+	purging.Wait()
+	close(totals)
+
+	sum := 0
+	for purged := range totals {
+		sum += purged
+	}
+
+	assert.Equal(t, trashed, sum, "every secret must be purged exactly once")
+}
@@ -0,0 +1,403 @@
+// Package service contains the business logic of the server application.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/totp"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// Errors returned by the two-factor authentication service.
+var (
+	// ErrTwoFactorAlreadyEnabled - the user has already enabled two-factor authentication.
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication already enabled")
+
+	// ErrTwoFactorNotEnrolled - the user has not started the enrollment.
+	ErrTwoFactorNotEnrolled = errors.New("two-factor authentication not enrolled")
+
+	// ErrTwoFactorNotEnabled - the user has not enabled two-factor authentication.
+	ErrTwoFactorNotEnabled = errors.New("two-factor authentication not enabled")
+
+	// ErrInvalidTwoFactorCode - the one-time code is wrong, outdated or has already been used.
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+
+	// ErrTwoFactorLocked - too many codes of the user have been rejected, the check is locked for a while.
+	ErrTwoFactorLocked = errors.New("two-factor authentication locked")
+)
+
+// Settings of the one-time codes.
+const (
+	twoFactorIssuer = "Go Password Keeper"
+
+	// twoFactorSkew - number of neighbouring time steps accepted to tolerate clock drift.
+	twoFactorSkew = 1
+
+	// TwoFactorMaxFailures - number of rejected codes in a row after which the check is locked.
+	TwoFactorMaxFailures = 5
+
+	// TwoFactorLockout - time the check is locked for after each rejected code beyond the limit.
+	TwoFactorLockout = 15 * time.Minute
+)
+
+// TwoFactorEnrollment describes the data for adding the key to an authenticator application.
+type TwoFactorEnrollment struct {
+	Secret string // Shared secret in the base32 form for manual entry.
+	URI    string // otpauth:// URI for the QR code.
+}
+
+// TwoFactorService implements two-factor authentication with time-based one-time codes.
+type TwoFactorService struct {
+	twoFactor repository.TwoFactorRepository
+	users     repository.UserRepository
+	hasher    PasswordHasher
+	audit     AuditRecorder
+	logger    logging.Logger
+}
+
+// NewTwoFactorService creates a new *TwoFactorService instance.
+//
+// Parameters:
+//   - twoFactor repository.TwoFactorRepository: storage of enrollments;
+//   - users repository.UserRepository: user storage;
+//   - hasher PasswordHasher: password hasher;
+//   - audit AuditRecorder: audit log;
+//   - logger logging.Logger: logger.
+func NewTwoFactorService(
+	twoFactor repository.TwoFactorRepository,
+	users repository.UserRepository,
+	hasher PasswordHasher,
+	audit AuditRecorder,
+	logger logging.Logger,
+) *TwoFactorService {
+	return &TwoFactorService{
+		twoFactor: twoFactor,
+		users:     users,
+		hasher:    hasher,
+		audit:     audit,
+		logger:    logger,
+	}
+}
+
+// Enroll generates a new secret for the user.
+//
+// The second factor is not required until the enrollment is confirmed with a code.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the user;
+//   - password string: current authentication password.
+func (s *TwoFactorService) Enroll(
+	ctx context.Context,
+	userID string,
+	password string,
+) (*TwoFactorEnrollment, error) {
+	user, err := verifyUserPassword(ctx, s.users, s.hasher, userID, password)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled, err := s.IsEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	key, err := totp.NewKey(twoFactorIssuer, user.Login)
+	if err != nil {
+		return nil, fmt.Errorf("generate TOTP key: %w", err)
+	}
+
+	err = s.twoFactor.SaveTwoFactor(ctx, &model.TwoFactor{
+		UserID:       userID,
+		Secret:       key.Secret,
+		Enabled:      false,
+		CreatedAt:    time.Now().UTC(),
+		EnabledAt:    time.Time{},
+		LastUsedStep: 0,
+
+		FailedAttempts: 0,
+		LastFailedAt:   time.Time{},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("save two-factor enrollment: %w", err)
+	}
+
+	return &TwoFactorEnrollment{
+		Secret: totp.EncodeSecret(key.Secret),
+		URI:    key.URI(),
+	}, nil
+}
+
+// Confirm enables two-factor authentication after checking the code from the authenticator.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the user;
+//   - code string: one-time code;
+//   - client model.ClientInfo: client from which the enrollment is confirmed.
+func (s *TwoFactorService) Confirm(
+	ctx context.Context,
+	userID string,
+	code string,
+	client model.ClientInfo,
+) error {
+	twoFactor, err := s.twoFactor.GetTwoFactor(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrTwoFactorNotEnrolled
+		}
+
+		return fmt.Errorf("get two-factor enrollment: %w", err)
+	}
+
+	if twoFactor.Enabled {
+		return ErrTwoFactorAlreadyEnabled
+	}
+
+	step, ok := newTOTPKey(twoFactor.Secret).Verify(code, time.Now(), twoFactorSkew)
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	twoFactor.Enabled = true
+	twoFactor.EnabledAt = time.Now().UTC()
+	twoFactor.LastUsedStep = step
+
+	err = s.twoFactor.SaveTwoFactor(ctx, twoFactor)
+	if err != nil {
+		return fmt.Errorf("save two-factor enrollment: %w", err)
+	}
+
+	err = s.audit.Record(ctx, userID, model.AuditEventTwoFactorEnabled, client, nil)
+	if err != nil {
+		return fmt.Errorf("record audit event: %w", err)
+	}
+
+	return nil
+}
+
+// Disable turns two-factor authentication off.
+//
+// The caller must check the one-time code before, see CheckCode.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the user;
+//   - client model.ClientInfo: client from which two-factor authentication is disabled.
+func (s *TwoFactorService) Disable(
+	ctx context.Context,
+	userID string,
+	client model.ClientInfo,
+) error {
+	enabled, err := s.IsEnabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !enabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	err = s.twoFactor.DeleteTwoFactor(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("delete two-factor enrollment: %w", err)
+	}
+
+	err = s.audit.Record(ctx, userID, model.AuditEventTwoFactorDisabled, client, nil)
+	if err != nil {
+		return fmt.Errorf("record audit event: %w", err)
+	}
+
+	return nil
+}
+
+// Reset removes the enrollment of the user, if any.
+//
+// Used when the access is regained with a recovery code, which replaces the second factor.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the user.
+func (s *TwoFactorService) Reset(ctx context.Context, userID string) error {
+	err := s.twoFactor.DeleteTwoFactor(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("delete two-factor enrollment: %w", err)
+	}
+
+	return nil
+}
+
+// IsEnabled checks whether the user has confirmed two-factor authentication.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the user.
+func (s *TwoFactorService) IsEnabled(ctx context.Context, userID string) (bool, error) {
+	twoFactor, err := s.twoFactor.GetTwoFactor(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("get two-factor enrollment: %w", err)
+	}
+
+	return twoFactor.Enabled, nil
+}
+
+// Verify checks the one-time code of the user.
+//
+// Each code is accepted only once, codes older than the last accepted one are rejected.
+// Every rejected code is written to the audit log. After TwoFactorMaxFailures codes rejected in a row
+// the check is locked for TwoFactorLockout after each further rejected code, so the six digits
+// cannot be guessed. The counter is kept with the enrollment and is shared by all instances;
+// an accepted code resets it.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the user;
+//   - code string: one-time code;
+//   - client model.ClientInfo: client that presented the code.
+func (s *TwoFactorService) Verify(
+	ctx context.Context,
+	userID string,
+	code string,
+	client model.ClientInfo,
+) error {
+	twoFactor, err := s.twoFactor.GetTwoFactor(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrTwoFactorNotEnabled
+		}
+
+		return fmt.Errorf("get two-factor enrollment: %w", err)
+	}
+
+	if !twoFactor.Enabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	now := time.Now()
+
+	if now.Before(twoFactorLockedUntil(twoFactor)) {
+		return ErrTwoFactorLocked
+	}
+
+	step, ok := newTOTPKey(twoFactor.Secret).Verify(code, now, twoFactorSkew)
+	if !ok || step <= twoFactor.LastUsedStep {
+		return s.fail(ctx, userID, client, now)
+	}
+
+	err = s.twoFactor.UseTwoFactorStep(ctx, userID, step)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) || errors.Is(err, repository.ErrNotFound) {
+			return s.fail(ctx, userID, client, now)
+		}
+
+		return fmt.Errorf("use two-factor code: %w", err)
+	}
+
+	return nil
+}
+
+// CheckCode checks the one-time code of a sensitive operation.
+//
+// Returns true if the user has not enabled two-factor authentication or the code is valid.
+// A missing code is not counted as a rejected one: it only asks the client for the code.
+// Returns ErrTwoFactorLocked if the check is locked after too many rejected codes.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the user;
+//   - code string: one-time code, may be empty;
+//   - client model.ClientInfo: client that presented the code.
+func (s *TwoFactorService) CheckCode(
+	ctx context.Context,
+	userID string,
+	code string,
+	client model.ClientInfo,
+) (bool, error) {
+	enabled, err := s.IsEnabled(ctx, userID)
+	if err != nil || !enabled {
+		return !enabled, err
+	}
+
+	if code == "" {
+		return false, nil
+	}
+
+	err = s.Verify(ctx, userID, code, client)
+	if err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) || errors.Is(err, ErrTwoFactorNotEnabled) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// fail records the rejected code in the enrollment and in the audit log.
+func (s *TwoFactorService) fail(
+	ctx context.Context,
+	userID string,
+	client model.ClientInfo,
+	failedAt time.Time,
+) error {
+	failures, err := s.twoFactor.FailTwoFactor(ctx, userID, failedAt)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidTwoFactorCode
+		}
+
+		return fmt.Errorf("record rejected two-factor code: %w", err)
+	}
+
+	details := map[string]string{"failures": strconv.Itoa(failures)}
+
+	if failures >= TwoFactorMaxFailures {
+		details["locked_until"] = failedAt.Add(TwoFactorLockout).UTC().Format(time.RFC3339)
+	}
+
+	err = s.audit.Record(ctx, userID, model.AuditEventTwoFactorFailed, client, details)
+	if err != nil {
+		return fmt.Errorf("record audit event: %w", err)
+	}
+
+	s.logger.Warn("Two-factor code rejected", nil, "user_id", userID, "failures", failures)
+
+	return ErrInvalidTwoFactorCode
+}
+
+// twoFactorLockedUntil returns the time the check of the codes is locked until,
+// zero if fewer codes than the limit have been rejected in a row.
+func twoFactorLockedUntil(twoFactor *model.TwoFactor) time.Time {
+	if twoFactor.FailedAttempts < TwoFactorMaxFailures {
+		return time.Time{}
+	}
+
+	return twoFactor.LastFailedAt.Add(TwoFactorLockout)
+}
+
+// newTOTPKey returns the key with the secret and the default parameters.
+func newTOTPKey(secret []byte) *totp.Key {
+	return &totp.Key{
+		Issuer:    twoFactorIssuer,
+		Account:   "",
+		Secret:    secret,
+		Algorithm: totp.DefaultAlgorithm,
+		Digits:    totp.DefaultDigits,
+		Period:    totp.DefaultPeriod,
+	}
+}
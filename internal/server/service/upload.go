@@ -0,0 +1,324 @@
+// Package service contains the business logic of the server application.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/server/model"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+)
+
+// Errors returned by the upload service.
+var (
+	// ErrUploadNotFound - the user has no such upload or it has expired.
+	ErrUploadNotFound = errors.New("upload not found")
+
+	// ErrInvalidUpload - the parameters of the upload do not meet the requirements.
+	ErrInvalidUpload = errors.New("invalid upload")
+
+	// ErrUploadTooLarge - the payload exceeds the maximum size or the declared length of the upload.
+	ErrUploadTooLarge = errors.New("upload too large")
+
+	// ErrUploadOffsetMismatch - the chunk does not start where the received content ends.
+	ErrUploadOffsetMismatch = errors.New("upload offset mismatch")
+
+	// ErrUploadIncomplete - the payload has not been received in full yet.
+	ErrUploadIncomplete = errors.New("upload incomplete")
+)
+
+// uploadPurgeInterval - interval between the deletions of expired uploads.
+const uploadPurgeInterval = 10 * time.Minute
+
+// UploadConfig describes the restrictions on resumable uploads.
+type UploadConfig struct {
+	MaxSize int64         // Maximum size of a payload in bytes.
+	TTL     time.Duration // Time an upload is kept after its last chunk.
+}
+
+// UploadService implements resumable uploads of large secret payloads sent in chunks.
+type UploadService struct {
+	uploads repository.UploadRepository
+	config  UploadConfig
+	logger  logging.Logger
+}
+
+// NewUploadService creates a new *UploadService instance.
+//
+// Parameters:
+//   - uploads repository.UploadRepository: storage of uploads;
+//   - config UploadConfig: restrictions on uploads;
+//   - logger logging.Logger: logger.
+func NewUploadService(
+	uploads repository.UploadRepository,
+	config UploadConfig,
+	logger logging.Logger,
+) *UploadService {
+	return &UploadService{
+		uploads: uploads,
+		config:  config,
+		logger:  logger,
+	}
+}
+
+// MaxSize returns the maximum size of a payload in bytes.
+func (s *UploadService) MaxSize() int64 {
+	return s.config.MaxSize
+}
+
+// Create starts a new upload of the payload of the given length.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - length int64: total size of the payload in bytes;
+//   - metadata map[string]string: metadata passed by the client.
+func (s *UploadService) Create(
+	ctx context.Context,
+	userID string,
+	length int64,
+	metadata map[string]string,
+) (*model.Upload, error) {
+	if length <= 0 {
+		return nil, fmt.Errorf("%w: length must be positive", ErrInvalidUpload)
+	}
+
+	if length > s.config.MaxSize {
+		return nil, fmt.Errorf("%w: at most %d bytes are allowed",
+			ErrUploadTooLarge, s.config.MaxSize)
+	}
+
+	err := validateMetadata(metadata, ErrInvalidUpload)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	upload := &model.Upload{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Length:    length,
+		Offset:    0,
+		Metadata:  metadata,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.config.TTL),
+	}
+
+	err = s.uploads.CreateUpload(ctx, upload)
+	if err != nil {
+		return nil, fmt.Errorf("create upload: %w", err)
+	}
+
+	s.logger.Debug("Upload created", "user_id", userID, "upload_id", upload.ID, "length", length)
+
+	return upload, nil
+}
+
+// Get returns the user's upload with the number of bytes received.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - uploadID string: upload identifier.
+func (s *UploadService) Get(
+	ctx context.Context,
+	userID string,
+	uploadID string,
+) (*model.Upload, error) {
+	upload, err := s.uploads.GetUpload(ctx, userID, uploadID)
+	if err != nil {
+		return nil, mapUploadError("get upload", err)
+	}
+
+	if !upload.ExpiresAt.After(time.Now()) {
+		return nil, ErrUploadNotFound
+	}
+
+	return upload, nil
+}
+
+// Append writes the chunk to the upload at the offset.
+//
+// The chunk is streamed to the storage. If it is interrupted, the bytes received
+// are kept and the client resumes from the offset returned by Get.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - uploadID string: upload identifier;
+//   - offset int64: position of the chunk, must be equal to the number of bytes received;
+//   - chunk io.Reader: content of the chunk.
+func (s *UploadService) Append(
+	ctx context.Context,
+	userID string,
+	uploadID string,
+	offset int64,
+	chunk io.Reader,
+) (*model.Upload, error) {
+	upload, err := s.Get(ctx, userID, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset != upload.Offset {
+		return nil, ErrUploadOffsetMismatch
+	}
+
+	expiresAt := time.Now().UTC().Add(s.config.TTL)
+	bounded := &boundedReader{source: chunk, remaining: upload.Length - offset}
+
+	written, err := s.uploads.WriteUpload(ctx, userID, uploadID, offset, bounded, expiresAt)
+	if err != nil {
+		s.logger.Debug("Upload chunk interrupted",
+			"user_id", userID, "upload_id", uploadID, "written", written, "error", err.Error())
+
+		return nil, mapUploadError("write upload", err)
+	}
+
+	upload.Offset += written
+	upload.ExpiresAt = expiresAt
+
+	if upload.IsComplete() {
+		s.logger.Info("Upload completed",
+			"user_id", userID, "upload_id", uploadID, "length", upload.Length)
+	}
+
+	return upload, nil
+}
+
+// Open returns the reader of the payload of the completed upload and its size.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - uploadID string: upload identifier.
+func (s *UploadService) Open(
+	ctx context.Context,
+	userID string,
+	uploadID string,
+) (io.ReadCloser, int64, error) {
+	upload, err := s.Get(ctx, userID, uploadID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if !upload.IsComplete() {
+		return nil, 0, ErrUploadIncomplete
+	}
+
+	content, err := s.uploads.OpenUpload(ctx, userID, uploadID)
+	if err != nil {
+		return nil, 0, mapUploadError("open upload", err)
+	}
+
+	return content, upload.Length, nil
+}
+
+// Terminate deletes the upload together with the bytes received.
+//
+// Parameters:
+//   - ctx context.Context: context;
+//   - userID string: identifier of the owner;
+//   - uploadID string: upload identifier.
+func (s *UploadService) Terminate(ctx context.Context, userID string, uploadID string) error {
+	err := s.uploads.DeleteUpload(ctx, userID, uploadID)
+	if err != nil {
+		return mapUploadError("delete upload", err)
+	}
+
+	s.logger.Debug("Upload terminated", "user_id", userID, "upload_id", uploadID)
+
+	return nil
+}
+
+// PurgeExpired deletes the abandoned uploads and returns their number.
+//
+// Parameters:
+//   - ctx context.Context: context.
+func (s *UploadService) PurgeExpired(ctx context.Context) (int, error) {
+	deleted, err := s.uploads.DeleteExpiredUploads(ctx, time.Now())
+	if err != nil {
+		return deleted, fmt.Errorf("delete expired uploads: %w", err)
+	}
+
+	if deleted > 0 {
+		s.logger.Info("Expired uploads deleted", "count", deleted)
+	}
+
+	return deleted, nil
+}
+
+// StartPurging deletes the abandoned uploads in the background until ctx is done.
+//
+// Parameters:
+//   - ctx context.Context: context of the application.
+func (s *UploadService) StartPurging(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(uploadPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_, err := s.PurgeExpired(ctx)
+				if err != nil {
+					s.logger.Error("Purging expired uploads error", err)
+				}
+			}
+		}
+	}()
+}
+
+// mapUploadError converts repository errors to the errors of the service.
+func mapUploadError(operation string, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return ErrUploadNotFound
+	case errors.Is(err, repository.ErrConflict):
+		return ErrUploadOffsetMismatch
+	case errors.Is(err, ErrUploadTooLarge):
+		return ErrUploadTooLarge
+	default:
+		return fmt.Errorf("%s: %w", operation, err)
+	}
+}
+
+// boundedReader passes at most the remaining bytes of the upload and fails
+// if the chunk goes beyond the declared length.
+type boundedReader struct {
+	source    io.Reader
+	remaining int64
+}
+
+// Read reads the next part of the chunk.
+//
+// Implements the io.Reader interface.
+func (r *boundedReader) Read(buf []byte) (int, error) {
+	if r.remaining <= 0 {
+		var probe [1]byte
+
+		n, err := r.source.Read(probe[:])
+		if n > 0 {
+			return 0, ErrUploadTooLarge
+		}
+
+		return 0, err //nolint:wrapcheck // io.EOF must be returned as is
+	}
+
+	if int64(len(buf)) > r.remaining {
+		buf = buf[:r.remaining]
+	}
+
+	n, err := r.source.Read(buf)
+	r.remaining -= int64(n)
+
+	return n, err //nolint:wrapcheck // io.EOF must be returned as is
+}
@@ -0,0 +1,304 @@
+// Package server provides general functionality for running a server application.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/atrest"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/caching/redis"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/server/config"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/encrypted"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/filesystem"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/memory"
+	redisrepo "github.com/mr-filatik/go-password-keeper/internal/server/repository/redis"
+	"github.com/mr-filatik/go-password-keeper/internal/server/repository/s3"
+	"github.com/mr-filatik/go-password-keeper/internal/server/service"
+)
+
+const cacherClientName = "server"
+
+// errUnknownBlobStorage - the configured kind of the blob storage is not supported.
+var errUnknownBlobStorage = errors.New("unknown blob storage")
+
+// secretRepository - storage of secrets with their trash counting the references of their revisions to blobs.
+type secretRepository interface {
+	repository.SecretRepository
+	repository.SecretTrash
+	repository.BlobReferenceStore
+}
+
+// startCacher creates and starts the redis cacher.
+//
+// Returns nil if the redis address is not configured.
+func startCacher(
+	ctx context.Context,
+	conf *config.Config,
+	logger logging.Logger,
+) (*redis.Cacher, error) {
+	if conf.RedisAddress == "" {
+		return nil, nil //nolint:nilnil // redis is optional
+	}
+
+	cacher := redis.NewCacher(redis.CacherConfig{
+		ClientName: cacherClientName,
+		Address:    conf.RedisAddress,
+		DBNumber:   conf.RedisDB,
+		Username:   "",
+		Password:   conf.RedisPassword,
+	}, logger)
+
+	err := cacher.Start(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("start cacher: %w", err)
+	}
+
+	return cacher, nil
+}
+
+// shutdownCacher closes the connection to redis if the cacher has been started.
+func shutdownCacher(ctx context.Context, cacher *redis.Cacher, logger logging.Logger) {
+	if cacher == nil {
+		return
+	}
+
+	err := cacher.Shutdown(ctx)
+	if err != nil {
+		logger.Error("Shutdown cacher error", err)
+	}
+}
+
+// newSessionStore creates the storage of sessions and refresh tokens.
+//
+// The state is kept in redis if it is available, otherwise in memory of the instance.
+//
+//nolint:ireturn // the implementation is selected by configuration
+func newSessionStore(cacher *redis.Cacher, logger logging.Logger) repository.SessionStore {
+	if cacher == nil {
+		logger.Warn("Redis address is not set, sessions are stored in memory", nil)
+
+		return memory.NewSessionStore()
+	}
+
+	return redisrepo.NewSessionStore(cacher)
+}
+
+// newEventBroker creates the delivery of the change events of the users' vaults.
+//
+// The events are fanned out through redis pub/sub to all instances if redis is available,
+// otherwise they reach only the clients connected to this instance.
+//
+//nolint:ireturn // the implementation is selected by configuration
+func newEventBroker(
+	ctx context.Context,
+	cacher *redis.Cacher,
+	logger logging.Logger,
+) (repository.EventBroker, error) {
+	if cacher == nil {
+		logger.Warn("Redis address is not set, vault events are delivered within the instance", nil)
+
+		return memory.NewEventBroker(), nil
+	}
+
+	broker := redisrepo.NewEventBroker(cacher, logger)
+
+	err := broker.Start(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("start event broker: %w", err)
+	}
+
+	return broker, nil
+}
+
+// newKeyProvider creates the provider of master keys for encryption at rest.
+//
+// The keyring file has priority over the environment variable.
+// Returns nil if no keys are configured.
+//
+//nolint:ireturn // the implementation is selected by configuration
+func newKeyProvider(conf *config.Config) (atrest.KeyProvider, error) {
+	if conf.EncryptionKeysFile != "" {
+		provider, err := atrest.NewFileKeyProvider(conf.EncryptionKeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("create file key provider: %w", err)
+		}
+
+		return provider, nil
+	}
+
+	if conf.EncryptionKeys == "" {
+		return nil, nil //nolint:nilnil // encryption at rest is optional
+	}
+
+	keys, err := atrest.ParseKeyring(conf.EncryptionKeys)
+	if err != nil {
+		return nil, fmt.Errorf("parse encryption keys: %w", err)
+	}
+
+	provider, err := atrest.NewStaticKeyProvider(keys)
+	if err != nil {
+		return nil, fmt.Errorf("create env key provider: %w", err)
+	}
+
+	return provider, nil
+}
+
+// newSecretStorage creates the underlying storage of secrets.
+//
+//nolint:ireturn // the implementation is selected by configuration
+func newSecretStorage() encrypted.SecretStorage {
+	return memory.NewSecretRepository()
+}
+
+// newSecretRepository creates the storage of secrets.
+//
+// The data is encrypted at rest if the master keys are configured.
+//
+//nolint:ireturn // the implementation is selected by configuration
+func newSecretRepository(keys atrest.KeyProvider, logger logging.Logger) secretRepository {
+	storage := newSecretStorage()
+
+	if keys == nil {
+		logger.Warn(
+			"Encryption keys are not set, secrets are stored without encryption at rest",
+			nil,
+		)
+
+		return storage
+	}
+
+	return encrypted.NewSecretRepository(storage, atrest.NewEncrypter(keys))
+}
+
+// newFolderStorage creates the underlying storage of folders.
+//
+//nolint:ireturn // the implementation is selected by configuration
+func newFolderStorage() encrypted.FolderStorage {
+	return memory.NewFolderRepository()
+}
+
+// newFolderRepository creates the storage of folders.
+//
+// The names are encrypted at rest if the master keys are configured.
+//
+//nolint:ireturn // the implementation is selected by configuration
+func newFolderRepository(keys atrest.KeyProvider) repository.FolderRepository {
+	storage := newFolderStorage()
+
+	if keys == nil {
+		return storage
+	}
+
+	return encrypted.NewFolderRepository(storage, atrest.NewEncrypter(keys))
+}
+
+// secretStores - storages of the users' vaults with the maintenance of their data.
+type secretStores struct {
+	secrets secretRepository
+	folders repository.FolderRepository
+	blobs   *service.BlobService
+
+	// keyRotation - rotation of the master key, nil if encryption at rest is disabled.
+	keyRotation *service.KeyRotationService
+}
+
+// startSecretStores creates the storages of secrets, of their folders and of their binary payloads
+// and starts the collection of the payloads no secret refers to.
+//
+// The references to the payloads are counted in the memory of the instance, so the payloads
+// are collected only in the storage owned by the instance (see collectsBlobs): collecting
+// a bucket shared by several instances would lose the payloads of the others.
+func startSecretStores(
+	ctx context.Context,
+	conf *config.Config,
+	logger logging.Logger,
+) (*secretStores, error) {
+	keys, err := newKeyProvider(conf)
+	if err != nil {
+		return nil, fmt.Errorf("create key provider: %w", err)
+	}
+
+	blobs, err := newBlobStore(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := newSecretRepository(keys, logger)
+	folders := newFolderRepository(keys)
+	blobService := service.NewBlobService(blobs, secrets, newBlobEncrypter(keys), logger)
+
+	if collectsBlobs(conf) {
+		blobService.StartCollecting(ctx)
+	} else {
+		logger.Warn("Blob storage may be shared by several instances, orphaned blobs are not collected, "+
+			"set the key prefix of the instance or mark the instance as the owner of the bucket",
+			nil, "blob_storage", conf.BlobStorage)
+	}
+
+	return &secretStores{
+		secrets:     secrets,
+		folders:     folders,
+		blobs:       blobService,
+		keyRotation: newKeyRotationService(keys, logger, secrets, folders),
+	}, nil
+}
+
+// collectsBlobs checks whether the blob storage belongs to the instance alone, so the instance
+// collects the orphaned blobs in it.
+//
+// The directory of the filesystem storage is local to the instance. The objects of the S3 storage
+// belong to the instance if they are kept under its own key prefix or the instance is configured
+// as the only one saving to the bucket.
+func collectsBlobs(conf *config.Config) bool {
+	if conf.BlobStorage == config.BlobStorageS3 {
+		return conf.S3Prefix != "" || conf.BlobCollect
+	}
+
+	return true
+}
+
+// newBlobEncrypter creates the encrypter of the payloads of binary secrets at rest.
+//
+// Returns nil if no master keys are configured, the payloads are stored as is then.
+func newBlobEncrypter(keys atrest.KeyProvider) *atrest.Encrypter {
+	if keys == nil {
+		return nil
+	}
+
+	return atrest.NewEncrypter(keys)
+}
+
+// newBlobStore creates the storage of the payloads of binary secrets.
+//
+//nolint:ireturn // the implementation is selected by configuration
+func newBlobStore(conf *config.Config) (repository.BlobStore, error) {
+	switch conf.BlobStorage {
+	case config.BlobStorageFilesystem:
+		blobs, err := filesystem.NewBlobStore(conf.BlobDir)
+		if err != nil {
+			return nil, fmt.Errorf("create filesystem blob store: %w", err)
+		}
+
+		return blobs, nil
+	case config.BlobStorageS3:
+		blobs, err := s3.NewBlobStore(s3.Config{
+			Endpoint:  conf.S3Endpoint,
+			Region:    conf.S3Region,
+			Bucket:    conf.S3Bucket,
+			Prefix:    conf.S3Prefix,
+			AccessKey: conf.S3AccessKey,
+			SecretKey: conf.S3SecretKey,
+		}, http.DefaultClient)
+		if err != nil {
+			return nil, fmt.Errorf("create s3 blob store: %w", err)
+		}
+
+		return blobs, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownBlobStorage, conf.BlobStorage)
+	}
+}
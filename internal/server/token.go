@@ -0,0 +1,62 @@
+// Package server provides general functionality for running a server application.
+package server
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+
+	"github.com/mr-filatik/go-password-keeper/internal/platform/logging"
+	"github.com/mr-filatik/go-password-keeper/internal/platform/token"
+	"github.com/mr-filatik/go-password-keeper/internal/server/config"
+)
+
+const (
+	tokenIssuer           = "go-password-keeper"
+	generatedSecretLength = 32
+)
+
+// newTokenManager creates an access token manager according to the configuration.
+//
+// The Ed25519 key has priority over the HMAC secret.
+// If neither is specified, a random HMAC secret is generated,
+// and the issued tokens become invalid after a restart.
+func newTokenManager(conf *config.Config, logger logging.Logger) (*token.Manager, error) {
+	if conf.TokenKeyFile != "" {
+		data, err := os.ReadFile(conf.TokenKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read token key file: %w", err)
+		}
+
+		privateKey, err := token.ParseEd25519PrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse token key file: %w", err)
+		}
+
+		manager, err := token.NewEd25519Manager(privateKey, tokenIssuer, conf.AccessTokenTTL)
+		if err != nil {
+			return nil, fmt.Errorf("create ed25519 token manager: %w", err)
+		}
+
+		return manager, nil
+	}
+
+	secret := []byte(conf.TokenSecret)
+	if len(secret) == 0 {
+		logger.Warn("Token secret is not set, a random one is generated", nil)
+
+		secret = make([]byte, generatedSecretLength)
+
+		_, err := rand.Read(secret)
+		if err != nil {
+			return nil, fmt.Errorf("generate token secret: %w", err)
+		}
+	}
+
+	manager, err := token.NewHMACManager(secret, tokenIssuer, conf.AccessTokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("create hmac token manager: %w", err)
+	}
+
+	return manager, nil
+}